@@ -47,51 +47,45 @@ const docTemplate = `{
                 }
             }
         },
-        "/login": {
-            "post": {
-                "description": "Authenticates a user using username and password, returns a JWT token if successful",
-                "consumes": [
-                    "application/json"
+        "/barcodes/{code}": {
+            "get": {
+                "security": [
+                    {
+                        "JwtAuth": []
+                    }
                 ],
+                "description": "Find the product, and the variant for barcodes of variants, a scanned barcode stands for and the quantity to sell. EAN-13 scale labels matching one of the barcode formats of the store settings are decoded: the product is found by the embedded item code, and the embedded weight in kg, or the embedded price, is returned. Lines of price labels are sent with the label as price_label, so that they are charged the printed price. Other barcodes are looked up in the barcodes of the products and sell the units of the barcode, such as 6 for the barcode of a pack of six. The check digit of EAN and UPC barcodes is validated.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "user"
+                    "products"
                 ],
-                "summary": "Authenticate a user",
+                "summary": "Resolve a scanned barcode",
                 "parameters": [
                     {
-                        "description": "User login object",
-                        "name": "user",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/models.LoginUser"
-                        }
+                        "type": "string",
+                        "description": "Scanned barcode",
+                        "name": "code",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "JWT Token",
+                        "description": "Successfully resolved barcode",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/models.BarcodeResolution"
                         }
                     },
                     "400": {
-                        "description": "Bad Request",
-                        "schema": {
-                            "type": "string"
-                        }
-                    },
-                    "401": {
-                        "description": "Unauthorized",
+                        "description": "wrong check digit",
                         "schema": {
                             "type": "string"
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "404": {
+                        "description": "no product for barcode",
                         "schema": {
                             "type": "string"
                         }
@@ -99,14 +93,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/order_lines": {
+        "/checkout": {
             "post": {
                 "security": [
                     {
                         "JwtAuth": []
                     }
                 ],
-                "description": "Create a new orderLine with the given input data",
+                "description": "Create an order and its lines from a cart in a single transaction, pricing them from the catalog, applying the current promotions and the given coupon code and decrementing product stock",
                 "consumes": [
                     "application/json"
                 ],
@@ -114,25 +108,31 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "orderLines"
+                    "checkout"
                 ],
-                "summary": "Create a new orderLine",
+                "summary": "Checkout a cart",
                 "parameters": [
                     {
-                        "description": "Create orderLine object",
+                        "description": "Cart to checkout",
                         "name": "input",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.CreateOrderLine"
+                            "$ref": "#/definitions/models.Checkout"
                         }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Key under which the response is replayed to retries",
+                        "name": "Idempotency-Key",
+                        "in": "header"
                     }
                 ],
                 "responses": {
                     "201": {
-                        "description": "Successfully created orderLine",
+                        "description": "Successfully created order",
                         "schema": {
-                            "$ref": "#/definitions/models.OrderLine"
+                            "$ref": "#/definitions/models.OrderDetail"
                         }
                     },
                     "400": {
@@ -146,56 +146,76 @@ const docTemplate = `{
                         "schema": {
                             "type": "string"
                         }
+                    },
+                    "404": {
+                        "description": "Product or coupon not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "Insufficient stock, no open register session or coupon cannot be redeemed",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "422": {
+                        "description": "Amounts out of range, mixed currencies or coupon does not apply",
+                        "schema": {
+                            "type": "string"
+                        }
                     }
                 }
             }
         },
-        "/order_lines/{id}": {
+        "/coupons": {
             "get": {
                 "security": [
                     {
                         "JwtAuth": []
                     }
                 ],
-                "description": "Get details of a orderLine by its ID",
+                "description": "Get the coupons, optionally only those of a promotion",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "orderLines"
+                    "coupons"
                 ],
-                "summary": "Find a orderLine by ID",
+                "summary": "Get coupons",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "OrderLine ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "type": "integer",
+                        "description": "Promotion ID",
+                        "name": "promotion_id",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Successfully retrieved orderLine",
+                        "description": "Successfully retrieved list of coupons",
                         "schema": {
-                            "$ref": "#/definitions/models.OrderLine"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Coupon"
+                            }
                         }
                     },
-                    "404": {
-                        "description": "OrderLine not found",
+                    "500": {
+                        "description": "Internal Server Error",
                         "schema": {
                             "type": "string"
                         }
                     }
                 }
             },
-            "put": {
+            "post": {
                 "security": [
                     {
                         "JwtAuth": []
                     }
                 ],
-                "description": "Update the orderLine details for the given ID",
+                "description": "Create a coupon with the given code, or generate count coupons with random codes. Single use coupons have a max_redemptions of 1.",
                 "consumes": [
                     "application/json"
                 ],
@@ -203,32 +223,28 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "orderLines"
+                    "coupons"
                 ],
-                "summary": "Update a orderLine by ID",
+                "summary": "Create coupons",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "OrderLine ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "description": "Update orderLine object",
+                        "description": "Coupons to create",
                         "name": "input",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.UpdateOrderLine"
+                            "$ref": "#/definitions/models.CreateCoupons"
                         }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "Successfully updated orderLine",
+                    "201": {
+                        "description": "Successfully created coupons",
                         "schema": {
-                            "$ref": "#/definitions/models.OrderLine"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Coupon"
+                            }
                         }
                     },
                     "400": {
@@ -238,7 +254,53 @@ const docTemplate = `{
                         }
                     },
                     "404": {
-                        "description": "orderLine not found",
+                        "description": "promotion not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "Coupon code already exists",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/coupons/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "JwtAuth": []
+                    }
+                ],
+                "description": "Get details of a coupon by its ID",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "coupons"
+                ],
+                "summary": "Find a coupon by ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Coupon ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Successfully retrieved coupon",
+                        "schema": {
+                            "$ref": "#/definitions/models.Coupon"
+                        }
+                    },
+                    "404": {
+                        "description": "coupon not found",
                         "schema": {
                             "type": "string"
                         }
@@ -251,18 +313,18 @@ const docTemplate = `{
                         "JwtAuth": []
                     }
                 ],
-                "description": "Delete the orderLine with the given ID",
+                "description": "Deactivate the coupon with the given ID so it can no longer be redeemed. It is kept for its past redemptions.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "orderLines"
+                    "coupons"
                 ],
-                "summary": "Delete a orderLine by ID",
+                "summary": "Deactivate a coupon by ID",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "OrderLine ID",
+                        "description": "Coupon ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -270,13 +332,13 @@ const docTemplate = `{
                 ],
                 "responses": {
                     "204": {
-                        "description": "Successfully deleted orderLine",
+                        "description": "Successfully deactivated coupon",
                         "schema": {
                             "type": "string"
                         }
                     },
                     "404": {
-                        "description": "orderLine not found",
+                        "description": "coupon not found",
                         "schema": {
                             "type": "string"
                         }
@@ -284,14 +346,68 @@ const docTemplate = `{
                 }
             }
         },
-        "/orders": {
+        "/customers": {
+            "get": {
+                "security": [
+                    {
+                        "JwtAuth": []
+                    }
+                ],
+                "description": "Get customers with pagination, optionally only those whose name, tax ID, email or phone contains q",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "customers"
+                ],
+                "summary": "Search customers",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Text to search for",
+                        "name": "q",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 0,
+                        "description": "Offset for pagination",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 10,
+                        "description": "Limit for pagination",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Successfully retrieved list of customers",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Customer"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
             "post": {
                 "security": [
                     {
                         "JwtAuth": []
                     }
                 ],
-                "description": "Create a new order with the given input data",
+                "description": "Create a customer orders can be linked to",
                 "consumes": [
                     "application/json"
                 ],
@@ -299,25 +415,25 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "orders"
+                    "customers"
                 ],
-                "summary": "Create a new order",
+                "summary": "Create a customer",
                 "parameters": [
                     {
-                        "description": "Create order object",
+                        "description": "Customer object",
                         "name": "input",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.CreateOrder"
+                            "$ref": "#/definitions/models.CreateCustomer"
                         }
                     }
                 ],
                 "responses": {
                     "201": {
-                        "description": "Successfully created order",
+                        "description": "Successfully created customer",
                         "schema": {
-                            "$ref": "#/definitions/models.Order"
+                            "$ref": "#/definitions/models.Customer"
                         }
                     },
                     "400": {
@@ -325,35 +441,29 @@ const docTemplate = `{
                         "schema": {
                             "type": "string"
                         }
-                    },
-                    "401": {
-                        "description": "Unauthorized",
-                        "schema": {
-                            "type": "string"
-                        }
                     }
                 }
             }
         },
-        "/orders/{id}": {
+        "/customers/{id}": {
             "get": {
                 "security": [
                     {
                         "JwtAuth": []
                     }
                 ],
-                "description": "Get details of an order by its ID",
+                "description": "Get details of a customer by its ID",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "orders"
+                    "customers"
                 ],
-                "summary": "Find an order by ID",
+                "summary": "Find a customer by ID",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Order ID",
+                        "description": "Customer ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -361,13 +471,13 @@ const docTemplate = `{
                 ],
                 "responses": {
                     "200": {
-                        "description": "Successfully retrieved order",
+                        "description": "Successfully retrieved customer",
                         "schema": {
-                            "$ref": "#/definitions/models.Order"
+                            "$ref": "#/definitions/models.Customer"
                         }
                     },
                     "404": {
-                        "description": "Order not found",
+                        "description": "customer not found",
                         "schema": {
                             "type": "string"
                         }
@@ -380,7 +490,7 @@ const docTemplate = `{
                         "JwtAuth": []
                     }
                 ],
-                "description": "Update the order details for the given ID",
+                "description": "Update the customer details for the given ID. Omitted fields are left unchanged.",
                 "consumes": [
                     "application/json"
                 ],
@@ -388,32 +498,32 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "orders"
+                    "customers"
                 ],
-                "summary": "Update an order by ID",
+                "summary": "Update a customer by ID",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Order ID",
+                        "description": "Customer ID",
                         "name": "id",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "Update order object",
+                        "description": "Update customer object",
                         "name": "input",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.UpdateOrder"
+                            "$ref": "#/definitions/models.UpdateCustomer"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Successfully updated order",
+                        "description": "Successfully updated customer",
                         "schema": {
-                            "$ref": "#/definitions/models.Order"
+                            "$ref": "#/definitions/models.Customer"
                         }
                     },
                     "400": {
@@ -423,7 +533,7 @@ const docTemplate = `{
                         }
                     },
                     "404": {
-                        "description": "order not found",
+                        "description": "customer not found",
                         "schema": {
                             "type": "string"
                         }
@@ -436,18 +546,18 @@ const docTemplate = `{
                         "JwtAuth": []
                     }
                 ],
-                "description": "Delete the order with the given ID",
+                "description": "Delete the customer with the given ID. Its orders keep the customer name but are no longer linked to it.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "orders"
+                    "customers"
                 ],
-                "summary": "Delete an order by ID",
+                "summary": "Delete a customer by ID",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Order ID",
+                        "description": "Customer ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -455,13 +565,13 @@ const docTemplate = `{
                 ],
                 "responses": {
                     "204": {
-                        "description": "Successfully deleted order",
+                        "description": "Successfully deleted customer",
                         "schema": {
                             "type": "string"
                         }
                     },
                     "404": {
-                        "description": "order not found",
+                        "description": "customer not found",
                         "schema": {
                             "type": "string"
                         }
@@ -469,22 +579,29 @@ const docTemplate = `{
                 }
             }
         },
-        "/products": {
+        "/customers/{id}/orders": {
             "get": {
                 "security": [
                     {
                         "JwtAuth": []
                     }
                 ],
-                "description": "Get a list of all products with optional pagination",
+                "description": "Get the orders of a customer with pagination, the most recent first",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "products"
+                    "customers"
                 ],
-                "summary": "Get all products with pagination",
+                "summary": "Get the purchase history of a customer",
                 "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Customer ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
                     {
                         "type": "integer",
                         "default": 0,
@@ -495,62 +612,70 @@ const docTemplate = `{
                     {
                         "type": "integer",
                         "default": 10,
-                        "description": "Limit for paginaCreateProducttion",
+                        "description": "Limit for pagination",
                         "name": "limit",
                         "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Successfully retrieved list of products",
+                        "description": "Successfully retrieved orders",
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/models.Product"
+                                "$ref": "#/definitions/models.Order"
                             }
                         }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "customer not found",
+                        "schema": {
+                            "type": "string"
+                        }
                     }
                 }
-            },
-            "post": {
+            }
+        },
+        "/fiscal/export": {
+            "get": {
                 "security": [
                     {
                         "JwtAuth": []
                     }
                 ],
-                "description": "Create new products with the given input data",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Export the fiscal records generated between two dates as a Verifactu XML submission",
                 "produces": [
-                    "application/json"
+                    "text/xml"
                 ],
                 "tags": [
-                    "products"
+                    "fiscal"
                 ],
-                "summary": "Create new products",
+                "summary": "Export fiscal records",
                 "parameters": [
                     {
-                        "description": "Create product object",
-                        "name": "input",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/models.CreateProducts"
-                            }
-                        }
+                        "type": "string",
+                        "description": "First day, as YYYY-MM-DD (defaults to today)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Last day, as YYYY-MM-DD, inclusive (defaults to from)",
+                        "name": "to",
+                        "in": "query"
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Successfully created product",
+                    "200": {
+                        "description": "Verifactu XML",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/models.Product"
-                            }
+                            "type": "string"
                         }
                     },
                     "400": {
@@ -559,8 +684,8 @@ const docTemplate = `{
                             "type": "string"
                         }
                     },
-                    "401": {
-                        "description": "Unauthorized",
+                    "500": {
+                        "description": "Internal Server Error",
                         "schema": {
                             "type": "string"
                         }
@@ -568,85 +693,140 @@ const docTemplate = `{
                 }
             }
         },
-        "/products/{id}": {
+        "/fiscal/verify": {
             "get": {
                 "security": [
                     {
                         "JwtAuth": []
                     }
                 ],
-                "description": "Get details of a product by its ID",
+                "description": "Walk the chain of fiscal records from the first one and report the records whose hash does not match their content or the previous record",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "products"
+                    "fiscal"
                 ],
-                "summary": "Find a product by ID",
-                "parameters": [
+                "summary": "Verify the fiscal record chain",
+                "responses": {
+                    "200": {
+                        "description": "Verification result",
+                        "schema": {
+                            "$ref": "#/definitions/models.ChainVerification"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/giftcards/expire": {
+            "post": {
+                "security": [
                     {
-                        "type": "string",
-                        "description": "Product ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "JwtAuth": []
                     }
                 ],
+                "description": "Take the balance left off every gift card past its expiry date, recording it in their history",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "giftcards"
+                ],
+                "summary": "Expire gift cards",
                 "responses": {
                     "200": {
-                        "description": "Successfully retrieved product",
+                        "description": "Expired gift cards",
                         "schema": {
-                            "$ref": "#/definitions/models.Product"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.GiftCard"
+                            }
                         }
                     },
-                    "404": {
-                        "description": "Product not found",
+                    "500": {
+                        "description": "Internal Server Error",
                         "schema": {
                             "type": "string"
                         }
                     }
                 }
-            },
-            "put": {
+            }
+        },
+        "/giftcards/{number}": {
+            "get": {
                 "security": [
                     {
                         "JwtAuth": []
                     }
                 ],
-                "description": "Update the product details for the given ID",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Get the balance of a gift card by its number, with the history of its balance",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "products"
+                    "giftcards"
                 ],
-                "summary": "Update a product by ID",
+                "summary": "Find a gift card by number",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Product ID",
-                        "name": "id",
+                        "description": "Gift card number",
+                        "name": "number",
                         "in": "path",
                         "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Successfully retrieved gift card",
+                        "schema": {
+                            "$ref": "#/definitions/models.GiftCard"
+                        }
                     },
+                    "404": {
+                        "description": "gift card not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/login": {
+            "post": {
+                "description": "Authenticates a user using username and password, returns a JWT token if successful",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "Authenticate a user",
+                "parameters": [
                     {
-                        "description": "Update product object",
-                        "name": "input",
+                        "description": "User login object",
+                        "name": "user",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.UpdateProduct"
+                            "$ref": "#/definitions/models.LoginUser"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Successfully updated product",
+                        "description": "JWT Token",
                         "schema": {
-                            "$ref": "#/definitions/models.Product"
+                            "type": "string"
                         }
                     },
                     "400": {
@@ -655,46 +835,54 @@ const docTemplate = `{
                             "type": "string"
                         }
                     },
-                    "404": {
-                        "description": "product not found",
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
                         "schema": {
                             "type": "string"
                         }
                     }
                 }
-            },
-            "delete": {
+            }
+        },
+        "/loyalty/accounts/{identifier}": {
+            "get": {
                 "security": [
                     {
                         "JwtAuth": []
                     }
                 ],
-                "description": "Delete the product with the given ID",
+                "description": "Get the points of a loyalty account by its card number or phone, with its points ledger",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "products"
+                    "loyalty"
                 ],
-                "summary": "Delete a product by ID",
+                "summary": "Find a loyalty account",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Product ID",
-                        "name": "id",
+                        "description": "Card number or phone",
+                        "name": "identifier",
                         "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
-                    "204": {
-                        "description": "Successfully deleted product",
+                    "200": {
+                        "description": "Successfully retrieved loyalty account",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/models.LoyaltyAccount"
                         }
                     },
                     "404": {
-                        "description": "product not found",
+                        "description": "loyalty account not found",
                         "schema": {
                             "type": "string"
                         }
@@ -702,47 +890,64 @@ const docTemplate = `{
                 }
             }
         },
-        "/register": {
+        "/loyalty/expire": {
             "post": {
                 "security": [
                     {
                         "JwtAuth": []
                     }
                 ],
-                "description": "Registers a new user with the given username and password",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Take the earned points left past their expiry date off every loyalty account, recording it in their ledger",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "user"
-                ],
-                "summary": "Register a new user",
-                "parameters": [
-                    {
-                        "description": "User registration object",
-                        "name": "user",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/models.LoginUser"
-                        }
-                    }
+                    "loyalty"
                 ],
+                "summary": "Expire loyalty points",
                 "responses": {
-                    "201": {
-                        "description": "Successfully registered",
+                    "200": {
+                        "description": "Expiry entries recorded",
                         "schema": {
-                            "type": "string"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.LoyaltyEntry"
+                            }
                         }
                     },
-                    "400": {
-                        "description": "Bad Request",
+                    "500": {
+                        "description": "Internal Server Error",
                         "schema": {
                             "type": "string"
                         }
+                    }
+                }
+            }
+        },
+        "/loyalty/rules": {
+            "get": {
+                "security": [
+                    {
+                        "JwtAuth": []
+                    }
+                ],
+                "description": "Get the multipliers of the points earned on products and categories",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "loyalty"
+                ],
+                "summary": "List the loyalty rules",
+                "responses": {
+                    "200": {
+                        "description": "Successfully retrieved loyalty rules",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.LoyaltyRule"
+                            }
+                        }
                     },
                     "500": {
                         "description": "Internal Server Error",
@@ -751,16 +956,14 @@ const docTemplate = `{
                         }
                     }
                 }
-            }
-        },
-        "/resetPassword": {
+            },
             "post": {
                 "security": [
                     {
                         "JwtAuth": []
                     }
                 ],
-                "description": "Resets a user password with username and password",
+                "description": "Multiply the points earned on a product or on the products of a category. A rule for a product takes precedence over one for its category.",
                 "consumes": [
                     "application/json"
                 ],
@@ -768,25 +971,25 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "user"
+                    "loyalty"
                 ],
-                "summary": "Reset user password",
+                "summary": "Create a loyalty rule",
                 "parameters": [
                     {
-                        "description": "User registration object",
-                        "name": "user",
+                        "description": "Loyalty rule object",
+                        "name": "input",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.LoginUser"
+                            "$ref": "#/definitions/models.CreateLoyaltyRule"
                         }
                     }
                 ],
                 "responses": {
-                    "202": {
-                        "description": "Successfully reset password",
+                    "201": {
+                        "description": "Successfully created loyalty rule",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/models.LoyaltyRule"
                         }
                     },
                     "400": {
@@ -795,206 +998,5101 @@ const docTemplate = `{
                             "type": "string"
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "404": {
+                        "description": "product not found",
                         "schema": {
                             "type": "string"
                         }
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "models.CreateOrder": {
-            "type": "object",
-            "required": [
-                "cashout_number",
-                "customer",
-                "lines_id",
-                "total"
-            ],
-            "properties": {
-                "cashout_number": {
-                    "type": "integer"
-                },
-                "customer": {
-                    "type": "string"
-                },
-                "lines_id": {
-                    "type": "array",
-                    "items": {
-                        "type": "integer"
-                    }
-                },
-                "total": {
-                    "description": "In cents, with VAT",
-                    "type": "integer"
-                }
-            }
         },
-        "models.CreateOrderLine": {
-            "type": "object",
-            "required": [
-                "price",
-                "product_id",
-                "quantity",
-                "total",
-                "vat"
-            ],
-            "properties": {
-                "price": {
-                    "description": "In Cents, with VAT",
-                    "type": "integer"
-                },
-                "product_id": {
-                    "type": "integer"
-                },
-                "quantity": {
-                    "description": "decimal.NewFromString(\"136.02\")",
-                    "type": "number"
-                },
-                "total": {
+        "/loyalty/rules/{id}": {
+            "delete": {
+                "security": [
+                    {
+                        "JwtAuth": []
+                    }
+                ],
+                "description": "Delete the loyalty rule with the given ID. Points already earned are kept.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "loyalty"
+                ],
+                "summary": "Delete a loyalty rule by ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Loyalty rule ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Successfully deleted loyalty rule",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "loyalty rule not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/order_lines": {
+            "post": {
+                "security": [
+                    {
+                        "JwtAuth": []
+                    }
+                ],
+                "description": "Create a new orderLine with the given input data. Price, VAT and total are taken from the product catalog.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "orderLines"
+                ],
+                "summary": "Create a new orderLine",
+                "parameters": [
+                    {
+                        "description": "Create orderLine object",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.CreateOrderLine"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Key under which the response is replayed to retries",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Successfully created orderLine",
+                        "schema": {
+                            "$ref": "#/definitions/models.OrderLine"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Product not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "422": {
+                        "description": "Amounts do not match catalog",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/order_lines/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "JwtAuth": []
+                    }
+                ],
+                "description": "Get details of a orderLine by its ID",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "orderLines"
+                ],
+                "summary": "Find a orderLine by ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "OrderLine ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Successfully retrieved orderLine",
+                        "schema": {
+                            "$ref": "#/definitions/models.OrderLine"
+                        }
+                    },
+                    "404": {
+                        "description": "OrderLine not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "JwtAuth": []
+                    }
+                ],
+                "description": "Update the orderLine details for the given ID. The current promotions are applied again to its order and the order total is updated. The stock checkout took for the line follows the change.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "orderLines"
+                ],
+                "summary": "Update a orderLine by ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "OrderLine ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Update orderLine object",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.UpdateOrderLine"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Successfully updated orderLine",
+                        "schema": {
+                            "$ref": "#/definitions/models.OrderLine"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "orderLine not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "Order can no longer be modified or insufficient stock",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "422": {
+                        "description": "Amounts do not match catalog",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "JwtAuth": []
+                    }
+                ],
+                "description": "Delete the orderLine with the given ID. The current promotions are applied again to its order and the order total is updated. The stock checkout took for the line is returned.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "orderLines"
+                ],
+                "summary": "Delete a orderLine by ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "OrderLine ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Successfully deleted orderLine",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "orderLine not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "Order can no longer be modified",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/orders": {
+            "get": {
+                "security": [
+                    {
+                        "JwtAuth": []
+                    }
+                ],
+                "description": "Get orders with pagination, optionally filtered. Dates are RFC 3339 timestamps or YYYY-MM-DD days, to being exclusive unless it is a day. Totals are in cents. Sort by id, created_at, total or invoice, prefixed with - for descending order.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "orders"
+                ],
+                "summary": "List orders",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "default": 0,
+                        "description": "Offset for pagination",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 10,
+                        "description": "Limit for pagination",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Created at or after",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Created before, or on that day",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Cashout number",
+                        "name": "cashout_number",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Customer ID",
+                        "name": "customer_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Text the customer name contains",
+                        "name": "customer",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Statuses separated by commas (ex: paid,refunded)",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Username of the cashier",
+                        "name": "cashier",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Minimum total",
+                        "name": "min_total",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maximum total",
+                        "name": "max_total",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Product sold by the order",
+                        "name": "product_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Barcode of a product sold by the order",
+                        "name": "barcode",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "default": "-created_at",
+                        "description": "Sort key",
+                        "name": "sort",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Successfully retrieved list of orders",
+                        "schema": {
+                            "$ref": "#/definitions/models.PaginatedOrderResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "JwtAuth": []
+                    }
+                ],
+                "description": "Create a new order with the given input data. The current promotions are applied to the order lines, as they are when the lines change later, and the total is computed from the discounted lines.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "orders"
+                ],
+                "summary": "Create a new order",
+                "parameters": [
+                    {
+                        "description": "Create order object",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.CreateOrder"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Key under which the response is replayed to retries",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Successfully created order",
+                        "schema": {
+                            "$ref": "#/definitions/models.OrderDetail"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Order line not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "No open register session for the cashout number",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "422": {
+                        "description": "Total does not match order lines",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/orders/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "JwtAuth": []
+                    }
+                ],
+                "description": "Get details of an order by its ID, with its lines. Send include=products to also get the products the lines refer to.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "orders"
+                ],
+                "summary": "Find an order by ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Order ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Set to products to embed the products of the lines",
+                        "name": "include",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Successfully retrieved order",
+                        "schema": {
+                            "$ref": "#/definitions/models.OrderDetail"
+                        }
+                    },
+                    "404": {
+                        "description": "Order not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "JwtAuth": []
+                    }
+                ],
+                "description": "Update the order details for the given ID. When lines_id is sent, it replaces the lines of the order and the lines left out are deleted, returning the stock their checkout took. The current promotions, and those of the coupons the order redeemed, are then applied again to its lines.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "orders"
+                ],
+                "summary": "Update an order by ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Order ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Update order object",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.UpdateOrder"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Successfully updated order",
+                        "schema": {
+                            "$ref": "#/definitions/models.OrderDetail"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "order not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "Order can no longer be modified",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "422": {
+                        "description": "Total does not match order lines",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "JwtAuth": []
+                    }
+                ],
+                "description": "Delete the order with the given ID. Only open and parked orders can be deleted. The stock taken by its checkout is returned.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "orders"
+                ],
+                "summary": "Delete an order by ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Order ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Successfully deleted order",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "order not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "Order can no longer be modified",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/orders/{id}/lines": {
+            "post": {
+                "security": [
+                    {
+                        "JwtAuth": []
+                    }
+                ],
+                "description": "Add a line to an open or parked order. Price, VAT and total are taken from the product catalog, the current promotions are applied again and the order total is updated.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "orders"
+                ],
+                "summary": "Add a line to an order",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Order ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Create orderLine object",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.CreateOrderLine"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Key under which the response is replayed to retries",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Successfully added order line",
+                        "schema": {
+                            "$ref": "#/definitions/models.OrderDetail"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Order or product not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "Order can no longer be modified",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "422": {
+                        "description": "Amounts do not match catalog",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/orders/{id}/lines/{line_id}": {
+            "put": {
+                "security": [
+                    {
+                        "JwtAuth": []
+                    }
+                ],
+                "description": "Update the product or quantity of a line of an open or parked order. The current promotions are applied again and the order total is updated. The stock checkout took for the line follows the change.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "orders"
+                ],
+                "summary": "Update a line of an order",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Order ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "OrderLine ID",
+                        "name": "line_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Update orderLine object",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.UpdateOrderLine"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Successfully updated order line",
+                        "schema": {
+                            "$ref": "#/definitions/models.OrderDetail"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Order, line or product not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "Order can no longer be modified or insufficient stock",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "422": {
+                        "description": "Amounts do not match catalog",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "JwtAuth": []
+                    }
+                ],
+                "description": "Delete a line of an open or parked order. The current promotions are applied again and the order total is updated. The stock checkout took for the line is returned.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "orders"
+                ],
+                "summary": "Remove a line from an order",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Order ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "OrderLine ID",
+                        "name": "line_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Successfully removed order line",
+                        "schema": {
+                            "$ref": "#/definitions/models.OrderDetail"
+                        }
+                    },
+                    "404": {
+                        "description": "Order or line not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "Order can no longer be modified",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/orders/{id}/park": {
+            "post": {
+                "security": [
+                    {
+                        "JwtAuth": []
+                    }
+                ],
+                "description": "Put an open order aside so it can be resumed later",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "orders"
+                ],
+                "summary": "Park an order",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Order ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Transition details",
+                        "name": "input",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/models.TransitionOrder"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Successfully parked order",
+                        "schema": {
+                            "$ref": "#/definitions/models.Order"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "order not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "Invalid status transition",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/orders/{id}/pay": {
+            "post": {
+                "security": [
+                    {
+                        "JwtAuth": []
+                    }
+                ],
+                "description": "Mark an open order as paid once its payments cover the total. The order is given the next number of the invoice series of its cashout number, gift cards it sells are issued and the loyalty account captured on it earns points.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "orders"
+                ],
+                "summary": "Pay an order",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Order ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Transition details",
+                        "name": "input",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/models.TransitionOrder"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Key under which the response is replayed to retries",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Successfully paid order",
+                        "schema": {
+                            "$ref": "#/definitions/models.Order"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "order not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "Invalid status transition or payments do not cover the total",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/orders/{id}/payments": {
+            "get": {
+                "security": [
+                    {
+                        "JwtAuth": []
+                    }
+                ],
+                "description": "Get the payments of an order, in the order they were added",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "orders"
+                ],
+                "summary": "List the payments of an order",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Order ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Successfully retrieved payments",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Payment"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "JwtAuth": []
+                    }
+                ],
+                "description": "Pay part or all of an open order. Several payments can be added to split the total between tenders. Cash tendered above what is due is given back as change. Gift cards are given by their number as reference and must have enough balance left. Loyalty points are taken from the account captured on the order unless another card number or phone is given as reference.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "orders"
+                ],
+                "summary": "Add a payment to an order",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Order ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Payment object",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.CreatePayment"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Key under which the response is replayed to retries",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Successfully added payment",
+                        "schema": {
+                            "$ref": "#/definitions/models.Payment"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "order, gift card or loyalty account not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "Order is not open or already paid, gift card expired or points cannot be redeemed",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "422": {
+                        "description": "Payment exceeds amount due, gift card balance or loyalty points",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/orders/{id}/payments/{payment_id}": {
+            "delete": {
+                "security": [
+                    {
+                        "JwtAuth": []
+                    }
+                ],
+                "description": "Remove a payment from an order that is still open. Gift card payments are credited back to the card and redeemed loyalty points to their account.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "orders"
+                ],
+                "summary": "Remove a payment from an order",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Order ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Payment ID",
+                        "name": "payment_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Successfully deleted payment",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "payment not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "Order is not open",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/orders/{id}/receipt": {
+            "get": {
+                "security": [
+                    {
+                        "JwtAuth": []
+                    }
+                ],
+                "description": "Render the receipt of an order with the store settings, as plain text or as an ESC/POS byte stream for thermal printers",
+                "produces": [
+                    "text/plain",
+                    "application/octet-stream"
+                ],
+                "tags": [
+                    "orders"
+                ],
+                "summary": "Print the receipt of an order",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Order ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "text (default) or escpos",
+                        "name": "format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Receipt",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "order not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/orders/{id}/refunds": {
+            "post": {
+                "security": [
+                    {
+                        "JwtAuth": []
+                    }
+                ],
+                "description": "Create a refund document for some or all lines of a paid order. Quantities cannot exceed what was sold minus what was already refunded. Refunded products can be restocked per line. Coupons redeemed by a fully refunded order can be redeemed again. Refunds to a gift card are credited to its balance. Loyalty points earned by the order are taken back in proportion to the amount refunded.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "orders"
+                ],
+                "summary": "Refund an order",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Order ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Refund object",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.CreateRefund"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Successfully created refund",
+                        "schema": {
+                            "$ref": "#/definitions/models.OrderDetail"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "order or gift card not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "Order cannot be refunded or no open register session",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "422": {
+                        "description": "Refund exceeds quantity sold",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/orders/{id}/resume": {
+            "post": {
+                "security": [
+                    {
+                        "JwtAuth": []
+                    }
+                ],
+                "description": "Reopen a parked order",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "orders"
+                ],
+                "summary": "Resume a parked order",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Order ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Transition details",
+                        "name": "input",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/models.TransitionOrder"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Successfully resumed order",
+                        "schema": {
+                            "$ref": "#/definitions/models.Order"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "order not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "Invalid status transition",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/orders/{id}/void": {
+            "post": {
+                "security": [
+                    {
+                        "JwtAuth": []
+                    }
+                ],
+                "description": "Void an open, parked or paid order. A reason is required. Voiding a paid order appends a cancellation record to the fiscal chain and takes back the loyalty points it earned. Voiding an unpaid order returns the stock its checkout took. Gift cards and loyalty points the order was paid with are credited back and gift cards it sold are voided. Paid orders partly refunded cannot be voided. Coupons redeemed by the order can be redeemed again.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "orders"
+                ],
+                "summary": "Void an order",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Order ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Transition details",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.TransitionOrder"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Successfully voided order",
+                        "schema": {
+                            "$ref": "#/definitions/models.Order"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "order not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "Invalid status transition",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/products": {
+            "get": {
+                "security": [
+                    {
+                        "JwtAuth": []
+                    }
+                ],
+                "description": "Get a list of all products with optional pagination. With variants=true, products sold by variant come with their variants.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "products"
+                ],
+                "summary": "Get all products with pagination",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "default": 0,
+                        "description": "Offset for pagination",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 10,
+                        "description": "Limit for paginaCreateProducttion",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "default": false,
+                        "description": "Nest the variants of products sold by variant",
+                        "name": "variants",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Successfully retrieved list of products",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Product"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "JwtAuth": []
+                    }
+                ],
+                "description": "Create new products with the given input data. barcode_number and barcodes are the barcodes the products are scanned with: each belongs to a single product, and the check digit of EAN and UPC barcodes is validated. Products with variant_attributes are sold by variant: their variants are added with /products/{id}/variants.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "products"
+                ],
+                "summary": "Create new products",
+                "parameters": [
+                    {
+                        "description": "Create product object",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.CreateProducts"
+                            }
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Key under which the response is replayed to retries",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Successfully created product",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Product"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "tax category not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "barcode or item code belongs to another product",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/products/barcode/{code}": {
+            "get": {
+                "security": [
+                    {
+                        "JwtAuth": []
+                    }
+                ],
+                "description": "Get the product one of whose barcodes is code, with all its barcodes. The check digit of EAN and UPC barcodes is validated. Lookups are cached.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "products"
+                ],
+                "summary": "Find a product by barcode",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Barcode",
+                        "name": "code",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Successfully retrieved product",
+                        "schema": {
+                            "$ref": "#/definitions/models.Product"
+                        }
+                    },
+                    "400": {
+                        "description": "wrong check digit",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "no product for barcode",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/products/changes": {
+            "get": {
+                "security": [
+                    {
+                        "JwtAuth": []
+                    }
+                ],
+                "description": "Get the products created, updated or deleted after the cursor, oldest change first, so a terminal can keep a local catalog in sync. Deleted products are returned as tombstones with deleted_at set. Start with since=0 and send the returned cursor on the next call; while has_more is true further changes may be waiting. Products changed together share a revision and are returned in the same call, which can then hold more than limit products. Changes are returned once every change with a lower revision is committed. Products sold by variant come with their variants, and adding, updating or deleting a variant changes its product.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "products"
+                ],
+                "summary": "Get the products changed since a cursor",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "default": 0,
+                        "description": "Cursor returned by the previous call",
+                        "name": "since",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 100,
+                        "description": "Maximum number of products",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Successfully retrieved changed products",
+                        "schema": {
+                            "$ref": "#/definitions/models.ProductChanges"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/products/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "JwtAuth": []
+                    }
+                ],
+                "description": "Get details of a product by its ID",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "products"
+                ],
+                "summary": "Find a product by ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Product ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Successfully retrieved product",
+                        "schema": {
+                            "$ref": "#/definitions/models.Product"
+                        }
+                    },
+                    "404": {
+                        "description": "Product not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "JwtAuth": []
+                    }
+                ],
+                "description": "Update the product details for the given ID. barcodes, when set, replace the barcodes of the product other than barcode_number.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "products"
+                ],
+                "summary": "Update a product by ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Product ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Update product object",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.UpdateProduct"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Successfully updated product",
+                        "schema": {
+                            "$ref": "#/definitions/models.Product"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "product or tax category not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "barcode or item code belongs to another product",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "JwtAuth": []
+                    }
+                ],
+                "description": "Delete the product with the given ID. The product is kept as a tombstone so that terminals learn about the deletion from /products/changes, and past orders still show it. Its barcodes are freed for other products.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "products"
+                ],
+                "summary": "Delete a product by ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Product ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Successfully deleted product",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "product not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/products/{id}/variants": {
+            "post": {
+                "security": [
+                    {
+                        "JwtAuth": []
+                    }
+                ],
+                "description": "Add a variant to a product sold by variant, with a value for each of the variant attributes of the product. The variant has its own SKU, barcode and stock, and sells at the price of the product unless it sets its own.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "products"
+                ],
+                "summary": "Add a variant to a product",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Product ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Product variant object",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.CreateProductVariant"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Successfully created product variant",
+                        "schema": {
+                            "$ref": "#/definitions/models.ProductVariant"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "product not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "SKU, options or barcode already taken",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "422": {
+                        "description": "Options do not match the variant attributes of the product",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/products/{id}/variants/{variant_id}": {
+            "put": {
+                "security": [
+                    {
+                        "JwtAuth": []
+                    }
+                ],
+                "description": "Update the SKU, options, barcode, price or stock of a variant. Stock can be set to zero, and clear_price sells the variant at the price of its product again.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "products"
+                ],
+                "summary": "Update a variant of a product",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Product ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Product variant ID",
+                        "name": "variant_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Update product variant object",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.UpdateProductVariant"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Successfully updated product variant",
+                        "schema": {
+                            "$ref": "#/definitions/models.ProductVariant"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "product or variant not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "SKU, options or barcode already taken",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "422": {
+                        "description": "Options do not match the variant attributes of the product",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "JwtAuth": []
+                    }
+                ],
+                "description": "Delete a variant of a product. Past order lines keep referring to it; its barcode is freed for other products.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "products"
+                ],
+                "summary": "Delete a variant of a product",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Product ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Product variant ID",
+                        "name": "variant_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Successfully deleted product variant",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "product or variant not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/promotions": {
+            "get": {
+                "security": [
+                    {
+                        "JwtAuth": []
+                    }
+                ],
+                "description": "Get every promotion, the ones with the highest priority first",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "promotions"
+                ],
+                "summary": "Get all promotions",
+                "responses": {
+                    "200": {
+                        "description": "Successfully retrieved list of promotions",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Promotion"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "JwtAuth": []
+                    }
+                ],
+                "description": "Create a discount rule applied at checkout while it is active and within its validity window",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "promotions"
+                ],
+                "summary": "Create a promotion",
+                "parameters": [
+                    {
+                        "description": "Promotion object",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.CreatePromotion"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Successfully created promotion",
+                        "schema": {
+                            "$ref": "#/definitions/models.Promotion"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "422": {
+                        "description": "Invalid promotion",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/promotions/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "JwtAuth": []
+                    }
+                ],
+                "description": "Get details of a promotion by its ID",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "promotions"
+                ],
+                "summary": "Find a promotion by ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Promotion ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Successfully retrieved promotion",
+                        "schema": {
+                            "$ref": "#/definitions/models.Promotion"
+                        }
+                    },
+                    "404": {
+                        "description": "promotion not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "JwtAuth": []
+                    }
+                ],
+                "description": "Update the given fields of a promotion. Omitted fields are left unchanged.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "promotions"
+                ],
+                "summary": "Update a promotion by ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Promotion ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Update promotion object",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.UpdatePromotion"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Successfully updated promotion",
+                        "schema": {
+                            "$ref": "#/definitions/models.Promotion"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "promotion not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "422": {
+                        "description": "Invalid promotion",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "JwtAuth": []
+                    }
+                ],
+                "description": "Delete the promotion with the given ID. Discounts it already gave are kept on their orders.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "promotions"
+                ],
+                "summary": "Delete a promotion by ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Promotion ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Successfully deleted promotion",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "promotion not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/register": {
+            "post": {
+                "security": [
+                    {
+                        "JwtAuth": []
+                    }
+                ],
+                "description": "Registers a new user with the given username and password",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "Register a new user",
+                "parameters": [
+                    {
+                        "description": "User registration object",
+                        "name": "user",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.LoginUser"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Successfully registered",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/registers/sessions": {
+            "post": {
+                "security": [
+                    {
+                        "JwtAuth": []
+                    }
+                ],
+                "description": "Open the cash drawer of a cashout number with an opening float. Orders can only be created for cashout numbers with an open session.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "registers"
+                ],
+                "summary": "Open a register session",
+                "parameters": [
+                    {
+                        "description": "Open session object",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.OpenSession"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Successfully opened session",
+                        "schema": {
+                            "$ref": "#/definitions/models.RegisterSession"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "A session is already open",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/registers/sessions/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "JwtAuth": []
+                    }
+                ],
+                "description": "Get a register session with its cash movements and, once closed, its counts",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "registers"
+                ],
+                "summary": "Find a register session by ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Session ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Successfully retrieved session",
+                        "schema": {
+                            "$ref": "#/definitions/models.RegisterSession"
+                        }
+                    },
+                    "404": {
+                        "description": "register session not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/registers/sessions/{id}/close": {
+            "post": {
+                "security": [
+                    {
+                        "JwtAuth": []
+                    }
+                ],
+                "description": "Close the drawer with the cash counted per denomination. The expected cash is the opening float plus pay-ins, minus pay-outs, plus cash payments of the session orders; the variance is counted minus expected.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "registers"
+                ],
+                "summary": "Close a register session",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Session ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Counted cash",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.CloseSession"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Successfully closed session",
+                        "schema": {
+                            "$ref": "#/definitions/models.RegisterSession"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "register session not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "Session is closed",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/registers/sessions/{id}/movements": {
+            "post": {
+                "security": [
+                    {
+                        "JwtAuth": []
+                    }
+                ],
+                "description": "Record cash put into or taken out of the drawer of an open session outside of a sale",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "registers"
+                ],
+                "summary": "Record a pay-in or pay-out",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Session ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Cash movement object",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.CreateCashMovement"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Successfully recorded movement",
+                        "schema": {
+                            "$ref": "#/definitions/models.CashMovement"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "register session not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "Session is closed",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/registers/{number}/x-report": {
+            "get": {
+                "security": [
+                    {
+                        "JwtAuth": []
+                    }
+                ],
+                "description": "Mid-shift snapshot of the orders of a cashout number since its last Z report. Nothing is closed. Use format=text for a printable layout.",
+                "produces": [
+                    "application/json",
+                    "text/plain"
+                ],
+                "tags": [
+                    "registers"
+                ],
+                "summary": "X report of a register",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Cashout number",
+                        "name": "number",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "json (default) or text",
+                        "name": "format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Successfully built report",
+                        "schema": {
+                            "$ref": "#/definitions/models.RegisterReport"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/registers/{number}/z-report": {
+            "post": {
+                "security": [
+                    {
+                        "JwtAuth": []
+                    }
+                ],
+                "description": "End-of-day close of the paid, refunded and voided orders of a cashout number since its last Z report. Z reports are numbered sequentially per cashout number and the orders they cover can no longer be changed. Use format=text for a printable layout.",
+                "produces": [
+                    "application/json",
+                    "text/plain"
+                ],
+                "tags": [
+                    "registers"
+                ],
+                "summary": "Z report of a register",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Cashout number",
+                        "name": "number",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "json (default) or text",
+                        "name": "format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Successfully closed the day",
+                        "schema": {
+                            "$ref": "#/definitions/models.RegisterReport"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "Orders were closed concurrently",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/reports/sales": {
+            "get": {
+                "security": [
+                    {
+                        "JwtAuth": []
+                    }
+                ],
+                "description": "Summarize paid orders between two dates, netting refunds against sales",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "reports"
+                ],
+                "summary": "Sales report",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "First day, as YYYY-MM-DD (defaults to today)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Last day, as YYYY-MM-DD, inclusive (defaults to from)",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Only orders of this cashout",
+                        "name": "cashout_number",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Successfully built report",
+                        "schema": {
+                            "$ref": "#/definitions/models.SalesSummary"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/resetPassword": {
+            "post": {
+                "security": [
+                    {
+                        "JwtAuth": []
+                    }
+                ],
+                "description": "Resets a user password with username and password",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "Reset user password",
+                "parameters": [
+                    {
+                        "description": "User registration object",
+                        "name": "user",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.LoginUser"
+                        }
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Successfully reset password",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/store": {
+            "get": {
+                "security": [
+                    {
+                        "JwtAuth": []
+                    }
+                ],
+                "description": "Get the store details and receipt layout. Defaults are returned until the store is configured.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "store"
+                ],
+                "summary": "Get the store settings",
+                "responses": {
+                    "200": {
+                        "description": "Successfully retrieved settings",
+                        "schema": {
+                            "$ref": "#/definitions/models.StoreSettings"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "JwtAuth": []
+                    }
+                ],
+                "description": "Update the store details and receipt layout. Omitted fields are left unchanged.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "store"
+                ],
+                "summary": "Update the store settings",
+                "parameters": [
+                    {
+                        "description": "Store settings",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.UpdateStoreSettings"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Successfully updated settings",
+                        "schema": {
+                            "$ref": "#/definitions/models.StoreSettings"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "422": {
+                        "description": "invalid barcode format",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/sync/orders": {
+            "post": {
+                "security": [
+                    {
+                        "JwtAuth": []
+                    }
+                ],
+                "description": "Record a batch of orders a terminal sold while it could not reach the API, in the given order. Each order is applied on its own: its lines are priced from the catalog with the promotions current at its local time, stock is decremented and its payments are recorded, paying it when they cover its total. Orders already synced, including by a concurrent sync, are reported as duplicates. Orders that cannot be recorded, for example because a product was deleted or is out of stock, are reported as rejected with the reason, without undoing the others. Register sessions are not checked, as they may have been closed in the meantime.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "sync"
+                ],
+                "summary": "Sync orders sold offline",
+                "parameters": [
+                    {
+                        "description": "Orders sold offline",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.SyncOrders"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "What became of each order",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.SyncResult"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/tax_categories": {
+            "get": {
+                "security": [
+                    {
+                        "JwtAuth": []
+                    }
+                ],
+                "description": "Get every tax category with its rates, oldest rate first",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "taxes"
+                ],
+                "summary": "Get all tax categories",
+                "responses": {
+                    "200": {
+                        "description": "Successfully retrieved list of tax categories",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.TaxCategory"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "JwtAuth": []
+                    }
+                ],
+                "description": "Create a tax category with its rate schedule. Products of the category are taxed at the rate in effect on the day they are sold.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "taxes"
+                ],
+                "summary": "Create a tax category",
+                "parameters": [
+                    {
+                        "description": "Tax category object",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.CreateTaxCategory"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Successfully created tax category",
+                        "schema": {
+                            "$ref": "#/definitions/models.TaxCategory"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "tax category already exists",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/tax_categories/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "JwtAuth": []
+                    }
+                ],
+                "description": "Get a tax category with its rates, oldest rate first",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "taxes"
+                ],
+                "summary": "Find a tax category by ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Tax category ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Successfully retrieved tax category",
+                        "schema": {
+                            "$ref": "#/definitions/models.TaxCategory"
+                        }
+                    },
+                    "404": {
+                        "description": "tax category not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/tax_categories/{id}/rates": {
+            "post": {
+                "security": [
+                    {
+                        "JwtAuth": []
+                    }
+                ],
+                "description": "Add a rate to a tax category, taking over from the current one at valid_from. Sales already made keep the rate they were made at, so rates cannot take effect in the past.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "taxes"
+                ],
+                "summary": "Schedule a rate change of a tax category",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Tax category ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Tax rate object",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.CreateTaxRate"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Successfully added tax rate",
+                        "schema": {
+                            "$ref": "#/definitions/models.TaxRate"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "tax category not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "422": {
+                        "description": "rates cannot take effect in the past",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "models.BarcodeFormat": {
+            "type": "object",
+            "required": [
+                "item_digits",
+                "prefix",
+                "value",
+                "value_digits"
+            ],
+            "properties": {
+                "decimals": {
+                    "description": "Of the value (ex: 3 for weights in grams, 2 for prices in cents)",
+                    "type": "integer",
+                    "maximum": 3
+                },
+                "item_digits": {
+                    "type": "integer",
+                    "maximum": 10,
+                    "minimum": 1
+                },
+                "prefix": {
+                    "description": "(ex: 21)",
+                    "type": "string",
+                    "maxLength": 3,
+                    "minLength": 1
+                },
+                "value": {
+                    "enum": [
+                        "weight",
+                        "price"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.BarcodeValue"
+                        }
+                    ]
+                },
+                "value_digits": {
+                    "type": "integer",
+                    "maximum": 10,
+                    "minimum": 1
+                }
+            }
+        },
+        "models.BarcodeResolution": {
+            "type": "object",
+            "properties": {
+                "barcode": {
+                    "type": "string"
+                },
+                "format": {
+                    "description": "Set on scale labels",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.BarcodeFormat"
+                        }
+                    ]
+                },
+                "price": {
+                    "description": "In cents, set on price labels",
+                    "type": "integer"
+                },
+                "product": {
+                    "$ref": "#/definitions/models.Product"
+                },
+                "quantity": {
+                    "type": "number"
+                },
+                "variant": {
+                    "description": "Set on the barcodes of variants",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.ProductVariant"
+                        }
+                    ]
+                }
+            }
+        },
+        "models.BarcodeValue": {
+            "type": "string",
+            "enum": [
+                "weight",
+                "price"
+            ],
+            "x-enum-comments": {
+                "BarcodePrice": "Price of the label, with VAT",
+                "BarcodeWeight": "In kg"
+            },
+            "x-enum-varnames": [
+                "BarcodeWeight",
+                "BarcodePrice"
+            ]
+        },
+        "models.CashMovement": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "description": "In cents, always positive",
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "kind": {
+                    "$ref": "#/definitions/models.MovementKind"
+                },
+                "reason": {
+                    "type": "string"
+                },
+                "session_id": {
+                    "type": "integer"
+                },
+                "username": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.ChainBreak": {
+            "type": "object",
+            "properties": {
+                "reason": {
+                    "type": "string"
+                },
+                "record_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.ChainVerification": {
+            "type": "object",
+            "properties": {
+                "breaks": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ChainBreak"
+                    }
+                },
+                "records": {
+                    "type": "integer"
+                },
+                "valid": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "models.Checkout": {
+            "type": "object",
+            "required": [
+                "cashout_number",
+                "lines"
+            ],
+            "properties": {
+                "cashout_number": {
+                    "type": "integer"
+                },
+                "coupon_code": {
+                    "type": "string",
+                    "maxLength": 40
+                },
+                "customer_id": {
+                    "type": "integer"
+                },
+                "customer_name": {
+                    "description": "Defaults to the name of the customer",
+                    "type": "string"
+                },
+                "lines": {
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "$ref": "#/definitions/models.CheckoutLine"
+                    }
+                },
+                "loyalty_id": {
+                    "description": "Card number or phone of the account earning points",
+                    "type": "string",
+                    "maxLength": 32
+                }
+            }
+        },
+        "models.CheckoutLine": {
+            "type": "object",
+            "required": [
+                "product_id",
+                "quantity"
+            ],
+            "properties": {
+                "price_label": {
+                    "description": "Price label the line was scanned from, whose printed price is charged",
+                    "type": "string",
+                    "maxLength": 32
+                },
+                "product_id": {
+                    "type": "integer"
+                },
+                "quantity": {
+                    "description": "decimal.NewFromString(\"136.02\")",
+                    "type": "number"
+                },
+                "variant_id": {
+                    "description": "Required for products sold by variant",
+                    "type": "integer"
+                }
+            }
+        },
+        "models.CloseSession": {
+            "type": "object",
+            "required": [
+                "counts"
+            ],
+            "properties": {
+                "counts": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.CountDenomination"
+                    }
+                }
+            }
+        },
+        "models.CountDenomination": {
+            "type": "object",
+            "required": [
+                "denomination"
+            ],
+            "properties": {
+                "denomination": {
+                    "description": "In cents",
+                    "type": "integer",
+                    "maximum": 99999999999,
+                    "minimum": 1
+                },
+                "quantity": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.Coupon": {
+            "type": "object",
+            "properties": {
+                "active": {
+                    "type": "boolean"
+                },
+                "code": {
+                    "description": "Upper case",
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "max_redemptions": {
+                    "description": "0 for unlimited, 1 for single use",
+                    "type": "integer"
+                },
+                "per_customer_limit": {
+                    "description": "0 for unlimited",
+                    "type": "integer"
+                },
+                "promotion_id": {
+                    "type": "integer"
+                },
+                "redemptions": {
+                    "description": "Not reversed",
+                    "type": "integer"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.CreateCashMovement": {
+            "type": "object",
+            "required": [
+                "amount",
+                "kind",
+                "reason"
+            ],
+            "properties": {
+                "amount": {
+                    "description": "In cents",
+                    "type": "integer",
+                    "maximum": 99999999999,
+                    "minimum": 1
+                },
+                "kind": {
+                    "enum": [
+                        "pay_in",
+                        "pay_out"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.MovementKind"
+                        }
+                    ]
+                },
+                "reason": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.CreateCoupons": {
+            "type": "object",
+            "required": [
+                "promotion_id"
+            ],
+            "properties": {
+                "code": {
+                    "type": "string",
+                    "maxLength": 40
+                },
+                "count": {
+                    "description": "Codes to generate, defaults to 1",
+                    "type": "integer",
+                    "maximum": 1000
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "max_redemptions": {
+                    "description": "0 for unlimited, 1 for single use",
+                    "type": "integer"
+                },
+                "per_customer_limit": {
+                    "description": "0 for unlimited",
+                    "type": "integer"
+                },
+                "prefix": {
+                    "type": "string",
+                    "maxLength": 8
+                },
+                "promotion_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.CreateCustomer": {
+            "type": "object",
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "address": {
+                    "type": "string",
+                    "maxLength": 256
+                },
+                "email": {
+                    "type": "string",
+                    "maxLength": 254
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 128
+                },
+                "phone": {
+                    "type": "string",
+                    "maxLength": 32
+                },
+                "tax_id": {
+                    "type": "string",
+                    "maxLength": 32
+                }
+            }
+        },
+        "models.CreateLoyaltyRule": {
+            "type": "object",
+            "required": [
+                "multiplier"
+            ],
+            "properties": {
+                "category": {
+                    "type": "string",
+                    "maxLength": 64
+                },
+                "multiplier": {
+                    "description": "In basis points",
+                    "type": "integer",
+                    "maximum": 100000
+                },
+                "product_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.CreateOrder": {
+            "type": "object",
+            "required": [
+                "cashout_number",
+                "lines_id"
+            ],
+            "properties": {
+                "cashout_number": {
+                    "type": "integer"
+                },
+                "customer": {
+                    "description": "Former name of customer_name, still accepted",
+                    "type": "string"
+                },
+                "customer_id": {
+                    "type": "integer"
+                },
+                "customer_name": {
+                    "description": "Defaults to the name of the customer",
+                    "type": "string"
+                },
+                "lines_id": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "loyalty_id": {
+                    "type": "string",
+                    "maxLength": 32
+                },
+                "total": {
+                    "description": "In cents, with VAT",
+                    "type": "integer",
+                    "maximum": 99999999999,
+                    "minimum": 0
+                }
+            }
+        },
+        "models.CreateOrderLine": {
+            "type": "object",
+            "required": [
+                "product_id",
+                "quantity"
+            ],
+            "properties": {
+                "price": {
+                    "description": "In Cents, with VAT",
+                    "type": "integer",
+                    "maximum": 99999999999,
+                    "minimum": 0
+                },
+                "price_label": {
+                    "description": "Price label the line was scanned from, whose printed price is charged",
+                    "type": "string",
+                    "maxLength": 32
+                },
+                "product_id": {
+                    "type": "integer"
+                },
+                "quantity": {
+                    "description": "decimal.NewFromString(\"136.02\")",
+                    "type": "number"
+                },
+                "total": {
                     "description": "In Cents",
+                    "type": "integer",
+                    "maximum": 99999999999,
+                    "minimum": 0
+                },
+                "variant_id": {
+                    "description": "Required for products sold by variant",
+                    "type": "integer"
+                },
+                "vat": {
+                    "description": "(ex: 2100 for 21.00%)",
+                    "type": "integer"
+                }
+            }
+        },
+        "models.CreatePayment": {
+            "type": "object",
+            "required": [
+                "tender"
+            ],
+            "properties": {
+                "amount": {
+                    "description": "In cents, defaults to what is left to pay",
+                    "type": "integer",
+                    "maximum": 99999999999,
+                    "minimum": 0
+                },
+                "reference": {
+                    "type": "string",
+                    "maxLength": 64
+                },
+                "tender": {
+                    "enum": [
+                        "cash",
+                        "card",
+                        "voucher",
+                        "store_credit",
+                        "gift_card",
+                        "loyalty_points"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.Tender"
+                        }
+                    ]
+                },
+                "tendered": {
+                    "description": "In cents, cash only",
+                    "type": "integer",
+                    "maximum": 99999999999,
+                    "minimum": 0
+                }
+            }
+        },
+        "models.CreateProductBarcode": {
+            "type": "object",
+            "required": [
+                "code"
+            ],
+            "properties": {
+                "code": {
+                    "type": "string",
+                    "maxLength": 32
+                },
+                "quantity": {
+                    "description": "Units sold per scan, defaults to 1",
+                    "type": "number"
+                }
+            }
+        },
+        "models.CreateProductVariant": {
+            "type": "object",
+            "required": [
+                "options",
+                "sku"
+            ],
+            "properties": {
+                "barcode": {
+                    "type": "string",
+                    "maxLength": 32
+                },
+                "options": {
+                    "description": "A value for each attribute of the product",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.VariantOptions"
+                        }
+                    ]
+                },
+                "price": {
+                    "description": "In cents, with VAT, defaults to the price of the product",
+                    "type": "integer",
+                    "maximum": 99999999999,
+                    "minimum": 0
+                },
+                "sku": {
+                    "type": "string",
+                    "maxLength": 64
+                },
+                "stock": {
+                    "type": "number"
+                }
+            }
+        },
+        "models.CreateProducts": {
+            "type": "object",
+            "required": [
+                "barcode_number",
+                "name",
+                "price",
+                "stock",
+                "variant_attributes"
+            ],
+            "properties": {
+                "barcode_number": {
+                    "type": "string",
+                    "maxLength": 32
+                },
+                "barcodes": {
+                    "description": "Barcodes other than barcode_number",
+                    "type": "array",
+                    "maxItems": 20,
+                    "items": {
+                        "$ref": "#/definitions/models.CreateProductBarcode"
+                    }
+                },
+                "category": {
+                    "type": "string",
+                    "maxLength": 64
+                },
+                "currency": {
+                    "description": "ISO 4217, defaults to the CURRENCY environment variable",
+                    "type": "string"
+                },
+                "gift_card": {
+                    "type": "boolean"
+                },
+                "item_code": {
+                    "type": "string",
+                    "maxLength": 10
+                },
+                "name": {
+                    "type": "string"
+                },
+                "price": {
+                    "description": "In cents, with VAT",
+                    "type": "integer",
+                    "maximum": 99999999999,
+                    "minimum": 0
+                },
+                "stock": {
+                    "type": "number"
+                },
+                "tax_category_id": {
+                    "type": "integer"
+                },
+                "variant_attributes": {
+                    "description": "Attributes the variants of the product differ by, for products sold by variant",
+                    "type": "array",
+                    "maxItems": 5,
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "vat": {
+                    "description": "(ex: 2100 for 21.00%)",
+                    "type": "integer"
+                }
+            }
+        },
+        "models.CreatePromotion": {
+            "type": "object",
+            "required": [
+                "kind",
+                "name"
+            ],
+            "properties": {
+                "active": {
+                    "description": "Defaults to true",
+                    "type": "boolean"
+                },
+                "amount": {
+                    "description": "In cents, with VAT",
+                    "type": "integer",
+                    "maximum": 99999999999,
+                    "minimum": 0
+                },
+                "buy_quantity": {
+                    "description": "Units to buy for buy_x_get_y, units of a bundle",
+                    "type": "integer"
+                },
+                "category": {
+                    "type": "string",
+                    "maxLength": 64
+                },
+                "coupon_only": {
+                    "type": "boolean"
+                },
+                "ends_at": {
+                    "type": "string"
+                },
+                "get_quantity": {
+                    "description": "Free units for buy_x_get_y",
+                    "type": "integer"
+                },
+                "kind": {
+                    "enum": [
+                        "percentage",
+                        "fixed",
+                        "buy_x_get_y",
+                        "bundle",
+                        "order_threshold"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.PromotionKind"
+                        }
+                    ]
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 64
+                },
+                "percent": {
+                    "description": "(ex: 1500 for 15.00%)",
+                    "type": "integer",
+                    "maximum": 10000
+                },
+                "priority": {
+                    "type": "integer"
+                },
+                "product_id": {
+                    "type": "integer"
+                },
+                "starts_at": {
+                    "type": "string"
+                },
+                "threshold": {
+                    "description": "In cents",
+                    "type": "integer",
+                    "maximum": 99999999999,
+                    "minimum": 0
+                }
+            }
+        },
+        "models.CreateRefund": {
+            "type": "object",
+            "required": [
+                "reason",
+                "tender"
+            ],
+            "properties": {
+                "cashout_number": {
+                    "description": "Defaults to the one of the refunded order",
+                    "type": "integer"
+                },
+                "gift_card": {
+                    "description": "Number of the card credited when refunding to a gift card",
+                    "type": "string",
+                    "maxLength": 32
+                },
+                "lines": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.RefundLine"
+                    }
+                },
+                "reason": {
+                    "type": "string"
+                },
+                "restock": {
+                    "description": "Used for every line of a full refund",
+                    "type": "boolean"
+                },
+                "tender": {
+                    "enum": [
+                        "cash",
+                        "card",
+                        "voucher",
+                        "store_credit",
+                        "gift_card"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.Tender"
+                        }
+                    ]
+                }
+            }
+        },
+        "models.CreateTaxCategory": {
+            "type": "object",
+            "required": [
+                "name",
+                "rates"
+            ],
+            "properties": {
+                "name": {
+                    "type": "string",
+                    "maxLength": 64
+                },
+                "rates": {
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "$ref": "#/definitions/models.CreateTaxRate"
+                    }
+                }
+            }
+        },
+        "models.CreateTaxRate": {
+            "type": "object",
+            "required": [
+                "valid_from"
+            ],
+            "properties": {
+                "surcharge": {
+                    "description": "(ex: 520 for 5.20%)",
+                    "type": "integer",
+                    "maximum": 10000
+                },
+                "valid_from": {
+                    "type": "string"
+                },
+                "vat": {
+                    "description": "(ex: 2100 for 21.00%)",
+                    "type": "integer",
+                    "maximum": 10000
+                }
+            }
+        },
+        "models.Customer": {
+            "type": "object",
+            "properties": {
+                "address": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "email": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "phone": {
+                    "type": "string"
+                },
+                "tax_id": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.DenominationCount": {
+            "type": "object",
+            "properties": {
+                "denomination": {
+                    "description": "In cents (ex: 500 for a 5.00 bill)",
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "quantity": {
+                    "type": "integer"
+                },
+                "session_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.GiftCard": {
+            "type": "object",
+            "properties": {
+                "balance": {
+                    "description": "In cents",
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "currency": {
+                    "description": "ISO 4217 (ex: EUR)",
+                    "type": "string"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "number": {
+                    "type": "string"
+                },
+                "order_line_id": {
+                    "description": "Line that sold the card",
+                    "type": "integer"
+                },
+                "transactions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.GiftCardTransaction"
+                    }
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "voided_at": {
+                    "description": "Set when the order that sold the card was voided",
+                    "type": "string"
+                }
+            }
+        },
+        "models.GiftCardTransaction": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "description": "In cents, negative when taken off the balance",
+                    "type": "integer"
+                },
+                "balance": {
+                    "description": "In cents, after the transaction",
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "gift_card_id": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "kind": {
+                    "$ref": "#/definitions/models.GiftCardTransactionKind"
+                },
+                "order_id": {
+                    "type": "integer"
+                },
+                "payment_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.GiftCardTransactionKind": {
+            "type": "string",
+            "enum": [
+                "issue",
+                "redeem",
+                "refund",
+                "expire",
+                "void"
+            ],
+            "x-enum-comments": {
+                "GiftCardExpire": "Balance left when the card expired",
+                "GiftCardIssue": "Card sold",
+                "GiftCardRedeem": "Card used to pay an order",
+                "GiftCardRefund": "Money given back to the card",
+                "GiftCardVoid": "Balance left when the order that sold the card was voided"
+            },
+            "x-enum-varnames": [
+                "GiftCardIssue",
+                "GiftCardRedeem",
+                "GiftCardRefund",
+                "GiftCardExpire",
+                "GiftCardVoid"
+            ]
+        },
+        "models.LoginUser": {
+            "type": "object",
+            "required": [
+                "password",
+                "username"
+            ],
+            "properties": {
+                "password": {
+                    "type": "string"
+                },
+                "username": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.LoyaltyAccount": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "customer_id": {
+                    "type": "integer"
+                },
+                "entries": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.LoyaltyEntry"
+                    }
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "identifier": {
+                    "description": "Card number or phone",
+                    "type": "string"
+                },
+                "points": {
+                    "description": "Balance, negative when refunds took back points already redeemed",
+                    "type": "integer"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.LoyaltyEntry": {
+            "type": "object",
+            "properties": {
+                "account_id": {
+                    "type": "integer"
+                },
+                "balance": {
+                    "description": "After the entry",
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "kind": {
+                    "$ref": "#/definitions/models.LoyaltyEntryKind"
+                },
+                "order_id": {
+                    "type": "integer"
+                },
+                "payment_id": {
+                    "type": "integer"
+                },
+                "points": {
+                    "description": "Negative when taken off the balance",
+                    "type": "integer"
+                },
+                "remaining": {
+                    "description": "Points of earn and credit entries not used yet",
+                    "type": "integer"
+                }
+            }
+        },
+        "models.LoyaltyEntryKind": {
+            "type": "string",
+            "enum": [
+                "earn",
+                "redeem",
+                "credit",
+                "reverse",
+                "expire"
+            ],
+            "x-enum-comments": {
+                "LoyaltyCredit": "Redeemed points given back",
+                "LoyaltyEarn": "Points earned by a sale",
+                "LoyaltyExpire": "Earned points left when they expired",
+                "LoyaltyRedeem": "Points used to pay an order",
+                "LoyaltyReverse": "Earned points taken back by a refund or void"
+            },
+            "x-enum-varnames": [
+                "LoyaltyEarn",
+                "LoyaltyRedeem",
+                "LoyaltyCredit",
+                "LoyaltyReverse",
+                "LoyaltyExpire"
+            ]
+        },
+        "models.LoyaltyRule": {
+            "type": "object",
+            "properties": {
+                "category": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "multiplier": {
+                    "description": "In basis points (ex: 20000 for double points, 0 for none)",
+                    "type": "integer"
+                },
+                "product_id": {
+                    "type": "integer"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.MovementKind": {
+            "type": "string",
+            "enum": [
+                "pay_in",
+                "pay_out"
+            ],
+            "x-enum-varnames": [
+                "MovementPayIn",
+                "MovementPayOut"
+            ]
+        },
+        "models.OpenSession": {
+            "type": "object",
+            "required": [
+                "cashout_number"
+            ],
+            "properties": {
+                "cashout_number": {
+                    "type": "integer"
+                },
+                "opening_float": {
+                    "description": "In cents",
+                    "type": "integer",
+                    "maximum": 99999999999,
+                    "minimum": 0
+                }
+            }
+        },
+        "models.Order": {
+            "type": "object",
+            "properties": {
+                "cashier": {
+                    "description": "Username of who rang up the order",
+                    "type": "string"
+                },
+                "cashout_number": {
+                    "type": "integer"
+                },
+                "client_id": {
+                    "description": "UUID given by the terminal to orders synced after being sold offline",
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "currency": {
+                    "description": "ISO 4217 (ex: EUR)",
+                    "type": "string"
+                },
+                "customer_id": {
+                    "type": "integer"
+                },
+                "customer_name": {
+                    "description": "Name of the customer, the only record of it on orders without a customer",
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "invoice": {
+                    "description": "Printed invoice number (ex: T-1-2026/000042)",
+                    "type": "string"
+                },
+                "invoice_number": {
+                    "description": "Sequential in its series, set when paid",
+                    "type": "integer"
+                },
+                "lines": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.OrderLine"
+                    }
+                },
+                "loyalty_id": {
+                    "description": "Card number or phone of the loyalty account the sale earns points for",
+                    "type": "string"
+                },
+                "refund_of_id": {
+                    "description": "Set on refunds, ID of the refunded order",
+                    "type": "integer"
+                },
+                "refund_tender": {
+                    "description": "Set on refunds",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.Tender"
+                        }
+                    ]
+                },
+                "status": {
+                    "$ref": "#/definitions/models.OrderStatus"
+                },
+                "taxes": {
+                    "description": "Breakdown per rate, computed with the rates in effect when the lines were priced",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.TaxLine"
+                    }
+                },
+                "total": {
+                    "description": "In cents, with VAT",
+                    "type": "integer"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "z_report_id": {
+                    "description": "Set once closed by a Z report",
+                    "type": "integer"
+                }
+            }
+        },
+        "models.OrderDetail": {
+            "type": "object",
+            "properties": {
+                "cashier": {
+                    "description": "Username of who rang up the order",
+                    "type": "string"
+                },
+                "cashout_number": {
+                    "type": "integer"
+                },
+                "client_id": {
+                    "description": "UUID given by the terminal to orders synced after being sold offline",
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "currency": {
+                    "description": "ISO 4217 (ex: EUR)",
+                    "type": "string"
+                },
+                "customer_id": {
+                    "type": "integer"
+                },
+                "customer_name": {
+                    "description": "Name of the customer, the only record of it on orders without a customer",
+                    "type": "string"
+                },
+                "discounts": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.OrderDiscount"
+                    }
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "invoice": {
+                    "description": "Printed invoice number (ex: T-1-2026/000042)",
+                    "type": "string"
+                },
+                "invoice_number": {
+                    "description": "Sequential in its series, set when paid",
+                    "type": "integer"
+                },
+                "lines": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.OrderLine"
+                    }
+                },
+                "loyalty_id": {
+                    "description": "Card number or phone of the loyalty account the sale earns points for",
+                    "type": "string"
+                },
+                "products": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Product"
+                    }
+                },
+                "refund_of_id": {
+                    "description": "Set on refunds, ID of the refunded order",
+                    "type": "integer"
+                },
+                "refund_tender": {
+                    "description": "Set on refunds",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.Tender"
+                        }
+                    ]
+                },
+                "status": {
+                    "$ref": "#/definitions/models.OrderStatus"
+                },
+                "taxes": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.TaxLine"
+                    }
+                },
+                "total": {
+                    "description": "In cents, with VAT",
+                    "type": "integer"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "z_report_id": {
+                    "description": "Set once closed by a Z report",
+                    "type": "integer"
+                }
+            }
+        },
+        "models.OrderDiscount": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "description": "In cents, with VAT, taken off the order",
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "order_id": {
+                    "type": "integer"
+                },
+                "order_line_id": {
+                    "type": "integer"
+                },
+                "promotion_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.OrderLine": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "currency": {
+                    "description": "ISO 4217 (ex: EUR)",
+                    "type": "string"
+                },
+                "discount": {
+                    "description": "In Cents, taken off price × quantity by promotions",
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "order_id": {
+                    "description": "Set once the line is part of an order",
+                    "type": "integer"
+                },
+                "price": {
+                    "description": "In Cents, with VAT",
+                    "type": "integer"
+                },
+                "product_id": {
+                    "type": "integer"
+                },
+                "quantity": {
+                    "description": "decimal.NewFromString(\"1.235\"), kg to the gram for products sold by weight",
+                    "type": "number"
+                },
+                "refund_of_line_id": {
+                    "description": "Set on refund lines, which carry negative quantities and totals",
+                    "type": "integer"
+                },
+                "stocked": {
+                    "description": "Set when checkout took the quantity from stock, which is returned if the order is voided or deleted unpaid",
+                    "type": "boolean"
+                },
+                "surcharge": {
+                    "description": "Equivalence surcharge (ex: 520 for 5.20%)",
+                    "type": "integer"
+                },
+                "total": {
+                    "description": "In Cents, with VAT, after Discount",
+                    "type": "integer"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "variant_id": {
+                    "description": "Set on lines of products sold by variant",
+                    "type": "integer"
+                },
+                "vat": {
+                    "description": "(ex: 2100 for 21.00%)",
+                    "type": "integer"
+                }
+            }
+        },
+        "models.OrderStatus": {
+            "type": "string",
+            "enum": [
+                "open",
+                "parked",
+                "paid",
+                "voided",
+                "refunded"
+            ],
+            "x-enum-varnames": [
+                "OrderOpen",
+                "OrderParked",
+                "OrderPaid",
+                "OrderVoided",
+                "OrderRefunded"
+            ]
+        },
+        "models.PaginatedOrderResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Order"
+                    }
+                },
+                "pagination": {
+                    "$ref": "#/definitions/models.Pagination"
+                }
+            }
+        },
+        "models.Pagination": {
+            "type": "object",
+            "properties": {
+                "limit": {
+                    "type": "integer"
+                },
+                "page": {
+                    "type": "integer"
+                },
+                "total_items": {
+                    "type": "integer"
+                },
+                "total_pages": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.Payment": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "description": "In cents, applied to the order total",
+                    "type": "integer"
+                },
+                "change": {
+                    "description": "In cents, given back in cash",
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "order_id": {
+                    "type": "integer"
+                },
+                "reference": {
+                    "description": "Card authorization, voucher, gift card number or loyalty account...",
+                    "type": "string"
+                },
+                "tender": {
+                    "$ref": "#/definitions/models.Tender"
+                },
+                "tendered": {
+                    "description": "In cents, handed over by the customer",
+                    "type": "integer"
+                }
+            }
+        },
+        "models.Product": {
+            "type": "object",
+            "properties": {
+                "barcode_number": {
+                    "description": "Main barcode, also one of Barcodes",
+                    "type": "string"
+                },
+                "barcodes": {
+                    "description": "Every barcode the product is scanned with, BarcodeNumber included",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ProductBarcode"
+                    }
+                },
+                "category": {
+                    "description": "Promotions can target every product of a category",
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "currency": {
+                    "description": "ISO 4217 (ex: EUR)",
+                    "type": "string"
+                },
+                "deleted_at": {
+                    "description": "Set on deleted products, which are kept as tombstones",
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "gift_card": {
+                    "description": "Each unit sold issues a gift card of the price",
+                    "type": "boolean"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "item_code": {
+                    "description": "Code of the product on scale labels (PLU), without leading zeros, unique among products",
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "price": {
+                    "description": "In cents, with VAT",
+                    "type": "integer"
+                },
+                "revision": {
+                    "description": "Bumped by the database on every change, see ProductChanges",
+                    "type": "integer"
+                },
+                "stock": {
+                    "description": "decimal.NewFromString(\"136.025\")",
+                    "type": "number"
+                },
+                "tax_category_id": {
+                    "description": "Rates of the category in effect on the day of the sale apply",
+                    "type": "integer"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "variant_attributes": {
+                    "description": "Set on products sold by variant (ex: [\"size\", \"color\"])",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "variants": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ProductVariant"
+                    }
+                },
+                "vat": {
+                    "description": "(ex: 2100 for 21.00%), unless TaxCategoryID is set",
+                    "type": "integer"
+                }
+            }
+        },
+        "models.ProductBarcode": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "product_id": {
+                    "type": "integer"
+                },
+                "quantity": {
+                    "description": "Units sold per scan (ex: 6 for a pack of six)",
+                    "type": "number"
+                },
+                "variant_id": {
+                    "description": "Set on the barcode of a variant",
+                    "type": "integer"
+                }
+            }
+        },
+        "models.ProductChanges": {
+            "type": "object",
+            "properties": {
+                "cursor": {
+                    "type": "integer"
+                },
+                "has_more": {
+                    "description": "More changes may be waiting past the cursor",
+                    "type": "boolean"
+                },
+                "products": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Product"
+                    }
+                }
+            }
+        },
+        "models.ProductVariant": {
+            "type": "object",
+            "properties": {
+                "barcode": {
+                    "description": "Also one of the barcodes of the product",
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "deleted_at": {
+                    "description": "Past order lines keep referring to deleted variants",
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "options": {
+                    "$ref": "#/definitions/models.VariantOptions"
+                },
+                "price": {
+                    "description": "In cents, with VAT, overriding the price of the product",
+                    "type": "integer"
+                },
+                "product_id": {
+                    "type": "integer"
+                },
+                "sku": {
+                    "type": "string"
+                },
+                "stock": {
+                    "type": "number"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.Promotion": {
+            "type": "object",
+            "properties": {
+                "active": {
+                    "type": "boolean"
+                },
+                "amount": {
+                    "description": "In cents, with VAT",
+                    "type": "integer"
+                },
+                "buy_quantity": {
+                    "description": "Units to buy for buy_x_get_y, units of a bundle",
+                    "type": "integer"
+                },
+                "category": {
+                    "type": "string"
+                },
+                "coupon_only": {
+                    "description": "Only applied when one of its coupon codes is given",
+                    "type": "boolean"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "ends_at": {
+                    "description": "Excluded",
+                    "type": "string"
+                },
+                "get_quantity": {
+                    "description": "Free units for buy_x_get_y",
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "kind": {
+                    "$ref": "#/definitions/models.PromotionKind"
+                },
+                "name": {
+                    "description": "Printed on receipts",
+                    "type": "string"
+                },
+                "percent": {
+                    "description": "(ex: 1500 for 15.00%)",
+                    "type": "integer"
+                },
+                "priority": {
+                    "description": "Higher first",
+                    "type": "integer"
+                },
+                "product_id": {
+                    "type": "integer"
+                },
+                "starts_at": {
+                    "type": "string"
+                },
+                "threshold": {
+                    "description": "In cents, order total reaching the threshold",
+                    "type": "integer"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.PromotionKind": {
+            "type": "string",
+            "enum": [
+                "percentage",
+                "fixed",
+                "buy_x_get_y",
+                "bundle",
+                "order_threshold"
+            ],
+            "x-enum-comments": {
+                "PromotionBundle": "BuyQuantity units of a line sold together for Amount",
+                "PromotionBuyXGetY": "For every BuyQuantity units bought, GetQuantity more are free",
+                "PromotionFixed": "Amount off every matching unit",
+                "PromotionPercentage": "Percent off the matching lines",
+                "PromotionThreshold": "Percent or Amount off orders of at least Threshold"
+            },
+            "x-enum-varnames": [
+                "PromotionPercentage",
+                "PromotionFixed",
+                "PromotionBuyXGetY",
+                "PromotionBundle",
+                "PromotionThreshold"
+            ]
+        },
+        "models.ReceiptCode": {
+            "type": "string",
+            "enum": [
+                "none",
+                "code128",
+                "qr"
+            ],
+            "x-enum-varnames": [
+                "ReceiptCodeNone",
+                "ReceiptCodeCode128",
+                "ReceiptCodeQR"
+            ]
+        },
+        "models.RefundLine": {
+            "type": "object",
+            "required": [
+                "order_line_id",
+                "quantity"
+            ],
+            "properties": {
+                "order_line_id": {
+                    "type": "integer"
+                },
+                "quantity": {
+                    "description": "decimal.NewFromString(\"136.02\")",
+                    "type": "number"
+                },
+                "restock": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "models.RegisterReport": {
+            "type": "object",
+            "properties": {
+                "cashout_number": {
+                    "type": "integer"
+                },
+                "currency": {
+                    "type": "string"
+                },
+                "discounts": {
+                    "description": "In cents, given by promotions on the net sales",
+                    "type": "integer"
+                },
+                "from": {
+                    "type": "string"
+                },
+                "gross_sales": {
+                    "description": "In cents",
+                    "type": "integer"
+                },
+                "net_sales": {
+                    "description": "In cents",
+                    "type": "integer"
+                },
+                "number": {
+                    "type": "integer"
+                },
+                "refunds": {
+                    "description": "In cents, as a positive amount",
+                    "type": "integer"
+                },
+                "refunds_count": {
+                    "type": "integer"
+                },
+                "sales_count": {
+                    "type": "integer"
+                },
+                "taxes": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.TaxLine"
+                    }
+                },
+                "tenders": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.TenderTotal"
+                    }
+                },
+                "to": {
+                    "type": "string"
+                },
+                "voids": {
+                    "description": "In cents",
+                    "type": "integer"
+                },
+                "voids_count": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.RegisterSession": {
+            "type": "object",
+            "properties": {
+                "cashout_number": {
+                    "type": "integer"
+                },
+                "closed_at": {
+                    "type": "string"
+                },
+                "closed_by": {
+                    "type": "string"
+                },
+                "counted_cash": {
+                    "description": "In cents, set on close",
+                    "type": "integer"
+                },
+                "counts": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.DenominationCount"
+                    }
+                },
+                "currency": {
+                    "description": "ISO 4217 (ex: EUR)",
+                    "type": "string"
+                },
+                "expected_cash": {
+                    "description": "In cents, set on close",
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "movements": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.CashMovement"
+                    }
+                },
+                "opened_at": {
+                    "type": "string"
+                },
+                "opened_by": {
+                    "type": "string"
+                },
+                "opening_float": {
+                    "description": "In cents",
+                    "type": "integer"
+                },
+                "status": {
+                    "$ref": "#/definitions/models.SessionStatus"
+                },
+                "variance": {
+                    "description": "In cents, counted minus expected",
+                    "type": "integer"
+                }
+            }
+        },
+        "models.SalesSummary": {
+            "type": "object",
+            "properties": {
+                "currency": {
+                    "type": "string"
+                },
+                "discounts": {
+                    "description": "In cents, given by promotions on the net sales",
+                    "type": "integer"
+                },
+                "from": {
+                    "type": "string"
+                },
+                "gross_sales": {
+                    "description": "In cents",
+                    "type": "integer"
+                },
+                "net_sales": {
+                    "description": "In cents",
+                    "type": "integer"
+                },
+                "refunds": {
+                    "description": "In cents, as a positive amount",
+                    "type": "integer"
+                },
+                "refunds_count": {
+                    "type": "integer"
+                },
+                "sales_count": {
+                    "type": "integer"
+                },
+                "taxes": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.TaxLine"
+                    }
+                },
+                "to": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.SessionStatus": {
+            "type": "string",
+            "enum": [
+                "open",
+                "closed"
+            ],
+            "x-enum-varnames": [
+                "SessionOpen",
+                "SessionClosed"
+            ]
+        },
+        "models.StoreSettings": {
+            "type": "object",
+            "properties": {
+                "barcode_formats": {
+                    "description": "Scale labels decoded when resolving barcodes",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.BarcodeFormat"
+                    }
+                },
+                "code": {
+                    "$ref": "#/definitions/models.ReceiptCode"
+                },
+                "code_page": {
+                    "description": "ESC/POS character code table (ex: 19 for PC858)",
                     "type": "integer"
                 },
-                "vat": {
-                    "description": "(ex: 2100 for 21.00%)",
+                "footer_lines": {
+                    "description": "Printed at the bottom",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "gift_card_months": {
+                    "description": "Validity of gift cards, 0 when they never expire",
+                    "type": "integer"
+                },
+                "header_lines": {
+                    "description": "Printed under the name (address, phone...)",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "invoice_prefix": {
+                    "description": "Prefix of the invoice series of sales",
+                    "type": "string"
+                },
+                "loyalty_months": {
+                    "description": "Validity of earned points, 0 when they never expire",
+                    "type": "integer"
+                },
+                "loyalty_per_unit": {
+                    "description": "Points earned per currency unit spent, 0 when sales earn none",
+                    "type": "integer"
+                },
+                "loyalty_value": {
+                    "description": "In cents, what a point pays for, 0 when points cannot be redeemed",
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "refund_prefix": {
+                    "description": "Prefix of the invoice series of refunds",
+                    "type": "string"
+                },
+                "tax_id": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "width": {
+                    "description": "Characters per line (ex: 42 or 48)",
                     "type": "integer"
                 }
             }
         },
-        "models.CreateProducts": {
+        "models.SyncOrder": {
             "type": "object",
             "required": [
-                "barcode_number",
-                "name",
-                "price",
-                "stock",
-                "vat"
+                "cashout_number",
+                "client_id",
+                "created_at",
+                "lines"
             ],
             "properties": {
-                "barcode_number": {
+                "cashout_number": {
+                    "type": "integer"
+                },
+                "client_id": {
                     "type": "string"
                 },
-                "name": {
+                "created_at": {
+                    "description": "Local time of the sale",
                     "type": "string"
                 },
-                "price": {
-                    "description": "In cents, with VAT",
+                "customer_id": {
                     "type": "integer"
                 },
-                "stock": {
-                    "type": "number"
+                "customer_name": {
+                    "type": "string"
                 },
-                "vat": {
-                    "description": "(ex: 2100 for 21.00%)",
-                    "type": "integer"
+                "lines": {
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "$ref": "#/definitions/models.CheckoutLine"
+                    }
+                },
+                "loyalty_id": {
+                    "type": "string",
+                    "maxLength": 32
+                },
+                "payments": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.CreatePayment"
+                    }
+                },
+                "total": {
+                    "description": "In cents, with VAT",
+                    "type": "integer",
+                    "maximum": 99999999999,
+                    "minimum": 0
                 }
             }
         },
-        "models.LoginUser": {
+        "models.SyncOrders": {
             "type": "object",
             "required": [
-                "password",
-                "username"
+                "orders"
             ],
             "properties": {
-                "password": {
-                    "type": "string"
-                },
-                "username": {
-                    "type": "string"
+                "orders": {
+                    "type": "array",
+                    "maxItems": 100,
+                    "minItems": 1,
+                    "items": {
+                        "$ref": "#/definitions/models.SyncOrder"
+                    }
                 }
             }
         },
-        "models.Order": {
+        "models.SyncResult": {
             "type": "object",
             "properties": {
-                "cashout_number": {
+                "client_id": {
+                    "type": "string"
+                },
+                "order_id": {
                     "type": "integer"
                 },
-                "created_at": {
+                "reason": {
+                    "description": "Why the order was rejected",
                     "type": "string"
                 },
-                "customer": {
+                "status": {
+                    "$ref": "#/definitions/models.SyncStatus"
+                }
+            }
+        },
+        "models.SyncStatus": {
+            "type": "string",
+            "enum": [
+                "accepted",
+                "duplicate",
+                "rejected"
+            ],
+            "x-enum-comments": {
+                "SyncAccepted": "The order was recorded",
+                "SyncDuplicate": "The order had already been synced",
+                "SyncRejected": "The order could not be recorded, see the reason"
+            },
+            "x-enum-varnames": [
+                "SyncAccepted",
+                "SyncDuplicate",
+                "SyncRejected"
+            ]
+        },
+        "models.TaxCategory": {
+            "type": "object",
+            "properties": {
+                "created_at": {
                     "type": "string"
                 },
                 "id": {
                     "type": "integer"
                 },
-                "lines_id": {
+                "name": {
+                    "type": "string"
+                },
+                "rates": {
                     "type": "array",
                     "items": {
-                        "type": "integer"
+                        "$ref": "#/definitions/models.TaxRate"
                     }
                 },
-                "total": {
-                    "description": "In cents, with VAT",
-                    "type": "integer"
-                },
                 "updated_at": {
                     "type": "string"
                 }
             }
         },
-        "models.OrderLine": {
+        "models.TaxLine": {
             "type": "object",
             "properties": {
-                "created_at": {
-                    "type": "string"
-                },
-                "id": {
+                "base": {
+                    "description": "In cents, without taxes",
                     "type": "integer"
                 },
-                "price": {
-                    "description": "In Cents, with VAT",
+                "surcharge": {
+                    "description": "(ex: 520 for 5.20%)",
                     "type": "integer"
                 },
-                "product_id": {
+                "surcharge_tax": {
+                    "description": "In cents",
                     "type": "integer"
                 },
-                "quantity": {
-                    "description": "decimal.NewFromString(\"136.02\")",
-                    "type": "number"
+                "tax": {
+                    "description": "In cents, VAT",
+                    "type": "integer"
                 },
                 "total": {
-                    "description": "In Cents, with VAT",
+                    "description": "In cents, with taxes",
                     "type": "integer"
                 },
-                "updated_at": {
-                    "type": "string"
-                },
                 "vat": {
                     "description": "(ex: 2100 for 21.00%)",
                     "type": "integer"
                 }
             }
         },
-        "models.Product": {
+        "models.TaxRate": {
             "type": "object",
             "properties": {
-                "barcode_number": {
-                    "type": "string"
-                },
                 "created_at": {
                     "type": "string"
                 },
                 "id": {
                     "type": "integer"
                 },
-                "name": {
-                    "type": "string"
-                },
-                "price": {
-                    "description": "In cents, with VAT",
+                "surcharge": {
+                    "description": "Equivalence surcharge charged on top of the VAT (ex: 520 for 5.20%)",
                     "type": "integer"
                 },
-                "stock": {
-                    "description": "decimal.NewFromString(\"136.02\")",
-                    "type": "number"
+                "tax_category_id": {
+                    "type": "integer"
                 },
-                "updated_at": {
+                "valid_from": {
                     "type": "string"
                 },
                 "vat": {
@@ -1003,27 +6101,107 @@ const docTemplate = `{
                 }
             }
         },
+        "models.Tender": {
+            "type": "string",
+            "enum": [
+                "cash",
+                "card",
+                "voucher",
+                "store_credit",
+                "gift_card",
+                "loyalty_points"
+            ],
+            "x-enum-varnames": [
+                "TenderCash",
+                "TenderCard",
+                "TenderVoucher",
+                "TenderStoreCredit",
+                "TenderGiftCard",
+                "TenderLoyalty"
+            ]
+        },
+        "models.TenderTotal": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "description": "In cents",
+                    "type": "integer"
+                },
+                "count": {
+                    "description": "Number of payments",
+                    "type": "integer"
+                },
+                "tender": {
+                    "$ref": "#/definitions/models.Tender"
+                }
+            }
+        },
+        "models.TransitionOrder": {
+            "type": "object",
+            "properties": {
+                "reason": {
+                    "description": "Required to void an order",
+                    "type": "string"
+                }
+            }
+        },
+        "models.UpdateCustomer": {
+            "type": "object",
+            "properties": {
+                "address": {
+                    "type": "string",
+                    "maxLength": 256
+                },
+                "email": {
+                    "type": "string",
+                    "maxLength": 254
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 128
+                },
+                "phone": {
+                    "type": "string",
+                    "maxLength": 32
+                },
+                "tax_id": {
+                    "type": "string",
+                    "maxLength": 32
+                }
+            }
+        },
         "models.UpdateOrder": {
             "type": "object",
-            "required": [
-                "lines_id"
-            ],
             "properties": {
                 "cashout_number": {
                     "type": "integer"
                 },
                 "customer": {
+                    "description": "Former name of customer_name, still accepted",
+                    "type": "string"
+                },
+                "customer_id": {
+                    "type": "integer"
+                },
+                "customer_name": {
                     "type": "string"
                 },
                 "lines_id": {
+                    "description": "Replaces the lines of the order when sent",
                     "type": "array",
                     "items": {
                         "type": "integer"
                     }
                 },
+                "loyalty_id": {
+                    "type": "string",
+                    "maxLength": 32
+                },
                 "total": {
                     "description": "In cents, with VAT",
-                    "type": "integer"
+                    "type": "integer",
+                    "maximum": 99999999999,
+                    "minimum": 0
                 }
             }
         },
@@ -1032,7 +6210,9 @@ const docTemplate = `{
             "properties": {
                 "price": {
                     "description": "In Cents, with VAT",
-                    "type": "integer"
+                    "type": "integer",
+                    "maximum": 99999999999,
+                    "minimum": 0
                 },
                 "product_id": {
                     "type": "integer"
@@ -1043,6 +6223,12 @@ const docTemplate = `{
                 },
                 "total": {
                     "description": "In Cents, with VAT",
+                    "type": "integer",
+                    "maximum": 99999999999,
+                    "minimum": 0
+                },
+                "variant_id": {
+                    "description": "Required when changing to a product sold by variant",
                     "type": "integer"
                 },
                 "vat": {
@@ -1055,23 +6241,243 @@ const docTemplate = `{
             "type": "object",
             "properties": {
                 "barcode_number": {
+                    "type": "string",
+                    "maxLength": 32
+                },
+                "barcodes": {
+                    "description": "Replace the barcodes other than barcode_number when set",
+                    "type": "array",
+                    "maxItems": 20,
+                    "items": {
+                        "$ref": "#/definitions/models.CreateProductBarcode"
+                    }
+                },
+                "category": {
+                    "type": "string",
+                    "maxLength": 64
+                },
+                "currency": {
+                    "description": "ISO 4217",
                     "type": "string"
                 },
+                "gift_card": {
+                    "type": "boolean"
+                },
+                "item_code": {
+                    "type": "string",
+                    "maxLength": 10
+                },
                 "name": {
                     "type": "string"
                 },
                 "price": {
                     "description": "In cents, with VAT",
-                    "type": "integer"
+                    "type": "integer",
+                    "maximum": 99999999999,
+                    "minimum": 0
                 },
                 "stock": {
                     "type": "number"
                 },
+                "tax_category_id": {
+                    "type": "integer"
+                },
                 "vat": {
                     "description": "(ex: 2100 for 21.00%)",
                     "type": "integer"
                 }
             }
+        },
+        "models.UpdateProductVariant": {
+            "type": "object",
+            "properties": {
+                "barcode": {
+                    "type": "string",
+                    "maxLength": 32
+                },
+                "clear_price": {
+                    "description": "Sells the variant at the price of its product again",
+                    "type": "boolean"
+                },
+                "options": {
+                    "$ref": "#/definitions/models.VariantOptions"
+                },
+                "price": {
+                    "description": "In cents, with VAT",
+                    "type": "integer",
+                    "maximum": 99999999999,
+                    "minimum": 0
+                },
+                "sku": {
+                    "type": "string",
+                    "maxLength": 64
+                },
+                "stock": {
+                    "description": "Zero included",
+                    "type": "number"
+                }
+            }
+        },
+        "models.UpdatePromotion": {
+            "type": "object",
+            "properties": {
+                "active": {
+                    "type": "boolean"
+                },
+                "amount": {
+                    "type": "integer",
+                    "maximum": 99999999999,
+                    "minimum": 0
+                },
+                "buy_quantity": {
+                    "type": "integer"
+                },
+                "category": {
+                    "type": "string",
+                    "maxLength": 64
+                },
+                "coupon_only": {
+                    "type": "boolean"
+                },
+                "ends_at": {
+                    "type": "string"
+                },
+                "get_quantity": {
+                    "type": "integer"
+                },
+                "kind": {
+                    "enum": [
+                        "percentage",
+                        "fixed",
+                        "buy_x_get_y",
+                        "bundle",
+                        "order_threshold"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.PromotionKind"
+                        }
+                    ]
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 64
+                },
+                "percent": {
+                    "type": "integer",
+                    "maximum": 10000
+                },
+                "priority": {
+                    "type": "integer"
+                },
+                "product_id": {
+                    "type": "integer"
+                },
+                "starts_at": {
+                    "type": "string"
+                },
+                "threshold": {
+                    "type": "integer",
+                    "maximum": 99999999999,
+                    "minimum": 0
+                }
+            }
+        },
+        "models.UpdateStoreSettings": {
+            "type": "object",
+            "properties": {
+                "barcode_formats": {
+                    "description": "Replaces the formats when sent",
+                    "type": "array",
+                    "maxItems": 10,
+                    "items": {
+                        "$ref": "#/definitions/models.BarcodeFormat"
+                    }
+                },
+                "code": {
+                    "enum": [
+                        "none",
+                        "code128",
+                        "qr"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.ReceiptCode"
+                        }
+                    ]
+                },
+                "code_page": {
+                    "description": "16 for WPC1252, 19 for PC858",
+                    "type": "integer",
+                    "enum": [
+                        16,
+                        19
+                    ]
+                },
+                "footer_lines": {
+                    "type": "array",
+                    "maxItems": 8,
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "gift_card_months": {
+                    "type": "integer",
+                    "maximum": 120
+                },
+                "header_lines": {
+                    "type": "array",
+                    "maxItems": 8,
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "invoice_prefix": {
+                    "description": "Starts new series for the following invoices",
+                    "type": "string",
+                    "maxLength": 8
+                },
+                "loyalty_months": {
+                    "type": "integer",
+                    "maximum": 120
+                },
+                "loyalty_per_unit": {
+                    "type": "integer",
+                    "maximum": 1000
+                },
+                "loyalty_value": {
+                    "description": "In cents",
+                    "type": "integer",
+                    "maximum": 10000,
+                    "minimum": 0
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 64
+                },
+                "refund_prefix": {
+                    "type": "string",
+                    "maxLength": 8
+                },
+                "tax_id": {
+                    "type": "string",
+                    "maxLength": 32
+                },
+                "width": {
+                    "type": "integer",
+                    "enum": [
+                        32,
+                        42,
+                        48
+                    ]
+                }
+            }
+        },
+        "models.VariantOptions": {
+            "type": "object",
+            "additionalProperties": {
+                "type": "string"
+            }
         }
     },
     "securityDefinitions": {
@@ -0,0 +1,363 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"postui_api/pkg/barcode"
+	"postui_api/pkg/cache"
+	"postui_api/pkg/database"
+	"postui_api/pkg/models"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+)
+
+var (
+	errBarcodeNotFound = errors.New("no product for barcode")
+	errBarcodeTaken    = errors.New("barcode belongs to another product")
+	errInvalidBarcode  = errors.New("invalid barcode")
+	errItemCodeTaken   = errors.New("item code belongs to another product")
+)
+
+// FindProductByBarcode godoc
+// @Summary Find a product by barcode
+// @Description Get the product one of whose barcodes is code, with all its barcodes. The check digit of EAN and UPC barcodes is validated. Lookups are cached.
+// @Tags products
+// @Security JwtAuth
+// @Produce json
+// @Param code path string true "Barcode"
+// @Success 200 {object} models.Product "Successfully retrieved product"
+// @Failure 400 {string} string "wrong check digit"
+// @Failure 404 {string} string "no product for barcode"
+// @Router /products/barcode/{code} [get]
+func (r *productRepository) FindProductByBarcode(c *gin.Context) {
+	code := strings.TrimSpace(c.Param("code"))
+
+	if err := barcode.Validate(code); err != nil {
+		respondBarcodeError(c, err)
+		return
+	}
+
+	product, _, err := findProductByBarcode(r.DB, r.RedisClient, *r.Ctx, code)
+	if err != nil {
+		respondBarcodeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": product})
+}
+
+// ResolveBarcode godoc
+// @Summary Resolve a scanned barcode
+// @Description Find the product, and the variant for barcodes of variants, a scanned barcode stands for and the quantity to sell. EAN-13 scale labels matching one of the barcode formats of the store settings are decoded: the product is found by the embedded item code, and the embedded weight in kg, or the embedded price, is returned. Lines of price labels are sent with the label as price_label, so that they are charged the printed price. Other barcodes are looked up in the barcodes of the products and sell the units of the barcode, such as 6 for the barcode of a pack of six. The check digit of EAN and UPC barcodes is validated.
+// @Tags products
+// @Security JwtAuth
+// @Produce json
+// @Param code path string true "Scanned barcode"
+// @Success 200 {object} models.BarcodeResolution "Successfully resolved barcode"
+// @Failure 400 {string} string "wrong check digit"
+// @Failure 404 {string} string "no product for barcode"
+// @Router /barcodes/{code} [get]
+func (r *productRepository) ResolveBarcode(c *gin.Context) {
+	resolution, err := resolveBarcode(r.DB, r.RedisClient, *r.Ctx, strings.TrimSpace(c.Param("code")))
+	if err != nil {
+		respondBarcodeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": resolution})
+}
+
+// resolveBarcode finds the product code stands for, decoding scale labels
+// with the formats of the store settings
+func resolveBarcode(db database.Database, redisClient cache.Cache, ctx context.Context, code string) (models.BarcodeResolution, error) {
+	resolution := models.BarcodeResolution{Barcode: code, Quantity: decimal.NewFromInt(1)}
+
+	if err := barcode.Validate(code); err != nil {
+		return resolution, err
+	}
+
+	settings, _, err := loadStoreSettings(db)
+	if err != nil {
+		return resolution, err
+	}
+
+	label, ok, err := barcode.Decode(code, settings.BarcodeFormats)
+	if err != nil {
+		return resolution, err
+	}
+	if !ok {
+		product, row, err := findProductByBarcode(db, redisClient, ctx, code)
+		if err != nil {
+			return resolution, err
+		}
+		resolution.Product, resolution.Quantity = product, row.Quantity
+		if row.VariantID != nil {
+			variant, err := findVariantOf(db, product, row.VariantID)
+			if err != nil {
+				return resolution, err
+			}
+			resolution.Variant = &variant
+		}
+		return resolution, nil
+	}
+
+	resolution.Product, err = findProductBy(db, "item_code = ?", label.ItemCode)
+	if err != nil {
+		return resolution, fmt.Errorf("%w %s (item code %s)", err, code, label.ItemCode)
+	}
+	resolution.Format = &label.Format
+
+	if label.Format.Value == models.BarcodeWeight {
+		resolution.Quantity = label.Quantity
+		return resolution, nil
+	}
+
+	// Price labels sell the weight the price pays for at the catalog price
+	resolution.Price = &label.Price
+	if resolution.Product.Price > 0 {
+		resolution.Quantity = labelQuantity(label.Price, resolution.Product.Price)
+	}
+	return resolution, nil
+}
+
+// labelQuantity returns the weight a price label pays for at price, to the
+// gram
+func labelQuantity(labelPrice, price models.Money) decimal.Decimal {
+	return decimal.NewFromInt(int64(labelPrice)).DivRound(decimal.NewFromInt(int64(price)), 3)
+}
+
+// applyPriceLabel charges line, a line of product, the price printed on the
+// price label code instead of its price × quantity, which the rounding of
+// the weight makes differ by a few cents. The line must sell the weight the
+// label pays for.
+func applyPriceLabel(db database.Database, line *models.OrderLine, product models.Product, code string) error {
+	code = strings.TrimSpace(code)
+	if err := barcode.Validate(code); err != nil {
+		return err
+	}
+
+	settings, _, err := loadStoreSettings(db)
+	if err != nil {
+		return err
+	}
+
+	label, ok, err := barcode.Decode(code, settings.BarcodeFormats)
+	if err != nil {
+		return err
+	}
+	if !ok || label.Format.Value != models.BarcodePrice {
+		return fmt.Errorf("%w: %s is not a price label", errInvalidBarcode, code)
+	}
+	if label.ItemCode != product.ItemCode {
+		return fmt.Errorf("%w: price label %s is not one of product %d", errInvalidBarcode, code, product.ID)
+	}
+	if product.Price > 0 && !line.Quantity.Equal(labelQuantity(label.Price, product.Price)) {
+		return fmt.Errorf("%w: price label %s pays for %s of product %d", errInvalidBarcode, code, labelQuantity(label.Price, product.Price), product.ID)
+	}
+
+	line.Total = label.Price
+	return nil
+}
+
+// findProductBy returns the product matching query, or errBarcodeNotFound
+func findProductBy(db database.Database, query string, value string) (models.Product, error) {
+	var product models.Product
+
+	if err := db.Where(query, value).First(&product).Error(); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return product, errBarcodeNotFound
+		}
+		return product, err
+	}
+	return product, nil
+}
+
+// findProductByBarcode returns the product code is one of the barcodes of,
+// with its barcodes, and the barcode itself. Products are cached by barcode
+// for a minute; a cache failure falls back to the database.
+func findProductByBarcode(db database.Database, redisClient cache.Cache, ctx context.Context, code string) (models.Product, models.ProductBarcode, error) {
+	var product models.Product
+
+	cacheKey := barcodeCacheKey(code)
+	if cached, err := redisClient.Get(ctx, cacheKey).Result(); err == nil {
+		if err := json.Unmarshal([]byte(cached), &product); err == nil {
+			for _, row := range product.Barcodes {
+				if row.Code == code {
+					return product, row, nil
+				}
+			}
+		}
+		product = models.Product{}
+	}
+
+	var row models.ProductBarcode
+	if err := db.Where("code = ?", code).First(&row).Error(); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return product, row, fmt.Errorf("%w %s", errBarcodeNotFound, code)
+		}
+		return product, row, err
+	}
+
+	if err := db.Where("id = ?", row.ProductID).First(&product).Error(); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return product, row, fmt.Errorf("%w %s", errBarcodeNotFound, code)
+		}
+		return product, row, err
+	}
+
+	if err := db.Where("product_id = ?", product.ID).Find(&product.Barcodes).Error; err != nil {
+		return product, row, err
+	}
+
+	if serialized, err := json.Marshal(product); err == nil {
+		redisClient.Set(ctx, cacheKey, serialized, time.Minute)
+	}
+
+	return product, row, nil
+}
+
+// productBarcodes validates the barcodes of a product, main first, and
+// gives one unit to those without a quantity
+func productBarcodes(main string, others []models.CreateProductBarcode) ([]models.ProductBarcode, error) {
+	inputs := append([]models.CreateProductBarcode{{Code: main}}, others...)
+	barcodes := make([]models.ProductBarcode, 0, len(inputs))
+	seen := make(map[string]bool, len(inputs))
+
+	for _, input := range inputs {
+		code := strings.TrimSpace(input.Code)
+		if code == "" {
+			continue
+		}
+		if err := barcode.Validate(code); err != nil {
+			return nil, err
+		}
+		if seen[code] {
+			return nil, fmt.Errorf("%w: %s is listed twice", errInvalidBarcode, code)
+		}
+		seen[code] = true
+
+		quantity := input.Quantity
+		if quantity.IsZero() {
+			quantity = decimal.NewFromInt(1)
+		}
+		if !quantity.IsPositive() {
+			return nil, fmt.Errorf("%w: quantity of %s must be positive", errInvalidBarcode, code)
+		}
+
+		barcodes = append(barcodes, models.ProductBarcode{Code: code, Quantity: quantity})
+	}
+	return barcodes, nil
+}
+
+// checkBarcodesFree fails with errBarcodeTaken when one of barcodes belongs
+// to a product other than productID
+func checkBarcodesFree(db database.Database, productID uint, barcodes []models.ProductBarcode) error {
+	if len(barcodes) == 0 {
+		return nil
+	}
+
+	codes := make([]string, len(barcodes))
+	for i, row := range barcodes {
+		codes[i] = row.Code
+	}
+
+	var taken []models.ProductBarcode
+	if err := db.Where("code IN ?", codes).Find(&taken).Error; err != nil {
+		return err
+	}
+	for _, row := range taken {
+		if row.ProductID != productID {
+			return fmt.Errorf("%w: %s", errBarcodeTaken, row.Code)
+		}
+	}
+	return nil
+}
+
+// checkItemCodeFree fails with errItemCodeTaken when code is the item code
+// of a product other than productID
+func checkItemCodeFree(db database.Database, productID uint, code string) error {
+	if code == "" {
+		return nil
+	}
+
+	var other models.Product
+	if err := db.Where("item_code = ?", code).First(&other).Error(); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return err
+	}
+	if other.ID != productID {
+		return fmt.Errorf("%w: %s is the item code of product %d", errItemCodeTaken, code, other.ID)
+	}
+	return nil
+}
+
+// replaceProductBarcodes replaces the barcodes of a product, other than the
+// ones of its variants, with barcodes
+func replaceProductBarcodes(tx database.Database, productID uint, barcodes []models.ProductBarcode) error {
+	if err := tx.Where("product_id = ? AND variant_id IS NULL", productID).Delete(&models.ProductBarcode{}).Error; err != nil {
+		return err
+	}
+	if len(barcodes) == 0 {
+		return nil
+	}
+
+	for i := range barcodes {
+		barcodes[i].ProductID = productID
+	}
+	return tx.Create(&barcodes).Error
+}
+
+// invalidateBarcodes drops the cached lookups of barcodes
+func invalidateBarcodes(redisClient cache.Cache, ctx context.Context, barcodes ...[]models.ProductBarcode) {
+	var keys []string
+	for _, rows := range barcodes {
+		for _, row := range rows {
+			if row.Code != "" {
+				keys = append(keys, barcodeCacheKey(row.Code))
+			}
+		}
+	}
+	if len(keys) > 0 {
+		redisClient.Del(ctx, keys...)
+	}
+}
+
+// barcodeCacheKey is the key under which the product of a barcode is cached
+func barcodeCacheKey(code string) string {
+	return "barcode_" + code
+}
+
+// respondBarcodeError maps an error about barcodes to a response
+func respondBarcodeError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, barcode.ErrCheckDigit), errors.Is(err, errInvalidBarcode):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, errBarcodeNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, errBarcodeTaken), errors.Is(err, errItemCodeTaken):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, gorm.ErrDuplicatedKey):
+		// A concurrent request took the barcode or item code first
+		c.JSON(http.StatusConflict, gin.H{"error": "barcode or item code belongs to another product"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+	}
+}
+
+// itemCode normalizes the item code of a product so that it matches the
+// ones decoded from scale labels
+func itemCode(code string) string {
+	if code == "" {
+		return ""
+	}
+	return barcode.ItemCode(code)
+}
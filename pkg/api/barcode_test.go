@@ -0,0 +1,414 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"postui_api/pkg/cache"
+	"postui_api/pkg/database"
+	"postui_api/pkg/models"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-redis/redis/v8"
+	"github.com/golang/mock/gomock"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func TestResolveBarcodeWeightLabel(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDB := database.NewMockDatabase(ctrl)
+	mockCache := cache.NewMockCache(ctrl)
+	ctx := context.Background()
+	repo := NewProductRepository(mockDB, mockCache, &ctx)
+
+	// Set up Gin
+	gin.SetMode(gin.TestMode)
+	r := gin.Default()
+	r.GET("/barcodes/:code", repo.ResolveBarcode)
+
+	settings := models.DefaultStoreSettings()
+	settings.BarcodeFormats = models.BarcodeFormats{{Prefix: "21", ItemDigits: 5, Value: models.BarcodeWeight, ValueDigits: 5, Decimals: 3}}
+
+	mockDB.EXPECT().Where("id = ?", models.StoreSettingsID).Return(mockDB).Times(1)
+	mockDB.EXPECT().Where("item_code = ?", "123").Return(mockDB).Times(1)
+	mockDB.EXPECT().
+		First(gomock.Any()).
+		DoAndReturn(func(dest interface{}, conds ...interface{}) database.Database {
+			switch b := dest.(type) {
+			case *models.StoreSettings:
+				*b = settings
+			case *models.Product:
+				*b = models.Product{ID: 8, Name: "Jamón", Price: 2990, ItemCode: "123"}
+			}
+			return mockDB
+		}).Times(2)
+	mockDB.EXPECT().Error().Return(nil).Times(2)
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/barcodes/2100123012343", nil)
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response struct {
+		Data models.BarcodeResolution `json:"data"`
+	}
+
+	assert.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+	assert.Equal(t, uint(8), response.Data.Product.ID)
+	assert.True(t, decimal.RequireFromString("1.234").Equal(response.Data.Quantity))
+	assert.Nil(t, response.Data.Price)
+}
+
+func TestResolveBarcodeWrongCheckDigit(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDB := database.NewMockDatabase(ctrl)
+	mockCache := cache.NewMockCache(ctrl)
+	ctx := context.Background()
+	repo := NewProductRepository(mockDB, mockCache, &ctx)
+
+	// Set up Gin
+	gin.SetMode(gin.TestMode)
+	r := gin.Default()
+	r.GET("/barcodes/:code", repo.ResolveBarcode)
+
+	// A misread is refused before any lookup
+	mockDB.EXPECT().Where(gomock.Any(), gomock.Any()).Times(0)
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/barcodes/4006381333932", nil)
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "wrong check digit")
+}
+
+func TestResolveBarcodePriceLabel(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDB := database.NewMockDatabase(ctrl)
+
+	settings := models.DefaultStoreSettings()
+	settings.BarcodeFormats = models.BarcodeFormats{{Prefix: "22", ItemDigits: 5, Value: models.BarcodePrice, ValueDigits: 5, Decimals: 2}}
+
+	mockDB.EXPECT().Where("id = ?", models.StoreSettingsID).Return(mockDB).Times(1)
+	mockDB.EXPECT().Where("item_code = ?", "45").Return(mockDB).Times(1)
+	mockDB.EXPECT().
+		First(gomock.Any()).
+		DoAndReturn(func(dest interface{}, conds ...interface{}) database.Database {
+			switch b := dest.(type) {
+			case *models.StoreSettings:
+				*b = settings
+			case *models.Product:
+				*b = models.Product{ID: 9, Name: "Queso", Price: 1200, ItemCode: "45"}
+			}
+			return mockDB
+		}).Times(2)
+	mockDB.EXPECT().Error().Return(nil).Times(2)
+
+	resolution, err := resolveBarcode(mockDB, nil, context.Background(), "2200045003495")
+
+	assert.NoError(t, err)
+	if assert.NotNil(t, resolution.Price) {
+		assert.Equal(t, models.Money(349), *resolution.Price)
+	}
+	assert.True(t, decimal.RequireFromString("0.291").Equal(resolution.Quantity), "3.49 should pay for 0.291 kg at 12.00 a kg")
+}
+
+func TestResolveBarcodePack(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDB := database.NewMockDatabase(ctrl)
+	mockCache := cache.NewMockCache(ctrl)
+	ctx := context.Background()
+	repo := NewProductRepository(mockDB, mockCache, &ctx)
+
+	// Set up Gin
+	gin.SetMode(gin.TestMode)
+	r := gin.Default()
+	r.GET("/barcodes/:code", repo.ResolveBarcode)
+
+	pack := models.ProductBarcode{ID: 2, ProductID: 3, Code: "4006381333931", Quantity: decimal.NewFromInt(6)}
+	barcodes := []models.ProductBarcode{{ID: 1, ProductID: 3, Code: "96385074", Quantity: decimal.NewFromInt(1)}, pack}
+
+	// Not cached yet
+	mockCache.EXPECT().Get(ctx, "barcode_4006381333931").Return(redis.NewStringResult("", redis.Nil)).Times(1)
+
+	mockDB.EXPECT().Where("id = ?", models.StoreSettingsID).Return(mockDB).Times(1)
+	mockDB.EXPECT().Where("code = ?", "4006381333931").Return(mockDB).Times(1)
+	mockDB.EXPECT().Where("id = ?", uint(3)).Return(mockDB).Times(1)
+	mockDB.EXPECT().
+		First(gomock.Any()).
+		DoAndReturn(func(dest interface{}, conds ...interface{}) database.Database {
+			switch b := dest.(type) {
+			case *models.StoreSettings:
+				*b = models.DefaultStoreSettings()
+			case *models.ProductBarcode:
+				*b = pack
+			case *models.Product:
+				*b = models.Product{ID: 3, Name: "Agua", Price: 50, BarcodeNumber: "96385074"}
+			}
+			return mockDB
+		}).Times(3)
+	mockDB.EXPECT().Error().Return(nil).Times(3)
+	mockDB.EXPECT().Where("product_id = ?", uint(3)).Return(mockDB).Times(1)
+	mockDB.EXPECT().
+		Find(gomock.Any()).
+		DoAndReturn(func(dest interface{}, conds ...interface{}) *gorm.DB {
+			*dest.(*[]models.ProductBarcode) = barcodes
+			return &gorm.DB{Error: nil}
+		}).Times(1)
+
+	// The product is cached for the next scan
+	mockCache.EXPECT().Set(ctx, "barcode_4006381333931", gomock.Any(), time.Minute).Return(redis.NewStatusResult("OK", nil)).Times(1)
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/barcodes/4006381333931", nil)
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response struct {
+		Data models.BarcodeResolution `json:"data"`
+	}
+
+	assert.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+	assert.Equal(t, uint(3), response.Data.Product.ID)
+	assert.Len(t, response.Data.Product.Barcodes, 2)
+	assert.True(t, decimal.NewFromInt(6).Equal(response.Data.Quantity), "the barcode of a pack of six sells six units")
+}
+
+func TestFindProductByBarcodeCached(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDB := database.NewMockDatabase(ctrl)
+	mockCache := cache.NewMockCache(ctrl)
+	ctx := context.Background()
+	repo := NewProductRepository(mockDB, mockCache, &ctx)
+
+	// Set up Gin
+	gin.SetMode(gin.TestMode)
+	r := gin.Default()
+	r.GET("/products/barcode/:code", repo.FindProductByBarcode)
+
+	product := models.Product{ID: 3, Name: "Agua", Price: 50, BarcodeNumber: "96385074", Barcodes: []models.ProductBarcode{
+		{ID: 1, ProductID: 3, Code: "96385074", Quantity: decimal.NewFromInt(1)},
+		{ID: 2, ProductID: 3, Code: "4006381333931", Quantity: decimal.NewFromInt(6)},
+	}}
+	cached, _ := json.Marshal(product)
+
+	mockCache.EXPECT().Get(ctx, "barcode_96385074").Return(redis.NewStringResult(string(cached), nil)).Times(1)
+
+	// A cached barcode needs no query
+	mockDB.EXPECT().Where(gomock.Any(), gomock.Any()).Times(0)
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/products/barcode/96385074", nil)
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response struct {
+		Data models.Product `json:"data"`
+	}
+
+	assert.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+	assert.Equal(t, "Agua", response.Data.Name)
+	assert.Len(t, response.Data.Barcodes, 2)
+}
+
+func TestFindProductByBarcodeNotFound(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDB := database.NewMockDatabase(ctrl)
+	mockCache := cache.NewMockCache(ctrl)
+	ctx := context.Background()
+	repo := NewProductRepository(mockDB, mockCache, &ctx)
+
+	// Set up Gin
+	gin.SetMode(gin.TestMode)
+	r := gin.Default()
+	r.GET("/products/barcode/:code", repo.FindProductByBarcode)
+
+	mockCache.EXPECT().Get(ctx, "barcode_96385074").Return(redis.NewStringResult("", redis.Nil)).Times(1)
+	mockDB.EXPECT().Where("code = ?", "96385074").Return(mockDB).Times(1)
+	mockDB.EXPECT().First(gomock.Any()).Return(mockDB).Times(1)
+	mockDB.EXPECT().Error().Return(gorm.ErrRecordNotFound).Times(1)
+
+	// Misses are not cached
+	mockCache.EXPECT().Set(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/products/barcode/96385074", nil)
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Contains(t, w.Body.String(), "no product for barcode")
+}
+
+func TestCreateProductBarcodeTaken(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDB := database.NewMockDatabase(ctrl)
+	mockCache := cache.NewMockCache(ctrl)
+	ctx := context.Background()
+	repo := NewProductRepository(mockDB, mockCache, &ctx)
+
+	// Set up Gin
+	gin.SetMode(gin.TestMode)
+	r := gin.Default()
+	r.POST("/products", func(c *gin.Context) {
+		c.Set("appCtxProduct", repo)
+		repo.CreateProducts(c)
+	})
+
+	inputProducts := []models.CreateProducts{
+		{Name: "Agua pack", Price: 300, Vat: 1000, Stock: decimal.NewFromInt(10), BarcodeNumber: "4006381333931"},
+	}
+	requestBody, _ := json.Marshal(inputProducts)
+
+	mockDB.EXPECT().Where("code IN ?", []string{"4006381333931"}).Return(mockDB).Times(1)
+	mockDB.EXPECT().
+		Find(gomock.Any()).
+		DoAndReturn(func(dest interface{}, conds ...interface{}) *gorm.DB {
+			*dest.(*[]models.ProductBarcode) = []models.ProductBarcode{{ID: 2, ProductID: 3, Code: "4006381333931"}}
+			return &gorm.DB{Error: nil}
+		}).Times(1)
+
+	// Nothing must be stored
+	mockDB.EXPECT().Create(gomock.Any()).Times(0)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/products", bytes.NewBuffer(requestBody))
+	req.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.Contains(t, w.Body.String(), "barcode belongs to another product")
+}
+
+func TestCreateProductWrongCheckDigit(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDB := database.NewMockDatabase(ctrl)
+	mockCache := cache.NewMockCache(ctrl)
+	ctx := context.Background()
+	repo := NewProductRepository(mockDB, mockCache, &ctx)
+
+	// Set up Gin
+	gin.SetMode(gin.TestMode)
+	r := gin.Default()
+	r.POST("/products", func(c *gin.Context) {
+		c.Set("appCtxProduct", repo)
+		repo.CreateProducts(c)
+	})
+
+	inputProducts := []models.CreateProducts{
+		{Name: "Agua", Price: 50, Vat: 1000, Stock: decimal.NewFromInt(10), BarcodeNumber: "96385074", Barcodes: []models.CreateProductBarcode{
+			{Code: "036000291453", Quantity: decimal.NewFromInt(6)},
+		}},
+	}
+	requestBody, _ := json.Marshal(inputProducts)
+
+	// A misread UPC-A is refused before any lookup
+	mockDB.EXPECT().Where(gomock.Any(), gomock.Any()).Times(0)
+	mockDB.EXPECT().Create(gomock.Any()).Times(0)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/products", bytes.NewBuffer(requestBody))
+	req.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "wrong check digit")
+}
+
+func TestApplyPriceLabel(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDB := database.NewMockDatabase(ctrl)
+
+	settings := models.DefaultStoreSettings()
+	settings.BarcodeFormats = models.BarcodeFormats{{Prefix: "22", ItemDigits: 5, Value: models.BarcodePrice, ValueDigits: 5, Decimals: 2}}
+
+	mockDB.EXPECT().Where("id = ?", models.StoreSettingsID).Return(mockDB).Times(2)
+	mockDB.EXPECT().
+		First(gomock.Any()).
+		DoAndReturn(func(dest interface{}, conds ...interface{}) database.Database {
+			*dest.(*models.StoreSettings) = settings
+			return mockDB
+		}).Times(2)
+	mockDB.EXPECT().Error().Return(nil).Times(2)
+
+	product := models.Product{ID: 9, Name: "Jamón", Price: 2999, ItemCode: "45"}
+
+	// 10.00 pays for 0.333 kg, which would otherwise be charged 9.99
+	line := models.OrderLine{ProductID: 9, Quantity: decimal.RequireFromString("0.333"), Price: 2999, Total: 999}
+	assert.NoError(t, applyPriceLabel(mockDB, &line, product, "2200045010004"))
+	assert.Equal(t, models.Money(1000), line.Total)
+
+	// The label cannot be used to charge 10.00 for more than it pays for
+	line = models.OrderLine{ProductID: 9, Quantity: decimal.NewFromInt(1), Price: 2999, Total: 2999}
+	assert.ErrorIs(t, applyPriceLabel(mockDB, &line, product, "2200045010004"), errInvalidBarcode)
+	assert.Equal(t, models.Money(2999), line.Total)
+}
+
+func TestCreateProductItemCodeTaken(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDB := database.NewMockDatabase(ctrl)
+	mockCache := cache.NewMockCache(ctrl)
+	ctx := context.Background()
+	repo := NewProductRepository(mockDB, mockCache, &ctx)
+
+	// Set up Gin
+	gin.SetMode(gin.TestMode)
+	r := gin.Default()
+	r.POST("/products", func(c *gin.Context) {
+		c.Set("appCtxProduct", repo)
+		repo.CreateProducts(c)
+	})
+
+	inputProducts := []models.CreateProducts{
+		{Name: "Jamón", Price: 2999, Vat: 1000, BarcodeNumber: "4006381333931", ItemCode: "0045"},
+	}
+	requestBody, _ := json.Marshal(inputProducts)
+
+	mockDB.EXPECT().Where("item_code = ?", "45").Return(mockDB).Times(1)
+	mockDB.EXPECT().
+		First(gomock.Any()).
+		DoAndReturn(func(dest interface{}, conds ...interface{}) database.Database {
+			*dest.(*models.Product) = models.Product{ID: 9, Name: "Queso", ItemCode: "45"}
+			return mockDB
+		}).Times(1)
+	mockDB.EXPECT().Error().Return(nil).Times(1)
+
+	// Nothing must be stored
+	mockDB.EXPECT().Create(gomock.Any()).Times(0)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/products", bytes.NewBuffer(requestBody))
+	req.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.Contains(t, w.Body.String(), "45 is the item code of product 9")
+}
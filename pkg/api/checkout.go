@@ -0,0 +1,209 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"postui_api/pkg/coupons"
+	"postui_api/pkg/database"
+	"postui_api/pkg/loyalty"
+	"postui_api/pkg/models"
+	"postui_api/pkg/pricing"
+	"postui_api/pkg/promotions"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+var (
+	errProductNotFound   = errors.New("product not found")
+	errInvalidQuantity   = errors.New("invalid quantity")
+	errInsufficientStock = errors.New("insufficient stock")
+)
+
+type CheckoutRepository interface {
+	Checkout(c *gin.Context)
+}
+
+// checkoutRepository holds shared resources like database
+type checkoutRepository struct {
+	DB  database.Database
+	Ctx *context.Context
+}
+
+// NewCheckoutRepository creates a new checkoutRepository
+func NewCheckoutRepository(db database.Database, ctx *context.Context) *checkoutRepository {
+	return &checkoutRepository{
+		DB:  db,
+		Ctx: ctx,
+	}
+}
+
+// @BasePath /api/v1
+
+// Checkout godoc
+// @Summary Checkout a cart
+// @Description Create an order and its lines from a cart in a single transaction, pricing them from the catalog, applying the current promotions and the given coupon code and decrementing product stock
+// @Tags checkout
+// @Security JwtAuth
+// @Accept  json
+// @Produce  json
+// @Param   input     body   models.Checkout   true   "Cart to checkout"
+// @Param Idempotency-Key header string false "Key under which the response is replayed to retries"
+// @Success 201 {object} models.OrderDetail "Successfully created order"
+// @Failure 400 {string} string "Bad Request"
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 404 {string} string "Product or coupon not found"
+// @Failure 409 {string} string "Insufficient stock, no open register session or coupon cannot be redeemed"
+// @Failure 422 {string} string "Amounts out of range, mixed currencies or coupon does not apply"
+// @Router /checkout [post]
+func (r *checkoutRepository) Checkout(c *gin.Context) {
+	appCtx, exists := c.MustGet("appCtxCheckout").(*checkoutRepository)
+	if !exists {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	var input models.Checkout
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	for _, line := range input.Lines {
+		if !line.Quantity.IsPositive() {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("quantity for product %d must be positive", line.ProductID)})
+			return
+		}
+	}
+
+	var detail models.OrderDetail
+
+	err := appCtx.DB.Transaction(func(tx database.Database) error {
+		if err := requireOpenSession(tx, input.CashoutNumber); err != nil {
+			return err
+		}
+
+		var err error
+		detail, err = checkoutOrder(tx, input, c.GetString("username"), time.Now(), nil)
+		return err
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, errInsufficientStock):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		case errors.Is(err, errNoOpenSession):
+			respondRegisterError(c, err)
+		case errors.Is(err, errCustomerNotFound):
+			respondCustomerError(c, err)
+		case errors.Is(err, errCouponNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case errors.Is(err, coupons.ErrInactive), errors.Is(err, coupons.ErrExpired), errors.Is(err, coupons.ErrExhausted), errors.Is(err, coupons.ErrCustomerLimit):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		case errors.Is(err, errCouponNotApplicable):
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		default:
+			respondPricingError(c, err)
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"data": detail})
+}
+
+// checkoutOrder creates an open order and its lines from a cart sold at now,
+// applying the promotions current then and decrementing product stock.
+// clientID is the id a terminal gave the order, if any.
+func checkoutOrder(tx database.Database, input models.Checkout, cashier string, now time.Time, clientID *string) (models.OrderDetail, error) {
+	customerName, err := orderCustomer(tx, input.CustomerID, input.CustomerName)
+	if err != nil {
+		return models.OrderDetail{}, err
+	}
+
+	items := make([]promotions.Item, 0, len(input.Lines))
+	sold := make([]models.OrderLine, 0, len(input.Lines))
+
+	for _, item := range input.Lines {
+		line, product, err := priceLabeledLine(tx, item.ProductID, item.VariantID, item.Quantity, item.PriceLabel, now)
+		if err != nil {
+			return models.OrderDetail{}, err
+		}
+		line.Stocked = true
+
+		items = append(items, promotions.Item{Line: line, Category: product.Category})
+		sold = append(sold, line)
+	}
+
+	// Variants have stock of their own
+	for _, line := range byProduct(sold) {
+		if err := takeStock(tx, line, line.Quantity); err != nil {
+			return models.OrderDetail{}, err
+		}
+	}
+
+	current, err := currentPromotions(tx, now)
+	if err != nil {
+		return models.OrderDetail{}, err
+	}
+
+	var coupon models.Coupon
+	if input.CouponCode != "" {
+		var promotion models.Promotion
+		if coupon, promotion, err = findCoupon(tx, input.CouponCode, now); err != nil {
+			return models.OrderDetail{}, err
+		}
+		current = append(current, promotion)
+	}
+
+	lines, discounts := promotions.Apply(items, current, now)
+
+	var couponDiscount models.Money
+	for _, discount := range discounts {
+		if input.CouponCode != "" && discount.PromotionID == coupon.PromotionID {
+			couponDiscount += discount.Amount
+		}
+	}
+	if input.CouponCode != "" && couponDiscount == 0 {
+		return models.OrderDetail{}, fmt.Errorf("%w: %s", errCouponNotApplicable, coupon.Code)
+	}
+
+	total, currency, err := orderAmounts(lines)
+	if err != nil {
+		return models.OrderDetail{}, err
+	}
+
+	order := models.Order{CustomerID: input.CustomerID, CustomerName: customerName, LoyaltyID: loyalty.Normalize(input.LoyaltyID), Total: total, Taxes: pricing.Breakdown(lines), Currency: currency, CashoutNumber: input.CashoutNumber, Status: models.OrderOpen, Cashier: cashier, ClientID: clientID, CreatedAt: now}
+	if err := tx.Create(&order).Error; err != nil {
+		return models.OrderDetail{}, err
+	}
+
+	for i := range lines {
+		lines[i].OrderID = &order.ID
+	}
+	if err := tx.Create(&lines).Error; err != nil {
+		return models.OrderDetail{}, err
+	}
+
+	if len(discounts) > 0 {
+		for i := range discounts {
+			discounts[i].OrderID = order.ID
+			if discounts[i].Line >= 0 {
+				lineID := lines[discounts[i].Line].ID
+				discounts[i].OrderLineID = &lineID
+			}
+		}
+		if err := tx.Create(&discounts).Error; err != nil {
+			return models.OrderDetail{}, err
+		}
+	}
+
+	if input.CouponCode != "" {
+		if err := redeemCoupon(tx, coupon, order, couponDiscount); err != nil {
+			return models.OrderDetail{}, err
+		}
+	}
+
+	return models.OrderDetail{Order: order, Lines: lines, Taxes: order.Taxes, Discounts: discounts}, nil
+}
@@ -0,0 +1,129 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"postui_api/pkg/database"
+	"postui_api/pkg/models"
+	"testing"
+
+	"gorm.io/gorm"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewCheckoutRepository(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDB := database.NewMockDatabase(ctrl)
+	mockCtx := context.Background()
+
+	repo := NewCheckoutRepository(mockDB, &mockCtx)
+
+	assert.NotNil(t, repo, "NewCheckoutRepository should return a non-nil instance of checkoutRepository")
+	assert.Equal(t, mockDB, repo.DB, "DB should be set to the mock database instance")
+}
+
+func TestCheckoutRejectsNonPositiveQuantity(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDB := database.NewMockDatabase(ctrl)
+	ctx := context.Background()
+
+	repo := NewCheckoutRepository(mockDB, &ctx)
+
+	// Set up Gin
+	gin.SetMode(gin.TestMode)
+	r := gin.Default()
+	r.POST("/checkout", func(c *gin.Context) {
+		// Set the appCtx in the Gin context
+		c.Set("appCtxCheckout", repo)
+		repo.Checkout(c)
+	})
+
+	input := models.Checkout{
+		CustomerName:  "username",
+		CashoutNumber: 1,
+		Lines:         []models.CheckoutLine{{ProductID: 1, Quantity: decimal.NewFromInt(-1)}},
+	}
+
+	requestBody, err := json.Marshal(input)
+	if err != nil {
+		t.Fatalf("Failed to marshal checkout data: %v", err)
+	}
+
+	// No transaction must be started for an invalid cart
+	mockDB.EXPECT().Transaction(gomock.Any()).Times(0)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPost, "/checkout", bytes.NewBuffer(requestBody))
+	req.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestCheckoutProductNotFound(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDB := database.NewMockDatabase(ctrl)
+	ctx := context.Background()
+
+	repo := NewCheckoutRepository(mockDB, &ctx)
+
+	// Set up Gin
+	gin.SetMode(gin.TestMode)
+	r := gin.Default()
+	r.POST("/checkout", func(c *gin.Context) {
+		// Set the appCtx in the Gin context
+		c.Set("appCtxCheckout", repo)
+		repo.Checkout(c)
+	})
+
+	input := models.Checkout{
+		CustomerName:  "username",
+		CashoutNumber: 1,
+		Lines:         []models.CheckoutLine{{ProductID: 42, Quantity: decimal.NewFromInt(2)}},
+	}
+
+	requestBody, err := json.Marshal(input)
+	if err != nil {
+		t.Fatalf("Failed to marshal checkout data: %v", err)
+	}
+
+	// Run the transaction body against the same mock
+	mockDB.EXPECT().
+		Transaction(gomock.Any()).
+		DoAndReturn(func(fc func(database.Database) error) error {
+			return fc(mockDB)
+		}).Times(1)
+
+	// A register session is open for the cashout number
+	mockDB.EXPECT().Where("cashout_number = ? AND status = ?", uint(1), models.SessionOpen).Return(mockDB).Times(1)
+	mockDB.EXPECT().First(gomock.Any()).Return(mockDB).Times(1)
+	mockDB.EXPECT().Error().Return(nil).Times(1)
+
+	mockDB.EXPECT().Where("id = ?", uint(42)).Return(mockDB).Times(1)
+	mockDB.EXPECT().First(gomock.Any()).Return(mockDB).Times(1)
+	mockDB.EXPECT().Error().Return(gorm.ErrRecordNotFound).Times(1)
+
+	// Nothing must be written when a product is missing
+	mockDB.EXPECT().Create(gomock.Any()).Times(0)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPost, "/checkout", bytes.NewBuffer(requestBody))
+	req.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Contains(t, w.Body.String(), "product not found")
+}
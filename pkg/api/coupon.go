@@ -0,0 +1,329 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"postui_api/pkg/coupons"
+	"postui_api/pkg/database"
+	"postui_api/pkg/models"
+	"postui_api/pkg/promotions"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+var (
+	errCouponNotFound      = errors.New("coupon not found")
+	errCouponNotApplicable = errors.New("coupon does not apply to this order")
+	errCouponExists        = errors.New("coupon code already exists")
+)
+
+type CouponRepository interface {
+	FindCoupons(c *gin.Context)
+	CreateCoupons(c *gin.Context)
+	FindCoupon(c *gin.Context)
+	DeleteCoupon(c *gin.Context)
+}
+
+// couponRepository holds shared resources like database
+type couponRepository struct {
+	DB  database.Database
+	Ctx *context.Context
+}
+
+// NewCouponRepository creates a new couponRepository
+func NewCouponRepository(db database.Database, ctx *context.Context) *couponRepository {
+	return &couponRepository{
+		DB:  db,
+		Ctx: ctx,
+	}
+}
+
+// @BasePath /api/v1
+
+// FindCoupons godoc
+// @Summary Get coupons
+// @Description Get the coupons, optionally only those of a promotion
+// @Tags coupons
+// @Security JwtAuth
+// @Produce json
+// @Param promotion_id query int false "Promotion ID"
+// @Success 200 {array} models.Coupon "Successfully retrieved list of coupons"
+// @Failure 500 {string} string "Internal Server Error"
+// @Router /coupons [get]
+func (r *couponRepository) FindCoupons(c *gin.Context) {
+	var found []models.Coupon
+
+	db := r.DB
+	if promotionID := c.Query("promotion_id"); promotionID != "" {
+		db = db.Where("promotion_id = ?", promotionID)
+	}
+
+	if err := db.Find(&found).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": found})
+}
+
+// CreateCoupons godoc
+// @Summary Create coupons
+// @Description Create a coupon with the given code, or generate count coupons with random codes. Single use coupons have a max_redemptions of 1.
+// @Tags coupons
+// @Security JwtAuth
+// @Accept  json
+// @Produce  json
+// @Param   input     body   models.CreateCoupons   true   "Coupons to create"
+// @Success 201 {array} models.Coupon "Successfully created coupons"
+// @Failure 400 {string} string "Bad Request"
+// @Failure 404 {string} string "promotion not found"
+// @Failure 409 {string} string "Coupon code already exists"
+// @Router /coupons [post]
+func (r *couponRepository) CreateCoupons(c *gin.Context) {
+	appCtx, exists := c.MustGet("appCtxCoupon").(*couponRepository)
+	if !exists {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	var input models.CreateCoupons
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if input.Code != "" && input.Count > 1 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "a code cannot be given when generating several coupons"})
+		return
+	}
+	count := input.Count
+	if count == 0 {
+		count = 1
+	}
+
+	var created []models.Coupon
+
+	err := appCtx.DB.Transaction(func(tx database.Database) error {
+		var promotion models.Promotion
+		if err := tx.Where("id = ?", input.PromotionID).First(&promotion).Error(); err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return fmt.Errorf("%w: %d", errPromotionNotFound, input.PromotionID)
+			}
+			return err
+		}
+
+		if input.Code != "" {
+			code := coupons.Normalize(input.Code)
+
+			var existing models.Coupon
+			err := tx.Where("code = ?", code).First(&existing).Error()
+			if err == nil {
+				return fmt.Errorf("%w: %s", errCouponExists, code)
+			}
+			if !errors.Is(err, gorm.ErrRecordNotFound) {
+				return err
+			}
+
+			created = append(created, newCoupon(input, code))
+		}
+
+		for uint(len(created)) < count {
+			code, err := coupons.Generate(input.Prefix)
+			if err != nil {
+				return err
+			}
+			created = append(created, newCoupon(input, code))
+		}
+
+		// The unique index on codes rejects codes created concurrently
+		return tx.Create(&created).Error
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, errPromotionNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case errors.Is(err, errCouponExists):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"data": created})
+}
+
+// FindCoupon godoc
+// @Summary Find a coupon by ID
+// @Description Get details of a coupon by its ID
+// @Tags coupons
+// @Security JwtAuth
+// @Produce json
+// @Param id path string true "Coupon ID"
+// @Success 200 {object} models.Coupon "Successfully retrieved coupon"
+// @Failure 404 {string} string "coupon not found"
+// @Router /coupons/{id} [get]
+func (r *couponRepository) FindCoupon(c *gin.Context) {
+	var coupon models.Coupon
+
+	if err := r.DB.Where("id = ?", c.Param("id")).First(&coupon).Error(); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": errCouponNotFound.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": coupon})
+}
+
+// DeleteCoupon godoc
+// @Summary Deactivate a coupon by ID
+// @Description Deactivate the coupon with the given ID so it can no longer be redeemed. It is kept for its past redemptions.
+// @Tags coupons
+// @Security JwtAuth
+// @Produce json
+// @Param id path string true "Coupon ID"
+// @Success 204 {string} string "Successfully deactivated coupon"
+// @Failure 404 {string} string "coupon not found"
+// @Router /coupons/{id} [delete]
+func (r *couponRepository) DeleteCoupon(c *gin.Context) {
+	var coupon models.Coupon
+
+	if err := r.DB.Where("id = ?", c.Param("id")).First(&coupon).Error(); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": errCouponNotFound.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	if err := r.DB.Model(&coupon).Update("active", false).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	c.JSON(http.StatusNoContent, gin.H{"data": true})
+}
+
+// newCoupon returns an active coupon with code and the limits of input
+func newCoupon(input models.CreateCoupons, code string) models.Coupon {
+	return models.Coupon{
+		Code:             code,
+		PromotionID:      input.PromotionID,
+		MaxRedemptions:   input.MaxRedemptions,
+		PerCustomerLimit: input.PerCustomerLimit,
+		ExpiresAt:        input.ExpiresAt,
+		Active:           true,
+	}
+}
+
+// findCoupon loads the coupon with code and its promotion, failing if it
+// cannot be redeemed at now
+func findCoupon(db database.Database, code string, now time.Time) (models.Coupon, models.Promotion, error) {
+	var coupon models.Coupon
+	var promotion models.Promotion
+
+	code = coupons.Normalize(code)
+	if err := db.Where("code = ?", code).First(&coupon).Error(); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return coupon, promotion, fmt.Errorf("%w: %s", errCouponNotFound, code)
+		}
+		return coupon, promotion, err
+	}
+
+	if err := coupons.Check(coupon, now); err != nil {
+		return coupon, promotion, err
+	}
+
+	if err := db.Where("id = ?", coupon.PromotionID).First(&promotion).Error(); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return coupon, promotion, fmt.Errorf("%w: its promotion was deleted", errCouponNotApplicable)
+		}
+		return coupon, promotion, err
+	}
+	if !promotions.Current(promotion, now) {
+		return coupon, promotion, fmt.Errorf("%w: its promotion is not running", errCouponNotApplicable)
+	}
+
+	return coupon, promotion, nil
+}
+
+// redeemCoupon records the redemption of coupon by order. The redemption
+// count is only incremented while redemptions are left, and the update locks
+// the coupon until the transaction ends so that per customer counts cannot
+// be raced either.
+func redeemCoupon(tx database.Database, coupon models.Coupon, order models.Order, amount models.Money) error {
+	result := tx.Model(&models.Coupon{}).
+		Where("id = ? AND active = ? AND (max_redemptions = 0 OR redemptions < max_redemptions)", coupon.ID, true).
+		Update("redemptions", gorm.Expr("redemptions + 1"))
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("%w: %s", coupons.ErrExhausted, coupon.Code)
+	}
+
+	if coupon.PerCustomerLimit != 0 {
+		// Linked customers are counted by record, others by the name on the order
+		query := tx.Model(&models.CouponRedemption{})
+		switch {
+		case order.CustomerID != nil:
+			query = query.Where("coupon_id = ? AND customer_id = ? AND reversed_at IS NULL", coupon.ID, *order.CustomerID)
+		case order.CustomerName != "":
+			query = query.Where("coupon_id = ? AND customer = ? AND reversed_at IS NULL", coupon.ID, order.CustomerName)
+		default:
+			return fmt.Errorf("%w: a customer is required for %s", coupons.ErrCustomerLimit, coupon.Code)
+		}
+
+		var redeemed int64
+		err := query.Count(&redeemed).Error
+		if err != nil {
+			return err
+		}
+		if redeemed >= int64(coupon.PerCustomerLimit) {
+			return fmt.Errorf("%w: %s", coupons.ErrCustomerLimit, coupon.Code)
+		}
+	}
+
+	return tx.Create(&models.CouponRedemption{CouponID: coupon.ID, OrderID: order.ID, CustomerID: order.CustomerID, Customer: order.CustomerName, Amount: amount}).Error
+}
+
+// reverseCouponRedemptions reverses the redemptions of order, giving their
+// coupons a redemption back
+func reverseCouponRedemptions(tx database.Database, orderID uint) error {
+	var found []models.CouponRedemption
+	if err := tx.Where("order_id = ? AND reversed_at IS NULL", orderID).Find(&found).Error; err != nil {
+		return err
+	}
+
+	now := time.Now()
+	for _, redemption := range found {
+		if err := reverseCouponRedemption(tx, redemption, now); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// reverseCouponRedemption reverses redemption at now, giving its coupon a
+// redemption back
+func reverseCouponRedemption(tx database.Database, redemption models.CouponRedemption, now time.Time) error {
+	result := tx.Model(&models.CouponRedemption{}).Where("id = ? AND reversed_at IS NULL", redemption.ID).Update("reversed_at", now)
+	if result.Error != nil {
+		return result.Error
+	}
+	// Already reversed concurrently
+	if result.RowsAffected == 0 {
+		return nil
+	}
+
+	return tx.Model(&models.Coupon{}).Where("id = ? AND redemptions > 0", redemption.CouponID).Update("redemptions", gorm.Expr("redemptions - 1")).Error
+}
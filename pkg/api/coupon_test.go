@@ -0,0 +1,179 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"postui_api/pkg/coupons"
+	"postui_api/pkg/database"
+	"postui_api/pkg/models"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func TestNewCouponRepository(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDB := database.NewMockDatabase(ctrl)
+	mockCtx := context.Background()
+
+	repo := NewCouponRepository(mockDB, &mockCtx)
+
+	assert.NotNil(t, repo, "NewCouponRepository should return a non-nil instance of couponRepository")
+	assert.Equal(t, mockDB, repo.DB, "DB should be set to the mock database instance")
+}
+
+func postCoupons(t *testing.T, repo *couponRepository, input models.CreateCoupons) *httptest.ResponseRecorder {
+	// Set up Gin
+	gin.SetMode(gin.TestMode)
+	r := gin.Default()
+	r.POST("/coupons", func(c *gin.Context) {
+		// Set the appCtx in the Gin context
+		c.Set("appCtxCoupon", repo)
+		repo.CreateCoupons(c)
+	})
+
+	requestBody, err := json.Marshal(input)
+	if err != nil {
+		t.Fatalf("Failed to marshal coupon data: %v", err)
+	}
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/coupons", bytes.NewBuffer(requestBody))
+	req.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(w, req)
+
+	return w
+}
+
+func TestCreateCouponsBulk(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDB := database.NewMockDatabase(ctrl)
+	ctx := context.Background()
+	repo := NewCouponRepository(mockDB, &ctx)
+
+	mockDB.EXPECT().
+		Transaction(gomock.Any()).
+		DoAndReturn(func(fc func(database.Database) error) error {
+			return fc(mockDB)
+		}).Times(1)
+	mockDB.EXPECT().Where("id = ?", uint(4)).Return(mockDB).Times(1)
+	mockDB.EXPECT().First(gomock.Any()).Return(mockDB).Times(1)
+	mockDB.EXPECT().Error().Return(nil).Times(1)
+	mockDB.EXPECT().Create(gomock.Any()).Return(&gorm.DB{Error: nil}).Times(1)
+
+	w := postCoupons(t, repo, models.CreateCoupons{PromotionID: 4, Count: 3, Prefix: "vip", MaxRedemptions: 1})
+
+	var response struct {
+		Data []models.Coupon `json:"data"`
+	}
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+	assert.Len(t, response.Data, 3)
+	for _, coupon := range response.Data {
+		assert.Regexp(t, "^VIP[A-Z2-9]{10}$", coupon.Code)
+		assert.Equal(t, uint(1), coupon.MaxRedemptions)
+		assert.True(t, coupon.Active)
+	}
+}
+
+func TestCreateCouponsCodeAndCount(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDB := database.NewMockDatabase(ctrl)
+	ctx := context.Background()
+	repo := NewCouponRepository(mockDB, &ctx)
+
+	mockDB.EXPECT().Transaction(gomock.Any()).Times(0)
+
+	w := postCoupons(t, repo, models.CreateCoupons{PromotionID: 4, Code: "WELCOME", Count: 2})
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestCreateCouponsExistingCode(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDB := database.NewMockDatabase(ctrl)
+	ctx := context.Background()
+	repo := NewCouponRepository(mockDB, &ctx)
+
+	mockDB.EXPECT().
+		Transaction(gomock.Any()).
+		DoAndReturn(func(fc func(database.Database) error) error {
+			return fc(mockDB)
+		}).Times(1)
+	mockDB.EXPECT().Where("id = ?", uint(4)).Return(mockDB).Times(1)
+	mockDB.EXPECT().Where("code = ?", "WELCOME").Return(mockDB).Times(1)
+	mockDB.EXPECT().First(gomock.Any()).Return(mockDB).Times(2)
+	mockDB.EXPECT().Error().Return(nil).Times(2)
+	mockDB.EXPECT().Create(gomock.Any()).Times(0)
+
+	w := postCoupons(t, repo, models.CreateCoupons{PromotionID: 4, Code: "welcome"})
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.Contains(t, w.Body.String(), "coupon code already exists: WELCOME")
+}
+
+func TestFindCoupon(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDB := database.NewMockDatabase(ctrl)
+	now := time.Date(2026, 5, 1, 10, 0, 0, 0, time.UTC)
+	expired := now.Add(-time.Hour)
+
+	// Unknown code
+	mockDB.EXPECT().Where("code = ?", "NOPE").Return(mockDB).Times(1)
+	mockDB.EXPECT().First(gomock.Any()).Return(mockDB).Times(1)
+	mockDB.EXPECT().Error().Return(gorm.ErrRecordNotFound).Times(1)
+
+	_, _, err := findCoupon(mockDB, " nope", now)
+	assert.True(t, errors.Is(err, errCouponNotFound))
+
+	// Expired coupon
+	mockDB.EXPECT().Where("code = ?", "OLD").Return(mockDB).Times(1)
+	mockDB.EXPECT().
+		First(gomock.Any()).
+		DoAndReturn(func(dest interface{}, conds ...interface{}) database.Database {
+			*dest.(*models.Coupon) = models.Coupon{ID: 1, Code: "OLD", PromotionID: 2, Active: true, ExpiresAt: &expired}
+			return mockDB
+		}).Times(1)
+	mockDB.EXPECT().Error().Return(nil).Times(1)
+
+	_, _, err = findCoupon(mockDB, "old", now)
+	assert.True(t, errors.Is(err, coupons.ErrExpired))
+
+	// Coupon of a promotion that is not running
+	mockDB.EXPECT().Where("code = ?", "SPRING").Return(mockDB).Times(1)
+	mockDB.EXPECT().Where("id = ?", uint(2)).Return(mockDB).Times(1)
+	mockDB.EXPECT().
+		First(gomock.Any()).
+		DoAndReturn(func(dest interface{}, conds ...interface{}) database.Database {
+			switch b := dest.(type) {
+			case *models.Coupon:
+				*b = models.Coupon{ID: 2, Code: "SPRING", PromotionID: 2, Active: true}
+			case *models.Promotion:
+				*b = models.Promotion{ID: 2, Kind: models.PromotionPercentage, Percent: 1000, CouponOnly: true}
+			}
+			return mockDB
+		}).Times(2)
+	mockDB.EXPECT().Error().Return(nil).Times(2)
+
+	_, _, err = findCoupon(mockDB, "SPRING", now)
+	assert.True(t, errors.Is(err, errCouponNotApplicable))
+}
@@ -0,0 +1,288 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"postui_api/pkg/database"
+	"postui_api/pkg/models"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+var errCustomerNotFound = errors.New("customer not found")
+
+type CustomerRepository interface {
+	FindCustomers(c *gin.Context)
+	CreateCustomer(c *gin.Context)
+	FindCustomer(c *gin.Context)
+	UpdateCustomer(c *gin.Context)
+	DeleteCustomer(c *gin.Context)
+	FindCustomerOrders(c *gin.Context)
+}
+
+// customerRepository holds shared resources like database
+type customerRepository struct {
+	DB  database.Database
+	Ctx *context.Context
+}
+
+// NewCustomerRepository creates a new customerRepository
+func NewCustomerRepository(db database.Database, ctx *context.Context) *customerRepository {
+	return &customerRepository{
+		DB:  db,
+		Ctx: ctx,
+	}
+}
+
+// @BasePath /api/v1
+
+// FindCustomers godoc
+// @Summary Search customers
+// @Description Get customers with pagination, optionally only those whose name, tax ID, email or phone contains q
+// @Tags customers
+// @Security JwtAuth
+// @Produce json
+// @Param q query string false "Text to search for"
+// @Param offset query int false "Offset for pagination" default(0)
+// @Param limit query int false "Limit for pagination" default(10)
+// @Success 200 {array} models.Customer "Successfully retrieved list of customers"
+// @Failure 400 {string} string "Bad Request"
+// @Router /customers [get]
+func (r *customerRepository) FindCustomers(c *gin.Context) {
+	offset, limit, ok := pageParams(c)
+	if !ok {
+		return
+	}
+
+	query := r.DB.Model(&models.Customer{})
+	if q := strings.TrimSpace(c.Query("q")); q != "" {
+		pattern := "%" + likeEscaper.Replace(q) + "%"
+		query = query.Where("name ILIKE ? OR tax_id ILIKE ? OR email ILIKE ? OR phone ILIKE ?", pattern, pattern, pattern, pattern)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	var found []models.Customer
+	if err := query.Order("name, id").Offset(offset).Limit(limit).Find(&found).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":       found,
+		"pagination": newPagination(offset, limit, total),
+	})
+}
+
+// CreateCustomer godoc
+// @Summary Create a customer
+// @Description Create a customer orders can be linked to
+// @Tags customers
+// @Security JwtAuth
+// @Accept  json
+// @Produce  json
+// @Param   input     body   models.CreateCustomer   true   "Customer object"
+// @Success 201 {object} models.Customer "Successfully created customer"
+// @Failure 400 {string} string "Bad Request"
+// @Router /customers [post]
+func (r *customerRepository) CreateCustomer(c *gin.Context) {
+	appCtx, exists := c.MustGet("appCtxCustomer").(*customerRepository)
+	if !exists {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	var input models.CreateCustomer
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	customer := models.Customer{Name: input.Name, TaxID: input.TaxID, Email: input.Email, Phone: input.Phone, Address: input.Address}
+	if err := appCtx.DB.Create(&customer).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"data": customer})
+}
+
+// FindCustomer godoc
+// @Summary Find a customer by ID
+// @Description Get details of a customer by its ID
+// @Tags customers
+// @Security JwtAuth
+// @Produce json
+// @Param id path string true "Customer ID"
+// @Success 200 {object} models.Customer "Successfully retrieved customer"
+// @Failure 404 {string} string "customer not found"
+// @Router /customers/{id} [get]
+func (r *customerRepository) FindCustomer(c *gin.Context) {
+	customer, err := findCustomer(r.DB, c.Param("id"))
+	if err != nil {
+		respondCustomerError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": customer})
+}
+
+// UpdateCustomer godoc
+// @Summary Update a customer by ID
+// @Description Update the customer details for the given ID. Omitted fields are left unchanged.
+// @Tags customers
+// @Security JwtAuth
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Customer ID"
+// @Param input body models.UpdateCustomer true "Update customer object"
+// @Success 200 {object} models.Customer "Successfully updated customer"
+// @Failure 400 {string} string "Bad Request"
+// @Failure 404 {string} string "customer not found"
+// @Router /customers/{id} [put]
+func (r *customerRepository) UpdateCustomer(c *gin.Context) {
+	var input models.UpdateCustomer
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	customer, err := findCustomer(r.DB, c.Param("id"))
+	if err != nil {
+		respondCustomerError(c, err)
+		return
+	}
+
+	if err := r.DB.Model(&customer).Updates(models.Customer{Name: input.Name, TaxID: input.TaxID, Email: input.Email, Phone: input.Phone, Address: input.Address}).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": customer})
+}
+
+// DeleteCustomer godoc
+// @Summary Delete a customer by ID
+// @Description Delete the customer with the given ID. Its orders keep the customer name but are no longer linked to it.
+// @Tags customers
+// @Security JwtAuth
+// @Produce json
+// @Param id path string true "Customer ID"
+// @Success 204 {string} string "Successfully deleted customer"
+// @Failure 404 {string} string "customer not found"
+// @Router /customers/{id} [delete]
+func (r *customerRepository) DeleteCustomer(c *gin.Context) {
+	err := r.DB.Transaction(func(tx database.Database) error {
+		customer, err := findCustomer(tx, c.Param("id"))
+		if err != nil {
+			return err
+		}
+
+		if err := tx.Model(&models.Order{}).Where("customer_id = ?", customer.ID).Update("customer_id", nil).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&customer).Error
+	})
+	if err != nil {
+		respondCustomerError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusNoContent, gin.H{"data": true})
+}
+
+// FindCustomerOrders godoc
+// @Summary Get the purchase history of a customer
+// @Description Get the orders of a customer with pagination, the most recent first
+// @Tags customers
+// @Security JwtAuth
+// @Produce json
+// @Param id path string true "Customer ID"
+// @Param offset query int false "Offset for pagination" default(0)
+// @Param limit query int false "Limit for pagination" default(10)
+// @Success 200 {array} models.Order "Successfully retrieved orders"
+// @Failure 400 {string} string "Bad Request"
+// @Failure 404 {string} string "customer not found"
+// @Router /customers/{id}/orders [get]
+func (r *customerRepository) FindCustomerOrders(c *gin.Context) {
+	offset, limit, ok := pageParams(c)
+	if !ok {
+		return
+	}
+
+	customer, err := findCustomer(r.DB, c.Param("id"))
+	if err != nil {
+		respondCustomerError(c, err)
+		return
+	}
+
+	var total int64
+	if err := r.DB.Model(&models.Order{}).Where("customer_id = ?", customer.ID).Count(&total).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	var found []models.Order
+	if err := r.DB.Model(&models.Order{}).Where("customer_id = ?", customer.ID).Order("id DESC").Offset(offset).Limit(limit).Find(&found).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":       found,
+		"pagination": newPagination(offset, limit, total),
+	})
+}
+
+// findCustomer returns the customer with the given id
+func findCustomer(db database.Database, id interface{}) (models.Customer, error) {
+	var customer models.Customer
+
+	if err := db.Where("id = ?", id).First(&customer).Error(); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return customer, fmt.Errorf("%w: %v", errCustomerNotFound, id)
+		}
+		return customer, err
+	}
+
+	return customer, nil
+}
+
+// orderCustomer returns the name to record on an order for the customer id
+// and name given, checking that the customer exists. The name defaults to
+// the one of the customer.
+func orderCustomer(db database.Database, id *uint, name string) (string, error) {
+	if id == nil {
+		return name, nil
+	}
+
+	customer, err := findCustomer(db, *id)
+	if err != nil {
+		return "", err
+	}
+
+	if name == "" {
+		return customer.Name, nil
+	}
+	return name, nil
+}
+
+// respondCustomerError maps an error returned while looking up a customer to
+// a response
+func respondCustomerError(c *gin.Context, err error) {
+	if errors.Is(err, errCustomerNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+}
@@ -0,0 +1,187 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"postui_api/pkg/database"
+	"postui_api/pkg/models"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func TestNewCustomerRepository(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDB := database.NewMockDatabase(ctrl)
+	mockCtx := context.Background()
+
+	repo := NewCustomerRepository(mockDB, &mockCtx)
+
+	assert.NotNil(t, repo, "NewCustomerRepository should return a non-nil instance of customerRepository")
+	assert.Equal(t, mockDB, repo.DB, "DB should be set to the mock database instance")
+}
+
+func TestCreateCustomer(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDB := database.NewMockDatabase(ctrl)
+	ctx := context.Background()
+	repo := NewCustomerRepository(mockDB, &ctx)
+
+	// Set up Gin
+	gin.SetMode(gin.TestMode)
+	r := gin.Default()
+	r.POST("/customers", func(c *gin.Context) {
+		c.Set("appCtxCustomer", repo)
+		repo.CreateCustomer(c)
+	})
+
+	requestBody, err := json.Marshal(models.CreateCustomer{Name: "Acme SL", TaxID: "B12345678", Email: "billing@acme.test"})
+	if err != nil {
+		t.Fatalf("Failed to marshal customer data: %v", err)
+	}
+
+	mockDB.EXPECT().Create(gomock.Any()).DoAndReturn(func(customer *models.Customer) *gorm.DB {
+		customer.ID = 7
+		return &gorm.DB{Error: nil}
+	}).Times(1)
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/customers", bytes.NewBuffer(requestBody))
+	req.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(w, req)
+
+	var response struct {
+		Data models.Customer `json:"data"`
+	}
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+	assert.Equal(t, uint(7), response.Data.ID)
+	assert.Equal(t, "B12345678", response.Data.TaxID)
+}
+
+func TestCreateCustomerRequiresName(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDB := database.NewMockDatabase(ctrl)
+	ctx := context.Background()
+	repo := NewCustomerRepository(mockDB, &ctx)
+
+	// Set up Gin
+	gin.SetMode(gin.TestMode)
+	r := gin.Default()
+	r.POST("/customers", func(c *gin.Context) {
+		c.Set("appCtxCustomer", repo)
+		repo.CreateCustomer(c)
+	})
+
+	mockDB.EXPECT().Create(gomock.Any()).Times(0)
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/customers", bytes.NewBufferString(`{"email":"billing@acme.test"}`))
+	req.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestFindCustomerNotFound(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDB := database.NewMockDatabase(ctrl)
+	ctx := context.Background()
+	repo := NewCustomerRepository(mockDB, &ctx)
+
+	// Set up Gin
+	gin.SetMode(gin.TestMode)
+	r := gin.Default()
+	r.GET("/customers/:id", repo.FindCustomer)
+
+	mockDB.EXPECT().Where("id = ?", "9").Return(mockDB).Times(1)
+	mockDB.EXPECT().First(gomock.Any()).Return(mockDB).Times(1)
+	mockDB.EXPECT().Error().Return(gorm.ErrRecordNotFound).Times(1)
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/customers/9", nil)
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Contains(t, w.Body.String(), "customer not found")
+}
+
+func TestFindCustomersInvalidLimit(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDB := database.NewMockDatabase(ctrl)
+	ctx := context.Background()
+	repo := NewCustomerRepository(mockDB, &ctx)
+
+	// Set up Gin
+	gin.SetMode(gin.TestMode)
+	r := gin.Default()
+	r.GET("/customers", repo.FindCustomers)
+
+	mockDB.EXPECT().Model(gomock.Any()).Times(0)
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/customers?q=acme&limit=1000", nil)
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestCreateOrderUnknownCustomer(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDB := database.NewMockDatabase(ctrl)
+	ctx := context.Background()
+	repo := NewOrderRepository(mockDB, &ctx)
+
+	// Set up Gin
+	gin.SetMode(gin.TestMode)
+	r := gin.Default()
+	r.POST("/orders", func(c *gin.Context) {
+		c.Set("appCtxOrder", repo)
+		repo.CreateOrder(c)
+	})
+
+	customerID := uint(9)
+	requestBody, err := json.Marshal(models.CreateOrder{CustomerID: &customerID, Total: 121, LinesID: pq.Int64Array{1}, CashoutNumber: 1})
+	if err != nil {
+		t.Fatalf("Failed to marshal input order data: %v", err)
+	}
+
+	// A register session is open for the cashout number
+	mockDB.EXPECT().Where("cashout_number = ? AND status = ?", uint(1), models.SessionOpen).Return(mockDB).Times(1)
+	mockDB.EXPECT().First(gomock.Any()).Return(mockDB).Times(2)
+	mockDB.EXPECT().Error().Return(nil).Times(1)
+
+	// The customer does not exist
+	mockDB.EXPECT().Where("id = ?", uint(9)).Return(mockDB).Times(1)
+	mockDB.EXPECT().Error().Return(gorm.ErrRecordNotFound).Times(1)
+
+	mockDB.EXPECT().Create(gomock.Any()).Times(0)
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/orders", bytes.NewBuffer(requestBody))
+	req.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Contains(t, w.Body.String(), "customer not found")
+}
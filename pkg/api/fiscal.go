@@ -0,0 +1,199 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"postui_api/pkg/database"
+	"postui_api/pkg/fiscal"
+	"postui_api/pkg/models"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type FiscalRepository interface {
+	VerifyFiscalChain(c *gin.Context)
+	ExportFiscalRecords(c *gin.Context)
+}
+
+// fiscalRepository holds shared resources like database
+type fiscalRepository struct {
+	DB  database.Database
+	Ctx *context.Context
+}
+
+// NewFiscalRepository creates a new fiscalRepository
+func NewFiscalRepository(db database.Database, ctx *context.Context) *fiscalRepository {
+	return &fiscalRepository{
+		DB:  db,
+		Ctx: ctx,
+	}
+}
+
+// @BasePath /api/v1
+
+// VerifyFiscalChain godoc
+// @Summary Verify the fiscal record chain
+// @Description Walk the chain of fiscal records from the first one and report the records whose hash does not match their content or the previous record
+// @Tags fiscal
+// @Security JwtAuth
+// @Produce  json
+// @Success 200 {object} models.ChainVerification "Verification result"
+// @Failure 500 {string} string "Internal Server Error"
+// @Router /fiscal/verify [get]
+func (r *fiscalRepository) VerifyFiscalChain(c *gin.Context) {
+	var records []models.FiscalRecord
+
+	if err := r.DB.Order("id").Find(&records).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": fiscal.Verify(records)})
+}
+
+// ExportFiscalRecords godoc
+// @Summary Export fiscal records
+// @Description Export the fiscal records generated between two dates as a Verifactu XML submission
+// @Tags fiscal
+// @Security JwtAuth
+// @Produce  xml
+// @Param from query string false "First day, as YYYY-MM-DD (defaults to today)"
+// @Param to query string false "Last day, as YYYY-MM-DD, inclusive (defaults to from)"
+// @Success 200 {string} string "Verifactu XML"
+// @Failure 400 {string} string "Bad Request"
+// @Failure 500 {string} string "Internal Server Error"
+// @Router /fiscal/export [get]
+func (r *fiscalRepository) ExportFiscalRecords(c *gin.Context) {
+	from, to, err := reportPeriod(c.Query("from"), c.Query("to"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var records []models.FiscalRecord
+	if err := r.DB.Where("created_at >= ? AND created_at < ?", from, to).Order("id").Find(&records).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	// The first record exported is chained to one exported before
+	var previous *models.FiscalRecord
+	if len(records) > 0 && records[0].PreviousHash != "" {
+		var found models.FiscalRecord
+		if err := r.DB.Where("hash = ?", records[0].PreviousHash).First(&found).Error(); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+			return
+		}
+		previous = &found
+	}
+
+	settings, _, err := loadStoreSettings(r.DB)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	out, err := fiscal.Export(settings.Name, settings.TaxID, records, previous)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	c.Data(http.StatusOK, "application/xml", out)
+}
+
+// registerFiscalInvoice appends the registration record of order, paid with
+// lines. Refunds rectify the invoice of the order they refund.
+func registerFiscalInvoice(tx database.Database, order models.Order, lines []models.OrderLine) error {
+	taxes := orderTaxes(order, lines)
+
+	record := models.FiscalRecord{
+		Kind:        models.FiscalRegistration,
+		OrderID:     order.ID,
+		Invoice:     fiscalInvoice(order),
+		InvoiceType: models.InvoiceSimplified,
+		Total:       order.Total,
+		Taxes:       taxes,
+	}
+	for _, tax := range taxes {
+		record.TaxTotal += tax.Tax + tax.SurchargeTax
+	}
+
+	if order.RefundOfID != nil {
+		var rectified models.FiscalRecord
+		if err := tx.Where("order_id = ? AND kind = ?", *order.RefundOfID, models.FiscalRegistration).First(&rectified).Error(); err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+		record.InvoiceType = models.InvoiceRectification
+		record.RectifiedInvoice = rectified.Invoice
+		record.RectifiedDate = rectified.IssueDate
+	}
+
+	return appendFiscalRecord(tx, &record)
+}
+
+// cancelFiscalInvoice appends the cancellation record of the invoice of a
+// voided order, leaving its registration record untouched
+func cancelFiscalInvoice(tx database.Database, order models.Order) error {
+	var registration models.FiscalRecord
+	if err := tx.Where("order_id = ? AND kind = ?", order.ID, models.FiscalRegistration).First(&registration).Error(); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			// Paid before records existed, there is nothing to cancel
+			return nil
+		}
+		return err
+	}
+
+	return appendFiscalRecord(tx, &models.FiscalRecord{
+		Kind:      models.FiscalCancellation,
+		OrderID:   order.ID,
+		Invoice:   registration.Invoice,
+		IssueDate: registration.IssueDate,
+	})
+}
+
+// appendFiscalRecord chains record to the last record and stores it. The
+// chain head row stays locked until the transaction ends, so concurrent
+// appends are chained one after the other.
+func appendFiscalRecord(tx database.Database, record *models.FiscalRecord) error {
+	settings, _, err := loadStoreSettings(tx)
+	if err != nil {
+		return err
+	}
+	record.IssuerTaxID = settings.TaxID
+
+	if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&models.FiscalChain{ID: models.FiscalChainID}).Error; err != nil {
+		return err
+	}
+	if err := tx.Model(&models.FiscalChain{}).Where("id = ?", models.FiscalChainID).Update("length", gorm.Expr("length + 1")).Error; err != nil {
+		return err
+	}
+
+	var previous *models.FiscalRecord
+	var last models.FiscalRecord
+	err = tx.Order("id desc").First(&last).Error
+	switch {
+	case err == nil:
+		previous = &last
+	case !errors.Is(err, gorm.ErrRecordNotFound):
+		return err
+	}
+
+	fiscal.Seal(record, previous, time.Now())
+
+	return tx.Create(record).Error
+}
+
+// fiscalInvoice returns the invoice number of order, or its ID for orders
+// paid before invoices were numbered
+func fiscalInvoice(order models.Order) string {
+	if order.Invoice != "" {
+		return order.Invoice
+	}
+	return strconv.FormatUint(uint64(order.ID), 10)
+}
@@ -0,0 +1,54 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"postui_api/pkg/database"
+	"postui_api/pkg/models"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewFiscalRepository(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDB := database.NewMockDatabase(ctrl)
+	mockCtx := context.Background()
+
+	repo := NewFiscalRepository(mockDB, &mockCtx)
+
+	assert.NotNil(t, repo, "NewFiscalRepository should return a non-nil instance of fiscalRepository")
+	assert.Equal(t, mockDB, repo.DB, "DB should be set to the mock database instance")
+}
+
+func TestExportFiscalRecordsInvalidPeriod(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDB := database.NewMockDatabase(ctrl)
+	ctx := context.Background()
+	repo := NewFiscalRepository(mockDB, &ctx)
+
+	// Set up Gin
+	gin.SetMode(gin.TestMode)
+	r := gin.Default()
+	r.GET("/fiscal/export", repo.ExportFiscalRecords)
+
+	mockDB.EXPECT().Where(gomock.Any(), gomock.Any()).Times(0)
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/fiscal/export?from=2026-10-17&to=2026-10-01", nil)
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestFiscalInvoice(t *testing.T) {
+	assert.Equal(t, "T1-2026/000042", fiscalInvoice(models.Order{ID: 7, Invoice: "T1-2026/000042"}))
+	assert.Equal(t, "7", fiscalInvoice(models.Order{ID: 7}), "Orders paid before numbering should use their ID")
+}
@@ -0,0 +1,318 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"postui_api/pkg/database"
+	"postui_api/pkg/giftcards"
+	"postui_api/pkg/models"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	errGiftCardNotFound     = errors.New("gift card not found")
+	errGiftCardExpired      = errors.New("gift card has expired")
+	errGiftCardVoided       = errors.New("gift card was voided")
+	errInsufficientBalance  = errors.New("insufficient gift card balance")
+	errGiftCardCurrency     = errors.New("gift card is in another currency")
+	errGiftCardNumberNeeded = errors.New("a gift card number is required")
+)
+
+type GiftCardRepository interface {
+	FindGiftCard(c *gin.Context)
+	ExpireGiftCards(c *gin.Context)
+}
+
+// giftCardRepository holds shared resources like database
+type giftCardRepository struct {
+	DB  database.Database
+	Ctx *context.Context
+}
+
+// NewGiftCardRepository creates a new giftCardRepository
+func NewGiftCardRepository(db database.Database, ctx *context.Context) *giftCardRepository {
+	return &giftCardRepository{
+		DB:  db,
+		Ctx: ctx,
+	}
+}
+
+// @BasePath /api/v1
+
+// FindGiftCard godoc
+// @Summary Find a gift card by number
+// @Description Get the balance of a gift card by its number, with the history of its balance
+// @Tags giftcards
+// @Security JwtAuth
+// @Produce json
+// @Param number path string true "Gift card number"
+// @Success 200 {object} models.GiftCard "Successfully retrieved gift card"
+// @Failure 404 {string} string "gift card not found"
+// @Router /giftcards/{number} [get]
+func (r *giftCardRepository) FindGiftCard(c *gin.Context) {
+	card, err := findGiftCard(r.DB, c.Param("number"))
+	if err != nil {
+		respondGiftCardError(c, err)
+		return
+	}
+
+	if err := r.DB.Where("gift_card_id = ?", card.ID).Find(&card.Transactions).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": card})
+}
+
+// ExpireGiftCards godoc
+// @Summary Expire gift cards
+// @Description Take the balance left off every gift card past its expiry date, recording it in their history
+// @Tags giftcards
+// @Security JwtAuth
+// @Produce json
+// @Success 200 {array} models.GiftCard "Expired gift cards"
+// @Failure 500 {string} string "Internal Server Error"
+// @Router /giftcards/expire [post]
+func (r *giftCardRepository) ExpireGiftCards(c *gin.Context) {
+	var expired []models.GiftCard
+
+	err := r.DB.Transaction(func(tx database.Database) error {
+		var found []models.GiftCard
+		if err := tx.Where("expires_at <= ? AND balance > 0", time.Now()).Find(&found).Error; err != nil {
+			return err
+		}
+
+		for _, card := range found {
+			// Cards redeemed concurrently are left for the next run
+			result := tx.Model(&models.GiftCard{}).Where("id = ? AND balance = ?", card.ID, card.Balance).Update("balance", 0)
+			if result.Error != nil {
+				return result.Error
+			}
+			if result.RowsAffected == 0 {
+				continue
+			}
+
+			if err := tx.Create(&models.GiftCardTransaction{GiftCardID: card.ID, Kind: models.GiftCardExpire, Amount: -card.Balance}).Error; err != nil {
+				return err
+			}
+			card.Balance = 0
+			expired = append(expired, card)
+		}
+		return nil
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": expired})
+}
+
+// findGiftCard returns the gift card with number, which may be printed
+// with spaces or dashes
+func findGiftCard(db database.Database, number string) (models.GiftCard, error) {
+	var card models.GiftCard
+
+	number = giftcards.Normalize(number)
+	if !giftcards.Valid(number) {
+		return card, fmt.Errorf("%w: %s", errGiftCardNotFound, number)
+	}
+
+	if err := db.Where("number = ?", number).First(&card).Error(); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return card, fmt.Errorf("%w: %s", errGiftCardNotFound, number)
+		}
+		return card, err
+	}
+
+	return card, nil
+}
+
+// issueGiftCards issues a gift card for each unit of the gift card products
+// sold by order, loaded with what was paid for it
+func issueGiftCards(tx database.Database, order models.Order) error {
+	lines, err := findOrderLines(tx, order.ID)
+	if err != nil {
+		return err
+	}
+	if len(lines) == 0 {
+		return nil
+	}
+
+	ids := make([]uint, 0, len(lines))
+	for _, line := range lines {
+		ids = append(ids, line.ProductID)
+	}
+
+	// The products may have been deleted since they were sold
+	var products []models.Product
+	if err := tx.Unscoped().Where("id IN ? AND gift_card = ?", ids, true).Find(&products).Error; err != nil {
+		return err
+	}
+	if len(products) == 0 {
+		return nil
+	}
+
+	isGiftCard := make(map[uint]bool, len(products))
+	for _, product := range products {
+		isGiftCard[product.ID] = true
+	}
+
+	settings, _, err := loadStoreSettings(tx)
+	if err != nil {
+		return err
+	}
+	expiresAt := giftcards.ExpiresAt(time.Now(), settings.GiftCardMonths)
+
+	for _, line := range lines {
+		if !isGiftCard[line.ProductID] {
+			continue
+		}
+
+		count := line.Quantity.IntPart()
+		if count <= 0 {
+			continue
+		}
+
+		// Discounts are shared by the cards of the line, the first one
+		// taking the cents left over
+		lineID := line.ID
+		balance := line.Total / models.Money(count)
+		leftover := line.Total - balance*models.Money(count)
+		for i := int64(0); i < count; i++ {
+			number, err := giftcards.Generate()
+			if err != nil {
+				return err
+			}
+
+			// The unique index on numbers rejects the unlikely duplicate
+			card := models.GiftCard{Number: number, Balance: balance, Currency: line.Currency, ExpiresAt: expiresAt, OrderLineID: &lineID}
+			if i == 0 {
+				card.Balance += leftover
+			}
+			if err := tx.Create(&card).Error; err != nil {
+				return err
+			}
+
+			orderID := order.ID
+			if err := tx.Create(&models.GiftCardTransaction{GiftCardID: card.ID, Kind: models.GiftCardIssue, Amount: card.Balance, Balance: card.Balance, OrderID: &orderID}).Error; err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// redeemGiftCard takes payment off the balance of the gift card its
+// reference is the number of. The balance is only decremented while it
+// covers the payment, so concurrent redemptions cannot overdraw the card.
+func redeemGiftCard(tx database.Database, payment models.Payment, currency string) error {
+	card, err := findGiftCard(tx, payment.Reference)
+	if err != nil {
+		return err
+	}
+	if card.VoidedAt != nil {
+		return fmt.Errorf("%w: %s", errGiftCardVoided, card.Number)
+	}
+	if card.ExpiresAt != nil && !time.Now().Before(*card.ExpiresAt) {
+		return fmt.Errorf("%w: %s", errGiftCardExpired, card.Number)
+	}
+	if card.Currency != currency {
+		return fmt.Errorf("%w: %s", errGiftCardCurrency, card.Currency)
+	}
+
+	result := tx.Model(&models.GiftCard{}).
+		Where("id = ? AND balance >= ?", card.ID, payment.Amount).
+		Update("balance", gorm.Expr("balance - ?", payment.Amount))
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("%w: %s left on %s", errInsufficientBalance, card.Balance, card.Number)
+	}
+
+	return recordGiftCardTransaction(tx, card.ID, models.GiftCardRedeem, -payment.Amount, payment)
+}
+
+// creditGiftCard gives amount back to the gift card with number, for the
+// refund or removal of payment
+func creditGiftCard(tx database.Database, number string, amount models.Money, payment models.Payment) error {
+	card, err := findGiftCard(tx, number)
+	if err != nil {
+		return err
+	}
+
+	result := tx.Model(&models.GiftCard{}).Where("id = ?", card.ID).Update("balance", gorm.Expr("balance + ?", amount))
+	if result.Error != nil {
+		return result.Error
+	}
+
+	return recordGiftCardTransaction(tx, card.ID, models.GiftCardRefund, amount, payment)
+}
+
+// voidGiftCards voids the gift cards sold by lines, taking off the balance
+// left on them. The cards are locked so that they cannot be redeemed
+// concurrently.
+func voidGiftCards(tx database.Database, order models.Order, lines []models.OrderLine) error {
+	if len(lines) == 0 {
+		return nil
+	}
+
+	ids := make([]uint, 0, len(lines))
+	for _, line := range lines {
+		ids = append(ids, line.ID)
+	}
+
+	var cards []models.GiftCard
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("order_line_id IN ? AND voided_at IS NULL", ids).Find(&cards).Error; err != nil {
+		return err
+	}
+
+	now := time.Now()
+	orderID := order.ID
+	for _, card := range cards {
+		if err := tx.Model(&models.GiftCard{}).Where("id = ?", card.ID).Updates(map[string]interface{}{"balance": 0, "voided_at": now}).Error; err != nil {
+			return err
+		}
+		if err := tx.Create(&models.GiftCardTransaction{GiftCardID: card.ID, Kind: models.GiftCardVoid, Amount: -card.Balance, OrderID: &orderID}).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// recordGiftCardTransaction appends the change of the balance of a card to
+// its history. The card row is locked by the update of its balance, so the
+// balance read back is the one after amount.
+func recordGiftCardTransaction(tx database.Database, cardID uint, kind models.GiftCardTransactionKind, amount models.Money, payment models.Payment) error {
+	var card models.GiftCard
+	if err := tx.Where("id = ?", cardID).First(&card).Error(); err != nil {
+		return err
+	}
+
+	orderID, paymentID := payment.OrderID, payment.ID
+	return tx.Create(&models.GiftCardTransaction{GiftCardID: cardID, Kind: kind, Amount: amount, Balance: card.Balance, OrderID: &orderID, PaymentID: &paymentID}).Error
+}
+
+// respondGiftCardError maps an error returned while using a gift card to a
+// response
+func respondGiftCardError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, errGiftCardNumberNeeded):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, errGiftCardNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, errGiftCardExpired), errors.Is(err, errGiftCardVoided):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, errInsufficientBalance), errors.Is(err, errGiftCardCurrency):
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+	}
+}
@@ -0,0 +1,194 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"postui_api/pkg/database"
+	"postui_api/pkg/models"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func TestNewGiftCardRepository(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDB := database.NewMockDatabase(ctrl)
+	mockCtx := context.Background()
+
+	repo := NewGiftCardRepository(mockDB, &mockCtx)
+
+	assert.NotNil(t, repo, "NewGiftCardRepository should return a non-nil instance of giftCardRepository")
+	assert.Equal(t, mockDB, repo.DB, "DB should be set to the mock database instance")
+}
+
+func TestFindGiftCard(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDB := database.NewMockDatabase(ctrl)
+	ctx := context.Background()
+	repo := NewGiftCardRepository(mockDB, &ctx)
+
+	// Set up Gin
+	gin.SetMode(gin.TestMode)
+	r := gin.Default()
+	r.GET("/giftcards/:number", repo.FindGiftCard)
+
+	mockDB.EXPECT().Where("number = ?", "4539578763621486").Return(mockDB).Times(1)
+	mockDB.EXPECT().
+		First(gomock.Any()).
+		DoAndReturn(func(dest interface{}, conds ...interface{}) database.Database {
+			*dest.(*models.GiftCard) = models.GiftCard{ID: 3, Number: "4539578763621486", Balance: 1500, Currency: "EUR"}
+			return mockDB
+		}).Times(1)
+	mockDB.EXPECT().Error().Return(nil).Times(1)
+	mockDB.EXPECT().Where("gift_card_id = ?", uint(3)).Return(mockDB).Times(1)
+	mockDB.EXPECT().Find(gomock.Any()).DoAndReturn(func(dest interface{}, conds ...interface{}) *gorm.DB {
+		*dest.(*[]models.GiftCardTransaction) = []models.GiftCardTransaction{
+			{ID: 1, GiftCardID: 3, Kind: models.GiftCardIssue, Amount: 2500, Balance: 2500},
+			{ID: 2, GiftCardID: 3, Kind: models.GiftCardRedeem, Amount: -1000, Balance: 1500},
+		}
+		return &gorm.DB{Error: nil}
+	}).Times(1)
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/giftcards/4539-5787-6362-1486", nil)
+	r.ServeHTTP(w, req)
+
+	var response struct {
+		Data models.GiftCard `json:"data"`
+	}
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+	assert.Equal(t, models.Money(1500), response.Data.Balance)
+	assert.Len(t, response.Data.Transactions, 2)
+}
+
+func TestFindGiftCardMistyped(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDB := database.NewMockDatabase(ctrl)
+	ctx := context.Background()
+	repo := NewGiftCardRepository(mockDB, &ctx)
+
+	// Set up Gin
+	gin.SetMode(gin.TestMode)
+	r := gin.Default()
+	r.GET("/giftcards/:number", repo.FindGiftCard)
+
+	// Numbers failing the check digit are not looked up
+	mockDB.EXPECT().Where(gomock.Any(), gomock.Any()).Times(0)
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/giftcards/4539578763621487", nil)
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Contains(t, w.Body.String(), "gift card not found")
+}
+
+func TestAddPaymentGiftCardRequiresNumber(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDB := database.NewMockDatabase(ctrl)
+	ctx := context.Background()
+	repo := NewOrderRepository(mockDB, &ctx)
+
+	// Set up Gin
+	gin.SetMode(gin.TestMode)
+	r := gin.Default()
+	r.POST("/order/:id/payments", repo.AddPayment)
+
+	requestBody, err := json.Marshal(models.CreatePayment{Tender: models.TenderGiftCard, Amount: 500})
+	if err != nil {
+		t.Fatalf("Failed to marshal payment data: %v", err)
+	}
+
+	mockDB.EXPECT().Transaction(gomock.Any()).Times(0)
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/order/1/payments", bytes.NewBuffer(requestBody))
+	req.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "a gift card number is required")
+}
+
+func TestRedeemGiftCardExpired(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDB := database.NewMockDatabase(ctrl)
+	expired := time.Now().Add(-time.Hour)
+
+	mockDB.EXPECT().Where("number = ?", "4539578763621486").Return(mockDB).Times(1)
+	mockDB.EXPECT().
+		First(gomock.Any()).
+		DoAndReturn(func(dest interface{}, conds ...interface{}) database.Database {
+			*dest.(*models.GiftCard) = models.GiftCard{ID: 3, Number: "4539578763621486", Balance: 1500, Currency: "EUR", ExpiresAt: &expired}
+			return mockDB
+		}).Times(1)
+	mockDB.EXPECT().Error().Return(nil).Times(1)
+	mockDB.EXPECT().Model(gomock.Any()).Times(0)
+
+	err := redeemGiftCard(mockDB, models.Payment{ID: 1, OrderID: 2, Tender: models.TenderGiftCard, Amount: 500, Reference: "4539578763621486"}, "EUR")
+
+	assert.True(t, errors.Is(err, errGiftCardExpired))
+}
+
+func TestIssueGiftCardsDiscounted(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDB := database.NewMockDatabase(ctrl)
+
+	orderID := uint(9)
+	line := models.OrderLine{ID: 5, OrderID: &orderID, ProductID: 2, Quantity: decimal.NewFromInt(3), Price: 2500, Currency: "EUR", Discount: 500, Total: 7000}
+
+	mockDB.EXPECT().Where("order_id = ?", orderID).Return(mockDB).Times(1)
+	mockDB.EXPECT().Unscoped().Return(mockDB).Times(1)
+	mockDB.EXPECT().Where("id IN ? AND gift_card = ?", []uint{2}, true).Return(mockDB).Times(1)
+	mockDB.EXPECT().
+		Find(gomock.Any()).
+		DoAndReturn(func(dest interface{}, conds ...interface{}) *gorm.DB {
+			switch b := dest.(type) {
+			case *[]models.OrderLine:
+				*b = []models.OrderLine{line}
+			case *[]models.Product:
+				*b = []models.Product{{ID: 2, Name: "Gift card", GiftCard: true}}
+			}
+			return &gorm.DB{Error: nil}
+		}).Times(2)
+
+	// The store was never configured
+	mockDB.EXPECT().Where("id = ?", models.StoreSettingsID).Return(mockDB).Times(1)
+	mockDB.EXPECT().First(gomock.Any()).Return(mockDB).Times(1)
+	mockDB.EXPECT().Error().Return(gorm.ErrRecordNotFound).Times(1)
+
+	var balances []models.Money
+	mockDB.EXPECT().
+		Create(gomock.AssignableToTypeOf(&models.GiftCard{})).
+		DoAndReturn(func(value interface{}) *gorm.DB {
+			balances = append(balances, value.(*models.GiftCard).Balance)
+			return &gorm.DB{Error: nil}
+		}).Times(3)
+	mockDB.EXPECT().Create(gomock.AssignableToTypeOf(&models.GiftCardTransaction{})).Return(&gorm.DB{Error: nil}).Times(3)
+
+	assert.NoError(t, issueGiftCards(mockDB, models.Order{ID: orderID}))
+	assert.Equal(t, []models.Money{2334, 2333, 2333}, balances, "The cards should be loaded with what was paid for them")
+}
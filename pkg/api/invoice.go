@@ -0,0 +1,59 @@
+package api
+
+import (
+	"postui_api/pkg/database"
+	"postui_api/pkg/models"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// assignInvoice gives order, paid at paidAt, the next number of the invoice
+// series of its cashout number for the year of paidAt, creating the series
+// if needed. It
+// must run in the transaction paying the order: incrementing the counter
+// locks the series row until the transaction ends, so concurrent payments
+// are numbered one after the other and a rollback leaves no gap. Orders
+// already stored are updated.
+func assignInvoice(tx database.Database, order *models.Order, paidAt time.Time) error {
+	settings, _, err := loadStoreSettings(tx)
+	if err != nil {
+		return err
+	}
+
+	defaults := models.DefaultStoreSettings()
+	prefix := settings.InvoicePrefix
+	if prefix == "" {
+		prefix = defaults.InvoicePrefix
+	}
+	if order.RefundOfID != nil {
+		prefix = settings.RefundPrefix
+		if prefix == "" {
+			prefix = defaults.RefundPrefix
+		}
+	}
+
+	year := paidAt.Year()
+	series := models.InvoiceSeries{Code: models.InvoiceSeriesCode(prefix, order.CashoutNumber, year), CashoutNumber: order.CashoutNumber, Year: year}
+
+	if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&series).Error; err != nil {
+		return err
+	}
+
+	if err := tx.Model(&models.InvoiceSeries{}).Where("code = ?", series.Code).Update("last_number", gorm.Expr("last_number + 1")).Error; err != nil {
+		return err
+	}
+
+	if err := tx.Where("code = ?", series.Code).First(&series).Error(); err != nil {
+		return err
+	}
+
+	order.InvoiceNumber = series.LastNumber
+	order.Invoice = series.Invoice(series.LastNumber)
+
+	if order.ID == 0 {
+		return nil
+	}
+	return tx.Model(&models.Order{}).Where("id = ?", order.ID).Updates(map[string]interface{}{"invoice_number": order.InvoiceNumber, "invoice": order.Invoice}).Error
+}
@@ -0,0 +1,511 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"postui_api/pkg/database"
+	"postui_api/pkg/loyalty"
+	"postui_api/pkg/models"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+var (
+	errLoyaltyAccountNotFound = errors.New("loyalty account not found")
+	errLoyaltyIDNeeded        = errors.New("a loyalty card number or phone is required")
+	errLoyaltyDisabled        = errors.New("loyalty points cannot be redeemed")
+	errInsufficientPoints     = errors.New("insufficient loyalty points")
+	errLoyaltyRuleNotFound    = errors.New("loyalty rule not found")
+)
+
+type LoyaltyRepository interface {
+	FindLoyaltyAccount(c *gin.Context)
+	FindLoyaltyRules(c *gin.Context)
+	CreateLoyaltyRule(c *gin.Context)
+	DeleteLoyaltyRule(c *gin.Context)
+	ExpireLoyaltyPoints(c *gin.Context)
+}
+
+// loyaltyRepository holds shared resources like database
+type loyaltyRepository struct {
+	DB  database.Database
+	Ctx *context.Context
+}
+
+// NewLoyaltyRepository creates a new loyaltyRepository
+func NewLoyaltyRepository(db database.Database, ctx *context.Context) *loyaltyRepository {
+	return &loyaltyRepository{
+		DB:  db,
+		Ctx: ctx,
+	}
+}
+
+// @BasePath /api/v1
+
+// FindLoyaltyAccount godoc
+// @Summary Find a loyalty account
+// @Description Get the points of a loyalty account by its card number or phone, with its points ledger
+// @Tags loyalty
+// @Security JwtAuth
+// @Produce json
+// @Param identifier path string true "Card number or phone"
+// @Success 200 {object} models.LoyaltyAccount "Successfully retrieved loyalty account"
+// @Failure 404 {string} string "loyalty account not found"
+// @Router /loyalty/accounts/{identifier} [get]
+func (r *loyaltyRepository) FindLoyaltyAccount(c *gin.Context) {
+	account, err := findLoyaltyAccount(r.DB, c.Param("identifier"))
+	if err != nil {
+		respondLoyaltyError(c, err)
+		return
+	}
+
+	if err := r.DB.Where("account_id = ?", account.ID).Find(&account.Entries).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": account})
+}
+
+// FindLoyaltyRules godoc
+// @Summary List the loyalty rules
+// @Description Get the multipliers of the points earned on products and categories
+// @Tags loyalty
+// @Security JwtAuth
+// @Produce json
+// @Success 200 {array} models.LoyaltyRule "Successfully retrieved loyalty rules"
+// @Failure 500 {string} string "Internal Server Error"
+// @Router /loyalty/rules [get]
+func (r *loyaltyRepository) FindLoyaltyRules(c *gin.Context) {
+	var found []models.LoyaltyRule
+
+	if err := r.DB.Find(&found).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": found})
+}
+
+// CreateLoyaltyRule godoc
+// @Summary Create a loyalty rule
+// @Description Multiply the points earned on a product or on the products of a category. A rule for a product takes precedence over one for its category.
+// @Tags loyalty
+// @Security JwtAuth
+// @Accept  json
+// @Produce  json
+// @Param   input     body   models.CreateLoyaltyRule   true   "Loyalty rule object"
+// @Success 201 {object} models.LoyaltyRule "Successfully created loyalty rule"
+// @Failure 400 {string} string "Bad Request"
+// @Failure 404 {string} string "product not found"
+// @Router /loyalty/rules [post]
+func (r *loyaltyRepository) CreateLoyaltyRule(c *gin.Context) {
+	appCtx, exists := c.MustGet("appCtxLoyalty").(*loyaltyRepository)
+	if !exists {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	var input models.CreateLoyaltyRule
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	rule := models.LoyaltyRule{ProductID: input.ProductID, Category: input.Category, Multiplier: *input.Multiplier}
+	if rule.ProductID != nil {
+		// Rules for a product ignore its category
+		rule.Category = ""
+
+		var product models.Product
+		if err := appCtx.DB.Where("id = ?", *rule.ProductID).First(&product).Error(); err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				c.JSON(http.StatusNotFound, gin.H{"error": "product not found"})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+			return
+		}
+	}
+
+	if err := appCtx.DB.Create(&rule).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"data": rule})
+}
+
+// DeleteLoyaltyRule godoc
+// @Summary Delete a loyalty rule by ID
+// @Description Delete the loyalty rule with the given ID. Points already earned are kept.
+// @Tags loyalty
+// @Security JwtAuth
+// @Produce json
+// @Param id path string true "Loyalty rule ID"
+// @Success 204 {string} string "Successfully deleted loyalty rule"
+// @Failure 404 {string} string "loyalty rule not found"
+// @Router /loyalty/rules/{id} [delete]
+func (r *loyaltyRepository) DeleteLoyaltyRule(c *gin.Context) {
+	var rule models.LoyaltyRule
+
+	if err := r.DB.Where("id = ?", c.Param("id")).First(&rule).Error(); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			respondLoyaltyError(c, errLoyaltyRuleNotFound)
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	if err := r.DB.Delete(&rule).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	c.JSON(http.StatusNoContent, gin.H{"data": true})
+}
+
+// ExpireLoyaltyPoints godoc
+// @Summary Expire loyalty points
+// @Description Take the earned points left past their expiry date off every loyalty account, recording it in their ledger
+// @Tags loyalty
+// @Security JwtAuth
+// @Produce json
+// @Success 200 {array} models.LoyaltyEntry "Expiry entries recorded"
+// @Failure 500 {string} string "Internal Server Error"
+// @Router /loyalty/expire [post]
+func (r *loyaltyRepository) ExpireLoyaltyPoints(c *gin.Context) {
+	var expired []models.LoyaltyEntry
+
+	err := r.DB.Transaction(func(tx database.Database) error {
+		var err error
+		expired, err = expireLoyaltyPoints(tx, nil, time.Now())
+		return err
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": expired})
+}
+
+// findLoyaltyAccount returns the loyalty account of identifier, which may
+// be written with spaces or dashes
+func findLoyaltyAccount(db database.Database, identifier string) (models.LoyaltyAccount, error) {
+	var account models.LoyaltyAccount
+
+	identifier = loyalty.Normalize(identifier)
+	if identifier == "" {
+		return account, errLoyaltyIDNeeded
+	}
+
+	if err := db.Where("identifier = ?", identifier).First(&account).Error(); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return account, fmt.Errorf("%w: %s", errLoyaltyAccountNotFound, identifier)
+		}
+		return account, err
+	}
+
+	return account, nil
+}
+
+// openLoyaltyAccount returns the loyalty account the points of order are
+// earned by, opening it on the first sale
+func openLoyaltyAccount(tx database.Database, order models.Order) (models.LoyaltyAccount, error) {
+	account, err := findLoyaltyAccount(tx, order.LoyaltyID)
+	if err == nil || !errors.Is(err, errLoyaltyAccountNotFound) {
+		return account, err
+	}
+
+	// The unique index on identifiers rejects an account opened concurrently
+	account = models.LoyaltyAccount{Identifier: order.LoyaltyID, CustomerID: order.CustomerID}
+	if err := tx.Create(&account).Error; err != nil {
+		return account, err
+	}
+	return account, nil
+}
+
+// accrueLoyaltyPoints credits the loyalty account captured on a paid order
+// with the points its lines earn. The part of the order paid with points
+// earns none.
+func accrueLoyaltyPoints(tx database.Database, order models.Order) error {
+	if order.LoyaltyID == "" || order.Total <= 0 {
+		return nil
+	}
+
+	settings, _, err := loadStoreSettings(tx)
+	if err != nil {
+		return err
+	}
+	if settings.LoyaltyPerUnit == 0 {
+		return nil
+	}
+
+	lines, err := findOrderLines(tx, order.ID)
+	if err != nil {
+		return err
+	}
+	if len(lines) == 0 {
+		return nil
+	}
+
+	ids := make([]uint, 0, len(lines))
+	for _, line := range lines {
+		ids = append(ids, line.ProductID)
+	}
+
+	// The products may have been deleted since they were sold
+	var products []models.Product
+	if err := tx.Unscoped().Where("id IN ?", ids).Find(&products).Error; err != nil {
+		return err
+	}
+	categories := make(map[uint]string, len(products))
+	for _, product := range products {
+		categories[product.ID] = product.Category
+	}
+
+	var rules []models.LoyaltyRule
+	if err := tx.Find(&rules).Error; err != nil {
+		return err
+	}
+
+	items := make([]loyalty.Item, 0, len(lines))
+	for _, line := range lines {
+		items = append(items, loyalty.Item{Line: line, Category: categories[line.ProductID]})
+	}
+	points := loyalty.Earn(items, rules, settings.LoyaltyPerUnit)
+
+	var paid []models.Payment
+	if err := tx.Where("order_id = ? AND tender = ?", order.ID, models.TenderLoyalty).Find(&paid).Error; err != nil {
+		return err
+	}
+	var paidWithPoints models.Money
+	for _, payment := range paid {
+		paidWithPoints += payment.Amount
+	}
+	if paidWithPoints > order.Total {
+		paidWithPoints = order.Total
+	}
+	points -= points * int64(paidWithPoints) / int64(order.Total)
+
+	if points <= 0 {
+		return nil
+	}
+
+	account, err := openLoyaltyAccount(tx, order)
+	if err != nil {
+		return err
+	}
+
+	result := tx.Model(&models.LoyaltyAccount{}).Where("id = ?", account.ID).Update("points", gorm.Expr("points + ?", points))
+	if result.Error != nil {
+		return result.Error
+	}
+
+	orderID := order.ID
+	return recordLoyaltyEntry(tx, &models.LoyaltyEntry{
+		AccountID: account.ID,
+		Kind:      models.LoyaltyEarn,
+		Points:    points,
+		Remaining: points,
+		ExpiresAt: loyalty.ExpiresAt(time.Now(), settings.LoyaltyMonths),
+		OrderID:   &orderID,
+	})
+}
+
+// redeemLoyaltyPoints takes the points paying for payment off the account
+// its reference is the identifier of, once the points of the account past
+// their expiry are taken off. Points are worth value each.
+func redeemLoyaltyPoints(tx database.Database, payment models.Payment, value models.Money) error {
+	account, err := findLoyaltyAccount(tx, payment.Reference)
+	if err != nil {
+		return err
+	}
+	if _, err := expireLoyaltyPoints(tx, &account.ID, time.Now()); err != nil {
+		return err
+	}
+
+	points := loyalty.Points(payment.Amount, value)
+
+	// The balance is only decremented while it covers the points, so
+	// concurrent redemptions cannot overdraw the account
+	result := tx.Model(&models.LoyaltyAccount{}).
+		Where("id = ? AND points >= ?", account.ID, points).
+		Update("points", gorm.Expr("points - ?", points))
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("%w: %d points needed", errInsufficientPoints, points)
+	}
+
+	// Points expiring first are used first
+	var entries []models.LoyaltyEntry
+	if err := tx.Where("account_id = ? AND remaining > 0", account.ID).Order("expires_at IS NULL, expires_at, id").Find(&entries).Error; err != nil {
+		return err
+	}
+	for i, taken := range loyalty.Consume(entries, points) {
+		if taken == 0 {
+			continue
+		}
+		if err := tx.Model(&models.LoyaltyEntry{}).Where("id = ?", entries[i].ID).Update("remaining", gorm.Expr("remaining - ?", taken)).Error; err != nil {
+			return err
+		}
+	}
+
+	orderID, paymentID := payment.OrderID, payment.ID
+	return recordLoyaltyEntry(tx, &models.LoyaltyEntry{AccountID: account.ID, Kind: models.LoyaltyRedeem, Points: -points, OrderID: &orderID, PaymentID: &paymentID})
+}
+
+// creditLoyaltyPoints gives back the points redeemed by payment when it is
+// removed. They expire like points earned now.
+func creditLoyaltyPoints(tx database.Database, payment models.Payment) error {
+	var redeemed models.LoyaltyEntry
+	if err := tx.Where("payment_id = ? AND kind = ?", payment.ID, models.LoyaltyRedeem).First(&redeemed).Error(); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return err
+	}
+
+	settings, _, err := loadStoreSettings(tx)
+	if err != nil {
+		return err
+	}
+
+	points := -redeemed.Points
+	result := tx.Model(&models.LoyaltyAccount{}).Where("id = ?", redeemed.AccountID).Update("points", gorm.Expr("points + ?", points))
+	if result.Error != nil {
+		return result.Error
+	}
+
+	orderID, paymentID := payment.OrderID, payment.ID
+	return recordLoyaltyEntry(tx, &models.LoyaltyEntry{
+		AccountID: redeemed.AccountID,
+		Kind:      models.LoyaltyCredit,
+		Points:    points,
+		Remaining: points,
+		ExpiresAt: loyalty.ExpiresAt(time.Now(), settings.LoyaltyMonths),
+		OrderID:   &orderID,
+		PaymentID: &paymentID,
+	})
+}
+
+// reverseLoyaltyPoints takes back the points order earned in proportion to
+// refunded, the amount of it refunded so far. Points already redeemed are
+// still taken back, leaving the account with a negative balance.
+func reverseLoyaltyPoints(tx database.Database, order models.Order, refunded models.Money) error {
+	if order.LoyaltyID == "" {
+		return nil
+	}
+
+	var earned models.LoyaltyEntry
+	if err := tx.Where("order_id = ? AND kind = ?", order.ID, models.LoyaltyEarn).First(&earned).Error(); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return err
+	}
+
+	var reversals []models.LoyaltyEntry
+	if err := tx.Where("order_id = ? AND kind = ?", order.ID, models.LoyaltyReverse).Find(&reversals).Error; err != nil {
+		return err
+	}
+	var reversed int64
+	for _, reversal := range reversals {
+		reversed -= reversal.Points
+	}
+
+	points := loyalty.Reversal(earned.Points, reversed, order.Total, refunded)
+	if points == 0 {
+		return nil
+	}
+
+	result := tx.Model(&models.LoyaltyAccount{}).Where("id = ?", earned.AccountID).Update("points", gorm.Expr("points - ?", points))
+	if result.Error != nil {
+		return result.Error
+	}
+	result = tx.Model(&models.LoyaltyEntry{}).Where("id = ?", earned.ID).Update("remaining", gorm.Expr("GREATEST(remaining - ?, 0)", points))
+	if result.Error != nil {
+		return result.Error
+	}
+
+	orderID := order.ID
+	return recordLoyaltyEntry(tx, &models.LoyaltyEntry{AccountID: earned.AccountID, Kind: models.LoyaltyReverse, Points: -points, OrderID: &orderID})
+}
+
+// expireLoyaltyPoints takes the points left on entries past their expiry
+// off their accounts, those of account only when it is given, and returns
+// the expiry entries recorded
+func expireLoyaltyPoints(tx database.Database, accountID *uint, now time.Time) ([]models.LoyaltyEntry, error) {
+	query := tx.Where("remaining > 0 AND expires_at <= ?", now)
+	if accountID != nil {
+		query = tx.Where("account_id = ? AND remaining > 0 AND expires_at <= ?", *accountID, now)
+	}
+
+	var found []models.LoyaltyEntry
+	if err := query.Find(&found).Error; err != nil {
+		return nil, err
+	}
+
+	var expired []models.LoyaltyEntry
+	for _, entry := range found {
+		// Entries redeemed concurrently are left for the next run
+		result := tx.Model(&models.LoyaltyEntry{}).Where("id = ? AND remaining = ?", entry.ID, entry.Remaining).Update("remaining", 0)
+		if result.Error != nil {
+			return nil, result.Error
+		}
+		if result.RowsAffected == 0 {
+			continue
+		}
+
+		result = tx.Model(&models.LoyaltyAccount{}).Where("id = ?", entry.AccountID).Update("points", gorm.Expr("points - ?", entry.Remaining))
+		if result.Error != nil {
+			return nil, result.Error
+		}
+
+		expiry := models.LoyaltyEntry{AccountID: entry.AccountID, Kind: models.LoyaltyExpire, Points: -entry.Remaining, OrderID: entry.OrderID}
+		if err := recordLoyaltyEntry(tx, &expiry); err != nil {
+			return nil, err
+		}
+		expired = append(expired, expiry)
+	}
+	return expired, nil
+}
+
+// recordLoyaltyEntry appends the change of the points of an account to its
+// ledger. The account row is locked by the update of its points, so the
+// balance read back is the one after the entry.
+func recordLoyaltyEntry(tx database.Database, entry *models.LoyaltyEntry) error {
+	var account models.LoyaltyAccount
+	if err := tx.Where("id = ?", entry.AccountID).First(&account).Error(); err != nil {
+		return err
+	}
+
+	entry.Balance = account.Points
+	return tx.Create(entry).Error
+}
+
+// respondLoyaltyError maps an error returned while using loyalty points to
+// a response
+func respondLoyaltyError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, errLoyaltyIDNeeded):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, errLoyaltyAccountNotFound), errors.Is(err, errLoyaltyRuleNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, errLoyaltyDisabled):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, errInsufficientPoints):
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+	}
+}
@@ -0,0 +1,239 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"postui_api/pkg/database"
+	"postui_api/pkg/models"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func TestNewLoyaltyRepository(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDB := database.NewMockDatabase(ctrl)
+	mockCtx := context.Background()
+
+	repo := NewLoyaltyRepository(mockDB, &mockCtx)
+
+	assert.NotNil(t, repo, "NewLoyaltyRepository should return a non-nil instance of loyaltyRepository")
+	assert.Equal(t, mockDB, repo.DB, "DB should be set to the mock database instance")
+}
+
+func TestFindLoyaltyAccount(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDB := database.NewMockDatabase(ctrl)
+	ctx := context.Background()
+	repo := NewLoyaltyRepository(mockDB, &ctx)
+
+	// Set up Gin
+	gin.SetMode(gin.TestMode)
+	r := gin.Default()
+	r.GET("/loyalty/accounts/:identifier", repo.FindLoyaltyAccount)
+
+	mockDB.EXPECT().Where("identifier = ?", "600123456").Return(mockDB).Times(1)
+	mockDB.EXPECT().
+		First(gomock.Any()).
+		DoAndReturn(func(dest interface{}, conds ...interface{}) database.Database {
+			*dest.(*models.LoyaltyAccount) = models.LoyaltyAccount{ID: 4, Identifier: "600123456", Points: 80}
+			return mockDB
+		}).Times(1)
+	mockDB.EXPECT().Error().Return(nil).Times(1)
+	mockDB.EXPECT().Where("account_id = ?", uint(4)).Return(mockDB).Times(1)
+	mockDB.EXPECT().Find(gomock.Any()).DoAndReturn(func(dest interface{}, conds ...interface{}) *gorm.DB {
+		*dest.(*[]models.LoyaltyEntry) = []models.LoyaltyEntry{
+			{ID: 1, AccountID: 4, Kind: models.LoyaltyEarn, Points: 120, Balance: 120, Remaining: 20},
+			{ID: 2, AccountID: 4, Kind: models.LoyaltyRedeem, Points: -100, Balance: 20},
+			{ID: 3, AccountID: 4, Kind: models.LoyaltyEarn, Points: 60, Balance: 80, Remaining: 60},
+		}
+		return &gorm.DB{Error: nil}
+	}).Times(1)
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/loyalty/accounts/600-123-456", nil)
+	r.ServeHTTP(w, req)
+
+	var response struct {
+		Data models.LoyaltyAccount `json:"data"`
+	}
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+	assert.Equal(t, int64(80), response.Data.Points)
+	assert.Len(t, response.Data.Entries, 3)
+}
+
+func TestFindLoyaltyAccountNotFound(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDB := database.NewMockDatabase(ctrl)
+	ctx := context.Background()
+	repo := NewLoyaltyRepository(mockDB, &ctx)
+
+	// Set up Gin
+	gin.SetMode(gin.TestMode)
+	r := gin.Default()
+	r.GET("/loyalty/accounts/:identifier", repo.FindLoyaltyAccount)
+
+	mockDB.EXPECT().Where("identifier = ?", "600123456").Return(mockDB).Times(1)
+	mockDB.EXPECT().First(gomock.Any()).Return(mockDB).Times(1)
+	mockDB.EXPECT().Error().Return(gorm.ErrRecordNotFound).Times(1)
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/loyalty/accounts/600123456", nil)
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Contains(t, w.Body.String(), "loyalty account not found")
+}
+
+func TestCreateLoyaltyRuleRequiresTarget(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDB := database.NewMockDatabase(ctrl)
+	ctx := context.Background()
+	repo := NewLoyaltyRepository(mockDB, &ctx)
+
+	// Set up Gin
+	gin.SetMode(gin.TestMode)
+	r := gin.Default()
+	r.POST("/loyalty/rules", func(c *gin.Context) {
+		c.Set("appCtxLoyalty", repo)
+		repo.CreateLoyaltyRule(c)
+	})
+
+	// Neither a product nor a category
+	mockDB.EXPECT().Create(gomock.Any()).Times(0)
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/loyalty/rules", bytes.NewBufferString(`{"multiplier":20000}`))
+	req.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestCreateLoyaltyRule(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDB := database.NewMockDatabase(ctrl)
+	ctx := context.Background()
+	repo := NewLoyaltyRepository(mockDB, &ctx)
+
+	// Set up Gin
+	gin.SetMode(gin.TestMode)
+	r := gin.Default()
+	r.POST("/loyalty/rules", func(c *gin.Context) {
+		c.Set("appCtxLoyalty", repo)
+		repo.CreateLoyaltyRule(c)
+	})
+
+	// A multiplier of 0 is a valid rule: the category earns no points
+	mockDB.EXPECT().Create(gomock.Any()).DoAndReturn(func(rule *models.LoyaltyRule) *gorm.DB {
+		assert.Equal(t, "tobacco", rule.Category)
+		assert.Equal(t, uint(0), rule.Multiplier)
+		rule.ID = 2
+		return &gorm.DB{Error: nil}
+	}).Times(1)
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/loyalty/rules", bytes.NewBufferString(`{"category":"tobacco","multiplier":0}`))
+	req.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+}
+
+func TestAddPaymentLoyaltyDisabled(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDB := database.NewMockDatabase(ctrl)
+	ctx := context.Background()
+	repo := NewOrderRepository(mockDB, &ctx)
+
+	// Set up Gin
+	gin.SetMode(gin.TestMode)
+	r := gin.Default()
+	r.POST("/order/:id/payments", repo.AddPayment)
+
+	openOrder := models.Order{ID: 1, CustomerName: "username", LoyaltyID: "600123456", Total: 1000, CashoutNumber: 1, Status: models.OrderOpen}
+
+	requestBody, err := json.Marshal(models.CreatePayment{Tender: models.TenderLoyalty})
+	if err != nil {
+		t.Fatalf("Failed to marshal payment data: %v", err)
+	}
+
+	// Run the transaction body against the same mock
+	mockDB.EXPECT().
+		Transaction(gomock.Any()).
+		DoAndReturn(func(fc func(database.Database) error) error {
+			return fc(mockDB)
+		}).Times(1)
+	mockDB.EXPECT().ForUpdate().Return(mockDB).Times(1)
+	mockDB.EXPECT().Where("id = ?", "1").Return(mockDB).Times(1)
+	mockDB.EXPECT().
+		First(gomock.Any()).
+		DoAndReturn(func(dest interface{}, conds ...interface{}) database.Database {
+			if b, ok := dest.(*models.Order); ok {
+				*b = openOrder
+			}
+			return mockDB
+		}).Times(2)
+	mockDB.EXPECT().Error().Return(nil).Times(1)
+	mockDB.EXPECT().Where("order_id = ?", uint(1)).Return(mockDB).Times(1)
+	mockDB.EXPECT().Find(gomock.Any()).Return(&gorm.DB{Error: nil}).Times(1)
+
+	// The store never set what a point is worth
+	mockDB.EXPECT().Where("id = ?", models.StoreSettingsID).Return(mockDB).Times(1)
+	mockDB.EXPECT().Error().Return(gorm.ErrRecordNotFound).Times(1)
+
+	mockDB.EXPECT().Create(gomock.Any()).Times(0)
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/order/1/payments", bytes.NewBuffer(requestBody))
+	req.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.Contains(t, w.Body.String(), "loyalty points cannot be redeemed")
+}
+
+func TestReverseTendersLooksUpRedeemedPoints(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDB := database.NewMockDatabase(ctrl)
+
+	mockDB.EXPECT().Where("order_id = ?", uint(4)).Return(mockDB).Times(1)
+	mockDB.EXPECT().
+		Find(gomock.Any()).
+		DoAndReturn(func(dest interface{}, conds ...interface{}) *gorm.DB {
+			*dest.(*[]models.Payment) = []models.Payment{
+				{ID: 6, OrderID: 4, Tender: models.TenderCash, Amount: 500},
+				{ID: 7, OrderID: 4, Tender: models.TenderLoyalty, Amount: 300, Reference: "555123"},
+			}
+			return &gorm.DB{Error: nil}
+		}).Times(1)
+
+	// Only the points redeemed by the loyalty payment are given back
+	mockDB.EXPECT().Where("payment_id = ? AND kind = ?", uint(7), models.LoyaltyRedeem).Return(mockDB).Times(1)
+	mockDB.EXPECT().First(gomock.Any()).Return(mockDB).Times(1)
+	mockDB.EXPECT().Error().Return(gorm.ErrRecordNotFound).Times(1)
+
+	assert.NoError(t, reverseTenders(mockDB, models.Order{ID: 4}))
+}
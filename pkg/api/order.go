@@ -2,19 +2,42 @@ package api
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"net/http"
 	"postui_api/pkg/database"
+	"postui_api/pkg/loyalty"
 	"postui_api/pkg/models"
+	"postui_api/pkg/pricing"
+	"sort"
+	"time"
 
 	"github.com/gin-gonic/gin"
-	"github.com/lib/pq"
+)
+
+var (
+	errOrderLineNotFound = errors.New("order line not found")
+	errOrderLineTaken    = errors.New("order line belongs to another order")
 )
 
 type OrderRepository interface {
+	FindOrders(c *gin.Context)
 	CreateOrder(c *gin.Context)
 	FindOrder(c *gin.Context)
 	UpdateOrder(c *gin.Context)
 	DeleteOrder(c *gin.Context)
+	PayOrder(c *gin.Context)
+	VoidOrder(c *gin.Context)
+	ParkOrder(c *gin.Context)
+	ResumeOrder(c *gin.Context)
+	RefundOrder(c *gin.Context)
+	AddPayment(c *gin.Context)
+	FindPayments(c *gin.Context)
+	DeletePayment(c *gin.Context)
+	OrderReceipt(c *gin.Context)
+	AddOrderLine(c *gin.Context)
+	ChangeOrderLine(c *gin.Context)
+	RemoveOrderLine(c *gin.Context)
 }
 
 // orderRepository holds shared resources like database
@@ -35,16 +58,20 @@ func NewOrderRepository(db database.Database, ctx *context.Context) *orderReposi
 
 // CreateOrder godoc
 // @Summary Create a new order
-// @Description Create a new order with the given input data
+// @Description Create a new order with the given input data. The current promotions are applied to the order lines, as they are when the lines change later, and the total is computed from the discounted lines.
 // @Tags orders
 // @Security JwtAuth
 // @Accept  json
 // @Produce  json
 // @Param   input     body   models.CreateOrder   true   "Create order object"
-// @Success 201 {object} models.Order "Successfully created order"
+// @Param Idempotency-Key header string false "Key under which the response is replayed to retries"
+// @Success 201 {object} models.OrderDetail "Successfully created order"
 // @Failure 400 {string} string "Bad Request"
 // @Failure 401 {string} string "Unauthorized"
-// @Router /orders [post]integer
+// @Failure 404 {string} string "Order line not found"
+// @Failure 409 {string} string "No open register session for the cashout number"
+// @Failure 422 {string} string "Total does not match order lines"
+// @Router /orders [post]
 func (r *orderRepository) CreateOrder(c *gin.Context) {
 	appCtx, exists := c.MustGet("appCtxOrder").(*orderRepository)
 	if !exists {
@@ -59,21 +86,89 @@ func (r *orderRepository) CreateOrder(c *gin.Context) {
 		return
 	}
 
-	order := models.Order{Vendor: input.Vendor, Total: input.Total, LinesID: pq.Int64Array(input.LinesID), CashoutNumber: input.CashoutNumber}
+	if err := requireOpenSession(appCtx.DB, input.CashoutNumber); err != nil {
+		respondRegisterError(c, err)
+		return
+	}
+
+	customerName, err := orderCustomer(appCtx.DB, input.CustomerID, input.Name())
+	if err != nil {
+		respondCustomerError(c, err)
+		return
+	}
+
+	lines, err := findLinesByID(appCtx.DB, input.LinesID)
+	if err != nil {
+		respondOrderLinesError(c, err)
+		return
+	}
+
+	if err := checkLinesFree(lines, 0); err != nil {
+		respondOrderLinesError(c, err)
+		return
+	}
+
+	total, currency, err := orderAmounts(lines)
+	if err != nil {
+		respondPricingError(c, err)
+		return
+	}
 
-	appCtx.DB.Create(&order)
+	order := models.Order{CustomerID: input.CustomerID, CustomerName: customerName, LoyaltyID: loyalty.Normalize(input.LoyaltyID), Total: total, Taxes: pricing.Breakdown(lines), Currency: currency, CashoutNumber: input.CashoutNumber, Status: models.OrderOpen, Cashier: c.GetString("username")}
+	var discounts []models.OrderDiscount
 
-	c.JSON(http.StatusCreated, gin.H{"data": order})
+	err = appCtx.DB.Transaction(func(tx database.Database) error {
+		if err := tx.Create(&order).Error; err != nil {
+			return err
+		}
+		if err := attachOrderLines(tx, order.ID, lines); err != nil {
+			return err
+		}
+
+		// The current promotions apply as they do when the lines change later.
+		// The lines were not part of an order, so they had no discount and
+		// only a discount changes the amounts the order was created with.
+		before := order.Total
+		var err error
+		if lines, err = applyOrderPromotions(tx, order, lines, time.Now()); err != nil {
+			return err
+		}
+		if pricing.OrderTotal(lines) != before {
+			if err := saveOrderAmounts(tx, &order, lines); err != nil {
+				return err
+			}
+		}
+		if err := pricing.CheckTotal(order.Total, input.Total); err != nil {
+			return err
+		}
+
+		discounts, err = orderDiscounts(tx, order.ID)
+		return err
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, errOrderLineNotFound), errors.Is(err, errOrderLineTaken):
+			respondOrderLinesError(c, err)
+		case errors.Is(err, pricing.ErrPriceMismatch):
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		default:
+			respondPricingError(c, err)
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"data": models.OrderDetail{Order: order, Lines: lines, Taxes: order.Taxes, Discounts: discounts}})
 }
 
 // FindOrder godoc
 // @Summary Find an order by ID
-// @Description Get details of an order by its ID
+// @Description Get details of an order by its ID, with its lines. Send include=products to also get the products the lines refer to.
 // @Tags orders
 // @Security JwtAuth
 // @Produce json
 // @Param id path string true "Order ID"
-// @Success 200 {object} models.Order "Successfully retrieved order"
+// @Param include query string false "Set to products to embed the products of the lines"
+// @Success 200 {object} models.OrderDetail "Successfully retrieved order"
 // @Failure 404 {string} string "Order not found"
 // @Router /orders/{id} [get]
 func (r *orderRepository) FindOrder(c *gin.Context) {
@@ -84,21 +179,36 @@ func (r *orderRepository) FindOrder(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"data": order})
+	detail, err := orderDetail(r.DB, order)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch order lines"})
+		return
+	}
+
+	if c.Query("include") == "products" {
+		if detail.Products, err = lineProducts(r.DB, detail.Lines); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": detail})
 }
 
 // UpdateOrder godoc
 // @Summary Update an order by ID
-// @Description Update the order details for the given ID
+// @Description Update the order details for the given ID. When lines_id is sent, it replaces the lines of the order and the lines left out are deleted, returning the stock their checkout took. The current promotions, and those of the coupons the order redeemed, are then applied again to its lines.
 // @Tags orders
 // @Security JwtAuth
 // @Accept  json
 // @Produce  json
 // @Param id path string true "Order ID"
 // @Param input body models.UpdateOrder true "Update order object"
-// @Success 200 {object} models.Order "Successfully updated order"
+// @Success 200 {object} models.OrderDetail "Successfully updated order"
 // @Failure 400 {string} string "Bad Request"
 // @Failure 404 {string} string "order not found"
+// @Failure 409 {string} string "Order can no longer be modified"
+// @Failure 422 {string} string "Total does not match order lines"
 // @Router /orders/{id} [put]
 func (r *orderRepository) UpdateOrder(c *gin.Context) {
 	var order models.Order
@@ -109,35 +219,354 @@ func (r *orderRepository) UpdateOrder(c *gin.Context) {
 		return
 	}
 
+	if !order.Status.Editable() {
+		respondOrderStatusError(c, fmt.Errorf("%w: order is %s", errOrderNotEditable, order.Status))
+		return
+	}
+
 	if err := c.ShouldBindJSON(&input); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	r.DB.Model(&order).Updates(models.Order{Vendor: input.Vendor, Total: input.Total, LinesID: pq.Int64Array(input.LinesID), CashoutNumber: input.CashoutNumber})
+	customerName, err := orderCustomer(r.DB, input.CustomerID, input.Name())
+	if err != nil {
+		respondCustomerError(c, err)
+		return
+	}
+
+	var lines []models.OrderLine
+	if input.LinesID != nil {
+		lines, err = findLinesByID(r.DB, input.LinesID)
+		if err == nil {
+			err = checkLinesFree(lines, order.ID)
+		}
+		if err != nil {
+			respondOrderLinesError(c, err)
+			return
+		}
+	}
+
+	err = r.DB.Transaction(func(tx database.Database) error {
+		// The order is locked so that it cannot be paid or voided meanwhile
+		var err error
+		if order, err = findEditableOrder(tx, c.Param("id")); err != nil {
+			return err
+		}
+
+		if err := tx.Model(&order).Updates(models.Order{CustomerID: input.CustomerID, CustomerName: customerName, LoyaltyID: loyalty.Normalize(input.LoyaltyID), CashoutNumber: input.CashoutNumber}).Error; err != nil {
+			return err
+		}
 
-	c.JSON(http.StatusOK, gin.H{"data": order})
+		if input.LinesID != nil {
+			if err := replaceOrderLines(tx, order.ID, lines); err != nil {
+				return err
+			}
+			if lines, err = refreshOrderTotal(tx, &order); err != nil {
+				return err
+			}
+		} else if lines, err = findOrderLines(tx, order.ID); err != nil {
+			return err
+		}
+		return pricing.CheckTotal(order.Total, input.Total)
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, errOrderNotFound), errors.Is(err, errOrderNotEditable):
+			respondOrderStatusError(c, err)
+		case errors.Is(err, errOrderLineNotFound), errors.Is(err, errOrderLineTaken):
+			respondOrderLinesError(c, err)
+		case errors.Is(err, pricing.ErrPriceMismatch):
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		default:
+			respondPricingError(c, err)
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": models.OrderDetail{Order: order, Lines: lines, Taxes: order.Taxes}})
 }
 
 // DeleteOrder godoc
 // @Summary Delete an order by ID
-// @Description Delete the order with the given ID
+// @Description Delete the order with the given ID. Only open and parked orders can be deleted. The stock taken by its checkout is returned.
 // @Tags orders
 // @Security JwtAuth
 // @Produce json
 // @Param id path string true "Order ID"
 // @Success 204 {string} string "Successfully deleted order"
 // @Failure 404 {string} string "order not found"
+// @Failure 409 {string} string "Order can no longer be modified"
 // @Router /orders/{id} [delete]
 func (r *orderRepository) DeleteOrder(c *gin.Context) {
-	var order models.Order
+	err := r.DB.Transaction(func(tx database.Database) error {
+		order, err := findEditableOrder(tx, c.Param("id"))
+		if err != nil {
+			return err
+		}
 
-	if err := r.DB.Where("id = ?", c.Param("id")).First(&order).Error(); err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "order not found"})
+		lines, err := findOrderLines(tx, order.ID)
+		if err != nil {
+			return err
+		}
+		if err := restockLines(tx, lines); err != nil {
+			return err
+		}
+
+		// The order may have been paid since it was loaded
+		result := tx.Where("status = ?", order.Status).Delete(&order)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errOrderConflict
+		}
+		return nil
+	})
+	if err != nil {
+		respondOrderStatusError(c, err)
 		return
 	}
 
-	r.DB.Delete(&order)
-
 	c.JSON(http.StatusNoContent, gin.H{"data": true})
 }
+
+// findOrderLines loads the lines of the order with id orderID, in the order
+// they were added
+func findOrderLines(db database.Database, orderID uint) ([]models.OrderLine, error) {
+	var found []models.OrderLine
+
+	if err := db.Where("order_id = ?", orderID).Find(&found).Error; err != nil {
+		return nil, err
+	}
+
+	sort.Slice(found, func(i, j int) bool { return found[i].ID < found[j].ID })
+	return found, nil
+}
+
+// checkLinesFree reports errOrderLineTaken when one of lines already belongs
+// to an order other than orderID
+func checkLinesFree(lines []models.OrderLine, orderID uint) error {
+	var taken []uint
+	for _, line := range lines {
+		if line.OrderID != nil && *line.OrderID != orderID {
+			taken = append(taken, line.ID)
+		}
+	}
+
+	if len(taken) > 0 {
+		return fmt.Errorf("%w: %v", errOrderLineTaken, taken)
+	}
+	return nil
+}
+
+// attachOrderLines links lines to the order with id orderID. Lines taken by
+// another order in the meantime are reported with errOrderLineTaken.
+func attachOrderLines(tx database.Database, orderID uint, lines []models.OrderLine) error {
+	if len(lines) == 0 {
+		return nil
+	}
+
+	ids := make([]uint, len(lines))
+	for i := range lines {
+		ids[i] = lines[i].ID
+		lines[i].OrderID = &orderID
+	}
+
+	result := tx.Where("id IN ? AND (order_id IS NULL OR order_id = ?)", ids, orderID).Updates(&models.OrderLine{OrderID: &orderID})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected != int64(len(ids)) {
+		return errOrderLineTaken
+	}
+	return nil
+}
+
+// replaceOrderLines makes lines the lines of the order with id orderID and
+// deletes the lines the order had that are not part of them, returning the
+// stock checkout took for them
+func replaceOrderLines(tx database.Database, orderID uint, lines []models.OrderLine) error {
+	current, err := findOrderLines(tx, orderID)
+	if err != nil {
+		return err
+	}
+
+	if err := attachOrderLines(tx, orderID, lines); err != nil {
+		return err
+	}
+
+	kept := make([]uint, 0, len(lines))
+	isKept := make(map[uint]bool, len(lines))
+	for _, line := range lines {
+		kept = append(kept, line.ID)
+		isKept[line.ID] = true
+	}
+
+	var removed []models.OrderLine
+	for _, line := range current {
+		if !isKept[line.ID] {
+			removed = append(removed, line)
+		}
+	}
+	if err := restockLines(tx, removed); err != nil {
+		return err
+	}
+	if len(kept) == 0 {
+		return tx.Where("order_id = ?", orderID).Delete(&models.OrderLine{}).Error
+	}
+	return tx.Where("order_id = ? AND id NOT IN ?", orderID, kept).Delete(&models.OrderLine{}).Error
+}
+
+// findLinesOfOrders loads the lines of the orders with the given ids
+func findLinesOfOrders(db database.Database, ids []uint) ([]models.OrderLine, error) {
+	var found []models.OrderLine
+
+	if len(ids) == 0 {
+		return found, nil
+	}
+	if err := db.Where("order_id IN ?", ids).Find(&found).Error; err != nil {
+		return nil, err
+	}
+	return found, nil
+}
+
+// findLinesByID loads the lines referenced by ids in the same order. Lines
+// that could not be found are left out and reported with errOrderLineNotFound.
+func findLinesByID(db database.Database, ids []int64) ([]models.OrderLine, error) {
+	var found []models.OrderLine
+
+	if len(ids) > 0 {
+		if err := db.Where("id IN ?", ids).Find(&found).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	byID := make(map[int64]models.OrderLine, len(found))
+	for _, line := range found {
+		byID[int64(line.ID)] = line
+	}
+
+	lines := make([]models.OrderLine, 0, len(ids))
+	var missing []int64
+	for _, id := range ids {
+		line, ok := byID[id]
+		if !ok {
+			missing = append(missing, id)
+			continue
+		}
+		lines = append(lines, line)
+	}
+
+	if len(missing) > 0 {
+		return lines, fmt.Errorf("%w: %v", errOrderLineNotFound, missing)
+	}
+	return lines, nil
+}
+
+// orderDetail loads the lines and discounts of order
+func orderDetail(db database.Database, order models.Order) (models.OrderDetail, error) {
+	lines, err := findOrderLines(db, order.ID)
+	if err != nil {
+		return models.OrderDetail{}, err
+	}
+
+	discounts, err := orderDiscounts(db, order.ID)
+	if err != nil {
+		return models.OrderDetail{}, err
+	}
+
+	return models.OrderDetail{Order: order, Lines: lines, Taxes: orderTaxes(order, lines), Discounts: discounts}, nil
+}
+
+// lineProducts loads the products lines refer to, as they are now in the
+// catalog. Products deleted since come with DeletedAt set.
+func lineProducts(db database.Database, lines []models.OrderLine) ([]models.Product, error) {
+	var found []models.Product
+
+	if len(lines) == 0 {
+		return found, nil
+	}
+
+	seen := make(map[uint]bool, len(lines))
+	ids := make([]uint, 0, len(lines))
+	for _, line := range lines {
+		if !seen[line.ProductID] {
+			seen[line.ProductID] = true
+			ids = append(ids, line.ProductID)
+		}
+	}
+
+	if err := db.Unscoped().Where("id IN ?", ids).Find(&found).Error; err != nil {
+		return nil, err
+	}
+	return found, nil
+}
+
+// refreshOrderTotal sets the total, tax breakdown and currency of order back
+// to the ones of its lines after they changed, and returns them. The
+// promotions are applied again to the changed lines, like at checkout.
+func refreshOrderTotal(db database.Database, order *models.Order) ([]models.OrderLine, error) {
+	lines, err := findOrderLines(db, order.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if lines, err = applyOrderPromotions(db, *order, lines, time.Now()); err != nil {
+		return nil, err
+	}
+
+	if err := saveOrderAmounts(db, order, lines); err != nil {
+		return nil, err
+	}
+	return lines, nil
+}
+
+// saveOrderAmounts stores the total, tax breakdown and currency of order
+// made of lines
+func saveOrderAmounts(db database.Database, order *models.Order, lines []models.OrderLine) error {
+	total, currency, err := orderAmounts(lines)
+	if err != nil {
+		return err
+	}
+
+	order.Total, order.Taxes, order.Currency = total, pricing.Breakdown(lines), currency
+	return db.Model(order).Updates(map[string]interface{}{"total": total, "taxes": order.Taxes, "currency": currency}).Error
+}
+
+// orderTaxes returns the tax breakdown stored with order, or computes it from
+// its lines for orders recorded before breakdowns were stored
+func orderTaxes(order models.Order, lines []models.OrderLine) []models.TaxLine {
+	if order.Taxes != nil {
+		return order.Taxes
+	}
+	return pricing.Breakdown(lines)
+}
+
+// orderAmounts returns the total and currency of an order made of lines
+func orderAmounts(lines []models.OrderLine) (models.Money, string, error) {
+	currency, err := pricing.Currency(lines)
+	if err != nil {
+		return 0, "", err
+	}
+
+	total := pricing.OrderTotal(lines)
+	if err := pricing.CheckRange(total); err != nil {
+		return 0, "", err
+	}
+
+	return total, currency, nil
+}
+
+// respondOrderLinesError maps an error returned while looking up the lines
+// of an order to a response
+func respondOrderLinesError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, errOrderLineNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, errOrderLineTaken):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch order lines"})
+	}
+}
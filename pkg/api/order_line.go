@@ -2,11 +2,18 @@ package api
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"net/http"
+	"postui_api/pkg/barcode"
 	"postui_api/pkg/database"
 	"postui_api/pkg/models"
+	"postui_api/pkg/pricing"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
 )
 
 type OrderLineRepository interface {
@@ -34,15 +41,18 @@ func NewOrderLineRepository(db database.Database, ctx *context.Context) *orderLi
 
 // CreateOrderLine godoc
 // @Summary Create a new orderLine
-// @Description Create a new orderLine with the given input data
+// @Description Create a new orderLine with the given input data. Price, VAT and total are taken from the product catalog.
 // @Tags orderLines
 // @Security JwtAuth
 // @Accept  json
 // @Produce  json
 // @Param   input     body   models.CreateOrderLine   true   "Create orderLine object"
+// @Param Idempotency-Key header string false "Key under which the response is replayed to retries"
 // @Success 201 {object} models.OrderLine "Successfully created orderLine"
 // @Failure 400 {string} string "Bad Request"
 // @Failure 401 {string} string "Unauthorized"
+// @Failure 404 {string} string "Product not found"
+// @Failure 422 {string} string "Amounts do not match catalog"
 // @Router /order_lines [post]
 func (r *orderLineRepository) CreateOrderLine(c *gin.Context) {
 	appCtx, exists := c.MustGet("appCtxOrderLine").(*orderLineRepository)
@@ -60,7 +70,17 @@ func (r *orderLineRepository) CreateOrderLine(c *gin.Context) {
 	var orderLines []models.OrderLine
 
 	for _, input := range inputs {
-		orderLine := models.OrderLine{ProductID: input.ProductID, Quantity: input.Quantity, Price: input.Price, Vat: input.Vat, Total: input.Total}
+		orderLine, _, err := priceLabeledLine(appCtx.DB, input.ProductID, input.VariantID, input.Quantity, input.PriceLabel, time.Now())
+		if err != nil {
+			respondPricingError(c, err)
+			return
+		}
+
+		if err := pricing.CheckLine(orderLine, input.Price, input.Vat, input.Total); err != nil {
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+			return
+		}
+
 		orderLines = append(orderLines, orderLine)
 	}
 
@@ -92,7 +112,7 @@ func (r *orderLineRepository) FindOrderLine(c *gin.Context) {
 
 // UpdateOrderLine godoc
 // @Summary Update a orderLine by ID
-// @Description Update the orderLine details for the given ID
+// @Description Update the orderLine details for the given ID. The current promotions are applied again to its order and the order total is updated. The stock checkout took for the line follows the change.
 // @Tags orderLines
 // @Security JwtAuth
 // @Accept  json
@@ -102,6 +122,8 @@ func (r *orderLineRepository) FindOrderLine(c *gin.Context) {
 // @Success 200 {object} models.OrderLine "Successfully updated orderLine"
 // @Failure 400 {string} string "Bad Request"
 // @Failure 404 {string} string "orderLine not found"
+// @Failure 409 {string} string "Order can no longer be modified or insufficient stock"
+// @Failure 422 {string} string "Amounts do not match catalog"
 // @Router /order_lines/{id} [put]
 func (r *orderLineRepository) UpdateOrderLine(c *gin.Context) {
 	var orderLine models.OrderLine
@@ -117,20 +139,60 @@ func (r *orderLineRepository) UpdateOrderLine(c *gin.Context) {
 		return
 	}
 
-	r.DB.Model(&orderLine).Updates(models.OrderLine{ProductID: input.ProductID, Quantity: input.Quantity, Price: input.Price, Vat: input.Vat, Total: input.Total})
+	err := r.DB.Transaction(func(tx database.Database) error {
+		if err := lockOrderLine(tx, &orderLine); err != nil {
+			return err
+		}
+
+		productID := orderLine.ProductID
+		if input.ProductID != 0 {
+			productID = input.ProductID
+		}
+		quantity := orderLine.Quantity
+		if !input.Quantity.IsZero() {
+			quantity = input.Quantity
+		}
+
+		priced, _, err := priceOrderLine(tx, productID, lineVariant(orderLine, productID, input.VariantID), quantity, time.Now())
+		if err != nil {
+			return err
+		}
+		if err := pricing.CheckLine(priced, input.Price, input.Vat, input.Total); err != nil {
+			return err
+		}
+
+		if err := moveStock(tx, orderLine, priced); err != nil {
+			return err
+		}
+		if err := updateLinePricing(tx, &orderLine, priced); err != nil {
+			return err
+		}
+
+		if orderLine.OrderID != nil {
+			if _, err := refreshOrderTotal(tx, &models.Order{ID: *orderLine.OrderID}); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		respondOrderLineError(c, err)
+		return
+	}
 
 	c.JSON(http.StatusOK, gin.H{"data": orderLine})
 }
 
 // DeleteOrderLine godoc
 // @Summary Delete a orderLine by ID
-// @Description Delete the orderLine with the given ID
+// @Description Delete the orderLine with the given ID. The current promotions are applied again to its order and the order total is updated. The stock checkout took for the line is returned.
 // @Tags orderLines
 // @Security JwtAuth
 // @Produce json
 // @Param id path string true "OrderLine ID"
 // @Success 204 {string} string "Successfully deleted orderLine"
 // @Failure 404 {string} string "orderLine not found"
+// @Failure 409 {string} string "Order can no longer be modified"
 // @Router /order_lines/{id} [delete]
 func (r *orderLineRepository) DeleteOrderLine(c *gin.Context) {
 	var orderLine models.OrderLine
@@ -140,7 +202,161 @@ func (r *orderLineRepository) DeleteOrderLine(c *gin.Context) {
 		return
 	}
 
-	r.DB.Delete(&orderLine)
+	err := r.DB.Transaction(func(tx database.Database) error {
+		if err := lockOrderLine(tx, &orderLine); err != nil {
+			return err
+		}
+		if err := restockLines(tx, []models.OrderLine{orderLine}); err != nil {
+			return err
+		}
+		if err := tx.Delete(&orderLine).Error; err != nil {
+			return err
+		}
+
+		if orderLine.OrderID != nil {
+			if _, err := refreshOrderTotal(tx, &models.Order{ID: *orderLine.OrderID}); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		respondOrderLineError(c, err)
+		return
+	}
 
 	c.JSON(http.StatusNoContent, gin.H{"data": true})
 }
+
+// priceOrderLine looks up a product and prices quantity units of it, or of
+// its variant variantID for products sold by variant, sold at the given
+// time. The product is returned along with the line.
+func priceOrderLine(db database.Database, productID uint, variantID *uint, quantity decimal.Decimal, at time.Time) (models.OrderLine, models.Product, error) {
+	var product models.Product
+
+	if !quantity.IsPositive() {
+		return models.OrderLine{}, product, fmt.Errorf("%w: quantity for product %d must be positive", errInvalidQuantity, productID)
+	}
+
+	if err := db.Where("id = ?", productID).First(&product).Error(); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return models.OrderLine{}, product, fmt.Errorf("%w: %d", errProductNotFound, productID)
+		}
+		return models.OrderLine{}, product, err
+	}
+
+	if product.GiftCard && !quantity.IsInteger() {
+		return models.OrderLine{}, product, fmt.Errorf("%w: gift cards of product %d are sold by the unit", errInvalidQuantity, productID)
+	}
+
+	line := pricing.PriceLine(product, quantity)
+	if len(product.VariantAttributes) > 0 || variantID != nil {
+		variant, err := findVariantOf(db, product, variantID)
+		if err != nil {
+			return models.OrderLine{}, product, err
+		}
+		pricing.ApplyVariant(&line, variant)
+	}
+	if product.TaxCategoryID != nil {
+		rate, err := effectiveTaxRate(db, *product.TaxCategoryID, at)
+		if err != nil {
+			return models.OrderLine{}, product, err
+		}
+		pricing.ApplyRate(&line, rate)
+	}
+	if err := pricing.CheckRange(line.Total); err != nil {
+		return models.OrderLine{}, product, err
+	}
+
+	return line, product, nil
+}
+
+// priceLabeledLine prices a line like priceOrderLine, at the price printed
+// on priceLabel when the line was scanned from a price label
+func priceLabeledLine(db database.Database, productID uint, variantID *uint, quantity decimal.Decimal, priceLabel string, at time.Time) (models.OrderLine, models.Product, error) {
+	line, product, err := priceOrderLine(db, productID, variantID, quantity, at)
+	if err != nil || priceLabel == "" {
+		return line, product, err
+	}
+
+	if err := applyPriceLabel(db, &line, product, priceLabel); err != nil {
+		return models.OrderLine{}, product, err
+	}
+	return line, product, nil
+}
+
+// updateLinePricing stores the product, quantity and amounts of priced on
+// line. Every column is written, zero values included, so that nothing of
+// the previous pricing, such as its discount, is left.
+func updateLinePricing(tx database.Database, line *models.OrderLine, priced models.OrderLine) error {
+	err := tx.Model(line).Updates(map[string]interface{}{
+		"product_id": priced.ProductID,
+		"variant_id": priced.VariantID,
+		"quantity":   priced.Quantity,
+		"price":      priced.Price,
+		"currency":   priced.Currency,
+		"vat":        priced.Vat,
+		"surcharge":  priced.Surcharge,
+		"total":      priced.Total,
+		"discount":   priced.Discount,
+	}).Error
+	if err != nil {
+		return err
+	}
+
+	line.ProductID, line.VariantID, line.Quantity = priced.ProductID, priced.VariantID, priced.Quantity
+	line.Price, line.Currency, line.Vat, line.Surcharge = priced.Price, priced.Currency, priced.Vat, priced.Surcharge
+	line.Total, line.Discount = priced.Total, priced.Discount
+	return nil
+}
+
+// lineVariant returns the variant a line changed to productID is sold as:
+// the requested one, else the one of line when the product is unchanged
+func lineVariant(line models.OrderLine, productID uint, variantID *uint) *uint {
+	if variantID != nil || productID != line.ProductID {
+		return variantID
+	}
+	return line.VariantID
+}
+
+// respondPricingError maps an error returned by priceOrderLine to a response
+func respondPricingError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, errInvalidQuantity), errors.Is(err, errVariantRequired), errors.Is(err, errInvalidBarcode), errors.Is(err, barcode.ErrCheckDigit), errors.Is(err, barcode.ErrInvalidFormat):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, errProductNotFound), errors.Is(err, errVariantNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, pricing.ErrAmountOutOfRange), errors.Is(err, pricing.ErrCurrencyMismatch), errors.Is(err, pricing.ErrNoTaxRate):
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+	}
+}
+
+// lockOrderLine locks the order of line, so that it cannot be paid while the
+// line changes, and reloads line, which may have changed while waiting for
+// the lock. It fails with errOrderNotEditable when the order can no longer be
+// modified.
+func lockOrderLine(tx database.Database, line *models.OrderLine) error {
+	if line.OrderID == nil {
+		return nil
+	}
+
+	var order models.Order
+
+	if err := tx.ForUpdate().Where("id = ?", *line.OrderID).First(&order).Error(); err != nil {
+		return err
+	}
+
+	if !order.Status.Editable() {
+		return fmt.Errorf("%w: line belongs to %s order %d", errOrderNotEditable, order.Status, order.ID)
+	}
+
+	if err := tx.Where("id = ?", line.ID).First(line).Error(); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("%w: %d", errOrderLineNotFound, line.ID)
+		}
+		return err
+	}
+	return nil
+}
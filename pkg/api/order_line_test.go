@@ -52,7 +52,7 @@ func TestCreateOrderLine(t *testing.T) {
 	// Example data for the test
 	quantity, _ := decimal.NewFromString("100")
 	inputOrderLines := []models.CreateOrderLine{
-		{ProductID: 1, Quantity: quantity, Price: 100, Vat: 2100, Total: 2},
+		{ProductID: 1, Quantity: quantity, Price: 100, Vat: 2100},
 	}
 
 	requestBody, err := json.Marshal(inputOrderLines)
@@ -60,6 +60,18 @@ func TestCreateOrderLine(t *testing.T) {
 		t.Fatalf("Failed to marshal input orderLine data: %v", err)
 	}
 
+	// Set up database mock to return the catalog product
+	mockDB.EXPECT().Where("id = ?", uint(1)).Return(mockDB).Times(1)
+	mockDB.EXPECT().
+		First(gomock.Any()).
+		DoAndReturn(func(dest interface{}, conds ...interface{}) database.Database {
+			if p, ok := dest.(*models.Product); ok {
+				*p = models.Product{ID: 1, Name: "Product", Price: 100, Vat: 2100}
+			}
+			return mockDB
+		}).Times(1)
+	mockDB.EXPECT().Error().Return(nil).Times(1)
+
 	// Set up database mock to simulate successful orderLine creation
 	mockDB.EXPECT().Create(gomock.Any()).DoAndReturn(func(orderLine *[]models.OrderLine) *gorm.DB {
 		// Normally, you might simulate setting an ID or other fields modified by the DB
@@ -79,6 +91,57 @@ func TestCreateOrderLine(t *testing.T) {
 	// Assertions to check the response
 	assert.Equal(t, http.StatusCreated, w.Code, "Expected HTTP status code 201")
 	assert.Contains(t, w.Body.String(), "2100", "Response body should contain the orderLine Vat")
+	assert.Contains(t, w.Body.String(), `"total":10000`, "Response body should contain the computed orderLine total")
+}
+
+func TestCreateOrderLinePriceMismatch(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDB := database.NewMockDatabase(ctrl)
+	ctx := context.Background()
+
+	repo := NewOrderLineRepository(mockDB, &ctx)
+
+	// Set up Gin
+	gin.SetMode(gin.TestMode)
+	r := gin.Default()
+	r.POST("/order_lines", func(c *gin.Context) {
+		// Set the appCtx in the Gin context
+		c.Set("appCtxOrderLine", repo)
+		repo.CreateOrderLine(c)
+	})
+
+	// The client claims a lower price than the catalog one
+	inputOrderLines := []models.CreateOrderLine{
+		{ProductID: 1, Quantity: decimal.NewFromInt(1), Price: 1},
+	}
+
+	requestBody, err := json.Marshal(inputOrderLines)
+	if err != nil {
+		t.Fatalf("Failed to marshal input orderLine data: %v", err)
+	}
+
+	mockDB.EXPECT().Where("id = ?", uint(1)).Return(mockDB).Times(1)
+	mockDB.EXPECT().
+		First(gomock.Any()).
+		DoAndReturn(func(dest interface{}, conds ...interface{}) database.Database {
+			if p, ok := dest.(*models.Product); ok {
+				*p = models.Product{ID: 1, Name: "Product", Price: 100, Vat: 2100}
+			}
+			return mockDB
+		}).Times(1)
+	mockDB.EXPECT().Error().Return(nil).Times(1)
+
+	// Nothing must be stored
+	mockDB.EXPECT().Create(gomock.Any()).Times(0)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/order_lines", bytes.NewBuffer(requestBody))
+	req.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
 }
 
 func TestFindOrderLine(t *testing.T) {
@@ -195,6 +258,19 @@ func TestDeleteOrderLine(t *testing.T) {
 			return mockDB
 		}).Times(1)
 
+	// The line belongs to no order, so there is no order to look up
+	mockDB.EXPECT().Find(gomock.Any()).Times(0)
+
+	// Run the transaction body against the same mock
+	mockDB.EXPECT().
+		Transaction(gomock.Any()).
+		DoAndReturn(func(fc func(database.Database) error) error {
+			return fc(mockDB)
+		}).Times(1)
+
+	// The line was not checked out, so no stock is returned
+	mockDB.EXPECT().Model(gomock.Any()).Times(0)
+
 	// Mock Delete method
 	mockDB.EXPECT().
 		Delete(&existingOrderLine).
@@ -211,3 +287,53 @@ func TestDeleteOrderLine(t *testing.T) {
 	// Assert the response
 	assert.Equal(t, http.StatusNoContent, w.Code)
 }
+
+func TestDeleteOrderLineRefusedWhenPaid(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDB := database.NewMockDatabase(ctrl)
+	ctx := context.Background()
+	repo := NewOrderLineRepository(mockDB, &ctx)
+
+	// Set up Gin for testing
+	gin.SetMode(gin.TestMode)
+	r := gin.Default()
+	r.DELETE("/orderLine/:id", repo.DeleteOrderLine)
+
+	orderID := uint(3)
+	existingOrderLine := models.OrderLine{ID: 1, OrderID: &orderID, ProductID: 1, Quantity: decimal.NewFromInt(1), Price: 100, Total: 100}
+
+	mockDB.EXPECT().Where("id = ?", "1").Return(mockDB).Times(1)
+
+	// The order is locked inside the transaction, where it is found paid
+	mockDB.EXPECT().
+		Transaction(gomock.Any()).
+		DoAndReturn(func(fc func(database.Database) error) error {
+			return fc(mockDB)
+		}).Times(1)
+	mockDB.EXPECT().ForUpdate().Return(mockDB).Times(1)
+	mockDB.EXPECT().Where("id = ?", orderID).Return(mockDB).Times(1)
+	mockDB.EXPECT().
+		First(gomock.Any()).
+		DoAndReturn(func(dest interface{}, conds ...interface{}) database.Database {
+			switch b := dest.(type) {
+			case *models.OrderLine:
+				*b = existingOrderLine
+			case *models.Order:
+				*b = models.Order{ID: orderID, Status: models.OrderPaid}
+			}
+			return mockDB
+		}).Times(2)
+	mockDB.EXPECT().Error().Return(nil).Times(2)
+
+	// The line must be kept
+	mockDB.EXPECT().Delete(gomock.Any()).Times(0)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodDelete, "/orderLine/1", nil)
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.Contains(t, w.Body.String(), "line belongs to paid order 3")
+}
@@ -0,0 +1,252 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"postui_api/pkg/database"
+	"postui_api/pkg/models"
+	"postui_api/pkg/pricing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// AddOrderLine godoc
+// @Summary Add a line to an order
+// @Description Add a line to an open or parked order. Price, VAT and total are taken from the product catalog, the current promotions are applied again and the order total is updated.
+// @Tags orders
+// @Security JwtAuth
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Order ID"
+// @Param input body models.CreateOrderLine true "Create orderLine object"
+// @Param Idempotency-Key header string false "Key under which the response is replayed to retries"
+// @Success 201 {object} models.OrderDetail "Successfully added order line"
+// @Failure 400 {string} string "Bad Request"
+// @Failure 404 {string} string "Order or product not found"
+// @Failure 409 {string} string "Order can no longer be modified"
+// @Failure 422 {string} string "Amounts do not match catalog"
+// @Router /orders/{id}/lines [post]
+func (r *orderRepository) AddOrderLine(c *gin.Context) {
+	var input models.CreateOrderLine
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var detail models.OrderDetail
+
+	err := r.DB.Transaction(func(tx database.Database) error {
+		order, err := findEditableOrder(tx, c.Param("id"))
+		if err != nil {
+			return err
+		}
+
+		line, _, err := priceLabeledLine(tx, input.ProductID, input.VariantID, input.Quantity, input.PriceLabel, time.Now())
+		if err != nil {
+			return err
+		}
+		if err := pricing.CheckLine(line, input.Price, input.Vat, input.Total); err != nil {
+			return err
+		}
+
+		line.OrderID = &order.ID
+		if err := tx.Create(&line).Error; err != nil {
+			return err
+		}
+
+		detail, err = refreshedOrderDetail(tx, order)
+		return err
+	})
+	if err != nil {
+		respondOrderLineError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"data": detail})
+}
+
+// ChangeOrderLine godoc
+// @Summary Update a line of an order
+// @Description Update the product or quantity of a line of an open or parked order. The current promotions are applied again and the order total is updated. The stock checkout took for the line follows the change.
+// @Tags orders
+// @Security JwtAuth
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Order ID"
+// @Param line_id path string true "OrderLine ID"
+// @Param input body models.UpdateOrderLine true "Update orderLine object"
+// @Success 200 {object} models.OrderDetail "Successfully updated order line"
+// @Failure 400 {string} string "Bad Request"
+// @Failure 404 {string} string "Order, line or product not found"
+// @Failure 409 {string} string "Order can no longer be modified or insufficient stock"
+// @Failure 422 {string} string "Amounts do not match catalog"
+// @Router /orders/{id}/lines/{line_id} [put]
+func (r *orderRepository) ChangeOrderLine(c *gin.Context) {
+	var input models.UpdateOrderLine
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var detail models.OrderDetail
+
+	err := r.DB.Transaction(func(tx database.Database) error {
+		order, err := findEditableOrder(tx, c.Param("id"))
+		if err != nil {
+			return err
+		}
+
+		line, err := findLineOfOrder(tx, order, c.Param("line_id"))
+		if err != nil {
+			return err
+		}
+
+		productID := line.ProductID
+		if input.ProductID != 0 {
+			productID = input.ProductID
+		}
+		quantity := line.Quantity
+		if !input.Quantity.IsZero() {
+			quantity = input.Quantity
+		}
+
+		priced, _, err := priceOrderLine(tx, productID, lineVariant(line, productID, input.VariantID), quantity, time.Now())
+		if err != nil {
+			return err
+		}
+		if err := pricing.CheckLine(priced, input.Price, input.Vat, input.Total); err != nil {
+			return err
+		}
+
+		if err := moveStock(tx, line, priced); err != nil {
+			return err
+		}
+		if err := updateLinePricing(tx, &line, priced); err != nil {
+			return err
+		}
+
+		detail, err = refreshedOrderDetail(tx, order)
+		return err
+	})
+	if err != nil {
+		respondOrderLineError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": detail})
+}
+
+// RemoveOrderLine godoc
+// @Summary Remove a line from an order
+// @Description Delete a line of an open or parked order. The current promotions are applied again and the order total is updated. The stock checkout took for the line is returned.
+// @Tags orders
+// @Security JwtAuth
+// @Produce json
+// @Param id path string true "Order ID"
+// @Param line_id path string true "OrderLine ID"
+// @Success 200 {object} models.OrderDetail "Successfully removed order line"
+// @Failure 404 {string} string "Order or line not found"
+// @Failure 409 {string} string "Order can no longer be modified"
+// @Router /orders/{id}/lines/{line_id} [delete]
+func (r *orderRepository) RemoveOrderLine(c *gin.Context) {
+	var detail models.OrderDetail
+
+	err := r.DB.Transaction(func(tx database.Database) error {
+		order, err := findEditableOrder(tx, c.Param("id"))
+		if err != nil {
+			return err
+		}
+
+		line, err := findLineOfOrder(tx, order, c.Param("line_id"))
+		if err != nil {
+			return err
+		}
+
+		if err := restockLines(tx, []models.OrderLine{line}); err != nil {
+			return err
+		}
+		if err := tx.Delete(&line).Error; err != nil {
+			return err
+		}
+
+		detail, err = refreshedOrderDetail(tx, order)
+		return err
+	})
+	if err != nil {
+		respondOrderLineError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": detail})
+}
+
+// findEditableOrder locks and returns the order with the given id if its
+// lines can still be changed, so that it cannot be paid while they change
+func findEditableOrder(tx database.Database, id string) (models.Order, error) {
+	var order models.Order
+
+	if err := tx.ForUpdate().Where("id = ?", id).First(&order).Error(); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return order, errOrderNotFound
+		}
+		return order, err
+	}
+
+	if !order.Status.Editable() {
+		return order, fmt.Errorf("%w: order is %s", errOrderNotEditable, order.Status)
+	}
+
+	return order, nil
+}
+
+// findLineOfOrder returns the line with the given id if it belongs to order
+func findLineOfOrder(tx database.Database, order models.Order, id string) (models.OrderLine, error) {
+	var line models.OrderLine
+
+	if err := tx.Where("id = ? AND order_id = ?", id, order.ID).First(&line).Error(); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return line, fmt.Errorf("%w: %s is not a line of order %d", errOrderLineNotFound, id, order.ID)
+		}
+		return line, err
+	}
+
+	return line, nil
+}
+
+// refreshedOrderDetail updates the total of order after its lines changed and
+// returns its detail
+func refreshedOrderDetail(tx database.Database, order models.Order) (models.OrderDetail, error) {
+	lines, err := refreshOrderTotal(tx, &order)
+	if err != nil {
+		return models.OrderDetail{}, err
+	}
+
+	discounts, err := orderDiscounts(tx, order.ID)
+	if err != nil {
+		return models.OrderDetail{}, err
+	}
+
+	return models.OrderDetail{Order: order, Lines: lines, Taxes: order.Taxes, Discounts: discounts}, nil
+}
+
+// respondOrderLineError maps an error returned while changing the lines of
+// an order to a response
+func respondOrderLineError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, errOrderNotFound), errors.Is(err, errOrderLineNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, errOrderNotEditable):
+		respondOrderStatusError(c, err)
+	case errors.Is(err, errInsufficientStock):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, pricing.ErrPriceMismatch):
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+	default:
+		respondPricingError(c, err)
+	}
+}
@@ -0,0 +1,179 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"postui_api/pkg/database"
+	"postui_api/pkg/models"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func TestAddOrderLineRefusedWhenPaid(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDB := database.NewMockDatabase(ctrl)
+	ctx := context.Background()
+	repo := NewOrderRepository(mockDB, &ctx)
+
+	// Set up Gin for testing
+	gin.SetMode(gin.TestMode)
+	r := gin.Default()
+	r.POST("/order/:id/lines", repo.AddOrderLine)
+
+	paidOrder := models.Order{ID: 1, CustomerName: "username", Total: 1000, CashoutNumber: 1, Status: models.OrderPaid}
+
+	requestBody, err := json.Marshal(models.CreateOrderLine{ProductID: 1, Quantity: decimal.NewFromInt(1)})
+	if err != nil {
+		t.Fatalf("Failed to marshal order line data: %v", err)
+	}
+
+	// Run the transaction body against the same mock
+	mockDB.EXPECT().
+		Transaction(gomock.Any()).
+		DoAndReturn(func(fc func(database.Database) error) error {
+			return fc(mockDB)
+		}).Times(1)
+	mockDB.EXPECT().ForUpdate().Return(mockDB).Times(1)
+	mockDB.EXPECT().Where("id = ?", "1").Return(mockDB).Times(1)
+	mockDB.EXPECT().
+		First(gomock.Any()).
+		DoAndReturn(func(dest interface{}, conds ...interface{}) database.Database {
+			if b, ok := dest.(*models.Order); ok {
+				*b = paidOrder
+			}
+			return mockDB
+		}).Times(1)
+	mockDB.EXPECT().Error().Return(nil).Times(1)
+
+	// No line must be added
+	mockDB.EXPECT().Create(gomock.Any()).Times(0)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPost, "/order/1/lines", bytes.NewBuffer(requestBody))
+	req.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.Contains(t, w.Body.String(), "order can no longer be modified")
+}
+
+func TestRemoveOrderLineOfAnotherOrder(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDB := database.NewMockDatabase(ctrl)
+	ctx := context.Background()
+	repo := NewOrderRepository(mockDB, &ctx)
+
+	// Set up Gin for testing
+	gin.SetMode(gin.TestMode)
+	r := gin.Default()
+	r.DELETE("/order/:id/lines/:line_id", repo.RemoveOrderLine)
+
+	openOrder := models.Order{ID: 1, CustomerName: "username", Total: 1000, CashoutNumber: 1, Status: models.OrderOpen}
+
+	// Run the transaction body against the same mock
+	mockDB.EXPECT().
+		Transaction(gomock.Any()).
+		DoAndReturn(func(fc func(database.Database) error) error {
+			return fc(mockDB)
+		}).Times(1)
+	mockDB.EXPECT().ForUpdate().Return(mockDB).Times(1)
+	mockDB.EXPECT().Where("id = ?", "1").Return(mockDB).Times(1)
+	mockDB.EXPECT().
+		First(gomock.Any()).
+		DoAndReturn(func(dest interface{}, conds ...interface{}) database.Database {
+			if b, ok := dest.(*models.Order); ok {
+				*b = openOrder
+			}
+			return mockDB
+		}).Times(1)
+	mockDB.EXPECT().Error().Return(nil).Times(1)
+
+	// Line 4 exists but belongs to another order
+	mockDB.EXPECT().Where("id = ? AND order_id = ?", "4", uint(1)).Return(mockDB).Times(1)
+	mockDB.EXPECT().First(gomock.Any()).Return(mockDB).Times(1)
+	mockDB.EXPECT().Error().Return(gorm.ErrRecordNotFound).Times(1)
+
+	mockDB.EXPECT().Delete(gomock.Any()).Times(0)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodDelete, "/order/1/lines/4", nil)
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Contains(t, w.Body.String(), "4 is not a line of order 1")
+}
+
+func TestFindOrderIncludeProducts(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDB := database.NewMockDatabase(ctrl)
+	ctx := context.Background()
+	repo := NewOrderRepository(mockDB, &ctx)
+
+	// Set up Gin for testing
+	gin.SetMode(gin.TestMode)
+	r := gin.Default()
+	r.GET("/order/:id", repo.FindOrder)
+
+	order := models.Order{ID: 1, CustomerName: "username", Total: 363, Currency: "EUR", CashoutNumber: 1, Status: models.OrderOpen}
+	orderID := order.ID
+	lines := []models.OrderLine{
+		{ID: 1, OrderID: &orderID, ProductID: 3, Quantity: decimal.NewFromInt(2), Price: 121, Currency: "EUR", Vat: 2100, Total: 242},
+		{ID: 2, OrderID: &orderID, ProductID: 3, Quantity: decimal.NewFromInt(1), Price: 121, Currency: "EUR", Vat: 2100, Total: 121},
+	}
+
+	mockDB.EXPECT().Where("id = ?", "1").Return(mockDB).Times(1)
+	mockDB.EXPECT().
+		First(gomock.Any()).
+		DoAndReturn(func(dest interface{}, conds ...interface{}) database.Database {
+			if b, ok := dest.(*models.Order); ok {
+				*b = order
+			}
+			return mockDB
+		}).Times(1)
+	mockDB.EXPECT().Error().Return(nil).Times(1)
+
+	mockDB.EXPECT().Where("order_id = ?", uint(1)).Return(mockDB).Times(2)
+	mockDB.EXPECT().Find(gomock.AssignableToTypeOf(&[]models.OrderLine{})).DoAndReturn(func(dest interface{}, conds ...interface{}) *gorm.DB {
+		*dest.(*[]models.OrderLine) = lines
+		return &gorm.DB{Error: nil}
+	}).Times(1)
+	mockDB.EXPECT().Find(gomock.AssignableToTypeOf(&[]models.OrderDiscount{})).Return(&gorm.DB{Error: nil}).Times(1)
+
+	// Both lines sell the same product, which is looked up once
+	mockDB.EXPECT().Unscoped().Return(mockDB).Times(1)
+	mockDB.EXPECT().Where("id IN ?", []uint{3}).Return(mockDB).Times(1)
+	mockDB.EXPECT().Find(gomock.AssignableToTypeOf(&[]models.Product{})).DoAndReturn(func(dest interface{}, conds ...interface{}) *gorm.DB {
+		*dest.(*[]models.Product) = []models.Product{{ID: 3, Name: "Baguette"}}
+		return &gorm.DB{Error: nil}
+	}).Times(1)
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/order/1?include=products", nil)
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response struct {
+		Data models.OrderDetail `json:"data"`
+	}
+
+	assert.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+	assert.Len(t, response.Data.Lines, 2)
+	if assert.Len(t, response.Data.Products, 1) {
+		assert.Equal(t, "Baguette", response.Data.Products[0].Name)
+	}
+}
@@ -0,0 +1,226 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"postui_api/pkg/models"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+var errInvalidOrderQuery = errors.New("invalid order query")
+
+// orderSorts maps the sort keys of the order list to their columns
+var orderSorts = map[string]string{
+	"id":         "id",
+	"created_at": "created_at",
+	"total":      "total",
+	"invoice":    "invoice",
+}
+
+// orderQuery holds the filters and sorting of the order list
+type orderQuery struct {
+	From          *time.Time
+	To            *time.Time
+	CashoutNumber *uint
+	CustomerID    *uint
+	Customer      string
+	Statuses      []models.OrderStatus
+	Cashier       string
+	MinTotal      *models.Money
+	MaxTotal      *models.Money
+	ProductID     *uint
+	Barcode       string
+	Sort          string
+}
+
+// FindOrders godoc
+// @Summary List orders
+// @Description Get orders with pagination, optionally filtered. Dates are RFC 3339 timestamps or YYYY-MM-DD days, to being exclusive unless it is a day. Totals are in cents. Sort by id, created_at, total or invoice, prefixed with - for descending order.
+// @Tags orders
+// @Security JwtAuth
+// @Produce json
+// @Param offset query int false "Offset for pagination" default(0)
+// @Param limit query int false "Limit for pagination" default(10)
+// @Param from query string false "Created at or after"
+// @Param to query string false "Created before, or on that day"
+// @Param cashout_number query int false "Cashout number"
+// @Param customer_id query int false "Customer ID"
+// @Param customer query string false "Text the customer name contains"
+// @Param status query string false "Statuses separated by commas (ex: paid,refunded)"
+// @Param cashier query string false "Username of the cashier"
+// @Param min_total query int false "Minimum total"
+// @Param max_total query int false "Maximum total"
+// @Param product_id query int false "Product sold by the order"
+// @Param barcode query string false "Barcode of a product sold by the order"
+// @Param sort query string false "Sort key" default(-created_at)
+// @Success 200 {object} models.PaginatedOrderResponse "Successfully retrieved list of orders"
+// @Failure 400 {string} string "Bad Request"
+// @Router /orders [get]
+func (r *orderRepository) FindOrders(c *gin.Context) {
+	offset, limit, ok := pageParams(c)
+	if !ok {
+		return
+	}
+
+	query, err := parseOrderQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	filtered := query.apply(r.DB.Model(&models.Order{}))
+
+	var total int64
+	if err := filtered.Count(&total).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	found := []models.Order{}
+	if err := filtered.Order(query.Sort).Offset(offset).Limit(limit).Find(&found).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.PaginatedOrderResponse{Data: found, Pagination: newPagination(offset, limit, total)})
+}
+
+// parseOrderQuery reads the filters and sorting of the order list from the
+// query parameters
+func parseOrderQuery(c *gin.Context) (orderQuery, error) {
+	var query orderQuery
+	var err error
+
+	if query.From, err = parseOrderDate(c.Query("from"), false); err != nil {
+		return query, fmt.Errorf("%w: from: %v", errInvalidOrderQuery, err)
+	}
+	if query.To, err = parseOrderDate(c.Query("to"), true); err != nil {
+		return query, fmt.Errorf("%w: to: %v", errInvalidOrderQuery, err)
+	}
+
+	for name, dest := range map[string]**uint{"cashout_number": &query.CashoutNumber, "customer_id": &query.CustomerID, "product_id": &query.ProductID} {
+		value := c.Query(name)
+		if value == "" {
+			continue
+		}
+		id, err := strconv.ParseUint(value, 10, 32)
+		if err != nil {
+			return query, fmt.Errorf("%w: %s must be a positive integer", errInvalidOrderQuery, name)
+		}
+		parsed := uint(id)
+		*dest = &parsed
+	}
+
+	for name, dest := range map[string]**models.Money{"min_total": &query.MinTotal, "max_total": &query.MaxTotal} {
+		value := c.Query(name)
+		if value == "" {
+			continue
+		}
+		amount, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return query, fmt.Errorf("%w: %s must be an amount in cents", errInvalidOrderQuery, name)
+		}
+		parsed := models.Money(amount)
+		*dest = &parsed
+	}
+
+	if statuses := c.Query("status"); statuses != "" {
+		for _, status := range strings.Split(statuses, ",") {
+			status := models.OrderStatus(strings.TrimSpace(status))
+			switch status {
+			case models.OrderOpen, models.OrderParked, models.OrderPaid, models.OrderVoided, models.OrderRefunded:
+				query.Statuses = append(query.Statuses, status)
+			default:
+				return query, fmt.Errorf("%w: unknown status %q", errInvalidOrderQuery, status)
+			}
+		}
+	}
+
+	query.Customer = strings.TrimSpace(c.Query("customer"))
+	query.Cashier = strings.TrimSpace(c.Query("cashier"))
+	query.Barcode = strings.TrimSpace(c.Query("barcode"))
+
+	sort := c.DefaultQuery("sort", "-created_at")
+	direction := "ASC"
+	if strings.HasPrefix(sort, "-") {
+		sort, direction = sort[1:], "DESC"
+	}
+	column, ok := orderSorts[sort]
+	if !ok {
+		return query, fmt.Errorf("%w: cannot sort by %q", errInvalidOrderQuery, sort)
+	}
+	// Ties are broken by id so that pages do not overlap
+	query.Sort = fmt.Sprintf("%s %s, id %s", column, direction, direction)
+	if column == "id" {
+		query.Sort = "id " + direction
+	}
+
+	return query, nil
+}
+
+// parseOrderDate parses an RFC 3339 timestamp or a YYYY-MM-DD day. A day
+// ending a range is taken as the start of the next one, so that it is
+// included.
+func parseOrderDate(value string, end bool) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return &t, nil
+	}
+
+	day, err := time.ParseInLocation("2006-01-02", value, time.Local)
+	if err != nil {
+		return nil, fmt.Errorf("%q is neither a timestamp nor a day", value)
+	}
+	if end {
+		day = day.AddDate(0, 0, 1)
+	}
+	return &day, nil
+}
+
+// apply adds the filters of q to db
+func (q orderQuery) apply(db *gorm.DB) *gorm.DB {
+	if q.From != nil {
+		db = db.Where("created_at >= ?", *q.From)
+	}
+	if q.To != nil {
+		db = db.Where("created_at < ?", *q.To)
+	}
+	if q.CashoutNumber != nil {
+		db = db.Where("cashout_number = ?", *q.CashoutNumber)
+	}
+	if q.CustomerID != nil {
+		db = db.Where("customer_id = ?", *q.CustomerID)
+	}
+	if q.Customer != "" {
+		db = db.Where("customer_name ILIKE ?", "%"+likeEscaper.Replace(q.Customer)+"%")
+	}
+	if len(q.Statuses) > 0 {
+		db = db.Where("status IN ?", q.Statuses)
+	}
+	if q.Cashier != "" {
+		db = db.Where("cashier = ?", q.Cashier)
+	}
+	if q.MinTotal != nil {
+		db = db.Where("total >= ?", *q.MinTotal)
+	}
+	if q.MaxTotal != nil {
+		db = db.Where("total <= ?", *q.MaxTotal)
+	}
+	if q.ProductID != nil {
+		db = db.Where("EXISTS (SELECT 1 FROM order_lines WHERE order_lines.order_id = orders.id AND order_lines.product_id = ?)", *q.ProductID)
+	}
+	if q.Barcode != "" {
+		// Deleted products keep their barcode number but lose their barcodes
+		db = db.Where("EXISTS (SELECT 1 FROM order_lines JOIN products ON products.id = order_lines.product_id WHERE order_lines.order_id = orders.id AND (products.barcode_number = ? OR EXISTS (SELECT 1 FROM product_barcodes WHERE product_barcodes.product_id = products.id AND product_barcodes.code = ?)))", q.Barcode, q.Barcode)
+	}
+	return db
+}
@@ -0,0 +1,83 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"postui_api/pkg/database"
+	"postui_api/pkg/models"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+// queryContext returns a gin context for a GET request with query
+func queryContext(query string) *gin.Context {
+	gin.SetMode(gin.TestMode)
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest(http.MethodGet, "/orders?"+query, nil)
+	return c
+}
+
+func TestParseOrderQuery(t *testing.T) {
+	query, err := parseOrderQuery(queryContext("from=2026-03-01&to=2026-03-31&cashout_number=2&status=paid,refunded&cashier=alice&min_total=1000&barcode=8412345678905&sort=-total"))
+
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2026, 3, 1, 0, 0, 0, 0, time.Local), *query.From)
+	assert.Equal(t, time.Date(2026, 4, 1, 0, 0, 0, 0, time.Local), *query.To, "A day ending the range should be included")
+	assert.Equal(t, uint(2), *query.CashoutNumber)
+	assert.Equal(t, []models.OrderStatus{models.OrderPaid, models.OrderRefunded}, query.Statuses)
+	assert.Equal(t, "alice", query.Cashier)
+	assert.Equal(t, models.Money(1000), *query.MinTotal)
+	assert.Nil(t, query.MaxTotal)
+	assert.Equal(t, "8412345678905", query.Barcode)
+	assert.Equal(t, "total DESC, id DESC", query.Sort)
+}
+
+func TestParseOrderQueryDefaults(t *testing.T) {
+	query, err := parseOrderQuery(queryContext(""))
+
+	assert.NoError(t, err)
+	assert.Nil(t, query.From)
+	assert.Empty(t, query.Statuses)
+	assert.Equal(t, "created_at DESC, id DESC", query.Sort)
+}
+
+func TestParseOrderQueryInvalid(t *testing.T) {
+	for _, query := range []string{
+		"from=yesterday",
+		"status=shipped",
+		"sort=customer_name",
+		"min_total=12.50",
+		"customer_id=-1",
+	} {
+		_, err := parseOrderQuery(queryContext(query))
+		assert.ErrorIs(t, err, errInvalidOrderQuery, query)
+	}
+}
+
+func TestFindOrdersInvalidQuery(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDB := database.NewMockDatabase(ctrl)
+	ctx := context.Background()
+	repo := NewOrderRepository(mockDB, &ctx)
+
+	// Set up Gin
+	gin.SetMode(gin.TestMode)
+	r := gin.Default()
+	r.GET("/orders", repo.FindOrders)
+
+	mockDB.EXPECT().Model(gomock.Any()).Times(0)
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/orders?status=shipped", nil)
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "unknown status")
+}
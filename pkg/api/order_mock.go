@@ -40,6 +40,42 @@ func (m *MockOrderRepository) EXPECT() *MockOrderRepositoryMockRecorder {
 	return m.recorder
 }
 
+// AddOrderLine mocks base method.
+func (m *MockOrderRepository) AddOrderLine(c *gin.Context) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "AddOrderLine", c)
+}
+
+// AddOrderLine indicates an expected call of AddOrderLine.
+func (mr *MockOrderRepositoryMockRecorder) AddOrderLine(c any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddOrderLine", reflect.TypeOf((*MockOrderRepository)(nil).AddOrderLine), c)
+}
+
+// AddPayment mocks base method.
+func (m *MockOrderRepository) AddPayment(c *gin.Context) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "AddPayment", c)
+}
+
+// AddPayment indicates an expected call of AddPayment.
+func (mr *MockOrderRepositoryMockRecorder) AddPayment(c any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddPayment", reflect.TypeOf((*MockOrderRepository)(nil).AddPayment), c)
+}
+
+// ChangeOrderLine mocks base method.
+func (m *MockOrderRepository) ChangeOrderLine(c *gin.Context) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "ChangeOrderLine", c)
+}
+
+// ChangeOrderLine indicates an expected call of ChangeOrderLine.
+func (mr *MockOrderRepositoryMockRecorder) ChangeOrderLine(c any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ChangeOrderLine", reflect.TypeOf((*MockOrderRepository)(nil).ChangeOrderLine), c)
+}
+
 // CreateOrder mocks base method.
 func (m *MockOrderRepository) CreateOrder(c *gin.Context) {
 	m.ctrl.T.Helper()
@@ -64,6 +100,18 @@ func (mr *MockOrderRepositoryMockRecorder) DeleteOrder(c any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteOrder", reflect.TypeOf((*MockOrderRepository)(nil).DeleteOrder), c)
 }
 
+// DeletePayment mocks base method.
+func (m *MockOrderRepository) DeletePayment(c *gin.Context) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "DeletePayment", c)
+}
+
+// DeletePayment indicates an expected call of DeletePayment.
+func (mr *MockOrderRepositoryMockRecorder) DeletePayment(c any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeletePayment", reflect.TypeOf((*MockOrderRepository)(nil).DeletePayment), c)
+}
+
 // FindOrder mocks base method.
 func (m *MockOrderRepository) FindOrder(c *gin.Context) {
 	m.ctrl.T.Helper()
@@ -76,6 +124,102 @@ func (mr *MockOrderRepositoryMockRecorder) FindOrder(c any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindOrder", reflect.TypeOf((*MockOrderRepository)(nil).FindOrder), c)
 }
 
+// FindOrders mocks base method.
+func (m *MockOrderRepository) FindOrders(c *gin.Context) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "FindOrders", c)
+}
+
+// FindOrders indicates an expected call of FindOrders.
+func (mr *MockOrderRepositoryMockRecorder) FindOrders(c any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindOrders", reflect.TypeOf((*MockOrderRepository)(nil).FindOrders), c)
+}
+
+// FindPayments mocks base method.
+func (m *MockOrderRepository) FindPayments(c *gin.Context) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "FindPayments", c)
+}
+
+// FindPayments indicates an expected call of FindPayments.
+func (mr *MockOrderRepositoryMockRecorder) FindPayments(c any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindPayments", reflect.TypeOf((*MockOrderRepository)(nil).FindPayments), c)
+}
+
+// OrderReceipt mocks base method.
+func (m *MockOrderRepository) OrderReceipt(c *gin.Context) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "OrderReceipt", c)
+}
+
+// OrderReceipt indicates an expected call of OrderReceipt.
+func (mr *MockOrderRepositoryMockRecorder) OrderReceipt(c any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OrderReceipt", reflect.TypeOf((*MockOrderRepository)(nil).OrderReceipt), c)
+}
+
+// ParkOrder mocks base method.
+func (m *MockOrderRepository) ParkOrder(c *gin.Context) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "ParkOrder", c)
+}
+
+// ParkOrder indicates an expected call of ParkOrder.
+func (mr *MockOrderRepositoryMockRecorder) ParkOrder(c any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ParkOrder", reflect.TypeOf((*MockOrderRepository)(nil).ParkOrder), c)
+}
+
+// PayOrder mocks base method.
+func (m *MockOrderRepository) PayOrder(c *gin.Context) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "PayOrder", c)
+}
+
+// PayOrder indicates an expected call of PayOrder.
+func (mr *MockOrderRepositoryMockRecorder) PayOrder(c any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PayOrder", reflect.TypeOf((*MockOrderRepository)(nil).PayOrder), c)
+}
+
+// RefundOrder mocks base method.
+func (m *MockOrderRepository) RefundOrder(c *gin.Context) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "RefundOrder", c)
+}
+
+// RefundOrder indicates an expected call of RefundOrder.
+func (mr *MockOrderRepositoryMockRecorder) RefundOrder(c any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RefundOrder", reflect.TypeOf((*MockOrderRepository)(nil).RefundOrder), c)
+}
+
+// RemoveOrderLine mocks base method.
+func (m *MockOrderRepository) RemoveOrderLine(c *gin.Context) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "RemoveOrderLine", c)
+}
+
+// RemoveOrderLine indicates an expected call of RemoveOrderLine.
+func (mr *MockOrderRepositoryMockRecorder) RemoveOrderLine(c any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveOrderLine", reflect.TypeOf((*MockOrderRepository)(nil).RemoveOrderLine), c)
+}
+
+// ResumeOrder mocks base method.
+func (m *MockOrderRepository) ResumeOrder(c *gin.Context) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "ResumeOrder", c)
+}
+
+// ResumeOrder indicates an expected call of ResumeOrder.
+func (mr *MockOrderRepositoryMockRecorder) ResumeOrder(c any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResumeOrder", reflect.TypeOf((*MockOrderRepository)(nil).ResumeOrder), c)
+}
+
 // UpdateOrder mocks base method.
 func (m *MockOrderRepository) UpdateOrder(c *gin.Context) {
 	m.ctrl.T.Helper()
@@ -87,3 +231,15 @@ func (mr *MockOrderRepositoryMockRecorder) UpdateOrder(c any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateOrder", reflect.TypeOf((*MockOrderRepository)(nil).UpdateOrder), c)
 }
+
+// VoidOrder mocks base method.
+func (m *MockOrderRepository) VoidOrder(c *gin.Context) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "VoidOrder", c)
+}
+
+// VoidOrder indicates an expected call of VoidOrder.
+func (mr *MockOrderRepositoryMockRecorder) VoidOrder(c any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VoidOrder", reflect.TypeOf((*MockOrderRepository)(nil).VoidOrder), c)
+}
@@ -0,0 +1,236 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"postui_api/pkg/database"
+	"postui_api/pkg/models"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+var (
+	errOrderNotFound     = errors.New("order not found")
+	errOrderNotEditable  = errors.New("order can no longer be modified")
+	errInvalidTransition = errors.New("invalid order status transition")
+	errOrderConflict     = errors.New("order was modified concurrently")
+	errOrderLocked       = errors.New("order is closed by a Z report")
+)
+
+// PayOrder godoc
+// @Summary Pay an order
+// @Description Mark an open order as paid once its payments cover the total. The order is given the next number of the invoice series of its cashout number, gift cards it sells are issued and the loyalty account captured on it earns points.
+// @Tags orders
+// @Security JwtAuth
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Order ID"
+// @Param input body models.TransitionOrder false "Transition details"
+// @Param Idempotency-Key header string false "Key under which the response is replayed to retries"
+// @Success 200 {object} models.Order "Successfully paid order"
+// @Failure 400 {string} string "Bad Request"
+// @Failure 404 {string} string "order not found"
+// @Failure 409 {string} string "Invalid status transition or payments do not cover the total"
+// @Router /orders/{id}/pay [post]
+func (r *orderRepository) PayOrder(c *gin.Context) {
+	r.changeOrderStatus(c, models.OrderPaid)
+}
+
+// VoidOrder godoc
+// @Summary Void an order
+// @Description Void an open, parked or paid order. A reason is required. Voiding a paid order appends a cancellation record to the fiscal chain and takes back the loyalty points it earned. Voiding an unpaid order returns the stock its checkout took. Gift cards and loyalty points the order was paid with are credited back and gift cards it sold are voided. Paid orders partly refunded cannot be voided. Coupons redeemed by the order can be redeemed again.
+// @Tags orders
+// @Security JwtAuth
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Order ID"
+// @Param input body models.TransitionOrder true "Transition details"
+// @Success 200 {object} models.Order "Successfully voided order"
+// @Failure 400 {string} string "Bad Request"
+// @Failure 404 {string} string "order not found"
+// @Failure 409 {string} string "Invalid status transition"
+// @Router /orders/{id}/void [post]
+func (r *orderRepository) VoidOrder(c *gin.Context) {
+	r.changeOrderStatus(c, models.OrderVoided)
+}
+
+// ParkOrder godoc
+// @Summary Park an order
+// @Description Put an open order aside so it can be resumed later
+// @Tags orders
+// @Security JwtAuth
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Order ID"
+// @Param input body models.TransitionOrder false "Transition details"
+// @Success 200 {object} models.Order "Successfully parked order"
+// @Failure 400 {string} string "Bad Request"
+// @Failure 404 {string} string "order not found"
+// @Failure 409 {string} string "Invalid status transition"
+// @Router /orders/{id}/park [post]
+func (r *orderRepository) ParkOrder(c *gin.Context) {
+	r.changeOrderStatus(c, models.OrderParked)
+}
+
+// ResumeOrder godoc
+// @Summary Resume a parked order
+// @Description Reopen a parked order
+// @Tags orders
+// @Security JwtAuth
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Order ID"
+// @Param input body models.TransitionOrder false "Transition details"
+// @Success 200 {object} models.Order "Successfully resumed order"
+// @Failure 400 {string} string "Bad Request"
+// @Failure 404 {string} string "order not found"
+// @Failure 409 {string} string "Invalid status transition"
+// @Router /orders/{id}/resume [post]
+func (r *orderRepository) ResumeOrder(c *gin.Context) {
+	r.changeOrderStatus(c, models.OrderOpen)
+}
+
+// changeOrderStatus moves the order identified by the id path parameter to
+// status to on behalf of the authenticated user
+func (r *orderRepository) changeOrderStatus(c *gin.Context, to models.OrderStatus) {
+	var input models.TransitionOrder
+
+	// The body is optional for every transition but void
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&input); err != nil && !errors.Is(err, io.EOF) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	if to == models.OrderVoided && input.Reason == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "a reason is required to void an order"})
+		return
+	}
+
+	var order models.Order
+
+	err := r.DB.Transaction(func(tx database.Database) error {
+		// Payments cannot change while the order is checked and moved
+		if err := tx.ForUpdate().Where("id = ?", c.Param("id")).First(&order).Error(); err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errOrderNotFound
+			}
+			return err
+		}
+
+		// Orders are only paid once their payments cover the total
+		if to == models.OrderPaid && order.Status.CanTransitionTo(to) {
+			if err := checkOrderPaid(tx, order); err != nil {
+				return err
+			}
+		}
+
+		from := order.Status
+		if err := transitionOrder(tx, &order, to, input.Reason, c.GetString("username")); err != nil {
+			return err
+		}
+
+		switch {
+		case to == models.OrderPaid:
+			if err := issueInvoice(tx, &order, time.Now()); err != nil {
+				return err
+			}
+			if err := issueGiftCards(tx, order); err != nil {
+				return err
+			}
+			return accrueLoyaltyPoints(tx, order)
+		case to == models.OrderVoided:
+			lines, err := findOrderLines(tx, order.ID)
+			if err != nil {
+				return err
+			}
+
+			if from == models.OrderPaid {
+				if err := checkNotRefunded(tx, order); err != nil {
+					return err
+				}
+				if err := cancelFiscalInvoice(tx, order); err != nil {
+					return err
+				}
+				if err := reverseLoyaltyPoints(tx, order, order.Total); err != nil {
+					return err
+				}
+				if err := voidGiftCards(tx, order, lines); err != nil {
+					return err
+				}
+			} else {
+				// The goods of an unpaid order never left the store
+				if err := restockLines(tx, lines); err != nil {
+					return err
+				}
+			}
+			if err := reverseTenders(tx, order); err != nil {
+				return err
+			}
+			return reverseCouponRedemptions(tx, order.ID)
+		}
+		return nil
+	})
+	if err != nil {
+		respondOrderStatusError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": order})
+}
+
+// transitionOrder moves order to status to and records who did it and why.
+// The update only applies if the status was not changed concurrently.
+func transitionOrder(tx database.Database, order *models.Order, to models.OrderStatus, reason, username string) error {
+	from := order.Status
+
+	if !from.CanTransitionTo(to) {
+		return fmt.Errorf("%w: %s to %s", errInvalidTransition, from, to)
+	}
+	// Refunds are new orders, so marking a closed order refunded changes no closed total
+	if order.ZReportID != nil && to != models.OrderRefunded {
+		return errOrderLocked
+	}
+
+	result := tx.Model(&models.Order{}).Where("id = ? AND status = ?", order.ID, from).Update("status", to)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errOrderConflict
+	}
+	order.Status = to
+
+	return tx.Create(&models.OrderTransition{OrderID: order.ID, From: from, To: to, Reason: reason, Username: username}).Error
+}
+
+// respondOrderStatusError maps an error returned while changing an order to a response
+func respondOrderStatusError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, errOrderNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, errOrderNotEditable), errors.Is(err, errInvalidTransition), errors.Is(err, errOrderConflict), errors.Is(err, errOrderNotPaid), errors.Is(err, errOrderLocked):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+	}
+}
+
+// issueInvoice numbers an order paid at paidAt and appends its fiscal record
+func issueInvoice(tx database.Database, order *models.Order, paidAt time.Time) error {
+	if err := assignInvoice(tx, order, paidAt); err != nil {
+		return err
+	}
+
+	lines, err := findOrderLines(tx, order.ID)
+	if err != nil {
+		return err
+	}
+
+	return registerFiscalInvoice(tx, *order, lines)
+}
@@ -75,19 +75,46 @@ func TestCreateOrder(t *testing.T) {
 	lines_id = append(lines_id, int64(line1.ID))
 	lines_id = append(lines_id, int64(line2.ID))
 
-	inputOrders := models.CreateOrder{Vendor: "username", Total: 1000, LinesID: lines_id, CashoutNumber: 1}
+	inputOrders := models.CreateOrder{CustomerName: "username", Total: 242, LinesID: lines_id, CashoutNumber: 1}
 
 	requestBody, err := json.Marshal(inputOrders)
 	if err != nil {
 		t.Fatalf("Failed to marshal input order data: %v", err)
 	}
 
+	// A register session is open for the cashout number
+	mockDB.EXPECT().Where("cashout_number = ? AND status = ?", uint(1), models.SessionOpen).Return(mockDB).Times(1)
+	mockDB.EXPECT().First(gomock.Any()).Return(mockDB).Times(1)
+	mockDB.EXPECT().Error().Return(nil).Times(1)
+
+	// Set up database mock to return the referenced order lines
+	mockDB.EXPECT().Where("id IN ?", []int64(lines_id)).Return(mockDB).Times(1)
+	mockDB.EXPECT().Find(gomock.Any()).DoAndReturn(func(dest interface{}, conds ...interface{}) *gorm.DB {
+		if lines, ok := dest.(*[]models.OrderLine); ok {
+			*lines = []models.OrderLine{line1, line2}
+		}
+		return &gorm.DB{Error: nil}
+	}).Times(5)
+
 	// Set up database mock to simulate successful order creation
+	mockDB.EXPECT().
+		Transaction(gomock.Any()).
+		DoAndReturn(func(fc func(database.Database) error) error {
+			return fc(mockDB)
+		}).Times(1)
 	mockDB.EXPECT().Create(gomock.Any()).DoAndReturn(func(order *models.Order) *gorm.DB {
-		// Normally, you might simulate setting an ID or other fields modified by the DB
+		order.ID = 7
 		return &gorm.DB{Error: nil}
 	})
 
+	// Both lines are still free and get attached to the new order
+	mockDB.EXPECT().Where("id IN ? AND (order_id IS NULL OR order_id = ?)", []uint{1, 2}, uint(7)).Return(mockDB).Times(1)
+	mockDB.EXPECT().Updates(gomock.Any()).Return(&gorm.DB{Error: nil, RowsAffected: 2}).Times(1)
+
+	// No promotion is current, so the amounts the order was created with are kept
+	expectNoOrderPromotions(mockDB, uint(7), []uint{1})
+	mockDB.EXPECT().Model(gomock.Any()).Times(0)
+
 	w := httptest.NewRecorder()
 	req, err := http.NewRequest("POST", "/orders", bytes.NewBuffer(requestBody))
 	if err != nil {
@@ -101,6 +128,129 @@ func TestCreateOrder(t *testing.T) {
 	// Assertions to check the response
 	assert.Equal(t, http.StatusCreated, w.Code, "Expected HTTP status code 201")
 	assert.Contains(t, w.Body.String(), "username", "Response body should contain the order Vat")
+	assert.Contains(t, w.Body.String(), `"taxes":[{"vat":2100,"base":200,"tax":42,"total":242}]`, "Response body should contain the VAT breakdown")
+}
+
+func TestCreateOrderFormerCustomerKey(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDB := database.NewMockDatabase(ctrl)
+	ctx := context.Background()
+	repo := NewOrderRepository(mockDB, &ctx)
+
+	// Set up Gin
+	gin.SetMode(gin.TestMode)
+	r := gin.Default()
+	r.POST("/orders", func(c *gin.Context) {
+		c.Set("appCtxOrder", repo)
+		repo.CreateOrder(c)
+	})
+
+	line := models.OrderLine{ID: 1, ProductID: 1, Quantity: decimal.NewFromInt(1), Price: 100, Vat: 2100, Total: 121}
+
+	// Clients not updated yet still send the name under "customer"
+	requestBody := []byte(`{"customer": "username", "lines_id": [1], "cashout_number": 1}`)
+
+	mockDB.EXPECT().Where("cashout_number = ? AND status = ?", uint(1), models.SessionOpen).Return(mockDB).Times(1)
+	mockDB.EXPECT().First(gomock.Any()).Return(mockDB).Times(1)
+	mockDB.EXPECT().Error().Return(nil).Times(1)
+
+	mockDB.EXPECT().Where("id IN ?", []int64{1}).Return(mockDB).Times(1)
+	mockDB.EXPECT().Find(gomock.Any()).DoAndReturn(func(dest interface{}, conds ...interface{}) *gorm.DB {
+		if lines, ok := dest.(*[]models.OrderLine); ok {
+			*lines = []models.OrderLine{line}
+		}
+		return &gorm.DB{Error: nil}
+	}).Times(5)
+
+	mockDB.EXPECT().
+		Transaction(gomock.Any()).
+		DoAndReturn(func(fc func(database.Database) error) error {
+			return fc(mockDB)
+		}).Times(1)
+	mockDB.EXPECT().Create(gomock.Any()).DoAndReturn(func(order *models.Order) *gorm.DB {
+		assert.Equal(t, "username", order.CustomerName)
+		order.ID = 7
+		return &gorm.DB{Error: nil}
+	})
+	mockDB.EXPECT().Where("id IN ? AND (order_id IS NULL OR order_id = ?)", []uint{1}, uint(7)).Return(mockDB).Times(1)
+	mockDB.EXPECT().Updates(gomock.Any()).Return(&gorm.DB{Error: nil, RowsAffected: 1}).Times(1)
+
+	expectNoOrderPromotions(mockDB, uint(7), []uint{1})
+	mockDB.EXPECT().Model(gomock.Any()).Times(0)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/orders", bytes.NewBuffer(requestBody))
+	req.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Contains(t, w.Body.String(), `"customer_name":"username"`)
+}
+
+func TestCreateOrderTotalMismatch(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDB := database.NewMockDatabase(ctrl)
+	ctx := context.Background()
+
+	repo := NewOrderRepository(mockDB, &ctx)
+
+	// Set up Gin
+	gin.SetMode(gin.TestMode)
+	r := gin.Default()
+	r.POST("/orders", func(c *gin.Context) {
+		// Set the appCtx in the Gin context
+		c.Set("appCtxOrder", repo)
+		repo.CreateOrder(c)
+	})
+
+	line := models.OrderLine{ID: 1, ProductID: 1, Quantity: decimal.NewFromInt(1), Price: 121, Vat: 2100, Total: 121}
+
+	// The client claims a lower total than the lines add up to
+	inputOrders := models.CreateOrder{CustomerName: "username", Total: 100, LinesID: pq.Int64Array{1}, CashoutNumber: 1}
+
+	requestBody, err := json.Marshal(inputOrders)
+	if err != nil {
+		t.Fatalf("Failed to marshal input order data: %v", err)
+	}
+
+	// A register session is open for the cashout number
+	mockDB.EXPECT().Where("cashout_number = ? AND status = ?", uint(1), models.SessionOpen).Return(mockDB).Times(1)
+	mockDB.EXPECT().First(gomock.Any()).Return(mockDB).Times(1)
+	mockDB.EXPECT().Error().Return(nil).Times(1)
+
+	mockDB.EXPECT().Where("id IN ?", []int64{1}).Return(mockDB).Times(1)
+	mockDB.EXPECT().Find(gomock.Any()).DoAndReturn(func(dest interface{}, conds ...interface{}) *gorm.DB {
+		if lines, ok := dest.(*[]models.OrderLine); ok {
+			*lines = []models.OrderLine{line}
+		}
+		return &gorm.DB{Error: nil}
+	}).Times(4)
+
+	// The total is checked once the promotions are applied, and the
+	// transaction is rolled back
+	mockDB.EXPECT().
+		Transaction(gomock.Any()).
+		DoAndReturn(func(fc func(database.Database) error) error {
+			return fc(mockDB)
+		}).Times(1)
+	mockDB.EXPECT().Create(gomock.Any()).DoAndReturn(func(order *models.Order) *gorm.DB {
+		order.ID = 7
+		return &gorm.DB{Error: nil}
+	}).Times(1)
+	mockDB.EXPECT().Where("id IN ? AND (order_id IS NULL OR order_id = ?)", []uint{1}, uint(7)).Return(mockDB).Times(1)
+	mockDB.EXPECT().Updates(gomock.Any()).Return(&gorm.DB{Error: nil, RowsAffected: 1}).Times(1)
+	expectNoOrderPromotions(mockDB, uint(7), []uint{1})
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/orders", bytes.NewBuffer(requestBody))
+	req.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
 }
 
 func TestFindOrder(t *testing.T) {
@@ -136,15 +286,11 @@ func TestFindOrder(t *testing.T) {
 		Vat:       2100,
 		Total:     121,
 	}
-	var lines_id pq.Int64Array
-	lines_id = append(lines_id, int64(line1.ID))
-	lines_id = append(lines_id, int64(line2.ID))
 
 	expectedOrder := models.Order{
 		ID:            1,
-		Vendor:        "username",
+		CustomerName:  "username",
 		Total:         1000,
-		LinesID:       lines_id,
 		CashoutNumber: 1,
 	}
 
@@ -174,6 +320,24 @@ func TestFindOrder(t *testing.T) {
 		Return(nil).
 		Times(1)
 
+	// Mock the order lines lookup, which come back in any order
+	mockDB.EXPECT().Where("order_id = ?", uint(1)).Return(mockDB).Times(1)
+	mockDB.EXPECT().Find(gomock.Any()).DoAndReturn(func(dest interface{}, conds ...interface{}) *gorm.DB {
+		if lines, ok := dest.(*[]models.OrderLine); ok {
+			*lines = []models.OrderLine{line2, line1}
+		}
+		return &gorm.DB{Error: nil}
+	}).Times(1)
+
+	// Mock the discounts lookup
+	mockDB.EXPECT().Where("order_id = ?", uint(1)).Return(mockDB).Times(1)
+	mockDB.EXPECT().Find(gomock.Any()).DoAndReturn(func(dest interface{}, conds ...interface{}) *gorm.DB {
+		if discounts, ok := dest.(*[]models.OrderDiscount); ok {
+			*discounts = []models.OrderDiscount{{ID: 1, OrderID: 1, PromotionID: 2, Name: "Happy hour", Amount: 42}}
+		}
+		return &gorm.DB{Error: nil}
+	}).Times(1)
+
 	// Perform the request
 	w := httptest.NewRecorder()
 	req := httptest.NewRequest(http.MethodGet, "/order/1", nil)
@@ -183,17 +347,20 @@ func TestFindOrder(t *testing.T) {
 	assert.Equal(t, http.StatusOK, w.Code)
 
 	var response struct {
-		Status  int          `json:"status"`
-		Message string       `json:"message"`
-		Data    models.Order `json:"data"`
+		Status  int                `json:"status"`
+		Message string             `json:"message"`
+		Data    models.OrderDetail `json:"data"`
 	}
 
 	err := json.NewDecoder(w.Body).Decode(&response)
 	assert.NoError(t, err)
 	assert.Equal(t, expectedOrder.ID, response.Data.ID)
-	assert.Equal(t, expectedOrder.Vendor, response.Data.Vendor)
+	assert.Equal(t, expectedOrder.CustomerName, response.Data.CustomerName)
 	assert.Equal(t, expectedOrder.Total, response.Data.Total)
-	assert.Equal(t, expectedOrder.LinesID, response.Data.LinesID)
+	if assert.Len(t, response.Data.Lines, 2) {
+		assert.Equal(t, line1.ID, response.Data.Lines[0].ID)
+		assert.Equal(t, line2.ID, response.Data.Lines[1].ID)
+	}
 	assert.Equal(t, expectedOrder.CashoutNumber, response.Data.CashoutNumber)
 }
 
@@ -231,18 +398,24 @@ func TestDeleteOrder(t *testing.T) {
 		Vat:       2100,
 		Total:     121,
 	}
-	var lines_id pq.Int64Array
-	lines_id = append(lines_id, int64(line1.ID))
-	lines_id = append(lines_id, int64(line2.ID))
 
 	existingOrder := models.Order{
 		ID:            1,
-		Vendor:        "username",
+		CustomerName:  "username",
 		Total:         1000,
-		LinesID:       lines_id,
+		Lines:         []models.OrderLine{line1, line2},
 		CashoutNumber: 1,
+		Status:        models.OrderOpen,
 	}
 
+	mockDB.EXPECT().
+		Transaction(gomock.Any()).
+		DoAndReturn(func(fc func(database.Database) error) error {
+			return fc(mockDB)
+		}).Times(1)
+
+	mockDB.EXPECT().ForUpdate().Return(mockDB).Times(1)
+
 	// Mock Where to return the existingOrder for chaining
 	mockDB.EXPECT().
 		Where("id = ?", "1").
@@ -258,10 +431,25 @@ func TestDeleteOrder(t *testing.T) {
 			return mockDB
 		}).Times(1)
 
-	// Mock Delete method
+	// The lines were attached, not checked out, so no stock is returned
+	mockDB.EXPECT().
+		Where("order_id = ?", uint(1)).
+		Return(mockDB).Times(1)
+	mockDB.EXPECT().
+		Find(gomock.Any()).
+		DoAndReturn(func(dest interface{}, conds ...interface{}) *gorm.DB {
+			*dest.(*[]models.OrderLine) = []models.OrderLine{line1, line2}
+			return &gorm.DB{Error: nil}
+		}).Times(1)
+	mockDB.EXPECT().Model(gomock.Any()).Times(0)
+
+	// Mock Delete method, which only applies while the order is still open
 	mockDB.EXPECT().
-		Delete(&existingOrder).
-		Return(&gorm.DB{Error: nil}).Times(1)
+		Where("status = ?", models.OrderOpen).
+		Return(mockDB).Times(1)
+	mockDB.EXPECT().
+		Delete(gomock.AssignableToTypeOf(&models.Order{})).
+		Return(&gorm.DB{Error: nil, RowsAffected: 1}).Times(1)
 
 	// Mock Error method to return nil
 	mockDB.EXPECT().Error().Return(nil).AnyTimes()
@@ -274,3 +462,174 @@ func TestDeleteOrder(t *testing.T) {
 	// Assert the response
 	assert.Equal(t, http.StatusNoContent, w.Code)
 }
+
+func TestDeleteOrderRefusedWhenPaid(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDB := database.NewMockDatabase(ctrl)
+	ctx := context.Background()
+	repo := NewOrderRepository(mockDB, &ctx)
+
+	// Set up Gin for testing
+	gin.SetMode(gin.TestMode)
+	r := gin.Default()
+	r.DELETE("/order/:id", repo.DeleteOrder)
+
+	paidOrder := models.Order{ID: 1, CustomerName: "username", Total: 1000, CashoutNumber: 1, Status: models.OrderPaid}
+
+	mockDB.EXPECT().
+		Transaction(gomock.Any()).
+		DoAndReturn(func(fc func(database.Database) error) error {
+			return fc(mockDB)
+		}).Times(1)
+	mockDB.EXPECT().ForUpdate().Return(mockDB).Times(1)
+	mockDB.EXPECT().Where("id = ?", "1").Return(mockDB).Times(1)
+	mockDB.EXPECT().
+		First(gomock.Any()).
+		DoAndReturn(func(dest interface{}, conds ...interface{}) database.Database {
+			if b, ok := dest.(*models.Order); ok {
+				*b = paidOrder
+			}
+			return mockDB
+		}).Times(1)
+	mockDB.EXPECT().Error().Return(nil).Times(1)
+
+	// Paid sales must be retained
+	mockDB.EXPECT().Delete(gomock.Any()).Times(0)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodDelete, "/order/1", nil)
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+}
+
+func TestVoidOrderRequiresReason(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDB := database.NewMockDatabase(ctrl)
+	ctx := context.Background()
+	repo := NewOrderRepository(mockDB, &ctx)
+
+	// Set up Gin for testing
+	gin.SetMode(gin.TestMode)
+	r := gin.Default()
+	r.POST("/order/:id/void", repo.VoidOrder)
+
+	// No transaction must be started without a reason
+	mockDB.EXPECT().Transaction(gomock.Any()).Times(0)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPost, "/order/1/void", nil)
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestParkOrderInvalidTransition(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDB := database.NewMockDatabase(ctrl)
+	ctx := context.Background()
+	repo := NewOrderRepository(mockDB, &ctx)
+
+	// Set up Gin for testing
+	gin.SetMode(gin.TestMode)
+	r := gin.Default()
+	r.POST("/order/:id/park", repo.ParkOrder)
+
+	paidOrder := models.Order{ID: 1, CustomerName: "username", Total: 1000, CashoutNumber: 1, Status: models.OrderPaid}
+
+	// Run the transaction body against the same mock
+	mockDB.EXPECT().
+		Transaction(gomock.Any()).
+		DoAndReturn(func(fc func(database.Database) error) error {
+			return fc(mockDB)
+		}).Times(1)
+	mockDB.EXPECT().ForUpdate().Return(mockDB).Times(1)
+	mockDB.EXPECT().Where("id = ?", "1").Return(mockDB).Times(1)
+	mockDB.EXPECT().
+		First(gomock.Any()).
+		DoAndReturn(func(dest interface{}, conds ...interface{}) database.Database {
+			if b, ok := dest.(*models.Order); ok {
+				*b = paidOrder
+			}
+			return mockDB
+		}).Times(1)
+	mockDB.EXPECT().Error().Return(nil).Times(1)
+
+	// The status must not be touched
+	mockDB.EXPECT().Model(gomock.Any()).Times(0)
+	mockDB.EXPECT().Create(gomock.Any()).Times(0)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPost, "/order/1/park", nil)
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.Contains(t, w.Body.String(), "invalid order status transition")
+}
+
+func TestUpdateOrderPaidMeanwhile(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDB := database.NewMockDatabase(ctrl)
+	ctx := context.Background()
+	repo := NewOrderRepository(mockDB, &ctx)
+
+	// Set up Gin for testing
+	gin.SetMode(gin.TestMode)
+	r := gin.Default()
+	r.PUT("/order/:id", repo.UpdateOrder)
+
+	requestBody, err := json.Marshal(models.UpdateOrder{CustomerName: "other"})
+	if err != nil {
+		t.Fatalf("Failed to marshal order data: %v", err)
+	}
+
+	// The order is open when read, but paid by the time it is locked
+	statuses := []models.OrderStatus{models.OrderOpen, models.OrderPaid}
+	mockDB.EXPECT().Where("id = ?", "1").Return(mockDB).Times(2)
+	mockDB.EXPECT().
+		First(gomock.Any()).
+		DoAndReturn(func(dest interface{}, conds ...interface{}) database.Database {
+			*dest.(*models.Order) = models.Order{ID: 1, CustomerName: "username", Total: 1000, CashoutNumber: 1, Status: statuses[0]}
+			statuses = statuses[1:]
+			return mockDB
+		}).Times(2)
+	mockDB.EXPECT().Error().Return(nil).Times(2)
+
+	mockDB.EXPECT().
+		Transaction(gomock.Any()).
+		DoAndReturn(func(fc func(database.Database) error) error {
+			return fc(mockDB)
+		}).Times(1)
+	mockDB.EXPECT().ForUpdate().Return(mockDB).Times(1)
+
+	// The paid order must be left as is
+	mockDB.EXPECT().Model(gomock.Any()).Times(0)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPut, "/order/1", bytes.NewBuffer(requestBody))
+	req.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.Contains(t, w.Body.String(), "order is paid")
+}
+
+// expectNoOrderPromotions expects the promotions of the order with id
+// orderID, made of lines of products productIDs, to be applied while none
+// is current
+func expectNoOrderPromotions(mockDB *database.MockDatabase, orderID uint, productIDs []uint) {
+	mockDB.EXPECT().Unscoped().Return(mockDB).Times(1)
+	mockDB.EXPECT().Where("id IN ?", productIDs).Return(mockDB).Times(1)
+	mockDB.EXPECT().Where("active = ? AND coupon_only = ? AND (starts_at IS NULL OR starts_at <= ?) AND (ends_at IS NULL OR ends_at > ?)", true, false, gomock.Any(), gomock.Any()).Return(mockDB).Times(1)
+	mockDB.EXPECT().Where("order_id = ? AND reversed_at IS NULL", orderID).Return(mockDB).Times(1)
+	mockDB.EXPECT().Where("order_id = ?", orderID).Return(mockDB).AnyTimes()
+	mockDB.EXPECT().Delete(gomock.AssignableToTypeOf(&models.OrderDiscount{})).Return(&gorm.DB{Error: nil}).Times(1)
+}
@@ -0,0 +1,276 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"postui_api/pkg/database"
+	"postui_api/pkg/loyalty"
+	"postui_api/pkg/models"
+	"postui_api/pkg/payments"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+var (
+	errPaymentNotFound = errors.New("payment not found")
+	errOrderNotPaid    = errors.New("payments do not cover the order total")
+)
+
+// AddPayment godoc
+// @Summary Add a payment to an order
+// @Description Pay part or all of an open order. Several payments can be added to split the total between tenders. Cash tendered above what is due is given back as change. Gift cards are given by their number as reference and must have enough balance left. Loyalty points are taken from the account captured on the order unless another card number or phone is given as reference.
+// @Tags orders
+// @Security JwtAuth
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Order ID"
+// @Param input body models.CreatePayment true "Payment object"
+// @Param Idempotency-Key header string false "Key under which the response is replayed to retries"
+// @Success 201 {object} models.Payment "Successfully added payment"
+// @Failure 400 {string} string "Bad Request"
+// @Failure 404 {string} string "order, gift card or loyalty account not found"
+// @Failure 409 {string} string "Order is not open or already paid, gift card expired or points cannot be redeemed"
+// @Failure 422 {string} string "Payment exceeds amount due, gift card balance or loyalty points"
+// @Router /orders/{id}/payments [post]
+func (r *orderRepository) AddPayment(c *gin.Context) {
+	var input models.CreatePayment
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if input.Tender == models.TenderGiftCard && input.Reference == "" {
+		respondPaymentError(c, errGiftCardNumberNeeded)
+		return
+	}
+
+	var payment models.Payment
+
+	err := r.DB.Transaction(func(tx database.Database) error {
+		order, err := findOpenOrder(tx, c.Param("id"))
+		if err != nil {
+			return err
+		}
+
+		var existing []models.Payment
+		if err := tx.Where("order_id = ?", order.ID).Find(&existing).Error; err != nil {
+			return err
+		}
+
+		due := payments.Due(order.Total, existing)
+
+		var settings models.StoreSettings
+		if input.Tender == models.TenderLoyalty {
+			if settings, _, err = loadStoreSettings(tx); err != nil {
+				return err
+			}
+			if settings.LoyaltyValue == 0 {
+				return errLoyaltyDisabled
+			}
+
+			if input.Reference == "" {
+				input.Reference = order.LoyaltyID
+			}
+			input.Reference = loyalty.Normalize(input.Reference)
+
+			// Points pay what they can of the order when no amount is given
+			if input.Amount == 0 {
+				account, err := findLoyaltyAccount(tx, input.Reference)
+				if err != nil {
+					return err
+				}
+				if worth := models.Money(account.Points) * settings.LoyaltyValue; worth > 0 && worth < due {
+					input.Amount = worth
+				}
+			}
+		}
+
+		// A gift card pays what it can of the order when no amount is given
+		if input.Tender == models.TenderGiftCard && input.Amount == 0 {
+			card, err := findGiftCard(tx, input.Reference)
+			if err != nil {
+				return err
+			}
+			if card.Balance > 0 && card.Balance < due {
+				input.Amount = card.Balance
+			}
+		}
+
+		payment, err = payments.Apply(due, input)
+		if err != nil {
+			return err
+		}
+		payment.OrderID = order.ID
+
+		if err := tx.Create(&payment).Error; err != nil {
+			return err
+		}
+
+		switch payment.Tender {
+		case models.TenderGiftCard:
+			return redeemGiftCard(tx, payment, order.Currency)
+		case models.TenderLoyalty:
+			return redeemLoyaltyPoints(tx, payment, settings.LoyaltyValue)
+		}
+		return nil
+	})
+	if err != nil {
+		respondPaymentError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"data": payment})
+}
+
+// FindPayments godoc
+// @Summary List the payments of an order
+// @Description Get the payments of an order, in the order they were added
+// @Tags orders
+// @Security JwtAuth
+// @Produce  json
+// @Param id path string true "Order ID"
+// @Success 200 {array} models.Payment "Successfully retrieved payments"
+// @Failure 500 {string} string "Internal Server Error"
+// @Router /orders/{id}/payments [get]
+func (r *orderRepository) FindPayments(c *gin.Context) {
+	var found []models.Payment
+
+	if err := r.DB.Where("order_id = ?", c.Param("id")).Order("id").Find(&found).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": found})
+}
+
+// DeletePayment godoc
+// @Summary Remove a payment from an order
+// @Description Remove a payment from an order that is still open. Gift card payments are credited back to the card and redeemed loyalty points to their account.
+// @Tags orders
+// @Security JwtAuth
+// @Produce  json
+// @Param id path string true "Order ID"
+// @Param payment_id path string true "Payment ID"
+// @Success 204 {string} string "Successfully deleted payment"
+// @Failure 404 {string} string "payment not found"
+// @Failure 409 {string} string "Order is not open"
+// @Router /orders/{id}/payments/{payment_id} [delete]
+func (r *orderRepository) DeletePayment(c *gin.Context) {
+	err := r.DB.Transaction(func(tx database.Database) error {
+		order, err := findOpenOrder(tx, c.Param("id"))
+		if err != nil {
+			return err
+		}
+
+		var payment models.Payment
+		if err := tx.Where("id = ? AND order_id = ?", c.Param("payment_id"), order.ID).First(&payment).Error(); err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errPaymentNotFound
+			}
+			return err
+		}
+
+		result := tx.Delete(&models.Payment{}, "id = ?", payment.ID)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errPaymentNotFound
+		}
+
+		switch payment.Tender {
+		case models.TenderGiftCard:
+			return creditGiftCard(tx, payment.Reference, payment.Amount, payment)
+		case models.TenderLoyalty:
+			return creditLoyaltyPoints(tx, payment)
+		}
+		return nil
+	})
+	if err != nil {
+		respondPaymentError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusNoContent, gin.H{"data": true})
+}
+
+// findOpenOrder returns the order with the given id if payments can still be
+// added to or removed from it. The order stays locked until the transaction
+// ends, so that its payments are changed one at a time against the balance
+// left.
+func findOpenOrder(tx database.Database, id string) (models.Order, error) {
+	var order models.Order
+
+	if err := tx.ForUpdate().Where("id = ?", id).First(&order).Error(); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return order, errOrderNotFound
+		}
+		return order, err
+	}
+
+	if order.Status != models.OrderOpen {
+		return order, fmt.Errorf("%w: order is %s", errOrderNotEditable, order.Status)
+	}
+
+	return order, nil
+}
+
+// reverseTenders gives back what the payments of order took off gift cards
+// and loyalty accounts, as the order is voided
+func reverseTenders(tx database.Database, order models.Order) error {
+	var found []models.Payment
+	if err := tx.Where("order_id = ?", order.ID).Find(&found).Error; err != nil {
+		return err
+	}
+
+	for _, payment := range found {
+		switch payment.Tender {
+		case models.TenderGiftCard:
+			if err := creditGiftCard(tx, payment.Reference, payment.Amount, payment); err != nil {
+				return err
+			}
+		case models.TenderLoyalty:
+			if err := creditLoyaltyPoints(tx, payment); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// checkOrderPaid returns errOrderNotPaid unless the payments of order cover
+// its total
+func checkOrderPaid(tx database.Database, order models.Order) error {
+	var found []models.Payment
+	if err := tx.Where("order_id = ?", order.ID).Find(&found).Error; err != nil {
+		return err
+	}
+
+	if due := payments.Due(order.Total, found); due > 0 {
+		return fmt.Errorf("%w: %s left to pay", errOrderNotPaid, due)
+	}
+	return nil
+}
+
+// respondPaymentError maps an error returned while adding or removing a
+// payment to a response
+func respondPaymentError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, errPaymentNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, payments.ErrNothingDue):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, payments.ErrOverpayment), errors.Is(err, payments.ErrInsufficientTendered):
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+	case errors.Is(err, errGiftCardNumberNeeded), errors.Is(err, errGiftCardNotFound), errors.Is(err, errGiftCardExpired), errors.Is(err, errGiftCardVoided),
+		errors.Is(err, errInsufficientBalance), errors.Is(err, errGiftCardCurrency):
+		respondGiftCardError(c, err)
+	case errors.Is(err, errLoyaltyIDNeeded), errors.Is(err, errLoyaltyAccountNotFound), errors.Is(err, errLoyaltyDisabled), errors.Is(err, errInsufficientPoints):
+		respondLoyaltyError(c, err)
+	default:
+		respondOrderStatusError(c, err)
+	}
+}
@@ -0,0 +1,179 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"postui_api/pkg/database"
+	"postui_api/pkg/models"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func TestAddPaymentRefusedWhenPaid(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDB := database.NewMockDatabase(ctrl)
+	ctx := context.Background()
+	repo := NewOrderRepository(mockDB, &ctx)
+
+	// Set up Gin for testing
+	gin.SetMode(gin.TestMode)
+	r := gin.Default()
+	r.POST("/order/:id/payments", repo.AddPayment)
+
+	paidOrder := models.Order{ID: 1, CustomerName: "username", Total: 1000, CashoutNumber: 1, Status: models.OrderPaid}
+
+	requestBody, err := json.Marshal(models.CreatePayment{Tender: models.TenderCash, Tendered: 2000})
+	if err != nil {
+		t.Fatalf("Failed to marshal payment data: %v", err)
+	}
+
+	// Run the transaction body against the same mock
+	mockDB.EXPECT().
+		Transaction(gomock.Any()).
+		DoAndReturn(func(fc func(database.Database) error) error {
+			return fc(mockDB)
+		}).Times(1)
+	mockDB.EXPECT().ForUpdate().Return(mockDB).Times(1)
+	mockDB.EXPECT().Where("id = ?", "1").Return(mockDB).Times(1)
+	mockDB.EXPECT().
+		First(gomock.Any()).
+		DoAndReturn(func(dest interface{}, conds ...interface{}) database.Database {
+			if b, ok := dest.(*models.Order); ok {
+				*b = paidOrder
+			}
+			return mockDB
+		}).Times(1)
+	mockDB.EXPECT().Error().Return(nil).Times(1)
+
+	// No payment must be recorded
+	mockDB.EXPECT().Create(gomock.Any()).Times(0)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPost, "/order/1/payments", bytes.NewBuffer(requestBody))
+	req.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.Contains(t, w.Body.String(), "order can no longer be modified")
+}
+
+func TestAddPaymentOverpayment(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDB := database.NewMockDatabase(ctrl)
+	ctx := context.Background()
+	repo := NewOrderRepository(mockDB, &ctx)
+
+	// Set up Gin for testing
+	gin.SetMode(gin.TestMode)
+	r := gin.Default()
+	r.POST("/order/:id/payments", repo.AddPayment)
+
+	openOrder := models.Order{ID: 1, CustomerName: "username", Total: 1000, CashoutNumber: 1, Status: models.OrderOpen}
+
+	requestBody, err := json.Marshal(models.CreatePayment{Tender: models.TenderCard, Amount: 800})
+	if err != nil {
+		t.Fatalf("Failed to marshal payment data: %v", err)
+	}
+
+	// Run the transaction body against the same mock
+	mockDB.EXPECT().
+		Transaction(gomock.Any()).
+		DoAndReturn(func(fc func(database.Database) error) error {
+			return fc(mockDB)
+		}).Times(1)
+	mockDB.EXPECT().ForUpdate().Return(mockDB).Times(1)
+	mockDB.EXPECT().Where("id = ?", "1").Return(mockDB).Times(1)
+	mockDB.EXPECT().
+		First(gomock.Any()).
+		DoAndReturn(func(dest interface{}, conds ...interface{}) database.Database {
+			if b, ok := dest.(*models.Order); ok {
+				*b = openOrder
+			}
+			return mockDB
+		}).Times(1)
+	mockDB.EXPECT().Error().Return(nil).Times(1)
+
+	// 500 was already paid in cash
+	mockDB.EXPECT().Where("order_id = ?", uint(1)).Return(mockDB).Times(1)
+	mockDB.EXPECT().Find(gomock.Any()).DoAndReturn(func(dest interface{}, conds ...interface{}) *gorm.DB {
+		if found, ok := dest.(*[]models.Payment); ok {
+			*found = []models.Payment{{ID: 1, OrderID: 1, Tender: models.TenderCash, Amount: 500, Tendered: 500}}
+		}
+		return &gorm.DB{Error: nil}
+	}).Times(1)
+
+	// A card cannot pay more than what is left
+	mockDB.EXPECT().Create(gomock.Any()).Times(0)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPost, "/order/1/payments", bytes.NewBuffer(requestBody))
+	req.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	assert.Contains(t, w.Body.String(), "payment exceeds amount due")
+}
+
+func TestPayOrderRequiresPayments(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDB := database.NewMockDatabase(ctrl)
+	ctx := context.Background()
+	repo := NewOrderRepository(mockDB, &ctx)
+
+	// Set up Gin for testing
+	gin.SetMode(gin.TestMode)
+	r := gin.Default()
+	r.POST("/order/:id/pay", repo.PayOrder)
+
+	openOrder := models.Order{ID: 1, CustomerName: "username", Total: 1000, CashoutNumber: 1, Status: models.OrderOpen}
+
+	// Run the transaction body against the same mock
+	mockDB.EXPECT().
+		Transaction(gomock.Any()).
+		DoAndReturn(func(fc func(database.Database) error) error {
+			return fc(mockDB)
+		}).Times(1)
+	mockDB.EXPECT().ForUpdate().Return(mockDB).Times(1)
+	mockDB.EXPECT().Where("id = ?", "1").Return(mockDB).Times(1)
+	mockDB.EXPECT().
+		First(gomock.Any()).
+		DoAndReturn(func(dest interface{}, conds ...interface{}) database.Database {
+			if b, ok := dest.(*models.Order); ok {
+				*b = openOrder
+			}
+			return mockDB
+		}).Times(1)
+	mockDB.EXPECT().Error().Return(nil).Times(1)
+
+	// Only part of the total was paid
+	mockDB.EXPECT().Where("order_id = ?", uint(1)).Return(mockDB).Times(1)
+	mockDB.EXPECT().Find(gomock.Any()).DoAndReturn(func(dest interface{}, conds ...interface{}) *gorm.DB {
+		if found, ok := dest.(*[]models.Payment); ok {
+			*found = []models.Payment{{ID: 1, OrderID: 1, Tender: models.TenderCard, Amount: 400, Tendered: 400}}
+		}
+		return &gorm.DB{Error: nil}
+	}).Times(1)
+
+	// The status must not be touched
+	mockDB.EXPECT().Model(gomock.Any()).Times(0)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPost, "/order/1/pay", nil)
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.Contains(t, w.Body.String(), "6.00 left to pay")
+}
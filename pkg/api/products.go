@@ -3,11 +3,14 @@ package api
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"postui_api/pkg/cache"
 	"postui_api/pkg/database"
 	"postui_api/pkg/models"
+	"sort"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -16,10 +19,16 @@ import (
 type ProductRepository interface {
 	Healthcheck(c *gin.Context)
 	FindProducts(c *gin.Context)
+	ProductChanges(c *gin.Context)
+	ResolveBarcode(c *gin.Context)
 	CreateProducts(c *gin.Context)
 	FindProduct(c *gin.Context)
+	FindProductByBarcode(c *gin.Context)
 	UpdateProduct(c *gin.Context)
 	DeleteProduct(c *gin.Context)
+	CreateProductVariant(c *gin.Context)
+	UpdateProductVariant(c *gin.Context)
+	DeleteProductVariant(c *gin.Context)
 }
 
 // productRepository holds shared resources like database and Redis client
@@ -55,12 +64,13 @@ func (r *productRepository) Healthcheck(c *gin.Context) {
 
 // FindProducts godoc
 // @Summary Get all products with pagination
-// @Description Get a list of all products with optional pagination
+// @Description Get a list of all products with optional pagination. With variants=true, products sold by variant come with their variants.
 // @Tags products
 // @Security JwtAuth
 // @Produce json
 // @Param offset query int false "Offset for pagination" default(0)
 // @Param limit query int false "Limit for paginaCreateProducttion" default(10)
+// @Param variants query bool false "Nest the variants of products sold by variant" default(false)
 // @Success 200 {array} models.Product "Successfully retrieved list of products"
 // @Router /products [get]
 func (r *productRepository) FindProducts(c *gin.Context) {
@@ -84,11 +94,20 @@ func (r *productRepository) FindProducts(c *gin.Context) {
 		return
 	}
 
+	withVariants, err := strconv.ParseBool(c.DefaultQuery("variants", "false"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid variants format"})
+		return
+	}
+
 	r.DB.Model(&models.Product{}).Count(&total_items)
 	total_pages := total_items / int64(limit)
 
 	// Create a cache key based on query params
 	cacheKey := "products_offset_" + offsetQuery + "_limit_" + limitQuery
+	if withVariants {
+		cacheKey += "_variants"
+	}
 	// Try fetching the data from Redis first
 	cachedProducts, err := r.RedisClient.Get(*r.Ctx, cacheKey).Result()
 	if err == nil {
@@ -116,6 +135,13 @@ func (r *productRepository) FindProducts(c *gin.Context) {
 		return
 	}
 
+	if withVariants {
+		if err := attachVariants(r.DB, products); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch products"})
+			return
+		}
+	}
+
 	// Serialize products object and store it in Redis
 	serializedProducts, err := json.Marshal(products)
 	if err != nil {
@@ -141,17 +167,102 @@ func (r *productRepository) FindProducts(c *gin.Context) {
 	})
 }
 
+// ProductChanges godoc
+// @Summary Get the products changed since a cursor
+// @Description Get the products created, updated or deleted after the cursor, oldest change first, so a terminal can keep a local catalog in sync. Deleted products are returned as tombstones with deleted_at set. Start with since=0 and send the returned cursor on the next call; while has_more is true further changes may be waiting. Products changed together share a revision and are returned in the same call, which can then hold more than limit products. Changes are returned once every change with a lower revision is committed. Products sold by variant come with their variants, and adding, updating or deleting a variant changes its product.
+// @Tags products
+// @Security JwtAuth
+// @Produce json
+// @Param since query int false "Cursor returned by the previous call" default(0)
+// @Param limit query int false "Maximum number of products" default(100)
+// @Success 200 {object} models.ProductChanges "Successfully retrieved changed products"
+// @Failure 400 {string} string "Bad Request"
+// @Router /products/changes [get]
+func (r *productRepository) ProductChanges(c *gin.Context) {
+	since, err := strconv.ParseInt(c.DefaultQuery("since", "0"), 10, 64)
+	if err != nil || since < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid since format"})
+		return
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(maxPageLimit)))
+	if err != nil || limit < 1 || limit > maxPageLimit {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("limit must be between 1 and %d", maxPageLimit)})
+		return
+	}
+
+	// Deleted products are part of the changes, which are only read below the
+	// revisions writers may still commit. One more is fetched to know whether
+	// another call is needed.
+	var products []models.Product
+	if err := r.DB.Unscoped().Where("revision > ? AND revision < product_revisions_committed()", since).Order("revision, id").Limit(limit + 1).Find(&products).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch products"})
+		return
+	}
+
+	changes := models.ProductChanges{Products: products, Cursor: since}
+	if len(products) > limit {
+		changes.Products, err = wholeRevisions(r.DB, products, limit)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch products"})
+			return
+		}
+		changes.HasMore = true
+	}
+	if err := attachVariants(r.DB, changes.Products); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch products"})
+		return
+	}
+	if len(changes.Products) > 0 {
+		changes.Cursor = changes.Products[len(changes.Products)-1].Revision
+	} else {
+		changes.Products = []models.Product{}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": changes})
+}
+
+// wholeRevisions cuts products, sorted by revision with one more than limit,
+// to limit products without splitting the products changed together, since
+// the cursor could not point within them. When they alone overflow the limit
+// they are all returned.
+func wholeRevisions(db database.Database, products []models.Product, limit int) ([]models.Product, error) {
+	next := products[limit].Revision
+	if products[limit-1].Revision != next {
+		return products[:limit], nil
+	}
+
+	kept := limit
+	for kept > 0 && products[kept-1].Revision == next {
+		kept--
+	}
+	if kept > 0 {
+		return products[:kept], nil
+	}
+
+	var changed []models.Product
+	if err := db.Unscoped().Where("revision = ?", next).Find(&changed).Error; err != nil {
+		return nil, err
+	}
+
+	sort.Slice(changed, func(i, j int) bool { return changed[i].ID < changed[j].ID })
+	return changed, nil
+}
+
 // CreateProducts godoc
 // @Summary Create new products
-// @Description Create new products with the given input data
+// @Description Create new products with the given input data. barcode_number and barcodes are the barcodes the products are scanned with: each belongs to a single product, and the check digit of EAN and UPC barcodes is validated. Products with variant_attributes are sold by variant: their variants are added with /products/{id}/variants.
 // @Tags products
 // @Security JwtAuth
 // @Accept  json
 // @Produce  json
 // @Param   input     body   []models.CreateProducts   true   "Create product object"
+// @Param Idempotency-Key header string false "Key under which the response is replayed to retries"
 // @Success 201 {object} []models.Product "Successfully created product"
 // @Failure 400 {string} string "Bad Request"
 // @Failure 401 {string} string "Unauthorized"
+// @Failure 404 {string} string "tax category not found"
+// @Failure 409 {string} string "barcode or item code belongs to another product"
 // @Router /products [post]
 func (r *productRepository) CreateProducts(c *gin.Context) {
 	appCtx, exists := c.MustGet("appCtxProduct").(*productRepository)
@@ -168,22 +279,70 @@ func (r *productRepository) CreateProducts(c *gin.Context) {
 	}
 
 	var products []models.Product
+	var barcodes []models.ProductBarcode
 	for _, input := range inputs {
-		product := models.Product{Name: input.Name, Price: input.Price, Vat: input.Vat, Stock: input.Stock, BarcodeNumber: input.BarcodeNumber}
+		if err := checkTaxCategory(appCtx.DB, input.TaxCategoryID); err != nil {
+			respondTaxCategoryError(c, err)
+			return
+		}
+
+		if strings.TrimSpace(input.BarcodeNumber) == "" {
+			respondBarcodeError(c, fmt.Errorf("%w: barcode_number is blank", errInvalidBarcode))
+			return
+		}
+		rows, err := productBarcodes(input.BarcodeNumber, input.Barcodes)
+		if err != nil {
+			respondBarcodeError(c, err)
+			return
+		}
+		for _, row := range rows {
+			for _, other := range barcodes {
+				if other.Code == row.Code {
+					respondBarcodeError(c, fmt.Errorf("%w: %s is listed twice", errInvalidBarcode, row.Code))
+					return
+				}
+			}
+		}
+		barcodes = append(barcodes, rows...)
+
+		attributes, err := variantAttributes(input.VariantAttributes)
+		if err != nil {
+			respondVariantError(c, err)
+			return
+		}
+
+		currency := input.Currency
+		if currency == "" {
+			currency = models.DefaultCurrency
+		}
+		product := models.Product{Name: input.Name, Price: input.Price, Currency: currency, Vat: input.Vat, TaxCategoryID: input.TaxCategoryID, Stock: input.Stock, BarcodeNumber: rows[0].Code, Barcodes: rows, ItemCode: itemCode(input.ItemCode), Category: input.Category, GiftCard: input.GiftCard, VariantAttributes: attributes}
+		for _, other := range products {
+			if product.ItemCode != "" && other.ItemCode == product.ItemCode {
+				respondBarcodeError(c, fmt.Errorf("%w: item code %s is listed twice", errInvalidBarcode, product.ItemCode))
+				return
+			}
+		}
+		if err := checkItemCodeFree(appCtx.DB, 0, product.ItemCode); err != nil {
+			respondBarcodeError(c, err)
+			return
+		}
 		products = append(products, product)
 	}
 
-	appCtx.DB.Create(&products)
+	if err := checkBarcodesFree(appCtx.DB, 0, barcodes); err != nil {
+		respondBarcodeError(c, err)
+		return
+	}
 
-	// Invalidate cache
-	keysPattern := "products_offset_*"
-	keys, err := appCtx.RedisClient.Keys(*appCtx.Ctx, keysPattern).Result()
-	if err == nil {
-		for _, key := range keys {
-			appCtx.RedisClient.Del(*appCtx.Ctx, key)
-		}
+	// The barcodes are created along with the products
+	if err := appCtx.DB.Create(&products).Error; err != nil {
+		respondBarcodeError(c, err)
+		return
 	}
 
+	// Invalidate cache
+	appCtx.invalidateProductPages()
+
 	c.JSON(http.StatusCreated, gin.H{"data": products})
 }
 
@@ -210,7 +369,7 @@ func (r *productRepository) FindProduct(c *gin.Context) {
 
 // UpdateProduct godoc
 // @Summary Update a product by ID
-// @Description Update the product details for the given ID
+// @Description Update the product details for the given ID. barcodes, when set, replace the barcodes of the product other than barcode_number.
 // @Tags products
 // @Security JwtAuth
 // @Accept  json
@@ -219,7 +378,8 @@ func (r *productRepository) FindProduct(c *gin.Context) {
 // @Param input body models.UpdateProduct true "Update product object"
 // @Success 200 {object} models.Product "Successfully updated product"
 // @Failure 400 {string} string "Bad Request"
-// @Failure 404 {string} string "product not found"
+// @Failure 404 {string} string "product or tax category not found"
+// @Failure 409 {string} string "barcode or item code belongs to another product"
 // @Router /products/{id} [put]
 func (r *productRepository) UpdateProduct(c *gin.Context) {
 	var product models.Product
@@ -235,14 +395,78 @@ func (r *productRepository) UpdateProduct(c *gin.Context) {
 		return
 	}
 
-	r.DB.Model(&product).Updates(models.Product{Name: input.Name, Price: input.Price, Vat: input.Vat, Stock: input.Stock, BarcodeNumber: input.BarcodeNumber})
+	if err := checkTaxCategory(r.DB, input.TaxCategoryID); err != nil {
+		respondTaxCategoryError(c, err)
+		return
+	}
+
+	// The barcodes of variants are left to them
+	var current []models.ProductBarcode
+	if err := r.DB.Where("product_id = ? AND variant_id IS NULL", product.ID).Find(&current).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	// The barcodes are replaced when barcode_number changes or barcodes are set
+	barcodes := current
+	input.BarcodeNumber = strings.TrimSpace(input.BarcodeNumber)
+	replaceBarcodes := input.Barcodes != nil || (input.BarcodeNumber != "" && input.BarcodeNumber != product.BarcodeNumber)
+	if replaceBarcodes {
+		main := product.BarcodeNumber
+		if input.BarcodeNumber != "" {
+			main = input.BarcodeNumber
+		}
 
+		others := input.Barcodes
+		if others == nil {
+			for _, row := range current {
+				if row.Code != product.BarcodeNumber {
+					others = append(others, models.CreateProductBarcode{Code: row.Code, Quantity: row.Quantity})
+				}
+			}
+		}
+
+		var err error
+		if barcodes, err = productBarcodes(main, others); err != nil {
+			respondBarcodeError(c, err)
+			return
+		}
+		if err := checkBarcodesFree(r.DB, product.ID, barcodes); err != nil {
+			respondBarcodeError(c, err)
+			return
+		}
+	}
+
+	if err := checkItemCodeFree(r.DB, product.ID, itemCode(input.ItemCode)); err != nil {
+		respondBarcodeError(c, err)
+		return
+	}
+
+	err := r.DB.Transaction(func(tx database.Database) error {
+		if err := tx.Model(&product).Updates(models.Product{Name: input.Name, Price: input.Price, Currency: input.Currency, Vat: input.Vat, TaxCategoryID: input.TaxCategoryID, Stock: input.Stock, BarcodeNumber: input.BarcodeNumber, ItemCode: itemCode(input.ItemCode), Category: input.Category, GiftCard: input.GiftCard}).Error; err != nil {
+			return err
+		}
+		if replaceBarcodes {
+			return replaceProductBarcodes(tx, product.ID, barcodes)
+		}
+		return nil
+	})
+	if err != nil {
+		respondBarcodeError(c, err)
+		return
+	}
+
+	// Cached pages and lookups hold the product as it was
+	r.invalidateProductPages()
+	invalidateBarcodes(r.RedisClient, *r.Ctx, current, barcodes)
+
+	product.Barcodes = barcodes
 	c.JSON(http.StatusOK, gin.H{"data": product})
 }
 
 // DeleteProduct godoc
 // @Summary Delete a product by ID
-// @Description Delete the product with the given ID
+// @Description Delete the product with the given ID. The product is kept as a tombstone so that terminals learn about the deletion from /products/changes, and past orders still show it. Its barcodes are freed for other products.
 // @Tags products
 // @Security JwtAuth
 // @Produce json
@@ -258,7 +482,30 @@ func (r *productRepository) DeleteProduct(c *gin.Context) {
 		return
 	}
 
-	r.DB.Delete(&product)
+	var barcodes []models.ProductBarcode
+	if err := r.DB.Where("product_id = ?", product.ID).Find(&barcodes).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	// The barcodes of the tombstone and its variants are freed for other
+	// products
+	err := r.DB.Transaction(func(tx database.Database) error {
+		if err := tx.Where("product_id = ?", product.ID).Delete(&models.ProductBarcode{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("product_id = ?", product.ID).Delete(&models.ProductVariant{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&product).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	r.invalidateProductPages()
+	invalidateBarcodes(r.RedisClient, *r.Ctx, barcodes)
 
 	c.JSON(http.StatusNoContent, gin.H{"data": true})
 }
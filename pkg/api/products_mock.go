@@ -46,6 +46,18 @@ func (mr *MockProductRepositoryMockRecorder) CreateProducts(c interface{}) *gomo
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateProducts", reflect.TypeOf((*MockProductRepository)(nil).CreateProducts), c)
 }
 
+// CreateProductVariant mocks base method.
+func (m *MockProductRepository) CreateProductVariant(c *gin.Context) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "CreateProductVariant", c)
+}
+
+// CreateProductVariant indicates an expected call of CreateProductVariant.
+func (mr *MockProductRepositoryMockRecorder) CreateProductVariant(c interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateProductVariant", reflect.TypeOf((*MockProductRepository)(nil).CreateProductVariant), c)
+}
+
 // DeleteProduct mocks base method.
 func (m *MockProductRepository) DeleteProduct(c *gin.Context) {
 	m.ctrl.T.Helper()
@@ -58,6 +70,18 @@ func (mr *MockProductRepositoryMockRecorder) DeleteProduct(c interface{}) *gomoc
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteProduct", reflect.TypeOf((*MockProductRepository)(nil).DeleteProduct), c)
 }
 
+// DeleteProductVariant mocks base method.
+func (m *MockProductRepository) DeleteProductVariant(c *gin.Context) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "DeleteProductVariant", c)
+}
+
+// DeleteProductVariant indicates an expected call of DeleteProductVariant.
+func (mr *MockProductRepositoryMockRecorder) DeleteProductVariant(c interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteProductVariant", reflect.TypeOf((*MockProductRepository)(nil).DeleteProductVariant), c)
+}
+
 // FindProduct mocks base method.
 func (m *MockProductRepository) FindProduct(c *gin.Context) {
 	m.ctrl.T.Helper()
@@ -94,6 +118,42 @@ func (mr *MockProductRepositoryMockRecorder) Healthcheck(c interface{}) *gomock.
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Healthcheck", reflect.TypeOf((*MockProductRepository)(nil).Healthcheck), c)
 }
 
+// ProductChanges mocks base method.
+func (m *MockProductRepository) ProductChanges(c *gin.Context) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "ProductChanges", c)
+}
+
+// ProductChanges indicates an expected call of ProductChanges.
+func (mr *MockProductRepositoryMockRecorder) ProductChanges(c interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ProductChanges", reflect.TypeOf((*MockProductRepository)(nil).ProductChanges), c)
+}
+
+// FindProductByBarcode mocks base method.
+func (m *MockProductRepository) FindProductByBarcode(c *gin.Context) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "FindProductByBarcode", c)
+}
+
+// FindProductByBarcode indicates an expected call of FindProductByBarcode.
+func (mr *MockProductRepositoryMockRecorder) FindProductByBarcode(c interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindProductByBarcode", reflect.TypeOf((*MockProductRepository)(nil).FindProductByBarcode), c)
+}
+
+// ResolveBarcode mocks base method.
+func (m *MockProductRepository) ResolveBarcode(c *gin.Context) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "ResolveBarcode", c)
+}
+
+// ResolveBarcode indicates an expected call of ResolveBarcode.
+func (mr *MockProductRepositoryMockRecorder) ResolveBarcode(c interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResolveBarcode", reflect.TypeOf((*MockProductRepository)(nil).ResolveBarcode), c)
+}
+
 // UpdateProduct mocks base method.
 func (m *MockProductRepository) UpdateProduct(c *gin.Context) {
 	m.ctrl.T.Helper()
@@ -105,3 +165,15 @@ func (mr *MockProductRepositoryMockRecorder) UpdateProduct(c interface{}) *gomoc
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateProduct", reflect.TypeOf((*MockProductRepository)(nil).UpdateProduct), c)
 }
+
+// UpdateProductVariant mocks base method.
+func (m *MockProductRepository) UpdateProductVariant(c *gin.Context) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "UpdateProductVariant", c)
+}
+
+// UpdateProductVariant indicates an expected call of UpdateProductVariant.
+func (mr *MockProductRepositoryMockRecorder) UpdateProductVariant(c interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateProductVariant", reflect.TypeOf((*MockProductRepository)(nil).UpdateProductVariant), c)
+}
@@ -86,7 +86,7 @@ func TestCreateProduct(t *testing.T) {
 	// Example data for the test
 	stock, _ := decimal.NewFromString("100")
 	inputProducts := []models.CreateProducts{
-		{Name: "New Product", Price: 10, Vat: 2100, Stock: stock, BarcodeNumber: "12345678"},
+		{Name: "New Product", Price: 10, Vat: 2100, Stock: stock, BarcodeNumber: "12345670"},
 		{Name: "Another Product", Price: 20, Vat: 1900, Stock: stock, BarcodeNumber: "461246179231"},
 	}
 	requestBody, err := json.Marshal(inputProducts)
@@ -94,6 +94,10 @@ func TestCreateProduct(t *testing.T) {
 		t.Fatalf("Failed to marshal input product data: %v", err)
 	}
 
+	// None of the barcodes belongs to another product
+	mockDB.EXPECT().Where("code IN ?", []string{"12345670", "461246179231"}).Return(mockDB).Times(1)
+	mockDB.EXPECT().Find(gomock.Any()).Return(&gorm.DB{Error: nil}).Times(1)
+
 	// Set up database mock to simulate successful product creation
 	mockDB.EXPECT().Create(gomock.Any()).DoAndReturn(func(product *[]models.Product) *gorm.DB {
 		// Normally, you might simulate setting an ID or other fields modified by the DB
@@ -120,6 +124,45 @@ func TestCreateProduct(t *testing.T) {
 	assert.Contains(t, w.Body.String(), "New Product", "Response body should contain the product title")
 }
 
+func TestCreateProductRejectsNegativePrice(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDB := database.NewMockDatabase(ctrl)
+	mockCache := cache.NewMockCache(ctrl)
+	ctx := context.Background()
+
+	repo := NewProductRepository(mockDB, mockCache, &ctx)
+
+	// Set up Gin
+	gin.SetMode(gin.TestMode)
+	r := gin.Default()
+	r.POST("/products", func(c *gin.Context) {
+		// Set the appCtx in the Gin context
+		c.Set("appCtxProduct", repo)
+		repo.CreateProducts(c)
+	})
+
+	stock, _ := decimal.NewFromString("100")
+	inputProducts := []models.CreateProducts{
+		{Name: "Broken Product", Price: -10, Vat: 2100, Stock: stock, BarcodeNumber: "12345670"},
+	}
+	requestBody, err := json.Marshal(inputProducts)
+	if err != nil {
+		t.Fatalf("Failed to marshal input product data: %v", err)
+	}
+
+	// Nothing must be stored
+	mockDB.EXPECT().Create(gomock.Any()).Times(0)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/products", bytes.NewBuffer(requestBody))
+	req.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
 func TestFindProduct(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -200,8 +243,9 @@ func TestDeleteProduct(t *testing.T) {
 
 	// Create mock for the database
 	mockDB := database.NewMockDatabase(ctrl)
+	mockCache := cache.NewMockCache(ctrl)
 	ctx := context.Background()
-	repo := NewProductRepository(mockDB, nil, &ctx)
+	repo := NewProductRepository(mockDB, mockCache, &ctx)
 
 	// Set up Gin for testing
 	gin.SetMode(gin.TestMode)
@@ -216,7 +260,7 @@ func TestDeleteProduct(t *testing.T) {
 		Price:         10,
 		Stock:         stock,
 		Vat:           2100,
-		BarcodeNumber: "12345678",
+		BarcodeNumber: "12345670",
 	}
 
 	// Mock Where to return the existingProduct for chaining
@@ -234,11 +278,40 @@ func TestDeleteProduct(t *testing.T) {
 			return mockDB
 		}).Times(1)
 
+	// Load the barcodes of the product, then free them and delete its variants
+	mockDB.EXPECT().
+		Where("product_id = ?", uint(1)).
+		Return(mockDB).Times(3)
+	mockDB.EXPECT().
+		Find(gomock.Any()).
+		DoAndReturn(func(dest interface{}, conds ...interface{}) *gorm.DB {
+			*dest.(*[]models.ProductBarcode) = []models.ProductBarcode{{ID: 1, ProductID: 1, Code: "12345670"}}
+			return &gorm.DB{Error: nil}
+		}).Times(1)
+	mockDB.EXPECT().
+		Transaction(gomock.Any()).
+		DoAndReturn(func(fc func(database.Database) error) error {
+			return fc(mockDB)
+		}).Times(1)
+	mockDB.EXPECT().
+		Delete(gomock.AssignableToTypeOf(&models.ProductBarcode{})).
+		Return(&gorm.DB{Error: nil}).Times(1)
+	mockDB.EXPECT().
+		Delete(gomock.AssignableToTypeOf(&models.ProductVariant{})).
+		Return(&gorm.DB{Error: nil}).Times(1)
+
 	// Mock Delete method
 	mockDB.EXPECT().
 		Delete(&existingProduct).
 		Return(&gorm.DB{Error: nil}).Times(1)
 
+	// The cached pages and the cached lookup of the barcode are dropped
+	mockCache.EXPECT().Keys(ctx, "products_offset_*").Return(redis.NewStringSliceResult([]string{"products_offset_0_limit_10"}, nil)).Times(1)
+	mockCache.EXPECT().Del(ctx, "products_offset_0_limit_10").Return(redis.NewIntResult(1, nil)).Times(1)
+	mockCache.EXPECT().
+		Del(ctx, "barcode_12345670").
+		Return(redis.NewIntResult(1, nil)).Times(1)
+
 	// Mock Error method to return nil
 	mockDB.EXPECT().Error().Return(nil).AnyTimes()
 
@@ -250,3 +323,58 @@ func TestDeleteProduct(t *testing.T) {
 	// Assert the response
 	assert.Equal(t, http.StatusNoContent, w.Code)
 }
+
+func TestWholeRevisions(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDB := database.NewMockDatabase(ctrl)
+
+	// The page ends between two revisions
+	page, err := wholeRevisions(mockDB, []models.Product{{ID: 1, Revision: 10}, {ID: 2, Revision: 11}, {ID: 3, Revision: 12}}, 2)
+	assert.NoError(t, err)
+	assert.Len(t, page, 2)
+
+	// Products 2 and 3 were changed together and are left for the next call
+	page, err = wholeRevisions(mockDB, []models.Product{{ID: 1, Revision: 10}, {ID: 2, Revision: 11}, {ID: 3, Revision: 11}}, 2)
+	assert.NoError(t, err)
+	assert.Len(t, page, 1)
+
+	// The products changed together do not fit in a page
+	mockDB.EXPECT().Unscoped().Return(mockDB).Times(1)
+	mockDB.EXPECT().Where("revision = ?", int64(11)).Return(mockDB).Times(1)
+	mockDB.EXPECT().
+		Find(gomock.Any()).
+		DoAndReturn(func(dest interface{}, conds ...interface{}) *gorm.DB {
+			*dest.(*[]models.Product) = []models.Product{{ID: 4, Revision: 11}, {ID: 1, Revision: 11}, {ID: 3, Revision: 11}, {ID: 2, Revision: 11}}
+			return &gorm.DB{Error: nil}
+		}).Times(1)
+	page, err = wholeRevisions(mockDB, []models.Product{{ID: 1, Revision: 11}, {ID: 2, Revision: 11}, {ID: 3, Revision: 11}}, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, []uint{1, 2, 3, 4}, []uint{page[0].ID, page[1].ID, page[2].ID, page[3].ID})
+}
+
+func TestProductChangesInvalidSince(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDB := database.NewMockDatabase(ctrl)
+	mockCache := cache.NewMockCache(ctrl)
+	ctx := context.Background()
+	repo := NewProductRepository(mockDB, mockCache, &ctx)
+
+	// Set up Gin
+	gin.SetMode(gin.TestMode)
+	r := gin.Default()
+	r.GET("/products/changes", repo.ProductChanges)
+
+	mockDB.EXPECT().Unscoped().Times(0)
+
+	for _, query := range []string{"since=abc", "since=-1", "since=3&limit=0"} {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/products/changes?"+query, nil)
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code, query)
+	}
+}
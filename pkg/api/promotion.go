@@ -0,0 +1,381 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"postui_api/pkg/database"
+	"postui_api/pkg/models"
+	"postui_api/pkg/promotions"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+var errPromotionNotFound = errors.New("promotion not found")
+
+type PromotionRepository interface {
+	FindPromotions(c *gin.Context)
+	CreatePromotion(c *gin.Context)
+	FindPromotion(c *gin.Context)
+	UpdatePromotion(c *gin.Context)
+	DeletePromotion(c *gin.Context)
+}
+
+// promotionRepository holds shared resources like database
+type promotionRepository struct {
+	DB  database.Database
+	Ctx *context.Context
+}
+
+// NewPromotionRepository creates a new promotionRepository
+func NewPromotionRepository(db database.Database, ctx *context.Context) *promotionRepository {
+	return &promotionRepository{
+		DB:  db,
+		Ctx: ctx,
+	}
+}
+
+// @BasePath /api/v1
+
+// FindPromotions godoc
+// @Summary Get all promotions
+// @Description Get every promotion, the ones with the highest priority first
+// @Tags promotions
+// @Security JwtAuth
+// @Produce json
+// @Success 200 {array} models.Promotion "Successfully retrieved list of promotions"
+// @Failure 500 {string} string "Internal Server Error"
+// @Router /promotions [get]
+func (r *promotionRepository) FindPromotions(c *gin.Context) {
+	var found []models.Promotion
+
+	if err := r.DB.Order("priority DESC, id").Find(&found).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": found})
+}
+
+// CreatePromotion godoc
+// @Summary Create a promotion
+// @Description Create a discount rule applied at checkout while it is active and within its validity window
+// @Tags promotions
+// @Security JwtAuth
+// @Accept  json
+// @Produce  json
+// @Param   input     body   models.CreatePromotion   true   "Promotion object"
+// @Success 201 {object} models.Promotion "Successfully created promotion"
+// @Failure 400 {string} string "Bad Request"
+// @Failure 422 {string} string "Invalid promotion"
+// @Router /promotions [post]
+func (r *promotionRepository) CreatePromotion(c *gin.Context) {
+	appCtx, exists := c.MustGet("appCtxPromotion").(*promotionRepository)
+	if !exists {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	var input models.CreatePromotion
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	promotion := models.Promotion{
+		Name:        input.Name,
+		Kind:        input.Kind,
+		ProductID:   input.ProductID,
+		Category:    input.Category,
+		Percent:     input.Percent,
+		Amount:      input.Amount,
+		BuyQuantity: input.BuyQuantity,
+		GetQuantity: input.GetQuantity,
+		Threshold:   input.Threshold,
+		Priority:    input.Priority,
+		StartsAt:    input.StartsAt,
+		EndsAt:      input.EndsAt,
+		Active:      input.Active == nil || *input.Active,
+		CouponOnly:  input.CouponOnly,
+	}
+	if err := promotions.Validate(promotion); err != nil {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := appCtx.DB.Create(&promotion).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"data": promotion})
+}
+
+// FindPromotion godoc
+// @Summary Find a promotion by ID
+// @Description Get details of a promotion by its ID
+// @Tags promotions
+// @Security JwtAuth
+// @Produce json
+// @Param id path string true "Promotion ID"
+// @Success 200 {object} models.Promotion "Successfully retrieved promotion"
+// @Failure 404 {string} string "promotion not found"
+// @Router /promotions/{id} [get]
+func (r *promotionRepository) FindPromotion(c *gin.Context) {
+	promotion, ok := r.findPromotion(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": promotion})
+}
+
+// UpdatePromotion godoc
+// @Summary Update a promotion by ID
+// @Description Update the given fields of a promotion. Omitted fields are left unchanged.
+// @Tags promotions
+// @Security JwtAuth
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Promotion ID"
+// @Param input body models.UpdatePromotion true "Update promotion object"
+// @Success 200 {object} models.Promotion "Successfully updated promotion"
+// @Failure 400 {string} string "Bad Request"
+// @Failure 404 {string} string "promotion not found"
+// @Failure 422 {string} string "Invalid promotion"
+// @Router /promotions/{id} [put]
+func (r *promotionRepository) UpdatePromotion(c *gin.Context) {
+	var input models.UpdatePromotion
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	promotion, ok := r.findPromotion(c)
+	if !ok {
+		return
+	}
+
+	mergePromotion(&promotion, input)
+	if err := promotions.Validate(promotion); err != nil {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Every column is saved so that fields can be set back to zero
+	if err := r.DB.Model(&promotion).Select("*").Updates(promotion).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": promotion})
+}
+
+// DeletePromotion godoc
+// @Summary Delete a promotion by ID
+// @Description Delete the promotion with the given ID. Discounts it already gave are kept on their orders.
+// @Tags promotions
+// @Security JwtAuth
+// @Produce json
+// @Param id path string true "Promotion ID"
+// @Success 204 {string} string "Successfully deleted promotion"
+// @Failure 404 {string} string "promotion not found"
+// @Router /promotions/{id} [delete]
+func (r *promotionRepository) DeletePromotion(c *gin.Context) {
+	promotion, ok := r.findPromotion(c)
+	if !ok {
+		return
+	}
+
+	if err := r.DB.Delete(&promotion).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	c.JSON(http.StatusNoContent, gin.H{"data": true})
+}
+
+// findPromotion loads the promotion of the id path parameter, responding
+// with an error when it cannot
+func (r *promotionRepository) findPromotion(c *gin.Context) (models.Promotion, bool) {
+	var promotion models.Promotion
+
+	if err := r.DB.Where("id = ?", c.Param("id")).First(&promotion).Error(); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": errPromotionNotFound.Error()})
+			return promotion, false
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return promotion, false
+	}
+
+	return promotion, true
+}
+
+// mergePromotion copies the fields set in input into promotion
+func mergePromotion(promotion *models.Promotion, input models.UpdatePromotion) {
+	if input.Name != "" {
+		promotion.Name = input.Name
+	}
+	if input.Kind != "" {
+		promotion.Kind = input.Kind
+	}
+	if input.ProductID != nil {
+		promotion.ProductID = input.ProductID
+	}
+	if input.Category != nil {
+		promotion.Category = *input.Category
+	}
+	if input.Percent != nil {
+		promotion.Percent = *input.Percent
+	}
+	if input.Amount != nil {
+		promotion.Amount = *input.Amount
+	}
+	if input.BuyQuantity != nil {
+		promotion.BuyQuantity = *input.BuyQuantity
+	}
+	if input.GetQuantity != nil {
+		promotion.GetQuantity = *input.GetQuantity
+	}
+	if input.Threshold != nil {
+		promotion.Threshold = *input.Threshold
+	}
+	if input.Priority != nil {
+		promotion.Priority = *input.Priority
+	}
+	if input.StartsAt != nil {
+		promotion.StartsAt = input.StartsAt
+	}
+	if input.EndsAt != nil {
+		promotion.EndsAt = input.EndsAt
+	}
+	if input.Active != nil {
+		promotion.Active = *input.Active
+	}
+	if input.CouponOnly != nil {
+		promotion.CouponOnly = *input.CouponOnly
+	}
+}
+
+// currentPromotions returns the active promotions whose validity window
+// includes now, but the coupon only ones
+func currentPromotions(db database.Database, now time.Time) ([]models.Promotion, error) {
+	var found []models.Promotion
+
+	err := db.Where("active = ? AND coupon_only = ? AND (starts_at IS NULL OR starts_at <= ?) AND (ends_at IS NULL OR ends_at > ?)", true, false, now, now).Find(&found).Error
+	return found, err
+}
+
+// applyOrderPromotions takes the discounts of the promotions current at now
+// off lines, the lines of order, in place of the discounts they were given
+// before they changed. The promotions of the coupons order redeemed apply
+// too, and coupons that no longer give a discount are released. The
+// discounted lines are returned.
+func applyOrderPromotions(tx database.Database, order models.Order, lines []models.OrderLine, now time.Time) ([]models.OrderLine, error) {
+	products, err := lineProducts(tx, lines)
+	if err != nil {
+		return nil, err
+	}
+	categories := make(map[uint]string, len(products))
+	for _, product := range products {
+		categories[product.ID] = product.Category
+	}
+
+	// Lines get their full amount back before the discounts are computed again
+	items := make([]promotions.Item, len(lines))
+	for i, line := range lines {
+		line.Total += line.Discount
+		line.Discount = 0
+		items[i] = promotions.Item{Line: line, Category: categories[line.ProductID]}
+	}
+
+	current, err := currentPromotions(tx, now)
+	if err != nil {
+		return nil, err
+	}
+
+	var redemptions []models.CouponRedemption
+	if err := tx.Where("order_id = ? AND reversed_at IS NULL", order.ID).Find(&redemptions).Error; err != nil {
+		return nil, err
+	}
+	couponPromotions := make(map[uint]uint, len(redemptions))
+	for _, redemption := range redemptions {
+		var coupon models.Coupon
+		if err := tx.Where("id = ?", redemption.CouponID).First(&coupon).Error(); err != nil {
+			return nil, err
+		}
+		couponPromotions[redemption.ID] = coupon.PromotionID
+
+		// The promotion may have been deleted since
+		var promotion models.Promotion
+		if err := tx.Where("id = ?", coupon.PromotionID).First(&promotion).Error(); err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				continue
+			}
+			return nil, err
+		}
+		current = append(current, promotion)
+	}
+
+	discounted, discounts := promotions.Apply(items, current, now)
+
+	for i, line := range discounted {
+		if line.Total == lines[i].Total && line.Discount == lines[i].Discount {
+			continue
+		}
+		if err := tx.Model(&models.OrderLine{}).Where("id = ?", line.ID).Updates(map[string]interface{}{"total": line.Total, "discount": line.Discount}).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Where("order_id = ?", order.ID).Delete(&models.OrderDiscount{}).Error; err != nil {
+		return nil, err
+	}
+	if len(discounts) > 0 {
+		for i := range discounts {
+			discounts[i].OrderID = order.ID
+			if discounts[i].Line >= 0 {
+				lineID := discounted[discounts[i].Line].ID
+				discounts[i].OrderLineID = &lineID
+			}
+		}
+		if err := tx.Create(&discounts).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	for _, redemption := range redemptions {
+		var amount models.Money
+		for _, discount := range discounts {
+			if discount.PromotionID == couponPromotions[redemption.ID] {
+				amount += discount.Amount
+			}
+		}
+
+		switch {
+		case amount == 0:
+			if err := reverseCouponRedemption(tx, redemption, now); err != nil {
+				return nil, err
+			}
+		case amount != redemption.Amount:
+			if err := tx.Model(&models.CouponRedemption{}).Where("id = ?", redemption.ID).Update("amount", amount).Error; err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return discounted, nil
+}
+
+// orderDiscounts returns the discounts applied to order
+func orderDiscounts(db database.Database, orderID uint) ([]models.OrderDiscount, error) {
+	var found []models.OrderDiscount
+
+	err := db.Where("order_id = ?", orderID).Find(&found).Error
+	return found, err
+}
@@ -0,0 +1,219 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"postui_api/pkg/database"
+	"postui_api/pkg/models"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func TestNewPromotionRepository(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDB := database.NewMockDatabase(ctrl)
+	mockCtx := context.Background()
+
+	repo := NewPromotionRepository(mockDB, &mockCtx)
+
+	assert.NotNil(t, repo, "NewPromotionRepository should return a non-nil instance of promotionRepository")
+	assert.Equal(t, mockDB, repo.DB, "DB should be set to the mock database instance")
+}
+
+func TestCreatePromotion(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDB := database.NewMockDatabase(ctrl)
+	ctx := context.Background()
+	repo := NewPromotionRepository(mockDB, &ctx)
+
+	// Set up Gin
+	gin.SetMode(gin.TestMode)
+	r := gin.Default()
+	r.POST("/promotions", func(c *gin.Context) {
+		// Set the appCtx in the Gin context
+		c.Set("appCtxPromotion", repo)
+		repo.CreatePromotion(c)
+	})
+
+	requestBody, err := json.Marshal(models.CreatePromotion{Name: "Bakery -10%", Kind: models.PromotionPercentage, Category: "bakery", Percent: 1000, Priority: 1})
+	if err != nil {
+		t.Fatalf("Failed to marshal promotion data: %v", err)
+	}
+
+	mockDB.EXPECT().Create(gomock.Any()).DoAndReturn(func(value interface{}) *gorm.DB {
+		value.(*models.Promotion).ID = 1
+		return &gorm.DB{Error: nil}
+	}).Times(1)
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/promotions", bytes.NewBuffer(requestBody))
+	req.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(w, req)
+
+	var response struct {
+		Data models.Promotion `json:"data"`
+	}
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+	assert.Equal(t, uint(1), response.Data.ID)
+	assert.True(t, response.Data.Active, "Promotions should be active by default")
+}
+
+func TestCreatePromotionInvalid(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDB := database.NewMockDatabase(ctrl)
+	ctx := context.Background()
+	repo := NewPromotionRepository(mockDB, &ctx)
+
+	// Set up Gin
+	gin.SetMode(gin.TestMode)
+	r := gin.Default()
+	r.POST("/promotions", func(c *gin.Context) {
+		// Set the appCtx in the Gin context
+		c.Set("appCtxPromotion", repo)
+		repo.CreatePromotion(c)
+	})
+
+	requestBody, err := json.Marshal(models.CreatePromotion{Name: "3x2", Kind: models.PromotionBuyXGetY, BuyQuantity: 2})
+	if err != nil {
+		t.Fatalf("Failed to marshal promotion data: %v", err)
+	}
+
+	mockDB.EXPECT().Create(gomock.Any()).Times(0)
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/promotions", bytes.NewBuffer(requestBody))
+	req.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	assert.Contains(t, w.Body.String(), "buy and get quantities are required")
+}
+
+func TestFindPromotionNotFound(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDB := database.NewMockDatabase(ctrl)
+	ctx := context.Background()
+	repo := NewPromotionRepository(mockDB, &ctx)
+
+	// Set up Gin
+	gin.SetMode(gin.TestMode)
+	r := gin.Default()
+	r.GET("/promotions/:id", repo.FindPromotion)
+
+	mockDB.EXPECT().Where("id = ?", "9").Return(mockDB).Times(1)
+	mockDB.EXPECT().First(gomock.Any()).Return(mockDB).Times(1)
+	mockDB.EXPECT().Error().Return(gorm.ErrRecordNotFound).Times(1)
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/promotions/9", nil)
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Contains(t, w.Body.String(), "promotion not found")
+}
+
+func TestUpdatePromotionInvalid(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDB := database.NewMockDatabase(ctrl)
+	ctx := context.Background()
+	repo := NewPromotionRepository(mockDB, &ctx)
+
+	// Set up Gin
+	gin.SetMode(gin.TestMode)
+	r := gin.Default()
+	r.PUT("/promotions/:id", repo.UpdatePromotion)
+
+	// Switching to a threshold promotion without a threshold
+	requestBody, err := json.Marshal(gin.H{"kind": models.PromotionThreshold})
+	if err != nil {
+		t.Fatalf("Failed to marshal promotion data: %v", err)
+	}
+
+	mockDB.EXPECT().Where("id = ?", "1").Return(mockDB).Times(1)
+	mockDB.EXPECT().
+		First(gomock.Any()).
+		DoAndReturn(func(dest interface{}, conds ...interface{}) database.Database {
+			if b, ok := dest.(*models.Promotion); ok {
+				*b = models.Promotion{ID: 1, Name: "-10%", Kind: models.PromotionPercentage, Percent: 1000, Active: true}
+			}
+			return mockDB
+		}).Times(1)
+	mockDB.EXPECT().Error().Return(nil).Times(1)
+	mockDB.EXPECT().Model(gomock.Any()).Times(0)
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPut, "/promotions/1", bytes.NewBuffer(requestBody))
+	req.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	assert.Contains(t, w.Body.String(), "threshold is required")
+}
+
+func TestApplyOrderPromotionsKeepsCurrentDiscount(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDB := database.NewMockDatabase(ctrl)
+	now := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+
+	orderID := uint(8)
+	lines := []models.OrderLine{{ID: 3, OrderID: &orderID, ProductID: 2, Quantity: decimal.NewFromInt(1), Price: 1000, Total: 900, Discount: 100}}
+
+	mockDB.EXPECT().Unscoped().Return(mockDB).Times(1)
+	mockDB.EXPECT().Where("id IN ?", []uint{2}).Return(mockDB).Times(1)
+	mockDB.EXPECT().Where("active = ? AND coupon_only = ? AND (starts_at IS NULL OR starts_at <= ?) AND (ends_at IS NULL OR ends_at > ?)", true, false, now, now).Return(mockDB).Times(1)
+	mockDB.EXPECT().Where("order_id = ? AND reversed_at IS NULL", orderID).Return(mockDB).Times(1)
+	mockDB.EXPECT().
+		Find(gomock.Any()).
+		DoAndReturn(func(dest interface{}, conds ...interface{}) *gorm.DB {
+			switch b := dest.(type) {
+			case *[]models.Product:
+				*b = []models.Product{{ID: 2, Name: "Coffee", Category: "coffee"}}
+			case *[]models.Promotion:
+				*b = []models.Promotion{{ID: 5, Name: "Coffee -10%", Kind: models.PromotionPercentage, Category: "coffee", Percent: 1000, Active: true}}
+			}
+			return &gorm.DB{Error: nil}
+		}).Times(3)
+
+	// The line keeps its discount, so only the discounts of the order are stored again
+	mockDB.EXPECT().Model(gomock.Any()).Times(0)
+	mockDB.EXPECT().Where("order_id = ?", orderID).Return(mockDB).Times(1)
+	mockDB.EXPECT().Delete(gomock.AssignableToTypeOf(&models.OrderDiscount{})).Return(&gorm.DB{Error: nil}).Times(1)
+	mockDB.EXPECT().
+		Create(gomock.AssignableToTypeOf(&[]models.OrderDiscount{})).
+		DoAndReturn(func(value interface{}) *gorm.DB {
+			discounts := *value.(*[]models.OrderDiscount)
+			assert.Len(t, discounts, 1)
+			assert.Equal(t, models.Money(100), discounts[0].Amount)
+			assert.Equal(t, orderID, discounts[0].OrderID)
+			assert.Equal(t, uint(3), *discounts[0].OrderLineID)
+			return &gorm.DB{Error: nil}
+		}).Times(1)
+
+	discounted, err := applyOrderPromotions(mockDB, models.Order{ID: orderID}, lines, now)
+	assert.NoError(t, err)
+	assert.Equal(t, models.Money(900), discounted[0].Total)
+	assert.Equal(t, models.Money(100), discounted[0].Discount)
+}
@@ -0,0 +1,115 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"postui_api/pkg/database"
+	"postui_api/pkg/models"
+	"postui_api/pkg/receipt"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// OrderReceipt godoc
+// @Summary Print the receipt of an order
+// @Description Render the receipt of an order with the store settings, as plain text or as an ESC/POS byte stream for thermal printers
+// @Tags orders
+// @Security JwtAuth
+// @Produce  plain
+// @Produce  octet-stream
+// @Param id path string true "Order ID"
+// @Param format query string false "text (default) or escpos"
+// @Success 200 {string} string "Receipt"
+// @Failure 400 {string} string "Bad Request"
+// @Failure 404 {string} string "order not found"
+// @Router /orders/{id}/receipt [get]
+func (r *orderRepository) OrderReceipt(c *gin.Context) {
+	format := c.DefaultQuery("format", "text")
+	if format != "text" && format != "escpos" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "format must be text or escpos"})
+		return
+	}
+
+	var order models.Order
+
+	if err := r.DB.Where("id = ?", c.Param("id")).First(&order).Error(); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": errOrderNotFound.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	r.renderReceipt(c, order, format)
+}
+
+// renderReceipt loads what is printed on the receipt of order and writes it
+// in format
+func (r *orderRepository) renderReceipt(c *gin.Context, order models.Order, format string) {
+	lines, err := findOrderLines(r.DB, order.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	names, err := productNames(r.DB, lines)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	discounts, err := orderDiscounts(r.DB, order.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	var found []models.Payment
+	if err := r.DB.Where("order_id = ?", order.ID).Find(&found).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	settings, _, err := loadStoreSettings(r.DB)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	ticket := receipt.Receipt{Store: settings, Order: order, Taxes: orderTaxes(order, lines), Discounts: discounts, Payments: found}
+	for _, line := range lines {
+		ticket.Lines = append(ticket.Lines, receipt.Line{OrderLine: line, Name: names[line.ProductID]})
+	}
+
+	if format == "escpos" {
+		c.Data(http.StatusOK, "application/octet-stream", receipt.ESCPOS(ticket))
+		return
+	}
+	c.String(http.StatusOK, receipt.Text(ticket))
+}
+
+// productNames returns the names of the products of lines by ID
+func productNames(db database.Database, lines []models.OrderLine) (map[uint]string, error) {
+	names := make(map[uint]string, len(lines))
+	if len(lines) == 0 {
+		return names, nil
+	}
+
+	ids := make([]uint, 0, len(lines))
+	for _, line := range lines {
+		ids = append(ids, line.ProductID)
+	}
+
+	// Products deleted since the sale keep their name on the receipt
+	var products []models.Product
+	if err := db.Unscoped().Where("id IN ?", ids).Find(&products).Error; err != nil {
+		return nil, err
+	}
+
+	for _, product := range products {
+		names[product.ID] = product.Name
+	}
+	return names, nil
+}
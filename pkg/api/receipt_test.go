@@ -0,0 +1,110 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"postui_api/pkg/database"
+	"postui_api/pkg/models"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func TestOrderReceiptInvalidFormat(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDB := database.NewMockDatabase(ctrl)
+	ctx := context.Background()
+	repo := NewOrderRepository(mockDB, &ctx)
+
+	// Set up Gin
+	gin.SetMode(gin.TestMode)
+	r := gin.Default()
+	r.GET("/order/:id/receipt", repo.OrderReceipt)
+
+	mockDB.EXPECT().Where(gomock.Any(), gomock.Any()).Times(0)
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/order/1/receipt?format=pdf", nil)
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestOrderReceiptText(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDB := database.NewMockDatabase(ctrl)
+	ctx := context.Background()
+	repo := NewOrderRepository(mockDB, &ctx)
+
+	// Set up Gin
+	gin.SetMode(gin.TestMode)
+	r := gin.Default()
+	r.GET("/order/:id/receipt", repo.OrderReceipt)
+
+	order := models.Order{ID: 1, CustomerName: "username", Total: 242, Currency: "EUR", CashoutNumber: 1, Status: models.OrderPaid}
+	line := models.OrderLine{ID: 1, ProductID: 3, Quantity: decimal.NewFromInt(2), Price: 121, Currency: "EUR", Vat: 2100, Total: 242}
+
+	mockDB.EXPECT().Where("id = ?", "1").Return(mockDB).Times(1)
+	mockDB.EXPECT().
+		First(gomock.Any()).
+		DoAndReturn(func(dest interface{}, conds ...interface{}) database.Database {
+			if b, ok := dest.(*models.Order); ok {
+				*b = order
+			}
+			return mockDB
+		}).Times(1)
+
+	mockDB.EXPECT().Where("order_id = ?", uint(1)).Return(mockDB).Times(3)
+	mockDB.EXPECT().Find(gomock.AssignableToTypeOf(&[]models.OrderLine{})).DoAndReturn(func(dest interface{}, conds ...interface{}) *gorm.DB {
+		if lines, ok := dest.(*[]models.OrderLine); ok {
+			*lines = []models.OrderLine{line}
+		}
+		return &gorm.DB{Error: nil}
+	}).Times(1)
+
+	mockDB.EXPECT().Unscoped().Return(mockDB).Times(1)
+	mockDB.EXPECT().Where("id IN ?", []uint{3}).Return(mockDB).Times(1)
+	mockDB.EXPECT().Find(gomock.Any()).DoAndReturn(func(dest interface{}, conds ...interface{}) *gorm.DB {
+		if products, ok := dest.(*[]models.Product); ok {
+			*products = []models.Product{{ID: 3, Name: "Baguette"}}
+		}
+		return &gorm.DB{Error: nil}
+	}).Times(1)
+
+	mockDB.EXPECT().Find(gomock.AssignableToTypeOf(&[]models.OrderDiscount{})).Return(&gorm.DB{Error: nil}).Times(1)
+	mockDB.EXPECT().Find(gomock.Any()).DoAndReturn(func(dest interface{}, conds ...interface{}) *gorm.DB {
+		if found, ok := dest.(*[]models.Payment); ok {
+			*found = []models.Payment{{OrderID: 1, Tender: models.TenderCash, Amount: 242, Tendered: 500, Change: 258}}
+		}
+		return &gorm.DB{Error: nil}
+	}).Times(1)
+
+	mockDB.EXPECT().Where("id = ?", models.StoreSettingsID).Return(mockDB).Times(1)
+	mockDB.EXPECT().
+		First(gomock.Any()).
+		DoAndReturn(func(dest interface{}, conds ...interface{}) database.Database {
+			if b, ok := dest.(*models.StoreSettings); ok {
+				*b = models.StoreSettings{ID: 1, Name: "Bakery", Width: 32, Code: models.ReceiptCodeNone}
+			}
+			return mockDB
+		}).Times(1)
+	mockDB.EXPECT().Error().Return(nil).Times(2)
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/order/1/receipt", nil)
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "BAKERY")
+	assert.Contains(t, w.Body.String(), "Baguette\n  2 x 1.21                  2.42\n")
+	assert.Contains(t, w.Body.String(), "Change                      2.58\n")
+}
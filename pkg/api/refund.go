@@ -0,0 +1,280 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"postui_api/pkg/database"
+	"postui_api/pkg/models"
+	"postui_api/pkg/pricing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+var (
+	errNotRefundable     = errors.New("order cannot be refunded")
+	errRefundExceedsSold = errors.New("refund exceeds quantity sold")
+)
+
+// RefundOrder godoc
+// @Summary Refund an order
+// @Description Create a refund document for some or all lines of a paid order. Quantities cannot exceed what was sold minus what was already refunded. Refunded products can be restocked per line. Coupons redeemed by a fully refunded order can be redeemed again. Refunds to a gift card are credited to its balance. Loyalty points earned by the order are taken back in proportion to the amount refunded.
+// @Tags orders
+// @Security JwtAuth
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Order ID"
+// @Param input body models.CreateRefund true "Refund object"
+// @Success 201 {object} models.OrderDetail "Successfully created refund"
+// @Failure 400 {string} string "Bad Request"
+// @Failure 404 {string} string "order or gift card not found"
+// @Failure 409 {string} string "Order cannot be refunded or no open register session"
+// @Failure 422 {string} string "Refund exceeds quantity sold"
+// @Router /orders/{id}/refunds [post]
+func (r *orderRepository) RefundOrder(c *gin.Context) {
+	var input models.CreateRefund
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if input.Tender == models.TenderGiftCard && input.GiftCard == "" {
+		respondGiftCardError(c, errGiftCardNumberNeeded)
+		return
+	}
+
+	for _, line := range input.Lines {
+		if !line.Quantity.IsPositive() {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("quantity for order line %d must be positive", line.OrderLineID)})
+			return
+		}
+	}
+
+	username := c.GetString("username")
+	var detail models.OrderDetail
+
+	err := r.DB.Transaction(func(tx database.Database) error {
+		var order models.Order
+
+		// Concurrent refunds of the order wait, so they see what this one refunds
+		if err := tx.ForUpdate().Where("id = ?", c.Param("id")).First(&order).Error(); err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errOrderNotFound
+			}
+			return err
+		}
+
+		if order.RefundOfID != nil {
+			return fmt.Errorf("%w: order %d is itself a refund", errNotRefundable, order.ID)
+		}
+		if order.Status != models.OrderPaid {
+			return fmt.Errorf("%w: order is %s", errNotRefundable, order.Status)
+		}
+
+		lines, err := findOrderLines(tx, order.ID)
+		if err != nil {
+			return err
+		}
+
+		refunded, err := refundedQuantities(tx, lines)
+		if err != nil {
+			return err
+		}
+
+		requested := input.Lines
+		if len(requested) == 0 {
+			for _, line := range lines {
+				requested = append(requested, models.RefundLine{OrderLineID: line.ID, Quantity: line.Quantity.Sub(refunded[line.ID].Quantity), Restock: input.Restock})
+			}
+		}
+
+		byID := make(map[uint]models.OrderLine, len(lines))
+		for _, line := range lines {
+			byID[line.ID] = line
+		}
+
+		var refundLines, restocked []models.OrderLine
+		for _, item := range requested {
+			line, ok := byID[item.OrderLineID]
+			if !ok {
+				return fmt.Errorf("%w: %d is not a line of order %d", errOrderLineNotFound, item.OrderLineID, order.ID)
+			}
+
+			if !item.Quantity.IsPositive() {
+				continue
+			}
+
+			previous := refunded[line.ID]
+			left := line.Quantity.Sub(previous.Quantity)
+			if item.Quantity.GreaterThan(left) {
+				return fmt.Errorf("%w: %s left to refund on order line %d", errRefundExceedsSold, left, line.ID)
+			}
+
+			refundLine := pricing.RefundLine(line, item.Quantity, previous)
+			refundLines = append(refundLines, refundLine)
+
+			previous.Quantity = previous.Quantity.Add(item.Quantity)
+			previous.Total -= refundLine.Total
+			refunded[line.ID] = previous
+
+			if item.Restock {
+				line.Quantity = item.Quantity
+				restocked = append(restocked, line)
+			}
+		}
+		for _, line := range byProduct(restocked) {
+			if err := returnStock(tx, line, line.Quantity); err != nil {
+				return err
+			}
+		}
+
+		if len(refundLines) == 0 {
+			return fmt.Errorf("%w: nothing left to refund", errNotRefundable)
+		}
+
+		cashoutNumber := input.CashoutNumber
+		if cashoutNumber == 0 {
+			cashoutNumber = order.CashoutNumber
+		}
+		if err := requireOpenSession(tx, cashoutNumber); err != nil {
+			return err
+		}
+
+		refund := models.Order{
+			CustomerID:    order.CustomerID,
+			CustomerName:  order.CustomerName,
+			Total:         pricing.OrderTotal(refundLines),
+			Taxes:         pricing.Breakdown(refundLines),
+			Currency:      order.Currency,
+			CashoutNumber: cashoutNumber,
+			Status:        models.OrderPaid,
+			RefundOfID:    &order.ID,
+			RefundTender:  input.Tender,
+			Cashier:       username,
+		}
+		if err := assignInvoice(tx, &refund, time.Now()); err != nil {
+			return err
+		}
+		if err := tx.Create(&refund).Error; err != nil {
+			return err
+		}
+		for i := range refundLines {
+			refundLines[i].OrderID = &refund.ID
+		}
+		if err := tx.Create(&refundLines).Error; err != nil {
+			return err
+		}
+		if err := registerFiscalInvoice(tx, refund, refundLines); err != nil {
+			return err
+		}
+
+		if err := tx.Create(&models.OrderTransition{OrderID: refund.ID, To: models.OrderPaid, Reason: input.Reason, Username: username}).Error; err != nil {
+			return err
+		}
+
+		// The money given back is recorded as a negative payment of the refund
+		payment := models.Payment{OrderID: refund.ID, Tender: input.Tender, Amount: refund.Total, Tendered: refund.Total, Reference: input.GiftCard}
+		if err := tx.Create(&payment).Error; err != nil {
+			return err
+		}
+		if input.Tender == models.TenderGiftCard {
+			if err := creditGiftCard(tx, input.GiftCard, -refund.Total, payment); err != nil {
+				return err
+			}
+		}
+
+		var refundedTotal models.Money
+		for _, line := range lines {
+			refundedTotal += refunded[line.ID].Total
+		}
+		if err := reverseLoyaltyPoints(tx, order, refundedTotal); err != nil {
+			return err
+		}
+
+		if fullyRefunded(lines, refunded) {
+			if err := transitionOrder(tx, &order, models.OrderRefunded, input.Reason, username); err != nil {
+				return err
+			}
+			if err := reverseCouponRedemptions(tx, order.ID); err != nil {
+				return err
+			}
+		}
+
+		detail = models.OrderDetail{Order: refund, Lines: refundLines, Taxes: refund.Taxes}
+		return nil
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, errOrderLineNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case errors.Is(err, errNotRefundable):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		case errors.Is(err, errRefundExceedsSold):
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		case errors.Is(err, errNoOpenSession):
+			respondRegisterError(c, err)
+		case errors.Is(err, errGiftCardNotFound):
+			respondGiftCardError(c, err)
+		default:
+			respondOrderStatusError(c, err)
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"data": detail})
+}
+
+// checkNotRefunded fails with errInvalidTransition when part of order was
+// refunded, so that the money refunded is not given back again by a void
+func checkNotRefunded(tx database.Database, order models.Order) error {
+	var refunds []models.Order
+	if err := tx.Where("refund_of_id = ?", order.ID).Find(&refunds).Error; err != nil {
+		return err
+	}
+
+	if len(refunds) > 0 {
+		return fmt.Errorf("%w: order %d was partly refunded, refund the rest instead", errInvalidTransition, order.ID)
+	}
+	return nil
+}
+
+// refundedQuantities returns, for each of lines, the quantity and total
+// already refunded as positive amounts
+func refundedQuantities(db database.Database, lines []models.OrderLine) (map[uint]models.OrderLine, error) {
+	refunded := make(map[uint]models.OrderLine, len(lines))
+	if len(lines) == 0 {
+		return refunded, nil
+	}
+
+	ids := make([]uint, 0, len(lines))
+	for _, line := range lines {
+		ids = append(ids, line.ID)
+	}
+
+	var refundLines []models.OrderLine
+	if err := db.Where("refund_of_line_id IN ?", ids).Find(&refundLines).Error; err != nil {
+		return nil, err
+	}
+
+	for _, line := range refundLines {
+		previous := refunded[*line.RefundOfLineID]
+		previous.Quantity = previous.Quantity.Sub(line.Quantity)
+		previous.Total -= line.Total
+		refunded[*line.RefundOfLineID] = previous
+	}
+
+	return refunded, nil
+}
+
+// fullyRefunded reports whether every unit of lines has been refunded
+func fullyRefunded(lines []models.OrderLine, refunded map[uint]models.OrderLine) bool {
+	for _, line := range lines {
+		if refunded[line.ID].Quantity.LessThan(line.Quantity) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,148 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"postui_api/pkg/database"
+	"postui_api/pkg/models"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func TestRefundOrderRefusedWhenOpen(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDB := database.NewMockDatabase(ctrl)
+	ctx := context.Background()
+	repo := NewOrderRepository(mockDB, &ctx)
+
+	// Set up Gin for testing
+	gin.SetMode(gin.TestMode)
+	r := gin.Default()
+	r.POST("/order/:id/refunds", repo.RefundOrder)
+
+	openOrder := models.Order{ID: 1, CustomerName: "username", Total: 1000, CashoutNumber: 1, Status: models.OrderOpen}
+
+	requestBody, err := json.Marshal(models.CreateRefund{Reason: "damaged", Tender: models.TenderCash})
+	if err != nil {
+		t.Fatalf("Failed to marshal refund data: %v", err)
+	}
+
+	// Run the transaction body against the same mock
+	mockDB.EXPECT().
+		Transaction(gomock.Any()).
+		DoAndReturn(func(fc func(database.Database) error) error {
+			return fc(mockDB)
+		}).Times(1)
+	// The order is locked before what is left to refund is computed
+	mockDB.EXPECT().ForUpdate().Return(mockDB).Times(1)
+	mockDB.EXPECT().Where("id = ?", "1").Return(mockDB).Times(1)
+	mockDB.EXPECT().
+		First(gomock.Any()).
+		DoAndReturn(func(dest interface{}, conds ...interface{}) database.Database {
+			if b, ok := dest.(*models.Order); ok {
+				*b = openOrder
+			}
+			return mockDB
+		}).Times(1)
+	mockDB.EXPECT().Error().Return(nil).Times(1)
+
+	// Nothing must be written for an order that was not paid
+	mockDB.EXPECT().Create(gomock.Any()).Times(0)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPost, "/order/1/refunds", bytes.NewBuffer(requestBody))
+	req.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.Contains(t, w.Body.String(), "order cannot be refunded")
+}
+
+func TestRefundOrderExceedsSold(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDB := database.NewMockDatabase(ctrl)
+	ctx := context.Background()
+	repo := NewOrderRepository(mockDB, &ctx)
+
+	// Set up Gin for testing
+	gin.SetMode(gin.TestMode)
+	r := gin.Default()
+	r.POST("/order/:id/refunds", repo.RefundOrder)
+
+	line := models.OrderLine{ID: 5, ProductID: 1, Quantity: decimal.NewFromInt(2), Price: 500, Currency: "EUR", Vat: 2100, Total: 1000}
+	paidOrder := models.Order{ID: 1, CustomerName: "username", Total: 1000, Currency: "EUR", CashoutNumber: 1, Status: models.OrderPaid}
+	lineID := line.ID
+	previousRefund := models.OrderLine{ID: 9, ProductID: 1, Quantity: decimal.NewFromInt(-1), Price: 500, Currency: "EUR", Vat: 2100, Total: -500, RefundOfLineID: &lineID}
+
+	input := models.CreateRefund{
+		Reason: "damaged",
+		Tender: models.TenderCard,
+		Lines:  []models.RefundLine{{OrderLineID: 5, Quantity: decimal.NewFromInt(2)}},
+	}
+
+	requestBody, err := json.Marshal(input)
+	if err != nil {
+		t.Fatalf("Failed to marshal refund data: %v", err)
+	}
+
+	// Run the transaction body against the same mock
+	mockDB.EXPECT().
+		Transaction(gomock.Any()).
+		DoAndReturn(func(fc func(database.Database) error) error {
+			return fc(mockDB)
+		}).Times(1)
+	// The order is locked before what is left to refund is computed
+	mockDB.EXPECT().ForUpdate().Return(mockDB).Times(1)
+	mockDB.EXPECT().Where("id = ?", "1").Return(mockDB).Times(1)
+	mockDB.EXPECT().
+		First(gomock.Any()).
+		DoAndReturn(func(dest interface{}, conds ...interface{}) database.Database {
+			if b, ok := dest.(*models.Order); ok {
+				*b = paidOrder
+			}
+			return mockDB
+		}).Times(1)
+	mockDB.EXPECT().Error().Return(nil).Times(1)
+
+	// Mock the order lines lookup
+	mockDB.EXPECT().Where("order_id = ?", uint(1)).Return(mockDB).Times(1)
+	mockDB.EXPECT().Find(gomock.Any()).DoAndReturn(func(dest interface{}, conds ...interface{}) *gorm.DB {
+		if lines, ok := dest.(*[]models.OrderLine); ok {
+			*lines = []models.OrderLine{line}
+		}
+		return &gorm.DB{Error: nil}
+	}).Times(1)
+
+	// One of the two units was already refunded
+	mockDB.EXPECT().Where("refund_of_line_id IN ?", []uint{5}).Return(mockDB).Times(1)
+	mockDB.EXPECT().Find(gomock.Any()).DoAndReturn(func(dest interface{}, conds ...interface{}) *gorm.DB {
+		if lines, ok := dest.(*[]models.OrderLine); ok {
+			*lines = []models.OrderLine{previousRefund}
+		}
+		return &gorm.DB{Error: nil}
+	}).Times(1)
+
+	// Neither stock nor documents must be written
+	mockDB.EXPECT().Model(gomock.Any()).Times(0)
+	mockDB.EXPECT().Create(gomock.Any()).Times(0)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPost, "/order/1/refunds", bytes.NewBuffer(requestBody))
+	req.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	assert.Contains(t, w.Body.String(), "refund exceeds quantity sold")
+}
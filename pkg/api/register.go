@@ -0,0 +1,301 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"postui_api/pkg/database"
+	"postui_api/pkg/models"
+	"postui_api/pkg/register"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+var (
+	errSessionNotFound    = errors.New("register session not found")
+	errSessionAlreadyOpen = errors.New("a register session is already open for this cashout number")
+	errSessionClosed      = errors.New("register session is closed")
+	errNoOpenSession      = errors.New("no open register session for this cashout number")
+)
+
+type RegisterRepository interface {
+	OpenSession(c *gin.Context)
+	FindSession(c *gin.Context)
+	AddCashMovement(c *gin.Context)
+	CloseSession(c *gin.Context)
+	XReport(c *gin.Context)
+	ZReport(c *gin.Context)
+}
+
+// registerRepository holds shared resources like database
+type registerRepository struct {
+	DB  database.Database
+	Ctx *context.Context
+}
+
+// NewRegisterRepository creates a new registerRepository
+func NewRegisterRepository(db database.Database, ctx *context.Context) *registerRepository {
+	return &registerRepository{
+		DB:  db,
+		Ctx: ctx,
+	}
+}
+
+// @BasePath /api/v1
+
+// OpenSession godoc
+// @Summary Open a register session
+// @Description Open the cash drawer of a cashout number with an opening float. Orders can only be created for cashout numbers with an open session.
+// @Tags registers
+// @Security JwtAuth
+// @Accept  json
+// @Produce  json
+// @Param   input     body   models.OpenSession   true   "Open session object"
+// @Success 201 {object} models.RegisterSession "Successfully opened session"
+// @Failure 400 {string} string "Bad Request"
+// @Failure 409 {string} string "A session is already open"
+// @Router /registers/sessions [post]
+func (r *registerRepository) OpenSession(c *gin.Context) {
+	appCtx, exists := c.MustGet("appCtxRegister").(*registerRepository)
+	if !exists {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	var input models.OpenSession
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	session := models.RegisterSession{
+		CashoutNumber: input.CashoutNumber,
+		Status:        models.SessionOpen,
+		Currency:      models.DefaultCurrency,
+		OpeningFloat:  input.OpeningFloat,
+		OpenedBy:      c.GetString("username"),
+	}
+
+	err := appCtx.DB.Transaction(func(tx database.Database) error {
+		err := requireOpenSession(tx, input.CashoutNumber)
+		if err == nil {
+			return errSessionAlreadyOpen
+		}
+		if !errors.Is(err, errNoOpenSession) {
+			return err
+		}
+
+		// A unique index on open sessions rejects concurrent opens
+		return tx.Create(&session).Error
+	})
+	if err != nil {
+		respondRegisterError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"data": session})
+}
+
+// FindSession godoc
+// @Summary Find a register session by ID
+// @Description Get a register session with its cash movements and, once closed, its counts
+// @Tags registers
+// @Security JwtAuth
+// @Produce  json
+// @Param id path string true "Session ID"
+// @Success 200 {object} models.RegisterSession "Successfully retrieved session"
+// @Failure 404 {string} string "register session not found"
+// @Router /registers/sessions/{id} [get]
+func (r *registerRepository) FindSession(c *gin.Context) {
+	session, err := findSession(r.DB, c.Param("id"))
+	if err != nil {
+		respondRegisterError(c, err)
+		return
+	}
+
+	if err := r.DB.Where("session_id = ?", session.ID).Find(&session.Movements).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+	if err := r.DB.Where("session_id = ?", session.ID).Find(&session.Counts).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": session})
+}
+
+// AddCashMovement godoc
+// @Summary Record a pay-in or pay-out
+// @Description Record cash put into or taken out of the drawer of an open session outside of a sale
+// @Tags registers
+// @Security JwtAuth
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Session ID"
+// @Param   input     body   models.CreateCashMovement   true   "Cash movement object"
+// @Success 201 {object} models.CashMovement "Successfully recorded movement"
+// @Failure 400 {string} string "Bad Request"
+// @Failure 404 {string} string "register session not found"
+// @Failure 409 {string} string "Session is closed"
+// @Router /registers/sessions/{id}/movements [post]
+func (r *registerRepository) AddCashMovement(c *gin.Context) {
+	var input models.CreateCashMovement
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var movement models.CashMovement
+
+	err := r.DB.Transaction(func(tx database.Database) error {
+		session, err := findSession(tx, c.Param("id"))
+		if err != nil {
+			return err
+		}
+		if session.Status != models.SessionOpen {
+			return errSessionClosed
+		}
+
+		movement = models.CashMovement{SessionID: session.ID, Kind: input.Kind, Amount: input.Amount, Reason: input.Reason, Username: c.GetString("username")}
+		return tx.Create(&movement).Error
+	})
+	if err != nil {
+		respondRegisterError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"data": movement})
+}
+
+// CloseSession godoc
+// @Summary Close a register session
+// @Description Close the drawer with the cash counted per denomination. The expected cash is the opening float plus pay-ins, minus pay-outs, plus cash payments of the session orders; the variance is counted minus expected.
+// @Tags registers
+// @Security JwtAuth
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Session ID"
+// @Param   input     body   models.CloseSession   true   "Counted cash"
+// @Success 200 {object} models.RegisterSession "Successfully closed session"
+// @Failure 400 {string} string "Bad Request"
+// @Failure 404 {string} string "register session not found"
+// @Failure 409 {string} string "Session is closed"
+// @Router /registers/sessions/{id}/close [post]
+func (r *registerRepository) CloseSession(c *gin.Context) {
+	var input models.CloseSession
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var session models.RegisterSession
+
+	err := r.DB.Transaction(func(tx database.Database) error {
+		var err error
+		session, err = findSession(tx, c.Param("id"))
+		if err != nil {
+			return err
+		}
+		if session.Status != models.SessionOpen {
+			return errSessionClosed
+		}
+
+		if err := tx.Where("session_id = ?", session.ID).Find(&session.Movements).Error; err != nil {
+			return err
+		}
+
+		// Cash taken by the orders of the cashout number since the session was opened
+		var found []models.Payment
+		if err := tx.Where("tender = ? AND created_at >= ? AND order_id IN (SELECT id FROM orders WHERE cashout_number = ? AND status IN ?)",
+			models.TenderCash, session.OpenedAt, session.CashoutNumber, []models.OrderStatus{models.OrderPaid, models.OrderRefunded}).
+			Find(&found).Error; err != nil {
+			return err
+		}
+
+		session.Counts = make([]models.DenominationCount, 0, len(input.Counts))
+		for _, count := range input.Counts {
+			session.Counts = append(session.Counts, models.DenominationCount{SessionID: session.ID, Denomination: count.Denomination, Quantity: count.Quantity})
+		}
+
+		register.Close(&session, session.Movements, found, session.Counts)
+		closedAt := time.Now()
+
+		result := tx.Model(&models.RegisterSession{}).Where("id = ? AND status = ?", session.ID, models.SessionOpen).Updates(map[string]interface{}{
+			"status":        models.SessionClosed,
+			"expected_cash": session.ExpectedCash,
+			"counted_cash":  session.CountedCash,
+			"variance":      session.Variance,
+			"closed_by":     c.GetString("username"),
+			"closed_at":     closedAt,
+		})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errSessionClosed
+		}
+		session.Status = models.SessionClosed
+		session.ClosedBy = c.GetString("username")
+		session.ClosedAt = &closedAt
+
+		if len(session.Counts) == 0 {
+			return nil
+		}
+		return tx.Create(&session.Counts).Error
+	})
+	if err != nil {
+		respondRegisterError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": session})
+}
+
+// findSession returns the register session with the given id
+func findSession(db database.Database, id string) (models.RegisterSession, error) {
+	var session models.RegisterSession
+
+	if err := db.Where("id = ?", id).First(&session).Error(); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return session, errSessionNotFound
+		}
+		return session, err
+	}
+
+	return session, nil
+}
+
+// requireOpenSession returns errNoOpenSession unless a register session is
+// open for cashoutNumber
+func requireOpenSession(db database.Database, cashoutNumber uint) error {
+	var session models.RegisterSession
+
+	if err := db.Where("cashout_number = ? AND status = ?", cashoutNumber, models.SessionOpen).First(&session).Error(); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("%w: %d", errNoOpenSession, cashoutNumber)
+		}
+		return err
+	}
+
+	return nil
+}
+
+// respondRegisterError maps an error returned while handling a register
+// session to a response
+func respondRegisterError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, errSessionNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, errSessionAlreadyOpen), errors.Is(err, errSessionClosed), errors.Is(err, errNoOpenSession):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+	}
+}
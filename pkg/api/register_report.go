@@ -0,0 +1,188 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"postui_api/pkg/database"
+	"postui_api/pkg/models"
+	"postui_api/pkg/reports"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+var errInvalidCashoutNumber = errors.New("invalid cashout number")
+
+// XReport godoc
+// @Summary X report of a register
+// @Description Mid-shift snapshot of the orders of a cashout number since its last Z report. Nothing is closed. Use format=text for a printable layout.
+// @Tags registers
+// @Security JwtAuth
+// @Produce  json
+// @Produce  plain
+// @Param number path int true "Cashout number"
+// @Param format query string false "json (default) or text"
+// @Success 200 {object} models.RegisterReport "Successfully built report"
+// @Failure 400 {string} string "Bad Request"
+// @Router /registers/{number}/x-report [get]
+func (r *registerRepository) XReport(c *gin.Context) {
+	cashoutNumber, err := cashoutNumberParam(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	report, _, err := buildRegisterReport(r.DB, cashoutNumber)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	respondRegisterReport(c, r.DB, http.StatusOK, report)
+}
+
+// ZReport godoc
+// @Summary Z report of a register
+// @Description End-of-day close of the paid, refunded and voided orders of a cashout number since its last Z report. Z reports are numbered sequentially per cashout number and the orders they cover can no longer be changed. Use format=text for a printable layout.
+// @Tags registers
+// @Security JwtAuth
+// @Produce  json
+// @Produce  plain
+// @Param number path int true "Cashout number"
+// @Param format query string false "json (default) or text"
+// @Success 201 {object} models.RegisterReport "Successfully closed the day"
+// @Failure 400 {string} string "Bad Request"
+// @Failure 409 {string} string "Orders were closed concurrently"
+// @Router /registers/{number}/z-report [post]
+func (r *registerRepository) ZReport(c *gin.Context) {
+	cashoutNumber, err := cashoutNumberParam(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var report models.RegisterReport
+
+	err = r.DB.Transaction(func(tx database.Database) error {
+		var orders []models.Order
+		report, orders, err = buildRegisterReport(tx, cashoutNumber)
+		if err != nil {
+			return err
+		}
+
+		var last uint
+		if err := tx.Model(&models.ZReport{}).Where("cashout_number = ?", cashoutNumber).Select("COALESCE(MAX(number), 0)").Scan(&last).Error; err != nil {
+			return err
+		}
+		report.Number = last + 1
+
+		// The unique index on cashout and number rejects concurrent Z reports
+		z := models.ZReport{
+			CashoutNumber: cashoutNumber,
+			Number:        report.Number,
+			From:          report.From,
+			To:            report.To,
+			Currency:      report.Currency,
+			SalesCount:    report.SalesCount,
+			GrossSales:    report.GrossSales,
+			RefundsCount:  report.RefundsCount,
+			Refunds:       report.Refunds,
+			NetSales:      report.NetSales,
+			Discounts:     report.Discounts,
+			VoidsCount:    report.VoidsCount,
+			Voids:         report.Voids,
+			Username:      c.GetString("username"),
+		}
+		if err := tx.Create(&z).Error; err != nil {
+			return err
+		}
+
+		if len(orders) == 0 {
+			return nil
+		}
+
+		ids := make([]uint, 0, len(orders))
+		for _, order := range orders {
+			ids = append(ids, order.ID)
+		}
+
+		result := tx.Model(&models.Order{}).Where("id IN ? AND z_report_id IS NULL", ids).Update("z_report_id", z.ID)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected != int64(len(ids)) {
+			return errOrderConflict
+		}
+		return nil
+	})
+	if err != nil {
+		respondOrderStatusError(c, err)
+		return
+	}
+
+	respondRegisterReport(c, r.DB, http.StatusCreated, report)
+}
+
+// buildRegisterReport reports the paid, refunded and voided orders of
+// cashoutNumber not closed by a Z report yet, and returns them
+func buildRegisterReport(db database.Database, cashoutNumber uint) (models.RegisterReport, []models.Order, error) {
+	var orders []models.Order
+	statuses := []models.OrderStatus{models.OrderPaid, models.OrderRefunded, models.OrderVoided}
+	if err := db.Where("cashout_number = ? AND z_report_id IS NULL AND status IN ?", cashoutNumber, statuses).Find(&orders).Error; err != nil {
+		return models.RegisterReport{}, nil, err
+	}
+
+	ids := make([]uint, 0, len(orders))
+	for _, order := range orders {
+		ids = append(ids, order.ID)
+	}
+
+	lines, err := findLinesOfOrders(db, ids)
+	if err != nil {
+		return models.RegisterReport{}, nil, err
+	}
+
+	var found []models.Payment
+	if len(ids) > 0 {
+		if err := db.Where("order_id IN ?", ids).Find(&found).Error; err != nil {
+			return models.RegisterReport{}, nil, err
+		}
+	}
+
+	report := reports.Register(cashoutNumber, orders, lines, found)
+	report.To = time.Now()
+	report.From = report.To
+	for _, order := range orders {
+		if order.CreatedAt.Before(report.From) {
+			report.From = order.CreatedAt
+		}
+	}
+
+	return report, orders, nil
+}
+
+// cashoutNumberParam parses the number path parameter
+func cashoutNumberParam(c *gin.Context) (uint, error) {
+	number, err := strconv.ParseUint(c.Param("number"), 10, 0)
+	if err != nil || number == 0 {
+		return 0, errInvalidCashoutNumber
+	}
+	return uint(number), nil
+}
+
+// respondRegisterReport writes report as JSON, or as text as wide as the
+// store receipts when asked to
+func respondRegisterReport(c *gin.Context, db database.Database, code int, report models.RegisterReport) {
+	if c.Query("format") == "text" {
+		settings, _, err := loadStoreSettings(db)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+			return
+		}
+		c.String(code, reports.Text(report, int(settings.Width)))
+		return
+	}
+
+	c.JSON(code, gin.H{"data": report})
+}
@@ -0,0 +1,135 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"postui_api/pkg/database"
+	"postui_api/pkg/models"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func TestXReportInvalidCashoutNumber(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDB := database.NewMockDatabase(ctrl)
+	ctx := context.Background()
+	repo := NewRegisterRepository(mockDB, &ctx)
+
+	// Set up Gin
+	gin.SetMode(gin.TestMode)
+	r := gin.Default()
+	r.GET("/registers/:number/x-report", repo.XReport)
+
+	mockDB.EXPECT().Where(gomock.Any(), gomock.Any()).Times(0)
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/registers/first/x-report", nil)
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "invalid cashout number")
+}
+
+func TestXReportText(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDB := database.NewMockDatabase(ctrl)
+	ctx := context.Background()
+	repo := NewRegisterRepository(mockDB, &ctx)
+
+	// Set up Gin
+	gin.SetMode(gin.TestMode)
+	r := gin.Default()
+	r.GET("/registers/:number/x-report", repo.XReport)
+
+	statuses := []models.OrderStatus{models.OrderPaid, models.OrderRefunded, models.OrderVoided}
+	voided := models.Order{ID: 7, Total: 1250, CashoutNumber: 2, Status: models.OrderVoided}
+
+	// Only the orders not closed by a Z report are reported
+	mockDB.EXPECT().Where("cashout_number = ? AND z_report_id IS NULL AND status IN ?", uint(2), statuses).Return(mockDB).Times(1)
+	mockDB.EXPECT().Find(gomock.Any()).DoAndReturn(func(dest interface{}, conds ...interface{}) *gorm.DB {
+		if orders, ok := dest.(*[]models.Order); ok {
+			*orders = []models.Order{voided}
+		}
+		return &gorm.DB{Error: nil}
+	}).Times(1)
+	// Neither lines nor payments are left on the voided order
+	mockDB.EXPECT().Where("order_id IN ?", []uint{7}).Return(mockDB).Times(2)
+	mockDB.EXPECT().Find(gomock.Any()).Return(&gorm.DB{Error: nil}).Times(2)
+
+	// The store was never configured, so the default width is used
+	mockDB.EXPECT().Where("id = ?", models.StoreSettingsID).Return(mockDB).Times(1)
+	mockDB.EXPECT().First(gomock.Any()).Return(mockDB).Times(1)
+	mockDB.EXPECT().Error().Return(gorm.ErrRecordNotFound).Times(1)
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/registers/2/x-report?format=text", nil)
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "X REPORT")
+	assert.Contains(t, w.Body.String(), "Cashout 2")
+	assert.Contains(t, w.Body.String(), "Voids (1)")
+	assert.Contains(t, w.Body.String(), "12.50")
+}
+
+func TestVoidOrderLockedByZReport(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDB := database.NewMockDatabase(ctrl)
+	ctx := context.Background()
+	repo := NewOrderRepository(mockDB, &ctx)
+
+	// Set up Gin for testing
+	gin.SetMode(gin.TestMode)
+	r := gin.Default()
+	r.POST("/order/:id/void", repo.VoidOrder)
+
+	zReportID := uint(3)
+	closedOrder := models.Order{ID: 1, CustomerName: "username", Total: 1000, CashoutNumber: 1, Status: models.OrderPaid, ZReportID: &zReportID}
+
+	requestBody, err := json.Marshal(models.TransitionOrder{Reason: "wrong customer"})
+	if err != nil {
+		t.Fatalf("Failed to marshal transition data: %v", err)
+	}
+
+	// Run the transaction body against the same mock
+	mockDB.EXPECT().
+		Transaction(gomock.Any()).
+		DoAndReturn(func(fc func(database.Database) error) error {
+			return fc(mockDB)
+		}).Times(1)
+	mockDB.EXPECT().ForUpdate().Return(mockDB).Times(1)
+	mockDB.EXPECT().Where("id = ?", "1").Return(mockDB).Times(1)
+	mockDB.EXPECT().
+		First(gomock.Any()).
+		DoAndReturn(func(dest interface{}, conds ...interface{}) database.Database {
+			if b, ok := dest.(*models.Order); ok {
+				*b = closedOrder
+			}
+			return mockDB
+		}).Times(1)
+	mockDB.EXPECT().Error().Return(nil).Times(1)
+
+	// The status must not be touched
+	mockDB.EXPECT().Model(gomock.Any()).Times(0)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPost, "/order/1/void", bytes.NewBuffer(requestBody))
+	req.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.Contains(t, w.Body.String(), "closed by a Z report")
+}
@@ -0,0 +1,132 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"postui_api/pkg/database"
+	"postui_api/pkg/models"
+	"postui_api/pkg/reports"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+var errInvalidPeriod = errors.New("to must not be before from")
+
+// reportDateLayout is the format of the from and to query parameters
+const reportDateLayout = "2006-01-02"
+
+type ReportRepository interface {
+	SalesReport(c *gin.Context)
+}
+
+// reportRepository holds shared resources like database
+type reportRepository struct {
+	DB  database.Database
+	Ctx *context.Context
+}
+
+// NewReportRepository creates a new reportRepository
+func NewReportRepository(db database.Database, ctx *context.Context) *reportRepository {
+	return &reportRepository{
+		DB:  db,
+		Ctx: ctx,
+	}
+}
+
+// @BasePath /api/v1
+
+// SalesReport godoc
+// @Summary Sales report
+// @Description Summarize paid orders between two dates, netting refunds against sales
+// @Tags reports
+// @Security JwtAuth
+// @Produce  json
+// @Param from query string false "First day, as YYYY-MM-DD (defaults to today)"
+// @Param to query string false "Last day, as YYYY-MM-DD, inclusive (defaults to from)"
+// @Param cashout_number query int false "Only orders of this cashout"
+// @Success 200 {object} models.SalesSummary "Successfully built report"
+// @Failure 400 {string} string "Bad Request"
+// @Failure 401 {string} string "Unauthorized"
+// @Router /reports/sales [get]
+func (r *reportRepository) SalesReport(c *gin.Context) {
+	appCtx, exists := c.MustGet("appCtxReport").(*reportRepository)
+	if !exists {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	from, to, err := reportPeriod(c.Query("from"), c.Query("to"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var cashoutNumber uint64
+	if cashout := c.Query("cashout_number"); cashout != "" {
+		cashoutNumber, err = strconv.ParseUint(cashout, 10, 0)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid cashout_number"})
+			return
+		}
+	}
+
+	query := appCtx.DB.Where("status IN ? AND created_at >= ? AND created_at < ?", []models.OrderStatus{models.OrderPaid, models.OrderRefunded}, from, to)
+	if cashoutNumber != 0 {
+		query = query.Where("cashout_number = ?", cashoutNumber)
+	}
+
+	var orders []models.Order
+	if err := query.Find(&orders).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	ids := make([]uint, 0, len(orders))
+	for _, order := range orders {
+		ids = append(ids, order.ID)
+	}
+
+	lines, err := findLinesOfOrders(appCtx.DB, ids)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	summary := reports.Sales(orders, lines)
+	summary.From = from
+	summary.To = to
+
+	c.JSON(http.StatusOK, gin.H{"data": summary})
+}
+
+// reportPeriod parses the from and to days of a report into the half-open
+// interval [from, to). Both default to the current day.
+func reportPeriod(fromDay, toDay string) (time.Time, time.Time, error) {
+	now := time.Now()
+	from := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+
+	if fromDay != "" {
+		parsed, err := time.ParseInLocation(reportDateLayout, fromDay, time.Local)
+		if err != nil {
+			return time.Time{}, time.Time{}, err
+		}
+		from = parsed
+	}
+
+	to := from
+	if toDay != "" {
+		parsed, err := time.ParseInLocation(reportDateLayout, toDay, time.Local)
+		if err != nil {
+			return time.Time{}, time.Time{}, err
+		}
+		to = parsed
+	}
+	if to.Before(from) {
+		return time.Time{}, time.Time{}, errInvalidPeriod
+	}
+
+	return from, to.AddDate(0, 0, 1), nil
+}
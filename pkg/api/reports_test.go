@@ -0,0 +1,67 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"postui_api/pkg/database"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewReportRepository(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDB := database.NewMockDatabase(ctrl)
+	mockCtx := context.Background()
+
+	repo := NewReportRepository(mockDB, &mockCtx)
+
+	assert.NotNil(t, repo, "NewReportRepository should return a non-nil instance of reportRepository")
+	assert.Equal(t, mockDB, repo.DB, "DB should be set to the mock database instance")
+}
+
+func TestSalesReportInvalidPeriod(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDB := database.NewMockDatabase(ctrl)
+	ctx := context.Background()
+	repo := NewReportRepository(mockDB, &ctx)
+
+	// Set up Gin
+	gin.SetMode(gin.TestMode)
+	r := gin.Default()
+	r.GET("/reports/sales", func(c *gin.Context) {
+		// Set the appCtx in the Gin context
+		c.Set("appCtxReport", repo)
+		repo.SalesReport(c)
+	})
+
+	// No query must be run for an invalid period
+	mockDB.EXPECT().Where(gomock.Any(), gomock.Any()).Times(0)
+
+	for _, query := range []string{"from=17-10-2026", "from=2026-10-17&to=2026-10-16", "cashout_number=first"} {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/reports/sales?"+query, nil)
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code, query)
+	}
+}
+
+func TestReportPeriod(t *testing.T) {
+	from, to, err := reportPeriod("2026-10-01", "2026-10-31")
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2026, 10, 1, 0, 0, 0, 0, time.Local), from)
+	assert.Equal(t, time.Date(2026, 11, 1, 0, 0, 0, 0, time.Local), to, "The last day should be included")
+
+	from, to, err = reportPeriod("2026-10-17", "")
+	assert.NoError(t, err)
+	assert.Equal(t, from.AddDate(0, 0, 1), to, "A single day should be reported by default")
+}
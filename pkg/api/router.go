@@ -18,11 +18,23 @@ import (
 	"golang.org/x/time/rate"
 )
 
-func ContextMiddleware(productRepository ProductRepository, orderRepository OrderRepository, orderLineRepository OrderLineRepository) gin.HandlerFunc {
+func ContextMiddleware(productRepository ProductRepository, orderRepository OrderRepository, orderLineRepository OrderLineRepository, checkoutRepository CheckoutRepository, reportRepository ReportRepository, registerRepository RegisterRepository, storeRepository StoreRepository, fiscalRepository FiscalRepository, promotionRepository PromotionRepository, couponRepository CouponRepository, giftCardRepository GiftCardRepository, customerRepository CustomerRepository, loyaltyRepository LoyaltyRepository, syncRepository SyncRepository, taxRepository TaxRepository) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		c.Set("appCtxProduct", productRepository)
 		c.Set("appCtxOrder", orderRepository)
 		c.Set("appCtxOrderLine", orderLineRepository)
+		c.Set("appCtxCheckout", checkoutRepository)
+		c.Set("appCtxReport", reportRepository)
+		c.Set("appCtxRegister", registerRepository)
+		c.Set("appCtxStore", storeRepository)
+		c.Set("appCtxFiscal", fiscalRepository)
+		c.Set("appCtxPromotion", promotionRepository)
+		c.Set("appCtxCoupon", couponRepository)
+		c.Set("appCtxGiftCard", giftCardRepository)
+		c.Set("appCtxCustomer", customerRepository)
+		c.Set("appCtxLoyalty", loyaltyRepository)
+		c.Set("appCtxSync", syncRepository)
+		c.Set("appCtxTax", taxRepository)
 		c.Next()
 	}
 }
@@ -32,9 +44,21 @@ func NewRouter(logger *zap.Logger, mongoCollection *mongo.Collection, db databas
 	userRepository := NewUserRepository(db, ctx)
 	orderLineRepository := NewOrderLineRepository(db, ctx)
 	orderRepository := NewOrderRepository(db, ctx)
+	checkoutRepository := NewCheckoutRepository(db, ctx)
+	reportRepository := NewReportRepository(db, ctx)
+	registerRepository := NewRegisterRepository(db, ctx)
+	storeRepository := NewStoreRepository(db, ctx)
+	fiscalRepository := NewFiscalRepository(db, ctx)
+	promotionRepository := NewPromotionRepository(db, ctx)
+	couponRepository := NewCouponRepository(db, ctx)
+	giftCardRepository := NewGiftCardRepository(db, ctx)
+	customerRepository := NewCustomerRepository(db, ctx)
+	loyaltyRepository := NewLoyaltyRepository(db, ctx)
+	syncRepository := NewSyncRepository(db, ctx)
+	taxRepository := NewTaxRepository(db, ctx)
 
 	r := gin.Default()
-	r.Use(ContextMiddleware(productRepository, orderRepository, orderLineRepository))
+	r.Use(ContextMiddleware(productRepository, orderRepository, orderLineRepository, checkoutRepository, reportRepository, registerRepository, storeRepository, fiscalRepository, promotionRepository, couponRepository, giftCardRepository, customerRepository, loyaltyRepository, syncRepository, taxRepository))
 
 	//r.Use(gin.Logger())
 	r.Use(middleware.Logger(logger, mongoCollection))
@@ -45,23 +69,90 @@ func NewRouter(logger *zap.Logger, mongoCollection *mongo.Collection, db databas
 	r.Use(middleware.Cors())
 	r.Use(middleware.RateLimiter(rate.Every(1*time.Minute), 60)) // 60 requests per minute
 
+	// Writes retried by the terminals on timeouts are only applied once
+	idempotent := middleware.Idempotency(redisClient, middleware.IdempotencyWindow)
+
 	docs.SwaggerInfo.BasePath = "/api/v1"
 	v1 := r.Group("/api/v1")
 	{
-		v1.GET("/", productRepository.Healthcheck)                                                              // No need to be admin
-		v1.GET("/products", middleware.JWTAuth(), productRepository.FindProducts)                               // No need to be admin
-		v1.POST("/products", middleware.JWTAuth(), middleware.IsAdmin(), productRepository.CreateProducts)      // Need to be admin
-		v1.GET("/products/:id", middleware.JWTAuth(), productRepository.FindProduct)                            // No need to be admin
-		v1.PUT("/products/:id", middleware.JWTAuth(), middleware.IsAdmin(), productRepository.UpdateProduct)    // Need to be admin
-		v1.DELETE("/products/:id", middleware.JWTAuth(), middleware.IsAdmin(), productRepository.DeleteProduct) // Need to be admin
-		v1.POST("/order_lines", middleware.JWTAuth(), orderLineRepository.CreateOrderLine)                      // No need to be admin
-		v1.GET("/order_lines/:id", middleware.JWTAuth(), orderLineRepository.FindOrderLine)                     // No need to be admin
-		v1.PUT("/order_lines/:id", middleware.JWTAuth(), orderLineRepository.UpdateOrderLine)                   // No need to be admin
-		v1.DELETE("/order_lines/:id", middleware.JWTAuth(), orderLineRepository.DeleteOrderLine)                // No need to be admin
-		v1.POST("/orders", middleware.JWTAuth(), orderRepository.CreateOrder)                                   // No need to be admin
-		v1.GET("/orders/:id", middleware.JWTAuth(), orderRepository.FindOrder)                                  // No need to be admin
-		v1.PUT("/orders/:id", middleware.JWTAuth(), orderRepository.UpdateOrder)                                // No need to be admin
-		v1.DELETE("/orders/:id", middleware.JWTAuth(), orderRepository.DeleteOrder)                             // No need to be admin
+		v1.GET("/", productRepository.Healthcheck)                                                                     // No need to be admin
+		v1.GET("/products", middleware.JWTAuth(), productRepository.FindProducts)                                      // No need to be admin
+		v1.POST("/products", middleware.JWTAuth(), middleware.IsAdmin(), idempotent, productRepository.CreateProducts) // Need to be admin
+		v1.GET("/products/changes", middleware.JWTAuth(), productRepository.ProductChanges)                            // No need to be admin
+		v1.GET("/products/barcode/:code", middleware.JWTAuth(), productRepository.FindProductByBarcode)                // No need to be admin
+		v1.GET("/products/:id", middleware.JWTAuth(), productRepository.FindProduct)                                   // No need to be admin
+		v1.PUT("/products/:id", middleware.JWTAuth(), middleware.IsAdmin(), productRepository.UpdateProduct)           // Need to be admin
+		v1.DELETE("/products/:id", middleware.JWTAuth(), middleware.IsAdmin(), productRepository.DeleteProduct)        // Need to be admin
+		v1.GET("/barcodes/:code", middleware.JWTAuth(), productRepository.ResolveBarcode)                              // No need to be admin
+		v1.POST("/order_lines", middleware.JWTAuth(), idempotent, orderLineRepository.CreateOrderLine)                 // No need to be admin
+		v1.GET("/order_lines/:id", middleware.JWTAuth(), orderLineRepository.FindOrderLine)                            // No need to be admin
+		v1.PUT("/order_lines/:id", middleware.JWTAuth(), orderLineRepository.UpdateOrderLine)                          // No need to be admin
+		v1.DELETE("/order_lines/:id", middleware.JWTAuth(), orderLineRepository.DeleteOrderLine)                       // No need to be admin
+		v1.POST("/orders", middleware.JWTAuth(), idempotent, orderRepository.CreateOrder)                              // No need to be admin
+		v1.GET("/orders", middleware.JWTAuth(), orderRepository.FindOrders)                                            // No need to be admin
+		v1.GET("/orders/:id", middleware.JWTAuth(), orderRepository.FindOrder)                                         // No need to be admin
+		v1.PUT("/orders/:id", middleware.JWTAuth(), orderRepository.UpdateOrder)                                       // No need to be admin
+		v1.DELETE("/orders/:id", middleware.JWTAuth(), orderRepository.DeleteOrder)                                    // No need to be admin
+		v1.POST("/orders/:id/pay", middleware.JWTAuth(), idempotent, orderRepository.PayOrder)                         // No need to be admin
+		v1.POST("/orders/:id/void", middleware.JWTAuth(), orderRepository.VoidOrder)                                   // No need to be admin
+		v1.POST("/orders/:id/park", middleware.JWTAuth(), orderRepository.ParkOrder)                                   // No need to be admin
+		v1.POST("/orders/:id/resume", middleware.JWTAuth(), orderRepository.ResumeOrder)                               // No need to be admin
+		v1.POST("/orders/:id/refunds", middleware.JWTAuth(), orderRepository.RefundOrder)                              // No need to be admin
+		v1.POST("/orders/:id/payments", middleware.JWTAuth(), idempotent, orderRepository.AddPayment)                  // No need to be admin
+		v1.GET("/orders/:id/payments", middleware.JWTAuth(), orderRepository.FindPayments)                             // No need to be admin
+		v1.DELETE("/orders/:id/payments/:payment_id", middleware.JWTAuth(), orderRepository.DeletePayment)             // No need to be admin
+		v1.GET("/orders/:id/receipt", middleware.JWTAuth(), orderRepository.OrderReceipt)                              // No need to be admin
+		v1.POST("/orders/:id/lines", middleware.JWTAuth(), idempotent, orderRepository.AddOrderLine)                   // No need to be admin
+		v1.PUT("/orders/:id/lines/:line_id", middleware.JWTAuth(), orderRepository.ChangeOrderLine)                    // No need to be admin
+		v1.DELETE("/orders/:id/lines/:line_id", middleware.JWTAuth(), orderRepository.RemoveOrderLine)                 // No need to be admin
+		v1.POST("/checkout", middleware.JWTAuth(), idempotent, checkoutRepository.Checkout)                            // No need to be admin
+		v1.POST("/sync/orders", middleware.JWTAuth(), syncRepository.SyncOrders)                                       // No need to be admin
+		v1.GET("/reports/sales", middleware.JWTAuth(), reportRepository.SalesReport)                                   // No need to be admin
+		v1.POST("/registers/sessions", middleware.JWTAuth(), registerRepository.OpenSession)                           // No need to be admin
+		v1.GET("/registers/sessions/:id", middleware.JWTAuth(), registerRepository.FindSession)                        // No need to be admin
+		v1.POST("/registers/sessions/:id/movements", middleware.JWTAuth(), registerRepository.AddCashMovement)         // No need to be admin
+		v1.POST("/registers/sessions/:id/close", middleware.JWTAuth(), registerRepository.CloseSession)                // No need to be admin
+		v1.GET("/registers/:number/x-report", middleware.JWTAuth(), registerRepository.XReport)                        // No need to be admin
+		v1.POST("/registers/:number/z-report", middleware.JWTAuth(), registerRepository.ZReport)                       // No need to be admin
+		v1.GET("/store", middleware.JWTAuth(), storeRepository.FindStoreSettings)                                      // No need to be admin
+		v1.PUT("/store", middleware.JWTAuth(), middleware.IsAdmin(), storeRepository.UpdateStoreSettings)              // Need to be admin
+
+		v1.POST("/products/:id/variants", middleware.JWTAuth(), middleware.IsAdmin(), productRepository.CreateProductVariant)               // Need to be admin
+		v1.PUT("/products/:id/variants/:variant_id", middleware.JWTAuth(), middleware.IsAdmin(), productRepository.UpdateProductVariant)    // Need to be admin
+		v1.DELETE("/products/:id/variants/:variant_id", middleware.JWTAuth(), middleware.IsAdmin(), productRepository.DeleteProductVariant) // Need to be admin
+
+		v1.GET("/fiscal/verify", middleware.JWTAuth(), middleware.IsAdmin(), fiscalRepository.VerifyFiscalChain)   // Need to be admin
+		v1.GET("/fiscal/export", middleware.JWTAuth(), middleware.IsAdmin(), fiscalRepository.ExportFiscalRecords) // Need to be admin
+
+		v1.GET("/promotions", middleware.JWTAuth(), promotionRepository.FindPromotions)                               // No need to be admin
+		v1.POST("/promotions", middleware.JWTAuth(), middleware.IsAdmin(), promotionRepository.CreatePromotion)       // Need to be admin
+		v1.GET("/promotions/:id", middleware.JWTAuth(), promotionRepository.FindPromotion)                            // No need to be admin
+		v1.PUT("/promotions/:id", middleware.JWTAuth(), middleware.IsAdmin(), promotionRepository.UpdatePromotion)    // Need to be admin
+		v1.DELETE("/promotions/:id", middleware.JWTAuth(), middleware.IsAdmin(), promotionRepository.DeletePromotion) // Need to be admin
+		v1.GET("/coupons", middleware.JWTAuth(), middleware.IsAdmin(), couponRepository.FindCoupons)                  // Need to be admin
+		v1.POST("/coupons", middleware.JWTAuth(), middleware.IsAdmin(), couponRepository.CreateCoupons)               // Need to be admin
+		v1.GET("/coupons/:id", middleware.JWTAuth(), middleware.IsAdmin(), couponRepository.FindCoupon)               // Need to be admin
+		v1.DELETE("/coupons/:id", middleware.JWTAuth(), middleware.IsAdmin(), couponRepository.DeleteCoupon)          // Need to be admin
+		v1.GET("/giftcards/:number", middleware.JWTAuth(), giftCardRepository.FindGiftCard)                           // No need to be admin
+		v1.POST("/giftcards/expire", middleware.JWTAuth(), middleware.IsAdmin(), giftCardRepository.ExpireGiftCards)  // Need to be admin
+
+		v1.GET("/customers", middleware.JWTAuth(), customerRepository.FindCustomers)                               // No need to be admin
+		v1.POST("/customers", middleware.JWTAuth(), customerRepository.CreateCustomer)                             // No need to be admin
+		v1.GET("/customers/:id", middleware.JWTAuth(), customerRepository.FindCustomer)                            // No need to be admin
+		v1.PUT("/customers/:id", middleware.JWTAuth(), customerRepository.UpdateCustomer)                          // No need to be admin
+		v1.DELETE("/customers/:id", middleware.JWTAuth(), middleware.IsAdmin(), customerRepository.DeleteCustomer) // Need to be admin
+		v1.GET("/customers/:id/orders", middleware.JWTAuth(), customerRepository.FindCustomerOrders)               // No need to be admin
+
+		v1.GET("/loyalty/accounts/:identifier", middleware.JWTAuth(), loyaltyRepository.FindLoyaltyAccount)              // No need to be admin
+		v1.GET("/loyalty/rules", middleware.JWTAuth(), loyaltyRepository.FindLoyaltyRules)                               // No need to be admin
+		v1.POST("/loyalty/rules", middleware.JWTAuth(), middleware.IsAdmin(), loyaltyRepository.CreateLoyaltyRule)       // Need to be admin
+		v1.DELETE("/loyalty/rules/:id", middleware.JWTAuth(), middleware.IsAdmin(), loyaltyRepository.DeleteLoyaltyRule) // Need to be admin
+		v1.POST("/loyalty/expire", middleware.JWTAuth(), middleware.IsAdmin(), loyaltyRepository.ExpireLoyaltyPoints)    // Need to be admin
+
+		v1.GET("/tax_categories", middleware.JWTAuth(), taxRepository.FindTaxCategories)                           // No need to be admin
+		v1.POST("/tax_categories", middleware.JWTAuth(), middleware.IsAdmin(), taxRepository.CreateTaxCategory)    // Need to be admin
+		v1.GET("/tax_categories/:id", middleware.JWTAuth(), taxRepository.FindTaxCategory)                         // No need to be admin
+		v1.POST("/tax_categories/:id/rates", middleware.JWTAuth(), middleware.IsAdmin(), taxRepository.AddTaxRate) // Need to be admin
 
 		v1.POST("/login", userRepository.LoginHandler)                                                             // No need to be admin neither to be logged
 		v1.POST("/register", middleware.JWTAuth(), middleware.IsAdmin(), userRepository.RegisterHandler)           // Need to be admin
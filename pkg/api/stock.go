@@ -0,0 +1,113 @@
+package api
+
+import (
+	"fmt"
+	"postui_api/pkg/database"
+	"postui_api/pkg/models"
+	"sort"
+
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+)
+
+// takeStock decrements the stock of the product of line, or of its variant,
+// by quantity. It only applies while enough stock is left, so concurrent
+// sales cannot oversell.
+func takeStock(tx database.Database, line models.OrderLine, quantity decimal.Decimal) error {
+	stock := tx.Model(&models.Product{}).Where("id = ? AND stock >= ?", line.ProductID, quantity)
+	if line.VariantID != nil {
+		stock = tx.Model(&models.ProductVariant{}).Where("id = ? AND stock >= ?", *line.VariantID, quantity)
+	}
+	result := stock.Update("stock", gorm.Expr("stock - ?", quantity))
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		if line.VariantID != nil {
+			return fmt.Errorf("%w for variant %d of product %d", errInsufficientStock, *line.VariantID, line.ProductID)
+		}
+		return fmt.Errorf("%w for product %d", errInsufficientStock, line.ProductID)
+	}
+	return nil
+}
+
+// returnStock increments the stock of the product of line, or of its
+// variant, by quantity
+func returnStock(tx database.Database, line models.OrderLine, quantity decimal.Decimal) error {
+	stock := tx.Model(&models.Product{}).Where("id = ?", line.ProductID)
+	if line.VariantID != nil {
+		stock = tx.Model(&models.ProductVariant{}).Where("id = ?", *line.VariantID)
+	}
+	return stock.Update("stock", gorm.Expr("stock + ?", quantity)).Error
+}
+
+// moveStock adjusts the stock taken at checkout for line once it is changed
+// to changed: by the change of quantity when it is still the same product,
+// else by returning the old product and taking the new one
+func moveStock(tx database.Database, line, changed models.OrderLine) error {
+	if !line.Stocked {
+		return nil
+	}
+
+	if line.ProductID == changed.ProductID && variantKey(line) == variantKey(changed) {
+		more := changed.Quantity.Sub(line.Quantity)
+		switch {
+		case more.IsPositive():
+			return takeStock(tx, changed, more)
+		case more.IsNegative():
+			return returnStock(tx, line, more.Neg())
+		}
+		return nil
+	}
+
+	if before(changed, line) {
+		if err := takeStock(tx, changed, changed.Quantity); err != nil {
+			return err
+		}
+		return returnStock(tx, line, line.Quantity)
+	}
+	if err := returnStock(tx, line, line.Quantity); err != nil {
+		return err
+	}
+	return takeStock(tx, changed, changed.Quantity)
+}
+
+// restockLines returns the stock taken at checkout for lines, in product
+// order
+func restockLines(tx database.Database, lines []models.OrderLine) error {
+	for _, line := range byProduct(lines) {
+		if !line.Stocked {
+			continue
+		}
+		if err := returnStock(tx, line, line.Quantity); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// byProduct returns a copy of lines sorted by product and variant. Stock rows
+// are always updated in that order, so that concurrent sales and refunds lock
+// them in the same order and cannot deadlock.
+func byProduct(lines []models.OrderLine) []models.OrderLine {
+	sorted := make([]models.OrderLine, len(lines))
+	copy(sorted, lines)
+	sort.SliceStable(sorted, func(i, j int) bool { return before(sorted[i], sorted[j]) })
+	return sorted
+}
+
+// before reports whether the stock of a is updated before the one of b
+func before(a, b models.OrderLine) bool {
+	if a.ProductID != b.ProductID {
+		return a.ProductID < b.ProductID
+	}
+	return variantKey(a) < variantKey(b)
+}
+
+// variantKey returns the variant of line, 0 for lines without one
+func variantKey(line models.OrderLine) uint {
+	if line.VariantID == nil {
+		return 0
+	}
+	return *line.VariantID
+}
@@ -0,0 +1,39 @@
+package api
+
+import (
+	"postui_api/pkg/database"
+	"postui_api/pkg/models"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestByProduct(t *testing.T) {
+	variant := uint(3)
+	lines := []models.OrderLine{{ID: 1, ProductID: 9}, {ID: 2, ProductID: 4, VariantID: &variant}, {ID: 3, ProductID: 4}}
+
+	sorted := byProduct(lines)
+
+	assert.Equal(t, []uint{3, 2, 1}, []uint{sorted[0].ID, sorted[1].ID, sorted[2].ID})
+	assert.Equal(t, uint(1), lines[0].ID, "The lines should be left in their order")
+}
+
+func TestMoveStockLeavesUntakenStock(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDB := database.NewMockDatabase(ctrl)
+	mockDB.EXPECT().Model(gomock.Any()).Times(0)
+
+	line := models.OrderLine{ID: 1, ProductID: 4, Quantity: decimal.NewFromInt(2)}
+	changed := models.OrderLine{ID: 1, ProductID: 5, Quantity: decimal.NewFromInt(3)}
+
+	// Lines attached to an order never took stock
+	assert.NoError(t, moveStock(mockDB, line, changed))
+
+	// Nothing changed for the stock of a checked out line repriced as is
+	line.Stocked = true
+	assert.NoError(t, moveStock(mockDB, line, line))
+}
@@ -0,0 +1,176 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"postui_api/pkg/barcode"
+	"postui_api/pkg/database"
+	"postui_api/pkg/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lib/pq"
+	"gorm.io/gorm"
+)
+
+type StoreRepository interface {
+	FindStoreSettings(c *gin.Context)
+	UpdateStoreSettings(c *gin.Context)
+}
+
+// storeRepository holds shared resources like database
+type storeRepository struct {
+	DB  database.Database
+	Ctx *context.Context
+}
+
+// NewStoreRepository creates a new storeRepository
+func NewStoreRepository(db database.Database, ctx *context.Context) *storeRepository {
+	return &storeRepository{
+		DB:  db,
+		Ctx: ctx,
+	}
+}
+
+// @BasePath /api/v1
+
+// FindStoreSettings godoc
+// @Summary Get the store settings
+// @Description Get the store details and receipt layout. Defaults are returned until the store is configured.
+// @Tags store
+// @Security JwtAuth
+// @Produce  json
+// @Success 200 {object} models.StoreSettings "Successfully retrieved settings"
+// @Failure 500 {string} string "Internal Server Error"
+// @Router /store [get]
+func (r *storeRepository) FindStoreSettings(c *gin.Context) {
+	settings, _, err := loadStoreSettings(r.DB)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": settings})
+}
+
+// UpdateStoreSettings godoc
+// @Summary Update the store settings
+// @Description Update the store details and receipt layout. Omitted fields are left unchanged.
+// @Tags store
+// @Security JwtAuth
+// @Accept  json
+// @Produce  json
+// @Param   input     body   models.UpdateStoreSettings   true   "Store settings"
+// @Success 200 {object} models.StoreSettings "Successfully updated settings"
+// @Failure 400 {string} string "Bad Request"
+// @Failure 422 {string} string "invalid barcode format"
+// @Failure 500 {string} string "Internal Server Error"
+// @Router /store [put]
+func (r *storeRepository) UpdateStoreSettings(c *gin.Context) {
+	var input models.UpdateStoreSettings
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	settings, stored, err := loadStoreSettings(r.DB)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	changes := models.StoreSettings{Name: input.Name, TaxID: input.TaxID, Width: input.Width, CodePage: input.CodePage, Code: input.Code, InvoicePrefix: input.InvoicePrefix, RefundPrefix: input.RefundPrefix, GiftCardMonths: input.GiftCardMonths,
+		LoyaltyPerUnit: input.LoyaltyPerUnit, LoyaltyValue: input.LoyaltyValue, LoyaltyMonths: input.LoyaltyMonths}
+	if input.HeaderLines != nil {
+		changes.HeaderLines = pq.StringArray(input.HeaderLines)
+	}
+	if input.FooterLines != nil {
+		changes.FooterLines = pq.StringArray(input.FooterLines)
+	}
+	if input.BarcodeFormats != nil {
+		for _, format := range input.BarcodeFormats {
+			if err := barcode.ValidateFormat(format); err != nil {
+				c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+				return
+			}
+		}
+		changes.BarcodeFormats = models.BarcodeFormats(input.BarcodeFormats)
+	}
+
+	if stored {
+		err = r.DB.Model(&settings).Updates(changes).Error
+	} else {
+		mergeStoreSettings(&settings, changes)
+		err = r.DB.Create(&settings).Error
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+	mergeStoreSettings(&settings, changes)
+
+	c.JSON(http.StatusOK, gin.H{"data": settings})
+}
+
+// loadStoreSettings returns the store settings and whether they were stored,
+// or the defaults when the store was never configured
+func loadStoreSettings(db database.Database) (models.StoreSettings, bool, error) {
+	var settings models.StoreSettings
+
+	if err := db.Where("id = ?", models.StoreSettingsID).First(&settings).Error(); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return models.DefaultStoreSettings(), false, nil
+		}
+		return settings, false, err
+	}
+
+	return settings, true, nil
+}
+
+// mergeStoreSettings copies the non-zero fields of changes into settings,
+// like Updates does in the database
+func mergeStoreSettings(settings *models.StoreSettings, changes models.StoreSettings) {
+	if changes.Name != "" {
+		settings.Name = changes.Name
+	}
+	if changes.TaxID != "" {
+		settings.TaxID = changes.TaxID
+	}
+	if changes.HeaderLines != nil {
+		settings.HeaderLines = changes.HeaderLines
+	}
+	if changes.FooterLines != nil {
+		settings.FooterLines = changes.FooterLines
+	}
+	if changes.Width != 0 {
+		settings.Width = changes.Width
+	}
+	if changes.CodePage != 0 {
+		settings.CodePage = changes.CodePage
+	}
+	if changes.Code != "" {
+		settings.Code = changes.Code
+	}
+	if changes.InvoicePrefix != "" {
+		settings.InvoicePrefix = changes.InvoicePrefix
+	}
+	if changes.RefundPrefix != "" {
+		settings.RefundPrefix = changes.RefundPrefix
+	}
+	if changes.GiftCardMonths != 0 {
+		settings.GiftCardMonths = changes.GiftCardMonths
+	}
+	if changes.LoyaltyPerUnit != 0 {
+		settings.LoyaltyPerUnit = changes.LoyaltyPerUnit
+	}
+	if changes.LoyaltyValue != 0 {
+		settings.LoyaltyValue = changes.LoyaltyValue
+	}
+	if changes.LoyaltyMonths != 0 {
+		settings.LoyaltyMonths = changes.LoyaltyMonths
+	}
+	if changes.BarcodeFormats != nil {
+		settings.BarcodeFormats = changes.BarcodeFormats
+	}
+}
@@ -0,0 +1,220 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"postui_api/pkg/database"
+	"postui_api/pkg/models"
+	"postui_api/pkg/payments"
+	"postui_api/pkg/pricing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"gorm.io/gorm"
+)
+
+var (
+	errInvalidSyncOrder = errors.New("invalid order")
+	errOfflineTender    = errors.New("tender cannot be used offline")
+)
+
+type SyncRepository interface {
+	SyncOrders(c *gin.Context)
+}
+
+// syncRepository holds shared resources like database
+type syncRepository struct {
+	DB  database.Database
+	Ctx *context.Context
+}
+
+// NewSyncRepository creates a new syncRepository
+func NewSyncRepository(db database.Database, ctx *context.Context) *syncRepository {
+	return &syncRepository{
+		DB:  db,
+		Ctx: ctx,
+	}
+}
+
+// @BasePath /api/v1
+
+// SyncOrders godoc
+// @Summary Sync orders sold offline
+// @Description Record a batch of orders a terminal sold while it could not reach the API, in the given order. Each order is applied on its own: its lines are priced from the catalog with the promotions current at its local time, stock is decremented and its payments are recorded, paying it when they cover its total. Orders already synced, including by a concurrent sync, are reported as duplicates. Orders that cannot be recorded, for example because a product was deleted or is out of stock, are reported as rejected with the reason, without undoing the others. Register sessions are not checked, as they may have been closed in the meantime.
+// @Tags sync
+// @Security JwtAuth
+// @Accept  json
+// @Produce  json
+// @Param   input     body   models.SyncOrders   true   "Orders sold offline"
+// @Success 200 {array} models.SyncResult "What became of each order"
+// @Failure 400 {string} string "Bad Request"
+// @Failure 401 {string} string "Unauthorized"
+// @Router /sync/orders [post]
+func (r *syncRepository) SyncOrders(c *gin.Context) {
+	appCtx, exists := c.MustGet("appCtxSync").(*syncRepository)
+	if !exists {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	var input models.SyncOrders
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	results := make([]models.SyncResult, 0, len(input.Orders))
+	for _, order := range input.Orders {
+		result, err := syncOrder(appCtx.DB, order, c.GetString("username"))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+			return
+		}
+		results = append(results, result)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": results})
+}
+
+// syncOrder records an order sold offline in a transaction of its own, so a
+// rejected order does not undo the others. Only failures the terminal cannot
+// do anything about are returned as errors.
+func syncOrder(db database.Database, input models.SyncOrder, cashier string) (models.SyncResult, error) {
+	result := models.SyncResult{ClientID: input.ClientID}
+
+	err := checkSyncOrder(input)
+	if err == nil {
+		err = db.Transaction(func(tx database.Database) error {
+			existing, found, err := findSyncedOrder(tx, input.ClientID)
+			if err != nil {
+				return err
+			}
+			if found {
+				result.Status = models.SyncDuplicate
+				result.OrderID = &existing.ID
+				return nil
+			}
+
+			order, err := applySyncOrder(tx, input, cashier)
+			if err != nil {
+				return err
+			}
+
+			result.Status = models.SyncAccepted
+			result.OrderID = &order.ID
+			return nil
+		})
+	}
+
+	// A concurrent sync of the same order committed it first
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		existing, found, findErr := findSyncedOrder(db, input.ClientID)
+		if findErr != nil {
+			return result, findErr
+		}
+		if found {
+			return models.SyncResult{ClientID: input.ClientID, Status: models.SyncDuplicate, OrderID: &existing.ID}, nil
+		}
+	}
+
+	if err != nil {
+		if !syncRejection(err) {
+			return result, err
+		}
+		return models.SyncResult{ClientID: input.ClientID, Status: models.SyncRejected, Reason: err.Error()}, nil
+	}
+	return result, nil
+}
+
+// findSyncedOrder returns the order already synced with clientID, if any
+func findSyncedOrder(db database.Database, clientID string) (models.Order, bool, error) {
+	var existing models.Order
+
+	if err := db.Where("client_id = ?", clientID).First(&existing).Error(); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return existing, false, nil
+		}
+		return existing, false, err
+	}
+	return existing, true, nil
+}
+
+// checkSyncOrder validates an order of a batch on its own, so that a single
+// malformed order is rejected rather than the whole batch
+func checkSyncOrder(input models.SyncOrder) error {
+	if err := binding.Validator.ValidateStruct(&input); err != nil {
+		return fmt.Errorf("%w: %s", errInvalidSyncOrder, err.Error())
+	}
+
+	for _, payment := range input.Payments {
+		if payment.Tender == models.TenderGiftCard || payment.Tender == models.TenderLoyalty {
+			return fmt.Errorf("%w: %s", errOfflineTender, payment.Tender)
+		}
+	}
+	return nil
+}
+
+// applySyncOrder checks out an order sold offline at its local time and
+// records its payments, paying it when they cover its total
+func applySyncOrder(tx database.Database, input models.SyncOrder, cashier string) (models.Order, error) {
+	checkout := models.Checkout{CustomerID: input.CustomerID, CustomerName: input.CustomerName, LoyaltyID: input.LoyaltyID, CashoutNumber: input.CashoutNumber, Lines: input.Lines}
+
+	detail, err := checkoutOrder(tx, checkout, cashier, input.CreatedAt, &input.ClientID)
+	if err != nil {
+		return models.Order{}, err
+	}
+	order := detail.Order
+
+	if err := pricing.CheckTotal(order.Total, input.Total); err != nil {
+		return order, err
+	}
+
+	var recorded []models.Payment
+	for _, item := range input.Payments {
+		payment, err := payments.Apply(payments.Due(order.Total, recorded), item)
+		if err != nil {
+			return order, err
+		}
+		payment.OrderID = order.ID
+		payment.CreatedAt = input.CreatedAt
+
+		if err := tx.Create(&payment).Error; err != nil {
+			return order, err
+		}
+		recorded = append(recorded, payment)
+	}
+
+	if payments.Due(order.Total, recorded) > 0 {
+		return order, nil
+	}
+
+	if err := transitionOrder(tx, &order, models.OrderPaid, "", cashier); err != nil {
+		return order, err
+	}
+	// The order was paid when it was sold, maybe in a year that has ended
+	if err := issueInvoice(tx, &order, input.CreatedAt); err != nil {
+		return order, err
+	}
+	if err := issueGiftCards(tx, order); err != nil {
+		return order, err
+	}
+	return order, accrueLoyaltyPoints(tx, order)
+}
+
+// syncRejection reports whether err means the order itself cannot be
+// recorded, as opposed to a failure of the server
+func syncRejection(err error) bool {
+	for _, target := range []error{
+		errInvalidSyncOrder, errOfflineTender, errProductNotFound, errVariantNotFound, errVariantRequired, errInvalidQuantity, errInsufficientStock, errCustomerNotFound,
+		pricing.ErrPriceMismatch, pricing.ErrAmountOutOfRange, pricing.ErrCurrencyMismatch, pricing.ErrNoTaxRate,
+		payments.ErrNothingDue, payments.ErrOverpayment, payments.ErrInsufficientTendered,
+	} {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,189 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"postui_api/pkg/database"
+	"postui_api/pkg/models"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func TestNewSyncRepository(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDB := database.NewMockDatabase(ctrl)
+	mockCtx := context.Background()
+
+	repo := NewSyncRepository(mockDB, &mockCtx)
+
+	assert.NotNil(t, repo, "NewSyncRepository should return a non-nil instance of syncRepository")
+	assert.Equal(t, mockDB, repo.DB, "DB should be set to the mock database instance")
+}
+
+func TestSyncOrdersRequiresOrders(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDB := database.NewMockDatabase(ctrl)
+	ctx := context.Background()
+	repo := NewSyncRepository(mockDB, &ctx)
+
+	// Set up Gin
+	gin.SetMode(gin.TestMode)
+	r := gin.Default()
+	r.POST("/sync/orders", func(c *gin.Context) {
+		c.Set("appCtxSync", repo)
+		repo.SyncOrders(c)
+	})
+
+	mockDB.EXPECT().Transaction(gomock.Any()).Times(0)
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/sync/orders", bytes.NewBufferString(`{"orders":[]}`))
+	req.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestSyncOrdersReportsEachOrder(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDB := database.NewMockDatabase(ctrl)
+	ctx := context.Background()
+	repo := NewSyncRepository(mockDB, &ctx)
+
+	// Set up Gin
+	gin.SetMode(gin.TestMode)
+	r := gin.Default()
+	r.POST("/sync/orders", func(c *gin.Context) {
+		c.Set("appCtxSync", repo)
+		repo.SyncOrders(c)
+	})
+
+	soldAt := time.Date(2026, 3, 2, 10, 30, 0, 0, time.UTC)
+	lines := []models.CheckoutLine{{ProductID: 42, Quantity: decimal.NewFromInt(1)}}
+	input := models.SyncOrders{Orders: []models.SyncOrder{
+		// Already synced before the connection dropped again
+		{ClientID: "7f1c7d6e-3b8a-4f5e-9a51-2f0b6d1e8c01", CreatedAt: soldAt, CustomerName: "username", CashoutNumber: 1, Lines: lines},
+		// Not a UUID
+		{ClientID: "order-2", CreatedAt: soldAt, CustomerName: "username", CashoutNumber: 1, Lines: lines},
+		// Sells a product deleted in the meantime
+		{ClientID: "7f1c7d6e-3b8a-4f5e-9a51-2f0b6d1e8c03", CreatedAt: soldAt, CustomerName: "username", CashoutNumber: 1, Lines: lines},
+	}}
+
+	requestBody, err := json.Marshal(input)
+	if err != nil {
+		t.Fatalf("Failed to marshal sync data: %v", err)
+	}
+
+	// Each valid order runs in its own transaction
+	mockDB.EXPECT().
+		Transaction(gomock.Any()).
+		DoAndReturn(func(fc func(database.Database) error) error {
+			return fc(mockDB)
+		}).Times(2)
+
+	mockDB.EXPECT().Where("client_id = ?", "7f1c7d6e-3b8a-4f5e-9a51-2f0b6d1e8c01").Return(mockDB).Times(1)
+	mockDB.EXPECT().
+		First(gomock.Any()).
+		DoAndReturn(func(dest interface{}, conds ...interface{}) database.Database {
+			if b, ok := dest.(*models.Order); ok {
+				*b = models.Order{ID: 5}
+			}
+			return mockDB
+		}).Times(1)
+	mockDB.EXPECT().Error().Return(nil).Times(1)
+
+	mockDB.EXPECT().Where("client_id = ?", "7f1c7d6e-3b8a-4f5e-9a51-2f0b6d1e8c03").Return(mockDB).Times(1)
+	mockDB.EXPECT().Where("id = ?", uint(42)).Return(mockDB).Times(1)
+	mockDB.EXPECT().First(gomock.Any()).Return(mockDB).Times(2)
+	mockDB.EXPECT().Error().Return(gorm.ErrRecordNotFound).Times(2)
+
+	// Nothing must be written for the rejected orders
+	mockDB.EXPECT().Create(gomock.Any()).Times(0)
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/sync/orders", bytes.NewBuffer(requestBody))
+	req.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response struct {
+		Data []models.SyncResult `json:"data"`
+	}
+
+	assert.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+	if assert.Len(t, response.Data, 3) {
+		assert.Equal(t, models.SyncDuplicate, response.Data[0].Status)
+		if assert.NotNil(t, response.Data[0].OrderID) {
+			assert.Equal(t, uint(5), *response.Data[0].OrderID)
+		}
+		assert.Equal(t, models.SyncRejected, response.Data[1].Status)
+		assert.Contains(t, response.Data[1].Reason, "invalid order")
+		assert.Equal(t, models.SyncRejected, response.Data[2].Status)
+		assert.Contains(t, response.Data[2].Reason, "product not found: 42")
+	}
+}
+
+func TestSyncOrderRefusesOnlineTenders(t *testing.T) {
+	input := models.SyncOrder{
+		ClientID:      "7f1c7d6e-3b8a-4f5e-9a51-2f0b6d1e8c01",
+		CreatedAt:     time.Now(),
+		CustomerName:  "username",
+		CashoutNumber: 1,
+		Lines:         []models.CheckoutLine{{ProductID: 1, Quantity: decimal.NewFromInt(1)}},
+		Payments:      []models.CreatePayment{{Tender: models.TenderGiftCard, Reference: "GC-1"}},
+	}
+
+	err := checkSyncOrder(input)
+	assert.ErrorIs(t, err, errOfflineTender)
+	assert.True(t, syncRejection(err))
+}
+
+func TestSyncOrderSyncedConcurrently(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDB := database.NewMockDatabase(ctrl)
+
+	input := models.SyncOrder{
+		ClientID:      "7f1c7d6e-3b8a-4f5e-9a51-2f0b6d1e8c01",
+		CreatedAt:     time.Now(),
+		CustomerName:  "username",
+		CashoutNumber: 1,
+		Lines:         []models.CheckoutLine{{ProductID: 1, Quantity: decimal.NewFromInt(1)}},
+	}
+
+	// Another sync of the order inserted its client id first
+	mockDB.EXPECT().Transaction(gomock.Any()).Return(gorm.ErrDuplicatedKey).Times(1)
+
+	mockDB.EXPECT().Where("client_id = ?", input.ClientID).Return(mockDB).Times(1)
+	mockDB.EXPECT().
+		First(gomock.Any()).
+		DoAndReturn(func(dest interface{}, conds ...interface{}) database.Database {
+			*dest.(*models.Order) = models.Order{ID: 6}
+			return mockDB
+		}).Times(1)
+	mockDB.EXPECT().Error().Return(nil).Times(1)
+
+	result, err := syncOrder(mockDB, input, "username")
+	assert.NoError(t, err)
+	assert.Equal(t, models.SyncDuplicate, result.Status)
+	if assert.NotNil(t, result.OrderID) {
+		assert.Equal(t, uint(6), *result.OrderID)
+	}
+}
@@ -0,0 +1,279 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"postui_api/pkg/database"
+	"postui_api/pkg/models"
+	"postui_api/pkg/pricing"
+	"sort"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+var (
+	errTaxCategoryNotFound = errors.New("tax category not found")
+	errTaxCategoryExists   = errors.New("tax category already exists")
+	errTaxRateInPast       = errors.New("rates cannot take effect in the past")
+)
+
+type TaxRepository interface {
+	FindTaxCategories(c *gin.Context)
+	CreateTaxCategory(c *gin.Context)
+	FindTaxCategory(c *gin.Context)
+	AddTaxRate(c *gin.Context)
+}
+
+// taxRepository holds shared resources like database
+type taxRepository struct {
+	DB  database.Database
+	Ctx *context.Context
+}
+
+// NewTaxRepository creates a new taxRepository
+func NewTaxRepository(db database.Database, ctx *context.Context) *taxRepository {
+	return &taxRepository{
+		DB:  db,
+		Ctx: ctx,
+	}
+}
+
+// @BasePath /api/v1
+
+// FindTaxCategories godoc
+// @Summary Get all tax categories
+// @Description Get every tax category with its rates, oldest rate first
+// @Tags taxes
+// @Security JwtAuth
+// @Produce json
+// @Success 200 {array} models.TaxCategory "Successfully retrieved list of tax categories"
+// @Failure 500 {string} string "Internal Server Error"
+// @Router /tax_categories [get]
+func (r *taxRepository) FindTaxCategories(c *gin.Context) {
+	var found []models.TaxCategory
+
+	if err := r.DB.Order("name").Find(&found).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	if err := attachTaxRates(r.DB, found); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": found})
+}
+
+// CreateTaxCategory godoc
+// @Summary Create a tax category
+// @Description Create a tax category with its rate schedule. Products of the category are taxed at the rate in effect on the day they are sold.
+// @Tags taxes
+// @Security JwtAuth
+// @Accept  json
+// @Produce  json
+// @Param   input     body   models.CreateTaxCategory   true   "Tax category object"
+// @Success 201 {object} models.TaxCategory "Successfully created tax category"
+// @Failure 400 {string} string "Bad Request"
+// @Failure 409 {string} string "tax category already exists"
+// @Router /tax_categories [post]
+func (r *taxRepository) CreateTaxCategory(c *gin.Context) {
+	appCtx, exists := c.MustGet("appCtxTax").(*taxRepository)
+	if !exists {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	var input models.CreateTaxCategory
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var existing models.TaxCategory
+	if err := appCtx.DB.Where("name = ?", input.Name).First(&existing).Error(); err == nil {
+		c.JSON(http.StatusConflict, gin.H{"error": fmt.Sprintf("%s: %s", errTaxCategoryExists, input.Name)})
+		return
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	// The rates are created along with the category
+	category := models.TaxCategory{Name: input.Name}
+	for _, rate := range input.Rates {
+		category.Rates = append(category.Rates, models.TaxRate{Vat: rate.Vat, Surcharge: rate.Surcharge, ValidFrom: rate.ValidFrom})
+	}
+	sortTaxRates(category.Rates)
+
+	if err := appCtx.DB.Create(&category).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"data": category})
+}
+
+// FindTaxCategory godoc
+// @Summary Find a tax category by ID
+// @Description Get a tax category with its rates, oldest rate first
+// @Tags taxes
+// @Security JwtAuth
+// @Produce json
+// @Param id path string true "Tax category ID"
+// @Success 200 {object} models.TaxCategory "Successfully retrieved tax category"
+// @Failure 404 {string} string "tax category not found"
+// @Router /tax_categories/{id} [get]
+func (r *taxRepository) FindTaxCategory(c *gin.Context) {
+	category, ok := r.findTaxCategory(c)
+	if !ok {
+		return
+	}
+
+	categories := []models.TaxCategory{category}
+	if err := attachTaxRates(r.DB, categories); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": categories[0]})
+}
+
+// AddTaxRate godoc
+// @Summary Schedule a rate change of a tax category
+// @Description Add a rate to a tax category, taking over from the current one at valid_from. Sales already made keep the rate they were made at, so rates cannot take effect in the past.
+// @Tags taxes
+// @Security JwtAuth
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Tax category ID"
+// @Param   input     body   models.CreateTaxRate   true   "Tax rate object"
+// @Success 201 {object} models.TaxRate "Successfully added tax rate"
+// @Failure 400 {string} string "Bad Request"
+// @Failure 404 {string} string "tax category not found"
+// @Failure 422 {string} string "rates cannot take effect in the past"
+// @Router /tax_categories/{id}/rates [post]
+func (r *taxRepository) AddTaxRate(c *gin.Context) {
+	category, ok := r.findTaxCategory(c)
+	if !ok {
+		return
+	}
+
+	var input models.CreateTaxRate
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if input.ValidFrom.Before(time.Now()) {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": errTaxRateInPast.Error()})
+		return
+	}
+
+	rate := models.TaxRate{TaxCategoryID: category.ID, Vat: input.Vat, Surcharge: input.Surcharge, ValidFrom: input.ValidFrom}
+	if err := r.DB.Create(&rate).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"data": rate})
+}
+
+// findTaxCategory loads the tax category of the id path parameter, or
+// responds with an error
+func (r *taxRepository) findTaxCategory(c *gin.Context) (models.TaxCategory, bool) {
+	var category models.TaxCategory
+
+	if err := r.DB.Where("id = ?", c.Param("id")).First(&category).Error(); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": errTaxCategoryNotFound.Error()})
+			return category, false
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return category, false
+	}
+
+	return category, true
+}
+
+// attachTaxRates loads the rates of categories, oldest first
+func attachTaxRates(db database.Database, categories []models.TaxCategory) error {
+	if len(categories) == 0 {
+		return nil
+	}
+
+	ids := make([]uint, len(categories))
+	for i, category := range categories {
+		ids[i] = category.ID
+	}
+
+	var rates []models.TaxRate
+	if err := db.Where("tax_category_id IN ?", ids).Find(&rates).Error; err != nil {
+		return err
+	}
+	sortTaxRates(rates)
+
+	for i := range categories {
+		categories[i].Rates = []models.TaxRate{}
+		for _, rate := range rates {
+			if rate.TaxCategoryID == categories[i].ID {
+				categories[i].Rates = append(categories[i].Rates, rate)
+			}
+		}
+	}
+	return nil
+}
+
+// sortTaxRates orders rates by the time they take effect
+func sortTaxRates(rates []models.TaxRate) {
+	sort.SliceStable(rates, func(i, j int) bool { return rates[i].ValidFrom.Before(rates[j].ValidFrom) })
+}
+
+// effectiveTaxRate returns the rate of a tax category in effect at the given
+// time
+func effectiveTaxRate(db database.Database, categoryID uint, at time.Time) (models.TaxRate, error) {
+	var rates []models.TaxRate
+
+	if err := db.Where("tax_category_id = ?", categoryID).Find(&rates).Error; err != nil {
+		return models.TaxRate{}, err
+	}
+
+	rate, err := pricing.EffectiveRate(rates, at)
+	if err != nil {
+		return rate, fmt.Errorf("%w for tax category %d", err, categoryID)
+	}
+	return rate, nil
+}
+
+// checkTaxCategory fails with errTaxCategoryNotFound when a product refers
+// to a tax category that does not exist
+func checkTaxCategory(db database.Database, categoryID *uint) error {
+	if categoryID == nil {
+		return nil
+	}
+
+	var category models.TaxCategory
+	if err := db.Where("id = ?", *categoryID).First(&category).Error(); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("%w: %d", errTaxCategoryNotFound, *categoryID)
+		}
+		return err
+	}
+	return nil
+}
+
+// respondTaxCategoryError maps an error returned by checkTaxCategory to a
+// response
+func respondTaxCategoryError(c *gin.Context, err error) {
+	if errors.Is(err, errTaxCategoryNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+}
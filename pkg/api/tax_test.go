@@ -0,0 +1,135 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"postui_api/pkg/database"
+	"postui_api/pkg/models"
+	"postui_api/pkg/pricing"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func TestNewTaxRepository(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDB := database.NewMockDatabase(ctrl)
+	mockCtx := context.Background()
+
+	repo := NewTaxRepository(mockDB, &mockCtx)
+
+	assert.NotNil(t, repo, "NewTaxRepository should return a non-nil instance of taxRepository")
+	assert.Equal(t, mockDB, repo.DB, "DB should be set to the mock database instance")
+}
+
+func TestCreateTaxCategoryExisting(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDB := database.NewMockDatabase(ctrl)
+	ctx := context.Background()
+	repo := NewTaxRepository(mockDB, &ctx)
+
+	// Set up Gin
+	gin.SetMode(gin.TestMode)
+	r := gin.Default()
+	r.POST("/tax_categories", func(c *gin.Context) {
+		c.Set("appCtxTax", repo)
+		repo.CreateTaxCategory(c)
+	})
+
+	mockDB.EXPECT().Where("name = ?", "reduced").Return(mockDB).Times(1)
+	mockDB.EXPECT().First(gomock.Any()).Return(mockDB).Times(1)
+	mockDB.EXPECT().Error().Return(nil).Times(1)
+	mockDB.EXPECT().Create(gomock.Any()).Times(0)
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/tax_categories", bytes.NewBufferString(`{"name":"reduced","rates":[{"vat":1000,"valid_from":"2012-09-01T00:00:00Z"}]}`))
+	req.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+}
+
+func TestAddTaxRateInPast(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDB := database.NewMockDatabase(ctrl)
+	ctx := context.Background()
+	repo := NewTaxRepository(mockDB, &ctx)
+
+	// Set up Gin
+	gin.SetMode(gin.TestMode)
+	r := gin.Default()
+	r.POST("/tax_categories/:id/rates", repo.AddTaxRate)
+
+	mockDB.EXPECT().Where("id = ?", "2").Return(mockDB).Times(1)
+	mockDB.EXPECT().
+		First(gomock.Any()).
+		DoAndReturn(func(dest interface{}, conds ...interface{}) database.Database {
+			if b, ok := dest.(*models.TaxCategory); ok {
+				*b = models.TaxCategory{ID: 2, Name: "reduced"}
+			}
+			return mockDB
+		}).Times(1)
+	mockDB.EXPECT().Error().Return(nil).Times(1)
+
+	// Sales were already made at the current rate
+	mockDB.EXPECT().Create(gomock.Any()).Times(0)
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/tax_categories/2/rates", bytes.NewBufferString(`{"vat":500,"valid_from":"2020-01-01T00:00:00Z"}`))
+	req.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	assert.Contains(t, w.Body.String(), "rates cannot take effect in the past")
+}
+
+func TestPriceOrderLineTaxCategory(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDB := database.NewMockDatabase(ctrl)
+
+	categoryID := uint(2)
+	product := models.Product{ID: 3, Price: 1262, Currency: "EUR", Vat: 2100, TaxCategoryID: &categoryID}
+	rates := []models.TaxRate{
+		{ID: 1, TaxCategoryID: 2, Vat: 2100, Surcharge: 520, ValidFrom: time.Date(2012, 9, 1, 0, 0, 0, 0, time.UTC)},
+		{ID: 2, TaxCategoryID: 2, Vat: 1000, Surcharge: 140, ValidFrom: time.Date(2026, 7, 1, 0, 0, 0, 0, time.UTC)},
+	}
+
+	mockDB.EXPECT().Where("id = ?", uint(3)).Return(mockDB).Times(2)
+	mockDB.EXPECT().
+		First(gomock.Any()).
+		DoAndReturn(func(dest interface{}, conds ...interface{}) database.Database {
+			*dest.(*models.Product) = product
+			return mockDB
+		}).Times(2)
+	mockDB.EXPECT().Error().Return(nil).Times(2)
+	mockDB.EXPECT().Where("tax_category_id = ?", uint(2)).Return(mockDB).Times(2)
+	mockDB.EXPECT().Find(gomock.Any()).DoAndReturn(func(dest interface{}, conds ...interface{}) *gorm.DB {
+		*dest.(*[]models.TaxRate) = rates
+		return &gorm.DB{Error: nil}
+	}).Times(2)
+
+	// The rate in effect on the day of the sale applies
+	line, _, err := priceOrderLine(mockDB, 3, nil, decimal.NewFromInt(1), time.Date(2026, 6, 30, 12, 0, 0, 0, time.UTC))
+	assert.NoError(t, err)
+	assert.Equal(t, uint16(2100), line.Vat)
+	assert.Equal(t, uint16(520), line.Surcharge)
+
+	_, _, err = priceOrderLine(mockDB, 3, nil, decimal.NewFromInt(1), time.Date(2010, 1, 1, 0, 0, 0, 0, time.UTC))
+	assert.True(t, errors.Is(err, pricing.ErrNoTaxRate))
+}
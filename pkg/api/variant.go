@@ -0,0 +1,425 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"postui_api/pkg/database"
+	"postui_api/pkg/models"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+var (
+	errVariantNotFound = errors.New("product variant not found")
+	errVariantRequired = errors.New("product is sold by variant")
+	errInvalidVariant  = errors.New("invalid variant")
+	errVariantExists   = errors.New("variant already exists")
+)
+
+// CreateProductVariant godoc
+// @Summary Add a variant to a product
+// @Description Add a variant to a product sold by variant, with a value for each of the variant attributes of the product. The variant has its own SKU, barcode and stock, and sells at the price of the product unless it sets its own.
+// @Tags products
+// @Security JwtAuth
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Product ID"
+// @Param   input     body   models.CreateProductVariant   true   "Product variant object"
+// @Success 201 {object} models.ProductVariant "Successfully created product variant"
+// @Failure 400 {string} string "Bad Request"
+// @Failure 404 {string} string "product not found"
+// @Failure 409 {string} string "SKU, options or barcode already taken"
+// @Failure 422 {string} string "Options do not match the variant attributes of the product"
+// @Router /products/{id}/variants [post]
+func (r *productRepository) CreateProductVariant(c *gin.Context) {
+	product, ok := r.findVariantProduct(c)
+	if !ok {
+		return
+	}
+
+	var input models.CreateProductVariant
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	variant := models.ProductVariant{ProductID: product.ID, SKU: strings.TrimSpace(input.SKU), Options: input.Options, Barcode: strings.TrimSpace(input.Barcode), Price: input.Price, Stock: input.Stock}
+
+	if err := checkProductVariant(r.DB, product, variant); err != nil {
+		respondVariantError(c, err)
+		return
+	}
+
+	err := r.DB.Transaction(func(tx database.Database) error {
+		if err := tx.Create(&variant).Error; err != nil {
+			return err
+		}
+		if err := replaceVariantBarcode(tx, variant); err != nil {
+			return err
+		}
+		return touchProduct(tx, product.ID)
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	r.invalidateProductPages()
+
+	c.JSON(http.StatusCreated, gin.H{"data": variant})
+}
+
+// UpdateProductVariant godoc
+// @Summary Update a variant of a product
+// @Description Update the SKU, options, barcode, price or stock of a variant. Stock can be set to zero, and clear_price sells the variant at the price of its product again.
+// @Tags products
+// @Security JwtAuth
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Product ID"
+// @Param variant_id path string true "Product variant ID"
+// @Param   input     body   models.UpdateProductVariant   true   "Update product variant object"
+// @Success 200 {object} models.ProductVariant "Successfully updated product variant"
+// @Failure 400 {string} string "Bad Request"
+// @Failure 404 {string} string "product or variant not found"
+// @Failure 409 {string} string "SKU, options or barcode already taken"
+// @Failure 422 {string} string "Options do not match the variant attributes of the product"
+// @Router /products/{id}/variants/{variant_id} [put]
+func (r *productRepository) UpdateProductVariant(c *gin.Context) {
+	product, variant, ok := r.findProductVariant(c)
+	if !ok {
+		return
+	}
+
+	var input models.UpdateProductVariant
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	previousBarcode := variant.Barcode
+	updated := variant
+	if sku := strings.TrimSpace(input.SKU); sku != "" {
+		updated.SKU = sku
+	}
+	if input.Options != nil {
+		updated.Options = input.Options
+	}
+	if barcode := strings.TrimSpace(input.Barcode); barcode != "" {
+		updated.Barcode = barcode
+	}
+	if input.Price != nil {
+		updated.Price = input.Price
+	} else if input.ClearPrice {
+		updated.Price = nil
+	}
+	if input.Stock != nil {
+		updated.Stock = *input.Stock
+	}
+
+	if err := checkProductVariant(r.DB, product, updated); err != nil {
+		respondVariantError(c, err)
+		return
+	}
+
+	err := r.DB.Transaction(func(tx database.Database) error {
+		// A map is saved so that the stock can be set to zero and the price removed
+		err := tx.Model(&variant).Updates(map[string]interface{}{
+			"sku":     updated.SKU,
+			"options": updated.Options,
+			"barcode": updated.Barcode,
+			"price":   updated.Price,
+			"stock":   updated.Stock,
+		}).Error
+		if err != nil {
+			return err
+		}
+		if updated.Barcode != previousBarcode {
+			if err := replaceVariantBarcode(tx, updated); err != nil {
+				return err
+			}
+		}
+		return touchProduct(tx, product.ID)
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	r.invalidateProductPages()
+	invalidateBarcodes(r.RedisClient, *r.Ctx, []models.ProductBarcode{{Code: previousBarcode}, {Code: updated.Barcode}})
+
+	c.JSON(http.StatusOK, gin.H{"data": updated})
+}
+
+// DeleteProductVariant godoc
+// @Summary Delete a variant of a product
+// @Description Delete a variant of a product. Past order lines keep referring to it; its barcode is freed for other products.
+// @Tags products
+// @Security JwtAuth
+// @Produce json
+// @Param id path string true "Product ID"
+// @Param variant_id path string true "Product variant ID"
+// @Success 204 {string} string "Successfully deleted product variant"
+// @Failure 404 {string} string "product or variant not found"
+// @Router /products/{id}/variants/{variant_id} [delete]
+func (r *productRepository) DeleteProductVariant(c *gin.Context) {
+	product, variant, ok := r.findProductVariant(c)
+	if !ok {
+		return
+	}
+
+	err := r.DB.Transaction(func(tx database.Database) error {
+		if err := tx.Where("variant_id = ?", variant.ID).Delete(&models.ProductBarcode{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Delete(&variant).Error; err != nil {
+			return err
+		}
+		return touchProduct(tx, product.ID)
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	r.invalidateProductPages()
+	invalidateBarcodes(r.RedisClient, *r.Ctx, []models.ProductBarcode{{Code: variant.Barcode}})
+
+	c.JSON(http.StatusNoContent, gin.H{"data": true})
+}
+
+// findVariantProduct loads the product of the id path parameter, or responds
+// with an error
+func (r *productRepository) findVariantProduct(c *gin.Context) (models.Product, bool) {
+	var product models.Product
+
+	if err := r.DB.Where("id = ?", c.Param("id")).First(&product).Error(); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "product not found"})
+			return product, false
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return product, false
+	}
+
+	return product, true
+}
+
+// findProductVariant loads the product and variant of the path parameters,
+// or responds with an error
+func (r *productRepository) findProductVariant(c *gin.Context) (models.Product, models.ProductVariant, bool) {
+	var variant models.ProductVariant
+
+	product, ok := r.findVariantProduct(c)
+	if !ok {
+		return product, variant, false
+	}
+
+	if err := r.DB.Where("id = ? AND product_id = ?", c.Param("variant_id"), product.ID).First(&variant).Error(); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": errVariantNotFound.Error()})
+			return product, variant, false
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return product, variant, false
+	}
+
+	return product, variant, true
+}
+
+// checkProductVariant fails when variant does not fit product, or takes the
+// SKU, options or barcode of another variant or product
+func checkProductVariant(db database.Database, product models.Product, variant models.ProductVariant) error {
+	if err := checkVariantOptions(product, variant.Options); err != nil {
+		return err
+	}
+
+	var others []models.ProductVariant
+	if err := db.Where("product_id = ?", product.ID).Find(&others).Error; err != nil {
+		return err
+	}
+	for _, other := range others {
+		if other.ID != variant.ID && sameVariantOptions(other.Options, variant.Options) {
+			return fmt.Errorf("%w: %s has the same options", errVariantExists, other.SKU)
+		}
+	}
+
+	var existing models.ProductVariant
+	if err := db.Where("sku = ?", variant.SKU).First(&existing).Error(); err == nil {
+		if existing.ID != variant.ID {
+			return fmt.Errorf("%w: SKU %s is taken", errVariantExists, variant.SKU)
+		}
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+
+	if variant.Barcode == "" {
+		return nil
+	}
+	barcodes, err := productBarcodes(variant.Barcode, nil)
+	if err != nil {
+		return err
+	}
+
+	var taken models.ProductBarcode
+	if err := db.Where("code = ?", barcodes[0].Code).First(&taken).Error(); err == nil {
+		if taken.VariantID == nil || *taken.VariantID != variant.ID {
+			return fmt.Errorf("%w: %s", errBarcodeTaken, taken.Code)
+		}
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+	return nil
+}
+
+// checkVariantOptions fails with errInvalidVariant unless options give a
+// value to each variant attribute of product, and to nothing else
+func checkVariantOptions(product models.Product, options models.VariantOptions) error {
+	if len(product.VariantAttributes) == 0 {
+		return fmt.Errorf("%w: product %d is not sold by variant", errInvalidVariant, product.ID)
+	}
+	if len(options) != len(product.VariantAttributes) {
+		return fmt.Errorf("%w: options must be %s", errInvalidVariant, strings.Join(product.VariantAttributes, ", "))
+	}
+	for _, attribute := range product.VariantAttributes {
+		if strings.TrimSpace(options[attribute]) == "" {
+			return fmt.Errorf("%w: options must be %s", errInvalidVariant, strings.Join(product.VariantAttributes, ", "))
+		}
+	}
+	return nil
+}
+
+// sameVariantOptions reports whether two variants take the same values
+func sameVariantOptions(a, b models.VariantOptions) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for attribute, value := range a {
+		if !strings.EqualFold(strings.TrimSpace(b[attribute]), strings.TrimSpace(value)) {
+			return false
+		}
+	}
+	return true
+}
+
+// variantAttributes normalizes the variant attributes of a product
+func variantAttributes(names []string) (models.VariantAttributes, error) {
+	if len(names) == 0 {
+		return nil, nil
+	}
+
+	attributes := make(models.VariantAttributes, 0, len(names))
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		for _, attribute := range attributes {
+			if attribute == name {
+				return nil, fmt.Errorf("%w: attribute %s is listed twice", errInvalidVariant, name)
+			}
+		}
+		attributes = append(attributes, name)
+	}
+	return attributes, nil
+}
+
+// replaceVariantBarcode makes the barcode of variant one of the barcodes of
+// its product, in place of its previous one
+func replaceVariantBarcode(tx database.Database, variant models.ProductVariant) error {
+	if err := tx.Where("variant_id = ?", variant.ID).Delete(&models.ProductBarcode{}).Error; err != nil {
+		return err
+	}
+	if variant.Barcode == "" {
+		return nil
+	}
+
+	variantID := variant.ID
+	barcodes, err := productBarcodes(variant.Barcode, nil)
+	if err != nil {
+		return err
+	}
+	barcodes[0].ProductID, barcodes[0].VariantID = variant.ProductID, &variantID
+	return tx.Create(&barcodes).Error
+}
+
+// touchProduct saves the product with the given id again, so that it gets a
+// new revision and clients syncing products see its variants changed
+func touchProduct(tx database.Database, productID uint) error {
+	return tx.Where("id = ?", productID).Updates(&models.Product{UpdatedAt: time.Now()}).Error
+}
+
+// findVariantOf returns the variant a line of product is sold as, failing
+// with errVariantRequired when product is sold by variant and none is given
+func findVariantOf(db database.Database, product models.Product, variantID *uint) (models.ProductVariant, error) {
+	var variant models.ProductVariant
+
+	if variantID == nil {
+		return variant, fmt.Errorf("%w: variant_id is required for product %d", errVariantRequired, product.ID)
+	}
+
+	if err := db.Where("id = ? AND product_id = ?", *variantID, product.ID).First(&variant).Error(); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return variant, fmt.Errorf("%w: %d of product %d", errVariantNotFound, *variantID, product.ID)
+		}
+		return variant, err
+	}
+	return variant, nil
+}
+
+// attachVariants loads the variants of products
+func attachVariants(db database.Database, products []models.Product) error {
+	ids := make([]uint, 0, len(products))
+	for _, product := range products {
+		if len(product.VariantAttributes) > 0 {
+			ids = append(ids, product.ID)
+		}
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+
+	var variants []models.ProductVariant
+	if err := db.Where("product_id IN ?", ids).Find(&variants).Error; err != nil {
+		return err
+	}
+
+	for i := range products {
+		if len(products[i].VariantAttributes) == 0 {
+			continue
+		}
+		products[i].Variants = []models.ProductVariant{}
+		for _, variant := range variants {
+			if variant.ProductID == products[i].ID {
+				products[i].Variants = append(products[i].Variants, variant)
+			}
+		}
+	}
+	return nil
+}
+
+// invalidateProductPages drops the cached pages of FindProducts
+func (r *productRepository) invalidateProductPages() {
+	keys, err := r.RedisClient.Keys(*r.Ctx, "products_offset_*").Result()
+	if err == nil {
+		for _, key := range keys {
+			r.RedisClient.Del(*r.Ctx, key)
+		}
+	}
+}
+
+// respondVariantError maps an error about variants to a response
+func respondVariantError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, errInvalidVariant):
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+	case errors.Is(err, errVariantExists):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		respondBarcodeError(c, err)
+	}
+}
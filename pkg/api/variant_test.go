@@ -0,0 +1,227 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"postui_api/pkg/cache"
+	"postui_api/pkg/database"
+	"postui_api/pkg/models"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-redis/redis/v8"
+	"github.com/golang/mock/gomock"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func TestCreateProductVariant(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDB := database.NewMockDatabase(ctrl)
+	mockCache := cache.NewMockCache(ctrl)
+	ctx := context.Background()
+	repo := NewProductRepository(mockDB, mockCache, &ctx)
+
+	// Set up Gin
+	gin.SetMode(gin.TestMode)
+	r := gin.Default()
+	r.POST("/products/:id/variants", repo.CreateProductVariant)
+
+	price := models.Money(1800)
+	input := models.CreateProductVariant{SKU: "TEE-XL", Options: models.VariantOptions{"size": "XL"}, Barcode: "96385074", Price: &price, Stock: decimal.NewFromInt(4)}
+	requestBody, _ := json.Marshal(input)
+
+	mockDB.EXPECT().Where("id = ?", "1").Return(mockDB).Times(1)
+	mockDB.EXPECT().Where("product_id = ?", uint(1)).Return(mockDB).Times(1)
+	mockDB.EXPECT().Where("sku = ?", "TEE-XL").Return(mockDB).Times(1)
+	mockDB.EXPECT().Where("code = ?", "96385074").Return(mockDB).Times(1)
+	mockDB.EXPECT().
+		First(gomock.Any()).
+		DoAndReturn(func(dest interface{}, conds ...interface{}) database.Database {
+			if b, ok := dest.(*models.Product); ok {
+				*b = models.Product{ID: 1, Name: "T-shirt", Price: 1500, VariantAttributes: models.VariantAttributes{"size"}}
+			}
+			return mockDB
+		}).Times(3)
+	mockDB.EXPECT().Error().Return(nil).Times(1)
+	// Neither the SKU nor the barcode are taken
+	mockDB.EXPECT().Error().Return(gorm.ErrRecordNotFound).Times(2)
+	mockDB.EXPECT().
+		Find(gomock.Any()).
+		DoAndReturn(func(dest interface{}, conds ...interface{}) *gorm.DB {
+			*dest.(*[]models.ProductVariant) = []models.ProductVariant{{ID: 4, ProductID: 1, SKU: "TEE-M", Options: models.VariantOptions{"size": "M"}}}
+			return &gorm.DB{Error: nil}
+		}).Times(1)
+
+	mockDB.EXPECT().
+		Transaction(gomock.Any()).
+		DoAndReturn(func(fc func(database.Database) error) error {
+			return fc(mockDB)
+		}).Times(1)
+	mockDB.EXPECT().
+		Create(gomock.AssignableToTypeOf(&models.ProductVariant{})).
+		DoAndReturn(func(value interface{}) *gorm.DB {
+			value.(*models.ProductVariant).ID = 5
+			return &gorm.DB{Error: nil}
+		}).Times(1)
+
+	// The barcode of the variant is one of the barcodes of the product
+	mockDB.EXPECT().Where("variant_id = ?", uint(5)).Return(mockDB).Times(1)
+	mockDB.EXPECT().Delete(gomock.AssignableToTypeOf(&models.ProductBarcode{})).Return(&gorm.DB{Error: nil}).Times(1)
+	mockDB.EXPECT().
+		Create(gomock.AssignableToTypeOf(&[]models.ProductBarcode{})).
+		DoAndReturn(func(value interface{}) *gorm.DB {
+			barcodes := *value.(*[]models.ProductBarcode)
+			assert.Len(t, barcodes, 1)
+			assert.Equal(t, uint(1), barcodes[0].ProductID)
+			assert.Equal(t, uint(5), *barcodes[0].VariantID)
+			return &gorm.DB{Error: nil}
+		}).Times(1)
+
+	// The product is saved again so that it gets a new revision
+	mockDB.EXPECT().Where("id = ?", uint(1)).Return(mockDB).Times(1)
+	mockDB.EXPECT().Updates(gomock.AssignableToTypeOf(&models.Product{})).Return(&gorm.DB{Error: nil, RowsAffected: 1}).Times(1)
+
+	mockCache.EXPECT().Keys(ctx, "products_offset_*").Return(redis.NewStringSliceResult([]string{}, nil)).Times(1)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPost, "/products/1/variants", bytes.NewBuffer(requestBody))
+	req.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+
+	var response struct {
+		Data models.ProductVariant `json:"data"`
+	}
+
+	assert.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+	assert.Equal(t, uint(5), response.Data.ID)
+	assert.Equal(t, "XL", response.Data.Options["size"])
+}
+
+func TestCreateProductVariantSameOptions(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDB := database.NewMockDatabase(ctrl)
+	mockCache := cache.NewMockCache(ctrl)
+	ctx := context.Background()
+	repo := NewProductRepository(mockDB, mockCache, &ctx)
+
+	// Set up Gin
+	gin.SetMode(gin.TestMode)
+	r := gin.Default()
+	r.POST("/products/:id/variants", repo.CreateProductVariant)
+
+	input := models.CreateProductVariant{SKU: "TEE-M-2", Options: models.VariantOptions{"size": "m"}}
+	requestBody, _ := json.Marshal(input)
+
+	mockDB.EXPECT().Where("id = ?", "1").Return(mockDB).Times(1)
+	mockDB.EXPECT().
+		First(gomock.Any()).
+		DoAndReturn(func(dest interface{}, conds ...interface{}) database.Database {
+			*dest.(*models.Product) = models.Product{ID: 1, Name: "T-shirt", Price: 1500, VariantAttributes: models.VariantAttributes{"size"}}
+			return mockDB
+		}).Times(1)
+	mockDB.EXPECT().Error().Return(nil).Times(1)
+	mockDB.EXPECT().Where("product_id = ?", uint(1)).Return(mockDB).Times(1)
+	mockDB.EXPECT().
+		Find(gomock.Any()).
+		DoAndReturn(func(dest interface{}, conds ...interface{}) *gorm.DB {
+			*dest.(*[]models.ProductVariant) = []models.ProductVariant{{ID: 4, ProductID: 1, SKU: "TEE-M", Options: models.VariantOptions{"size": "M"}}}
+			return &gorm.DB{Error: nil}
+		}).Times(1)
+
+	// Nothing must be stored
+	mockDB.EXPECT().Transaction(gomock.Any()).Times(0)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPost, "/products/1/variants", bytes.NewBuffer(requestBody))
+	req.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.Contains(t, w.Body.String(), "TEE-M has the same options")
+}
+
+func TestCreateProductVariantMissingOption(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDB := database.NewMockDatabase(ctrl)
+	mockCache := cache.NewMockCache(ctrl)
+	ctx := context.Background()
+	repo := NewProductRepository(mockDB, mockCache, &ctx)
+
+	// Set up Gin
+	gin.SetMode(gin.TestMode)
+	r := gin.Default()
+	r.POST("/products/:id/variants", repo.CreateProductVariant)
+
+	input := models.CreateProductVariant{SKU: "TEE-M-RED", Options: models.VariantOptions{"size": "M"}}
+	requestBody, _ := json.Marshal(input)
+
+	mockDB.EXPECT().Where("id = ?", "1").Return(mockDB).Times(1)
+	mockDB.EXPECT().
+		First(gomock.Any()).
+		DoAndReturn(func(dest interface{}, conds ...interface{}) database.Database {
+			*dest.(*models.Product) = models.Product{ID: 1, Name: "T-shirt", Price: 1500, VariantAttributes: models.VariantAttributes{"size", "color"}}
+			return mockDB
+		}).Times(1)
+	mockDB.EXPECT().Error().Return(nil).Times(1)
+
+	// Nothing must be stored
+	mockDB.EXPECT().Transaction(gomock.Any()).Times(0)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPost, "/products/1/variants", bytes.NewBuffer(requestBody))
+	req.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	assert.Contains(t, w.Body.String(), "options must be size, color")
+}
+
+func TestPriceOrderLineVariant(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDB := database.NewMockDatabase(ctrl)
+
+	price := models.Money(1800)
+	product := models.Product{ID: 7, Name: "T-shirt", Price: 1500, Currency: "EUR", Vat: 2100, VariantAttributes: models.VariantAttributes{"size"}}
+
+	mockDB.EXPECT().Where("id = ?", uint(7)).Return(mockDB).Times(2)
+	mockDB.EXPECT().Where("id = ? AND product_id = ?", uint(4), uint(7)).Return(mockDB).Times(1)
+	mockDB.EXPECT().
+		First(gomock.Any()).
+		DoAndReturn(func(dest interface{}, conds ...interface{}) database.Database {
+			switch b := dest.(type) {
+			case *models.Product:
+				*b = product
+			case *models.ProductVariant:
+				*b = models.ProductVariant{ID: 4, ProductID: 7, SKU: "TEE-XL", Options: models.VariantOptions{"size": "XL"}, Price: &price}
+			}
+			return mockDB
+		}).Times(3)
+	mockDB.EXPECT().Error().Return(nil).Times(3)
+
+	variantID := uint(4)
+	line, _, err := priceOrderLine(mockDB, 7, &variantID, decimal.NewFromInt(2), time.Now())
+	assert.NoError(t, err)
+	assert.Equal(t, uint(4), *line.VariantID)
+	assert.Equal(t, models.Money(1800), line.Price, "XL should sell at its own price")
+	assert.Equal(t, models.Money(3600), line.Total)
+
+	// Products sold by variant cannot be sold without one
+	_, _, err = priceOrderLine(mockDB, 7, nil, decimal.NewFromInt(1), time.Now())
+	assert.ErrorIs(t, err, errVariantRequired)
+}
@@ -0,0 +1,154 @@
+// Package barcode validates scanned GTIN barcodes and decodes the weight or
+// price embedded in the EAN-13 labels printed by scales.
+package barcode
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"postui_api/pkg/models"
+
+	"github.com/shopspring/decimal"
+)
+
+var (
+	// ErrCheckDigit is returned for a GTIN whose check digit does not
+	// match the other digits, usually a misread.
+	ErrCheckDigit = errors.New("wrong check digit")
+	// ErrInvalidFormat is returned for an embedded barcode format that
+	// does not fill an EAN-13.
+	ErrInvalidFormat = errors.New("invalid barcode format")
+)
+
+// ean13Data is the number of digits of an EAN-13 before its check digit
+const ean13Data = 12
+
+// Label is what a scale label embeds
+type Label struct {
+	Format   models.BarcodeFormat
+	ItemCode string          // Without leading zeros
+	Quantity decimal.Decimal // In kg, weight labels only
+	Price    models.Money    // In cents, price labels only
+}
+
+// CheckDigit returns the GS1 check digit of the digits of a GTIN before its
+// check digit
+func CheckDigit(digits string) (byte, error) {
+	sum := 0
+	for i := len(digits) - 1; i >= 0; i-- {
+		digit := digits[i]
+		if digit < '0' || digit > '9' {
+			return 0, fmt.Errorf("%q is not numeric", digits)
+		}
+		// Weights alternate 3 and 1, starting with 3 at the rightmost digit
+		weight := 1
+		if (len(digits)-1-i)%2 == 0 {
+			weight = 3
+		}
+		sum += int(digit-'0') * weight
+	}
+	return byte('0' + (10-sum%10)%10), nil
+}
+
+// IsGTIN reports whether code is made of the digits of an EAN-8, UPC-A,
+// EAN-13 or GTIN-14, whose check digit can be validated
+func IsGTIN(code string) bool {
+	switch len(code) {
+	case 8, 12, 13, 14:
+	default:
+		return false
+	}
+	for i := 0; i < len(code); i++ {
+		if code[i] < '0' || code[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// Validate fails with ErrCheckDigit when code is a GTIN with a wrong check
+// digit. Other codes, such as Code 128 labels, have no check digit to
+// validate.
+func Validate(code string) error {
+	if !IsGTIN(code) {
+		return nil
+	}
+	check, err := CheckDigit(code[:len(code)-1])
+	if err != nil {
+		return err
+	}
+	if check != code[len(code)-1] {
+		return fmt.Errorf("%w: %s", ErrCheckDigit, code)
+	}
+	return nil
+}
+
+// ValidateFormat fails with ErrInvalidFormat when format does not describe
+// the 12 digits before the check digit of an EAN-13
+func ValidateFormat(format models.BarcodeFormat) error {
+	if _, err := strconv.ParseUint(format.Prefix, 10, 64); err != nil {
+		return fmt.Errorf("%w: prefix %q is not numeric", ErrInvalidFormat, format.Prefix)
+	}
+	if length := len(format.Prefix) + int(format.ItemDigits) + int(format.ValueDigits); length != ean13Data {
+		return fmt.Errorf("%w: prefix %s, item code and value take %d digits instead of %d", ErrInvalidFormat, format.Prefix, length, ean13Data)
+	}
+	if format.Value == models.BarcodePrice && format.Decimals > 2 {
+		return fmt.Errorf("%w: prices have at most 2 decimals", ErrInvalidFormat)
+	}
+	return nil
+}
+
+// Decode returns the label code stands for when it is an EAN-13 starting
+// with the prefix of one of formats, the longest prefix winning. ok is false
+// for other codes, which are the barcodes of products.
+func Decode(code string, formats []models.BarcodeFormat) (label Label, ok bool, err error) {
+	if len(code) != ean13Data+1 || !IsGTIN(code) {
+		return label, false, nil
+	}
+
+	candidates := make([]models.BarcodeFormat, 0, len(formats))
+	for _, format := range formats {
+		if strings.HasPrefix(code, format.Prefix) && ValidateFormat(format) == nil {
+			candidates = append(candidates, format)
+		}
+	}
+	if len(candidates) == 0 {
+		return label, false, nil
+	}
+	sort.SliceStable(candidates, func(i, j int) bool { return len(candidates[i].Prefix) > len(candidates[j].Prefix) })
+
+	if err := Validate(code); err != nil {
+		return label, true, err
+	}
+
+	format := candidates[0]
+	itemStart := len(format.Prefix)
+	valueStart := itemStart + int(format.ItemDigits)
+
+	value, err := decimal.NewFromString(code[valueStart : valueStart+int(format.ValueDigits)])
+	if err != nil {
+		return label, true, err
+	}
+	value = value.Shift(-int32(format.Decimals))
+
+	label = Label{Format: format, ItemCode: ItemCode(code[itemStart:valueStart])}
+	if format.Value == models.BarcodePrice {
+		label.Price = models.Money(value.Shift(2).IntPart())
+	} else {
+		label.Quantity = value
+	}
+	return label, true, nil
+}
+
+// ItemCode normalizes the item code of a product, which scale labels pad
+// with leading zeros
+func ItemCode(code string) string {
+	code = strings.TrimLeft(strings.TrimSpace(code), "0")
+	if code == "" {
+		return "0"
+	}
+	return code
+}
@@ -0,0 +1,77 @@
+package barcode
+
+import (
+	"errors"
+	"postui_api/pkg/models"
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+var scaleFormats = []models.BarcodeFormat{
+	{Prefix: "2", ItemDigits: 6, Value: models.BarcodeWeight, ValueDigits: 5, Decimals: 3},
+	{Prefix: "21", ItemDigits: 5, Value: models.BarcodeWeight, ValueDigits: 5, Decimals: 3},
+	{Prefix: "22", ItemDigits: 5, Value: models.BarcodePrice, ValueDigits: 5, Decimals: 2},
+}
+
+func TestCheckDigit(t *testing.T) {
+	digit, err := CheckDigit("400638133393")
+	assert.NoError(t, err)
+	assert.Equal(t, byte('1'), digit)
+
+	_, err = CheckDigit("40063813339A")
+	assert.Error(t, err)
+}
+
+func TestValidate(t *testing.T) {
+	assert.NoError(t, Validate("4006381333931"))
+	assert.NoError(t, Validate("SKU-42"), "Codes other than GTINs have no check digit")
+	assert.True(t, errors.Is(Validate("4006381333932"), ErrCheckDigit))
+}
+
+func TestValidateFormat(t *testing.T) {
+	for _, format := range scaleFormats {
+		assert.NoError(t, ValidateFormat(format))
+	}
+	assert.True(t, errors.Is(ValidateFormat(models.BarcodeFormat{Prefix: "21", ItemDigits: 4, Value: models.BarcodeWeight, ValueDigits: 5}), ErrInvalidFormat))
+	assert.True(t, errors.Is(ValidateFormat(models.BarcodeFormat{Prefix: "22", ItemDigits: 5, Value: models.BarcodePrice, ValueDigits: 5, Decimals: 3}), ErrInvalidFormat))
+}
+
+func TestDecodeWeight(t *testing.T) {
+	label, ok, err := Decode("2100123012343", scaleFormats)
+
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, "21", label.Format.Prefix, "The longest prefix should win")
+	assert.Equal(t, "123", label.ItemCode)
+	assert.True(t, decimal.RequireFromString("1.234").Equal(label.Quantity))
+}
+
+func TestDecodePrice(t *testing.T) {
+	label, ok, err := Decode("2200045003495", scaleFormats)
+
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, "45", label.ItemCode)
+	assert.Equal(t, models.Money(349), label.Price)
+}
+
+func TestDecodeOtherBarcodes(t *testing.T) {
+	_, ok, err := Decode("4006381333931", scaleFormats)
+	assert.NoError(t, err)
+	assert.False(t, ok, "Barcodes outside the prefixes are product barcodes")
+
+	_, ok, err = Decode("2100123012343", nil)
+	assert.NoError(t, err)
+	assert.False(t, ok, "Labels are not decoded without formats")
+
+	_, ok, err = Decode("2100123012344", scaleFormats)
+	assert.True(t, ok)
+	assert.True(t, errors.Is(err, ErrCheckDigit))
+}
+
+func TestItemCode(t *testing.T) {
+	assert.Equal(t, "123", ItemCode("00123"))
+	assert.Equal(t, "0", ItemCode("00000"))
+}
@@ -11,6 +11,7 @@ import (
 type Cache interface {
 	Get(ctx context.Context, key string) *redis.StringCmd
 	Set(ctx context.Context, key string, value interface{}, expiration time.Duration) *redis.StatusCmd
+	SetNX(ctx context.Context, key string, value interface{}, expiration time.Duration) *redis.BoolCmd
 	Keys(context.Context, string) *redis.StringSliceCmd
 	Del(context.Context, ...string) *redis.IntCmd
 }
@@ -0,0 +1,71 @@
+// Package coupons generates coupon codes and checks whether a coupon can
+// still be redeemed.
+package coupons
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"postui_api/pkg/models"
+)
+
+var (
+	// ErrInactive is returned for coupons that were deactivated.
+	ErrInactive = errors.New("coupon is not active")
+	// ErrExpired is returned for coupons past their expiry.
+	ErrExpired = errors.New("coupon has expired")
+	// ErrExhausted is returned for coupons redeemed their maximum number of
+	// times.
+	ErrExhausted = errors.New("coupon has no redemptions left")
+	// ErrCustomerLimit is returned when a customer redeemed a coupon as many
+	// times as allowed.
+	ErrCustomerLimit = errors.New("coupon redemption limit reached for customer")
+)
+
+// alphabet leaves out characters easily mistaken for one another (0, O, 1, I)
+const alphabet = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+
+// CodeLength is the number of random characters of generated codes
+const CodeLength = 10
+
+// Generate returns prefix followed by CodeLength random characters.
+func Generate(prefix string) (string, error) {
+	max := big.NewInt(int64(len(alphabet)))
+
+	var b strings.Builder
+	b.WriteString(Normalize(prefix))
+	for i := 0; i < CodeLength; i++ {
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", err
+		}
+		b.WriteByte(alphabet[n.Int64()])
+	}
+	return b.String(), nil
+}
+
+// Normalize returns code the way it is stored, so that codes typed in lower
+// case or with surrounding spaces are found.
+func Normalize(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+// Check reports why c cannot be redeemed at now, if it cannot. Redemptions
+// are checked again when the coupon is redeemed, as they may be used
+// concurrently.
+func Check(c models.Coupon, now time.Time) error {
+	if !c.Active {
+		return fmt.Errorf("%w: %s", ErrInactive, c.Code)
+	}
+	if c.ExpiresAt != nil && !now.Before(*c.ExpiresAt) {
+		return fmt.Errorf("%w: %s", ErrExpired, c.Code)
+	}
+	if c.MaxRedemptions != 0 && c.Redemptions >= c.MaxRedemptions {
+		return fmt.Errorf("%w: %s", ErrExhausted, c.Code)
+	}
+	return nil
+}
@@ -0,0 +1,38 @@
+package coupons
+
+import (
+	"errors"
+	"postui_api/pkg/models"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerate(t *testing.T) {
+	code, err := Generate("spring")
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(code, "SPRING"))
+	assert.Len(t, code, len("SPRING")+CodeLength)
+	assert.Equal(t, -1, strings.IndexAny(code[len("SPRING"):], "0O1I"), "Ambiguous characters should not be used")
+
+	other, err := Generate("spring")
+	assert.NoError(t, err)
+	assert.NotEqual(t, code, other)
+}
+
+func TestNormalize(t *testing.T) {
+	assert.Equal(t, "WELCOME10", Normalize(" welcome10 "))
+}
+
+func TestCheck(t *testing.T) {
+	now := time.Date(2026, 5, 1, 10, 0, 0, 0, time.UTC)
+	later := now.Add(time.Hour)
+
+	assert.NoError(t, Check(models.Coupon{Code: "A", Active: true}, now))
+	assert.NoError(t, Check(models.Coupon{Code: "A", Active: true, MaxRedemptions: 2, Redemptions: 1, ExpiresAt: &later}, now))
+	assert.True(t, errors.Is(Check(models.Coupon{Code: "A"}, now), ErrInactive))
+	assert.True(t, errors.Is(Check(models.Coupon{Code: "A", Active: true, ExpiresAt: &now}, now), ErrExpired))
+	assert.True(t, errors.Is(Check(models.Coupon{Code: "A", Active: true, MaxRedemptions: 1, Redemptions: 1}, now), ErrExhausted), "Single use coupons should be exhausted once redeemed")
+}
@@ -11,6 +11,7 @@ import (
 
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type Database interface {
@@ -24,6 +25,10 @@ type Database interface {
 	First(dest interface{}, conds ...interface{}) Database
 	Updates(interface{}) *gorm.DB
 	Order(value interface{}) *gorm.DB
+	Transaction(fc func(tx Database) error) error
+	Unscoped() Database
+	ForUpdate() Database
+	Clauses(conds ...clause.Expression) *gorm.DB
 	Error() error
 }
 
@@ -39,6 +44,25 @@ func (db *GormDatabase) First(dest interface{}, conds ...interface{}) Database {
 	return &GormDatabase{db.DB.First(dest, conds...)}
 }
 
+// Transaction runs fc inside a database transaction. The transaction is
+// committed when fc returns nil and rolled back otherwise.
+func (db *GormDatabase) Transaction(fc func(tx Database) error) error {
+	return db.DB.Transaction(func(tx *gorm.DB) error {
+		return fc(&GormDatabase{tx})
+	})
+}
+
+// Unscoped includes soft deleted records in the queries that follow
+func (db *GormDatabase) Unscoped() Database {
+	return &GormDatabase{db.DB.Unscoped()}
+}
+
+// ForUpdate locks the rows read by the queries that follow until the
+// transaction ends
+func (db *GormDatabase) ForUpdate() Database {
+	return &GormDatabase{db.DB.Clauses(clause.Locking{Strength: "UPDATE"})}
+}
+
 func (db *GormDatabase) Error() error {
 	return db.DB.Error
 }
@@ -56,7 +80,7 @@ func NewDatabase() *gorm.DB {
 	dbURl := fmt.Sprintf("postgres://%s:%s@%s:%s/%s", db_user, db_pass, db_hostname, db_port, db_name)
 
 	for i := 1; i <= 3; i++ {
-		database, err = gorm.Open(postgres.Open(dbURl), &gorm.Config{})
+		database, err = gorm.Open(postgres.Open(dbURl), &gorm.Config{TranslateError: true})
 		if err == nil {
 			break
 		} else {
@@ -65,9 +89,37 @@ func NewDatabase() *gorm.DB {
 		}
 	}
 	database.AutoMigrate(&models.Product{})
+	database.AutoMigrate(&models.ProductBarcode{})
+	database.AutoMigrate(&models.ProductVariant{})
 	database.AutoMigrate(&models.User{})
 	database.AutoMigrate(&models.Order{})
 	database.AutoMigrate(&models.OrderLine{})
+	database.AutoMigrate(&models.OrderTransition{})
+	database.AutoMigrate(&models.Payment{})
+	database.AutoMigrate(&models.RegisterSession{})
+	database.AutoMigrate(&models.CashMovement{})
+	database.AutoMigrate(&models.DenominationCount{})
+	database.AutoMigrate(&models.ZReport{})
+	database.AutoMigrate(&models.StoreSettings{})
+	database.AutoMigrate(&models.InvoiceSeries{})
+	database.AutoMigrate(&models.FiscalRecord{})
+	database.AutoMigrate(&models.FiscalChain{})
+	database.AutoMigrate(&models.Promotion{})
+	database.AutoMigrate(&models.OrderDiscount{})
+	database.AutoMigrate(&models.Coupon{})
+	database.AutoMigrate(&models.CouponRedemption{})
+	database.AutoMigrate(&models.GiftCard{})
+	database.AutoMigrate(&models.GiftCardTransaction{})
+	database.AutoMigrate(&models.Customer{})
+	database.AutoMigrate(&models.LoyaltyAccount{})
+	database.AutoMigrate(&models.LoyaltyEntry{})
+	database.AutoMigrate(&models.LoyaltyRule{})
+	database.AutoMigrate(&models.TaxCategory{})
+	database.AutoMigrate(&models.TaxRate{})
+
+	if err := RunMigrations(database); err != nil {
+		log.Fatalf("Failed to run database migrations: %v", err)
+	}
 
 	middleware.CreateAdmin(database)
 
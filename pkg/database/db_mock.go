@@ -9,6 +9,7 @@ import (
 
 	gomock "github.com/golang/mock/gomock"
 	gorm "gorm.io/gorm"
+	clause "gorm.io/gorm/clause"
 )
 
 // MockDatabase is a mock of Database interface.
@@ -34,6 +35,24 @@ func (m *MockDatabase) EXPECT() *MockDatabaseMockRecorder {
 	return m.recorder
 }
 
+// Clauses mocks base method.
+func (m *MockDatabase) Clauses(conds ...clause.Expression) *gorm.DB {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{}
+	for _, a := range conds {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "Clauses", varargs...)
+	ret0, _ := ret[0].(*gorm.DB)
+	return ret0
+}
+
+// Clauses indicates an expected call of Clauses.
+func (mr *MockDatabaseMockRecorder) Clauses(conds ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Clauses", reflect.TypeOf((*MockDatabase)(nil).Clauses), conds...)
+}
+
 // Create mocks base method.
 func (m *MockDatabase) Create(value interface{}) *gorm.DB {
 	m.ctrl.T.Helper()
@@ -119,6 +138,20 @@ func (mr *MockDatabaseMockRecorder) First(dest interface{}, conds ...interface{}
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "First", reflect.TypeOf((*MockDatabase)(nil).First), varargs...)
 }
 
+// ForUpdate mocks base method.
+func (m *MockDatabase) ForUpdate() Database {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ForUpdate")
+	ret0, _ := ret[0].(Database)
+	return ret0
+}
+
+// ForUpdate indicates an expected call of ForUpdate.
+func (mr *MockDatabaseMockRecorder) ForUpdate() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ForUpdate", reflect.TypeOf((*MockDatabase)(nil).ForUpdate))
+}
+
 // Limit mocks base method.
 func (m *MockDatabase) Limit(limit int) *gorm.DB {
 	m.ctrl.T.Helper()
@@ -175,6 +208,34 @@ func (mr *MockDatabaseMockRecorder) Order(value interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Order", reflect.TypeOf((*MockDatabase)(nil).Order), value)
 }
 
+// Transaction mocks base method.
+func (m *MockDatabase) Transaction(fc func(Database) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Transaction", fc)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Transaction indicates an expected call of Transaction.
+func (mr *MockDatabaseMockRecorder) Transaction(fc interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Transaction", reflect.TypeOf((*MockDatabase)(nil).Transaction), fc)
+}
+
+// Unscoped mocks base method.
+func (m *MockDatabase) Unscoped() Database {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Unscoped")
+	ret0, _ := ret[0].(Database)
+	return ret0
+}
+
+// Unscoped indicates an expected call of Unscoped.
+func (mr *MockDatabaseMockRecorder) Unscoped() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Unscoped", reflect.TypeOf((*MockDatabase)(nil).Unscoped))
+}
+
 // Updates mocks base method.
 func (m *MockDatabase) Updates(arg0 interface{}) *gorm.DB {
 	m.ctrl.T.Helper()
@@ -14,3 +14,13 @@ func TestNewDatabase(t *testing.T) {
 	db := NewMockDatabase(ctrl)
 	assert.NotNil(t, db)
 }
+
+func TestMigrationsHaveUniqueIDs(t *testing.T) {
+	seen := make(map[string]bool)
+	for _, m := range migrations {
+		assert.NotEmpty(t, m.ID)
+		assert.NotNil(t, m.Up, "Migration %s should have an Up function", m.ID)
+		assert.False(t, seen[m.ID], "Migration %s is declared twice", m.ID)
+		seen[m.ID] = true
+	}
+}
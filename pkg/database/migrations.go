@@ -0,0 +1,333 @@
+package database
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"postui_api/pkg/barcode"
+	"postui_api/pkg/models"
+
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// migration is a schema or data change applied once, after AutoMigrate has
+// brought the tables in line with the models
+type migration struct {
+	ID string
+	Up func(tx *gorm.DB) error
+}
+
+// SchemaMigration records an applied migration
+type SchemaMigration struct {
+	ID        string    `gorm:"primaryKey"`
+	AppliedAt time.Time `gorm:"autoCreateTime"`
+}
+
+// migrations are applied in order, new ones must be appended
+var migrations = []migration{
+	{ID: "0001_money_minor_units", Up: migrateMoneyMinorUnits},
+	{ID: "0002_order_status", Up: migrateOrderStatus},
+	{ID: "0003_one_open_session_per_cashout", Up: migrateOneOpenSession},
+	{ID: "0004_unique_invoices", Up: migrateUniqueInvoices},
+	{ID: "0005_append_only_fiscal_records", Up: migrateAppendOnlyFiscalRecords},
+	{ID: "0006_order_customer_name", Up: migrateOrderCustomerName},
+	{ID: "0007_order_lines_foreign_key", Up: migrateOrderLinesForeignKey},
+	{ID: "0008_product_revisions", Up: migrateProductRevisions},
+	{ID: "0009_order_line_surcharge", Up: migrateOrderLineSurcharge},
+	{ID: "0010_weighed_quantities", Up: migrateWeighedQuantities},
+	{ID: "0011_product_barcodes", Up: migrateProductBarcodes},
+	{ID: "0012_product_revisions_by_transaction", Up: migrateProductRevisionsByTransaction},
+	{ID: "0013_unique_item_codes", Up: migrateUniqueItemCodes},
+	{ID: "0014_invoice_series_codes", Up: migrateInvoiceSeriesCodes},
+}
+
+// RunMigrations applies the pending migrations, each one in its own transaction
+func RunMigrations(db *gorm.DB) error {
+	if err := db.AutoMigrate(&SchemaMigration{}); err != nil {
+		return err
+	}
+
+	for _, m := range migrations {
+		var applied int64
+		if err := db.Model(&SchemaMigration{}).Where("id = ?", m.ID).Count(&applied).Error; err != nil {
+			return err
+		}
+		if applied > 0 {
+			continue
+		}
+
+		err := db.Transaction(func(tx *gorm.DB) error {
+			if err := m.Up(tx); err != nil {
+				return err
+			}
+			return tx.Create(&SchemaMigration{ID: m.ID}).Error
+		})
+		if err != nil {
+			return fmt.Errorf("migration %s: %w", m.ID, err)
+		}
+	}
+
+	return nil
+}
+
+// migrateMoneyMinorUnits widens the amount columns that used to hold uint16
+// cents to bigint and sets the currency of rows created before it existed
+func migrateMoneyMinorUnits(tx *gorm.DB) error {
+	amounts := []struct {
+		table   string
+		columns []string
+	}{
+		{"products", []string{"price"}},
+		{"order_lines", []string{"price", "total"}},
+		{"orders", []string{"total"}},
+	}
+
+	for _, amount := range amounts {
+		for _, column := range amount.columns {
+			if err := tx.Exec(fmt.Sprintf("ALTER TABLE %s ALTER COLUMN %s TYPE bigint USING %s::bigint", amount.table, column, column)).Error; err != nil {
+				return err
+			}
+		}
+		if err := tx.Exec(fmt.Sprintf("UPDATE %s SET currency = ? WHERE currency IS NULL OR currency = ''", amount.table), models.DefaultCurrency).Error; err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// migrateOrderStatus marks the orders created before statuses existed as
+// paid, since every order used to be a completed sale
+func migrateOrderStatus(tx *gorm.DB) error {
+	return tx.Exec("UPDATE orders SET status = ? WHERE status IS NULL OR status = ''", models.OrderPaid).Error
+}
+
+// migrateOneOpenSession makes sure a single register session can be open per
+// cashout number, even when two terminals open one concurrently
+func migrateOneOpenSession(tx *gorm.DB) error {
+	return tx.Exec("CREATE UNIQUE INDEX IF NOT EXISTS idx_register_sessions_open ON register_sessions (cashout_number) WHERE status = ?", models.SessionOpen).Error
+}
+
+// migrateUniqueInvoices makes sure an invoice number is never given twice.
+// Orders not paid yet have no invoice number.
+func migrateUniqueInvoices(tx *gorm.DB) error {
+	return tx.Exec("CREATE UNIQUE INDEX IF NOT EXISTS idx_orders_invoice ON orders (invoice) WHERE invoice <> ''").Error
+}
+
+// migrateAppendOnlyFiscalRecords rejects any change to fiscal records once
+// they are stored, so the chain can only grow
+func migrateAppendOnlyFiscalRecords(tx *gorm.DB) error {
+	statements := []string{
+		`CREATE OR REPLACE FUNCTION fiscal_records_append_only() RETURNS trigger AS $$
+BEGIN
+	RAISE EXCEPTION 'fiscal records are append-only';
+END;
+$$ LANGUAGE plpgsql`,
+		"DROP TRIGGER IF EXISTS fiscal_records_append_only ON fiscal_records",
+		"CREATE TRIGGER fiscal_records_append_only BEFORE UPDATE OR DELETE ON fiscal_records FOR EACH ROW EXECUTE FUNCTION fiscal_records_append_only()",
+	}
+
+	for _, statement := range statements {
+		if err := tx.Exec(statement).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// migrateOrderCustomerName moves the free text customer of orders from the
+// vendor column to customer_name, now that orders can reference a customer
+func migrateOrderCustomerName(tx *gorm.DB) error {
+	if !tx.Migrator().HasColumn(&models.Order{}, "vendor") {
+		return nil
+	}
+
+	if err := tx.Exec("UPDATE orders SET customer_name = vendor WHERE customer_name IS NULL OR customer_name = ''").Error; err != nil {
+		return err
+	}
+	return tx.Exec("ALTER TABLE orders DROP COLUMN vendor").Error
+}
+
+// migrateOrderLinesForeignKey moves the lines referenced by the lines_id
+// array of orders to an order_id foreign key. A line referenced by several
+// orders is kept by the first one.
+func migrateOrderLinesForeignKey(tx *gorm.DB) error {
+	if tx.Migrator().HasColumn(&models.Order{}, "lines_id") {
+		err := tx.Exec(`UPDATE order_lines SET order_id = (
+	SELECT MIN(orders.id) FROM orders WHERE order_lines.id = ANY(orders.lines_id)
+) WHERE order_id IS NULL`).Error
+		if err != nil {
+			return err
+		}
+		if err := tx.Exec("ALTER TABLE orders DROP COLUMN lines_id").Error; err != nil {
+			return err
+		}
+	}
+
+	// GORM only creates the constraints of has-many relations on request
+	if !tx.Migrator().HasConstraint(&models.Order{}, "Lines") {
+		return tx.Migrator().CreateConstraint(&models.Order{}, "Lines")
+	}
+	return nil
+}
+
+// migrateProductRevisions numbers every change to a product, deletions
+// included since products are soft deleted, so terminals can ask for the
+// changes after the last revision they saw. Writers take turns until they
+// commit, so a revision never becomes visible after a greater one.
+func migrateProductRevisions(tx *gorm.DB) error {
+	statements := []string{
+		"CREATE SEQUENCE IF NOT EXISTS product_revisions",
+		`CREATE OR REPLACE FUNCTION products_revision() RETURNS trigger AS $$
+BEGIN
+	PERFORM pg_advisory_xact_lock(hashtext('product_revisions'));
+	NEW.revision := nextval('product_revisions');
+	RETURN NEW;
+END;
+$$ LANGUAGE plpgsql`,
+		"DROP TRIGGER IF EXISTS products_revision ON products",
+		"CREATE TRIGGER products_revision BEFORE INSERT OR UPDATE ON products FOR EACH ROW EXECUTE FUNCTION products_revision()",
+		// Existing products get their first revision from the trigger
+		"UPDATE products SET revision = 0",
+	}
+
+	for _, statement := range statements {
+		if err := tx.Exec(statement).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// migrateOrderLineSurcharge sets the equivalence surcharge of the lines sold
+// before it existed to none. Their orders have no stored tax breakdown and
+// keep being broken down from their lines.
+func migrateOrderLineSurcharge(tx *gorm.DB) error {
+	return tx.Exec("UPDATE order_lines SET surcharge = 0 WHERE surcharge IS NULL").Error
+}
+
+// migrateWeighedQuantities keeps quantities and stock to the gram, as read
+// from scale labels. AutoMigrate leaves the scale of decimal columns as is.
+func migrateWeighedQuantities(tx *gorm.DB) error {
+	statements := []string{
+		"ALTER TABLE order_lines ALTER COLUMN quantity TYPE decimal(10,3)",
+		"ALTER TABLE products ALTER COLUMN stock TYPE decimal(10,3)",
+	}
+
+	for _, statement := range statements {
+		if err := tx.Exec(statement).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// migrateProductBarcodes copies the barcode numbers of the products into
+// their barcodes. When products share a barcode number, the oldest one keeps
+// it. Barcode numbers with a wrong check digit could never be scanned, so
+// they are not copied. Products left without a barcode are logged, to be
+// given one by hand.
+func migrateProductBarcodes(tx *gorm.DB) error {
+	var products []models.Product
+	if err := tx.Select("id", "barcode_number").Where("TRIM(barcode_number) <> ''").Order("id").Find(&products).Error; err != nil {
+		return err
+	}
+
+	owners := make(map[string]uint, len(products))
+	barcodes := make([]models.ProductBarcode, 0, len(products))
+	for _, product := range products {
+		code := strings.TrimSpace(product.BarcodeNumber)
+		if err := barcode.Validate(code); err != nil {
+			log.Printf("Product %d left without barcodes: %v", product.ID, err)
+			continue
+		}
+		if owner, ok := owners[code]; ok {
+			log.Printf("Product %d left without barcodes: %s belongs to product %d", product.ID, code, owner)
+			continue
+		}
+
+		owners[code] = product.ID
+		barcodes = append(barcodes, models.ProductBarcode{ProductID: product.ID, Code: code, Quantity: decimal.NewFromInt(1)})
+	}
+	if len(barcodes) == 0 {
+		return nil
+	}
+
+	return tx.Clauses(clause.OnConflict{DoNothing: true}).CreateInBatches(&barcodes, 500).Error
+}
+
+// migrateProductRevisionsByTransaction numbers the changes to a product with
+// the id of the transaction that made them instead of taking turns, which
+// serialized every transaction writing products until it committed. Ids are
+// handed out as transactions start rather than as they commit, so changes
+// are only read below the oldest transaction still running, see
+// product_revisions_committed. Revisions are offset past the ones already
+// handed out, so that terminals keep their cursor.
+func migrateProductRevisionsByTransaction(tx *gorm.DB) error {
+	var base int64
+	if err := tx.Raw("SELECT COALESCE(MAX(revision), 0) + 1 FROM products").Scan(&base).Error; err != nil {
+		return err
+	}
+
+	statements := []string{
+		fmt.Sprintf("CREATE OR REPLACE FUNCTION product_revisions_base() RETURNS bigint AS $$ SELECT %d::bigint $$ LANGUAGE sql IMMUTABLE", base),
+		`CREATE OR REPLACE FUNCTION products_revision() RETURNS trigger AS $$
+BEGIN
+	NEW.revision := product_revisions_base() + txid_current();
+	RETURN NEW;
+END;
+$$ LANGUAGE plpgsql`,
+		// Every revision below it was committed, or rolled back, for good
+		"CREATE OR REPLACE FUNCTION product_revisions_committed() RETURNS bigint AS $$ SELECT product_revisions_base() + txid_snapshot_xmin(txid_current_snapshot()) $$ LANGUAGE sql STABLE",
+		"DROP SEQUENCE IF EXISTS product_revisions",
+	}
+
+	for _, statement := range statements {
+		if err := tx.Exec(statement).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// migrateUniqueItemCodes makes sure a scale label item code stands for a
+// single product. Products sharing an item code must be given codes of
+// their own first, since which one the labels stand for cannot be guessed.
+func migrateUniqueItemCodes(tx *gorm.DB) error {
+	var shared []string
+	if err := tx.Model(&models.Product{}).Where("item_code <> ''").Group("item_code").Having("COUNT(*) > 1").Pluck("item_code", &shared).Error; err != nil {
+		return err
+	}
+	if len(shared) > 0 {
+		return fmt.Errorf("item codes %s are given to several products", strings.Join(shared, ", "))
+	}
+
+	return tx.Exec("CREATE UNIQUE INDEX IF NOT EXISTS idx_products_item_code_unique ON products (item_code) WHERE deleted_at IS NULL AND item_code <> ''").Error
+}
+
+// migrateInvoiceSeriesCodes separates the prefix of the series codes from
+// their cashout number, so that the series keep counting under their new
+// code. Invoices already issued keep the number they were printed with.
+func migrateInvoiceSeriesCodes(tx *gorm.DB) error {
+	var series []models.InvoiceSeries
+	if err := tx.Find(&series).Error; err != nil {
+		return err
+	}
+
+	for _, s := range series {
+		suffix := fmt.Sprintf("%d-%d", s.CashoutNumber, s.Year)
+		if !strings.HasSuffix(s.Code, suffix) {
+			continue
+		}
+
+		code := models.InvoiceSeriesCode(strings.TrimSuffix(s.Code, suffix), s.CashoutNumber, s.Year)
+		if err := tx.Model(&models.InvoiceSeries{}).Where("id = ?", s.ID).Update("code", code).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,94 @@
+// Package fiscal chains fiscal records of issued tickets with SHA-256
+// fingerprints the way Verifactu does, verifies the chain and exports it.
+package fiscal
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+
+	"postui_api/pkg/models"
+)
+
+const (
+	// DateLayout is how issue dates are written in records
+	DateLayout = "02-01-2006"
+	// TimestampLayout is how generation times are written in records
+	TimestampLayout = "2006-01-02T15:04:05-07:00"
+)
+
+// Fingerprint returns the string hashed for record, which includes the hash
+// of the previous record.
+func Fingerprint(record models.FiscalRecord) string {
+	if record.Kind == models.FiscalCancellation {
+		return strings.Join([]string{
+			"IDEmisorFacturaAnulada=" + record.IssuerTaxID,
+			"NumSerieFacturaAnulada=" + record.Invoice,
+			"FechaExpedicionFacturaAnulada=" + record.IssueDate,
+			"Huella=" + record.PreviousHash,
+			"FechaHoraHusoGenRegistro=" + record.GeneratedAt,
+		}, "&")
+	}
+
+	return strings.Join([]string{
+		"IDEmisorFactura=" + record.IssuerTaxID,
+		"NumSerieFactura=" + record.Invoice,
+		"FechaExpedicionFactura=" + record.IssueDate,
+		"TipoFactura=" + record.InvoiceType,
+		"CuotaTotal=" + record.TaxTotal.String(),
+		"ImporteTotal=" + record.Total.String(),
+		"Huella=" + record.PreviousHash,
+		"FechaHoraHusoGenRegistro=" + record.GeneratedAt,
+	}, "&")
+}
+
+// Hash returns the uppercase hexadecimal SHA-256 of the fingerprint of record.
+func Hash(record models.FiscalRecord) string {
+	sum := sha256.Sum256([]byte(Fingerprint(record)))
+	return strings.ToUpper(hex.EncodeToString(sum[:]))
+}
+
+// Seal dates record at now, links it to previous, the last record of the
+// chain or nil for the first one, and sets its hash.
+func Seal(record *models.FiscalRecord, previous *models.FiscalRecord, now time.Time) {
+	now = now.Truncate(time.Second)
+	if record.IssueDate == "" {
+		record.IssueDate = now.Format(DateLayout)
+	}
+	record.GeneratedAt = now.Format(TimestampLayout)
+
+	record.PreviousHash = ""
+	if previous != nil {
+		record.PreviousHash = previous.Hash
+	}
+	record.Hash = Hash(*record)
+}
+
+// Verify walks records, ordered as they were appended, and reports the
+// records whose hash does not match their content or that do not follow
+// the previous record.
+func Verify(records []models.FiscalRecord) models.ChainVerification {
+	verification := models.ChainVerification{Records: len(records), Breaks: []models.ChainBreak{}}
+
+	previousHash := ""
+	for _, record := range records {
+		if record.PreviousHash != previousHash {
+			verification.Breaks = append(verification.Breaks, models.ChainBreak{
+				RecordID: record.ID,
+				Reason:   fmt.Sprintf("previous hash %q does not match the hash of the previous record %q", record.PreviousHash, previousHash),
+			})
+		}
+		if hash := Hash(record); hash != record.Hash {
+			verification.Breaks = append(verification.Breaks, models.ChainBreak{
+				RecordID: record.ID,
+				Reason:   "hash does not match the record content",
+			})
+		}
+		previousHash = record.Hash
+	}
+
+	verification.Valid = len(verification.Breaks) == 0
+	return verification
+}
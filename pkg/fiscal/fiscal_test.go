@@ -0,0 +1,75 @@
+package fiscal
+
+import (
+	"postui_api/pkg/models"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func chainFixture() []models.FiscalRecord {
+	madrid := time.FixedZone("CET", 3600)
+	now := time.Date(2026, 1, 15, 19, 20, 30, 0, madrid)
+
+	first := models.FiscalRecord{ID: 1, Kind: models.FiscalRegistration, OrderID: 1, IssuerTaxID: "89890001K", Invoice: "T1-2026/000001", InvoiceType: models.InvoiceSimplified, TaxTotal: 21, Total: 121,
+		Taxes: models.TaxLines{{Vat: 2100, Base: 100, Tax: 21, Total: 121}}}
+	Seal(&first, nil, now)
+
+	refund := models.FiscalRecord{ID: 2, Kind: models.FiscalRegistration, OrderID: 2, IssuerTaxID: "89890001K", Invoice: "R1-2026/000001", InvoiceType: models.InvoiceRectification,
+		RectifiedInvoice: first.Invoice, RectifiedDate: first.IssueDate, TaxTotal: -21, Total: -121}
+	Seal(&refund, &first, now.Add(time.Minute))
+
+	cancellation := models.FiscalRecord{ID: 3, Kind: models.FiscalCancellation, OrderID: 1, IssuerTaxID: "89890001K", Invoice: first.Invoice, IssueDate: first.IssueDate}
+	Seal(&cancellation, &refund, now.Add(2*time.Minute))
+
+	return []models.FiscalRecord{first, refund, cancellation}
+}
+
+func TestFingerprint(t *testing.T) {
+	records := chainFixture()
+
+	assert.Equal(t, "IDEmisorFactura=89890001K&NumSerieFactura=T1-2026/000001&FechaExpedicionFactura=15-01-2026&TipoFactura=F2&CuotaTotal=0.21&ImporteTotal=1.21&Huella=&FechaHoraHusoGenRegistro=2026-01-15T19:20:30+01:00", Fingerprint(records[0]))
+	assert.Equal(t, "IDEmisorFacturaAnulada=89890001K&NumSerieFacturaAnulada=T1-2026/000001&FechaExpedicionFacturaAnulada=15-01-2026&Huella="+records[1].Hash+"&FechaHoraHusoGenRegistro=2026-01-15T19:22:30+01:00", Fingerprint(records[2]))
+	assert.Len(t, records[0].Hash, 64)
+	assert.Equal(t, strings.ToUpper(records[0].Hash), records[0].Hash)
+}
+
+func TestVerify(t *testing.T) {
+	records := chainFixture()
+
+	verification := Verify(records)
+	assert.True(t, verification.Valid)
+	assert.Equal(t, 3, verification.Records)
+	assert.Empty(t, verification.Breaks)
+
+	// Changing the amount of a record breaks it
+	records[1].Total = -100
+	verification = Verify(records)
+	assert.False(t, verification.Valid)
+	assert.Equal(t, []models.ChainBreak{{RecordID: 2, Reason: "hash does not match the record content"}}, verification.Breaks)
+
+	// Removing a record breaks the following one
+	records = chainFixture()
+	verification = Verify([]models.FiscalRecord{records[0], records[2]})
+	assert.False(t, verification.Valid)
+	assert.Equal(t, uint(3), verification.Breaks[0].RecordID)
+}
+
+func TestExport(t *testing.T) {
+	records := chainFixture()
+
+	out, err := Export("Café Sol SL", "89890001K", records, nil)
+	assert.NoError(t, err)
+
+	document := string(out)
+	assert.True(t, strings.HasPrefix(document, "<?xml"))
+	assert.Contains(t, document, "<sum1:NIF>89890001K</sum1:NIF>")
+	assert.Contains(t, document, "<sum1:PrimerRegistro>S</sum1:PrimerRegistro>")
+	assert.Contains(t, document, "<sum1:TipoFactura>R5</sum1:TipoFactura>")
+	assert.Contains(t, document, "<sum1:Huella>"+records[0].Hash+"</sum1:Huella>")
+	assert.Contains(t, document, "<sum1:RegistroAnulacion>")
+	assert.Contains(t, document, "<sum1:TipoImpositivo>21.00</sum1:TipoImpositivo>")
+	assert.Equal(t, 1, strings.Count(document, "<sum1:PrimerRegistro>"), "Only the first record should start the chain")
+}
@@ -0,0 +1,167 @@
+package fiscal
+
+import (
+	"encoding/xml"
+
+	"postui_api/pkg/models"
+)
+
+// Verifactu XML namespaces
+const (
+	namespaceSuministroLR          = "https://www2.agenciatributaria.gob.es/static_files/common/internet/dep/aplicaciones/es/aeat/tike/cont/ws/SuministroLR.xsd"
+	namespaceSuministroInformacion = "https://www2.agenciatributaria.gob.es/static_files/common/internet/dep/aplicaciones/es/aeat/tike/cont/ws/SuministroInformacion.xsd"
+)
+
+type registro struct {
+	XMLName  xml.Name          `xml:"sum:RegFactuSistemaFacturacion"`
+	Sum      string            `xml:"xmlns:sum,attr"`
+	Sum1     string            `xml:"xmlns:sum1,attr"`
+	Cabecera cabecera          `xml:"sum:Cabecera"`
+	Facturas []registroFactura `xml:"sum:RegistroFactura"`
+}
+
+type cabecera struct {
+	NombreRazon string `xml:"sum1:ObligadoEmision>sum1:NombreRazon"`
+	NIF         string `xml:"sum1:ObligadoEmision>sum1:NIF"`
+}
+
+type registroFactura struct {
+	Alta      *registroAlta      `xml:"sum1:RegistroAlta,omitempty"`
+	Anulacion *registroAnulacion `xml:"sum1:RegistroAnulacion,omitempty"`
+}
+
+type idFactura struct {
+	IDEmisorFactura        string `xml:"sum1:IDEmisorFactura"`
+	NumSerieFactura        string `xml:"sum1:NumSerieFactura"`
+	FechaExpedicionFactura string `xml:"sum1:FechaExpedicionFactura"`
+}
+
+type idFacturaAnulada struct {
+	IDEmisorFacturaAnulada        string `xml:"sum1:IDEmisorFacturaAnulada"`
+	NumSerieFacturaAnulada        string `xml:"sum1:NumSerieFacturaAnulada"`
+	FechaExpedicionFacturaAnulada string `xml:"sum1:FechaExpedicionFacturaAnulada"`
+}
+
+type registroAlta struct {
+	IDVersion                string            `xml:"sum1:IDVersion"`
+	IDFactura                idFactura         `xml:"sum1:IDFactura"`
+	NombreRazonEmisor        string            `xml:"sum1:NombreRazonEmisor"`
+	TipoFactura              string            `xml:"sum1:TipoFactura"`
+	FacturasRectificadas     *idFactura        `xml:"sum1:FacturasRectificadas>sum1:IDFacturaRectificada,omitempty"`
+	DescripcionOperacion     string            `xml:"sum1:DescripcionOperacion"`
+	Desglose                 []detalleDesglose `xml:"sum1:Desglose>sum1:DetalleDesglose"`
+	CuotaTotal               string            `xml:"sum1:CuotaTotal"`
+	ImporteTotal             string            `xml:"sum1:ImporteTotal"`
+	Encadenamiento           encadenamiento    `xml:"sum1:Encadenamiento"`
+	FechaHoraHusoGenRegistro string            `xml:"sum1:FechaHoraHusoGenRegistro"`
+	TipoHuella               string            `xml:"sum1:TipoHuella"`
+	Huella                   string            `xml:"sum1:Huella"`
+}
+
+type registroAnulacion struct {
+	IDVersion                string           `xml:"sum1:IDVersion"`
+	IDFactura                idFacturaAnulada `xml:"sum1:IDFactura"`
+	Encadenamiento           encadenamiento   `xml:"sum1:Encadenamiento"`
+	FechaHoraHusoGenRegistro string           `xml:"sum1:FechaHoraHusoGenRegistro"`
+	TipoHuella               string           `xml:"sum1:TipoHuella"`
+	Huella                   string           `xml:"sum1:Huella"`
+}
+
+type detalleDesglose struct {
+	ClaveRegimen                  string `xml:"sum1:ClaveRegimen"`
+	CalificacionOperacion         string `xml:"sum1:CalificacionOperacion"`
+	TipoImpositivo                string `xml:"sum1:TipoImpositivo"`
+	BaseImponibleOimporteNoSujeto string `xml:"sum1:BaseImponibleOimporteNoSujeto"`
+	CuotaRepercutida              string `xml:"sum1:CuotaRepercutida"`
+	TipoRecargoEquivalencia       string `xml:"sum1:TipoRecargoEquivalencia,omitempty"`
+	CuotaRecargoEquivalencia      string `xml:"sum1:CuotaRecargoEquivalencia,omitempty"`
+}
+
+type encadenamiento struct {
+	PrimerRegistro   string            `xml:"sum1:PrimerRegistro,omitempty"`
+	RegistroAnterior *registroAnterior `xml:"sum1:RegistroAnterior,omitempty"`
+}
+
+type registroAnterior struct {
+	IDEmisorFactura        string `xml:"sum1:IDEmisorFactura"`
+	NumSerieFactura        string `xml:"sum1:NumSerieFactura"`
+	FechaExpedicionFactura string `xml:"sum1:FechaExpedicionFactura"`
+	Huella                 string `xml:"sum1:Huella"`
+}
+
+// Export writes records as a Verifactu XML submission of issuer. previous is
+// the record preceding the first of records, or nil when records start the
+// chain.
+func Export(issuerName, issuerTaxID string, records []models.FiscalRecord, previous *models.FiscalRecord) ([]byte, error) {
+	document := registro{
+		Sum:      namespaceSuministroLR,
+		Sum1:     namespaceSuministroInformacion,
+		Cabecera: cabecera{NombreRazon: issuerName, NIF: issuerTaxID},
+	}
+
+	for i := range records {
+		record := records[i]
+
+		chain := encadenamiento{PrimerRegistro: "S"}
+		if previous != nil {
+			chain = encadenamiento{RegistroAnterior: &registroAnterior{
+				IDEmisorFactura:        previous.IssuerTaxID,
+				NumSerieFactura:        previous.Invoice,
+				FechaExpedicionFactura: previous.IssueDate,
+				Huella:                 previous.Hash,
+			}}
+		}
+
+		if record.Kind == models.FiscalCancellation {
+			document.Facturas = append(document.Facturas, registroFactura{Anulacion: &registroAnulacion{
+				IDVersion:                "1.0",
+				IDFactura:                idFacturaAnulada{record.IssuerTaxID, record.Invoice, record.IssueDate},
+				Encadenamiento:           chain,
+				FechaHoraHusoGenRegistro: record.GeneratedAt,
+				TipoHuella:               "01",
+				Huella:                   record.Hash,
+			}})
+		} else {
+			alta := &registroAlta{
+				IDVersion:                "1.0",
+				IDFactura:                idFactura{record.IssuerTaxID, record.Invoice, record.IssueDate},
+				NombreRazonEmisor:        issuerName,
+				TipoFactura:              record.InvoiceType,
+				DescripcionOperacion:     "Venta",
+				CuotaTotal:               record.TaxTotal.String(),
+				ImporteTotal:             record.Total.String(),
+				Encadenamiento:           chain,
+				FechaHoraHusoGenRegistro: record.GeneratedAt,
+				TipoHuella:               "01",
+				Huella:                   record.Hash,
+			}
+			if record.RectifiedInvoice != "" {
+				alta.DescripcionOperacion = "Devolución"
+				alta.FacturasRectificadas = &idFactura{record.IssuerTaxID, record.RectifiedInvoice, record.RectifiedDate}
+			}
+			for _, tax := range record.Taxes {
+				detail := detalleDesglose{
+					ClaveRegimen:                  "01",
+					CalificacionOperacion:         "S1",
+					TipoImpositivo:                models.Money(tax.Vat).String(),
+					BaseImponibleOimporteNoSujeto: tax.Base.String(),
+					CuotaRepercutida:              tax.Tax.String(),
+				}
+				if tax.Surcharge != 0 {
+					detail.TipoRecargoEquivalencia = models.Money(tax.Surcharge).String()
+					detail.CuotaRecargoEquivalencia = tax.SurchargeTax.String()
+				}
+				alta.Desglose = append(alta.Desglose, detail)
+			}
+			document.Facturas = append(document.Facturas, registroFactura{Alta: alta})
+		}
+
+		previous = &records[i]
+	}
+
+	out, err := xml.MarshalIndent(document, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), out...), nil
+}
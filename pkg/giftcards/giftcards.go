@@ -0,0 +1,78 @@
+// Package giftcards generates and checks gift card numbers and computes
+// their expiry.
+package giftcards
+
+import (
+	"crypto/rand"
+	"math/big"
+	"strings"
+	"time"
+)
+
+// NumberLength is the number of digits of generated card numbers, the last
+// one being a Luhn check digit so that mistyped numbers are caught
+const NumberLength = 16
+
+// Generate returns a random card number of NumberLength digits.
+func Generate() (string, error) {
+	digits := make([]byte, NumberLength-1)
+	for i := range digits {
+		n, err := rand.Int(rand.Reader, big.NewInt(10))
+		if err != nil {
+			return "", err
+		}
+		digits[i] = byte('0' + n.Int64())
+	}
+	// Numbers never start with 0, so that tools reading them as integers keep every digit
+	if digits[0] == '0' {
+		digits[0] = '9'
+	}
+
+	return string(digits) + string(checkDigit(string(digits))), nil
+}
+
+// Normalize removes the spaces and dashes numbers are printed with.
+func Normalize(number string) string {
+	return strings.NewReplacer(" ", "", "-", "").Replace(number)
+}
+
+// Valid reports whether number is made of digits and its last one is the
+// Luhn check digit of the others.
+func Valid(number string) bool {
+	if len(number) < 2 {
+		return false
+	}
+	for _, r := range number {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return checkDigit(number[:len(number)-1]) == number[len(number)-1]
+}
+
+// ExpiresAt returns when a card issued at issued expires after months, or
+// nil when months is 0 and cards never expire.
+func ExpiresAt(issued time.Time, months uint) *time.Time {
+	if months == 0 {
+		return nil
+	}
+	expires := issued.AddDate(0, int(months), 0)
+	return &expires
+}
+
+// checkDigit returns the Luhn check digit of digits
+func checkDigit(digits string) byte {
+	sum := 0
+	for i := len(digits) - 1; i >= 0; i-- {
+		d := int(digits[i] - '0')
+		// Every other digit is doubled, starting with the rightmost one
+		if (len(digits)-1-i)%2 == 0 {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+	}
+	return byte('0' + (10-sum%10)%10)
+}
@@ -0,0 +1,36 @@
+package giftcards
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValid(t *testing.T) {
+	assert.True(t, Valid("79927398713"))
+	assert.True(t, Valid("4539578763621486"))
+	assert.False(t, Valid("79927398710"), "A wrong check digit should be caught")
+	assert.False(t, Valid("7992739871A"))
+	assert.False(t, Valid("7"))
+}
+
+func TestGenerate(t *testing.T) {
+	number, err := Generate()
+
+	assert.NoError(t, err)
+	assert.Len(t, number, NumberLength)
+	assert.NotEqual(t, byte('0'), number[0])
+	assert.True(t, Valid(number))
+}
+
+func TestNormalize(t *testing.T) {
+	assert.Equal(t, "4539578763621486", Normalize("4539 5787-6362 1486"))
+}
+
+func TestExpiresAt(t *testing.T) {
+	issued := time.Date(2026, 1, 15, 10, 0, 0, 0, time.UTC)
+
+	assert.Nil(t, ExpiresAt(issued, 0))
+	assert.Equal(t, time.Date(2027, 1, 15, 10, 0, 0, 0, time.UTC), *ExpiresAt(issued, 12))
+}
@@ -0,0 +1,123 @@
+// Package loyalty computes the points customers earn on their purchases and
+// how redeemed, refunded and expired points are taken off what they earned.
+package loyalty
+
+import (
+	"strings"
+	"time"
+
+	"postui_api/pkg/models"
+)
+
+// NormalMultiplier is the multiplier, in basis points, of products no rule
+// applies to
+const NormalMultiplier = 10000
+
+// unitsPerMultiplier is the number of minor units of a currency unit times
+// the basis points of NormalMultiplier
+const unitsPerMultiplier = 100 * NormalMultiplier
+
+// Item is a sold line together with the category of its product.
+type Item struct {
+	Line     models.OrderLine
+	Category string
+}
+
+// Normalize removes the spaces, dashes, dots and parentheses card numbers
+// and phones are written with.
+func Normalize(identifier string) string {
+	return strings.NewReplacer(" ", "", "-", "", ".", "", "(", "", ")", "").Replace(identifier)
+}
+
+// Multiplier returns the multiplier of the rule that applies to a product,
+// a rule for the product itself taking precedence over one for its
+// category.
+func Multiplier(productID uint, category string, rules []models.LoyaltyRule) uint {
+	multiplier := uint(NormalMultiplier)
+	for _, rule := range rules {
+		switch {
+		case rule.ProductID != nil:
+			if *rule.ProductID == productID {
+				return rule.Multiplier
+			}
+		case rule.Category != "" && rule.Category == category:
+			multiplier = rule.Multiplier
+		}
+	}
+	return multiplier
+}
+
+// Earn returns the points earned by items at perUnit points per currency
+// unit. Points of each line are rounded down.
+func Earn(items []Item, rules []models.LoyaltyRule, perUnit uint) int64 {
+	var points int64
+	for _, item := range items {
+		if item.Line.Total <= 0 {
+			continue
+		}
+		multiplier := Multiplier(item.Line.ProductID, item.Category, rules)
+		points += int64(item.Line.Total) * int64(perUnit) * int64(multiplier) / unitsPerMultiplier
+	}
+	return points
+}
+
+// Points returns the points to redeem to pay amount when a point is worth
+// value, rounded up so that the points cover the amount.
+func Points(amount, value models.Money) int64 {
+	if value <= 0 || amount <= 0 {
+		return 0
+	}
+	return int64((amount + value - 1) / value)
+}
+
+// Reversal returns the points to take back from the earned points of an
+// order of total once refunded of it has been refunded, reversed points
+// having already been taken back by previous refunds. Computing from the
+// cumulative refunded amount keeps rounding from drifting across partial
+// refunds, and a full refund takes back every earned point.
+func Reversal(earned, reversed int64, total, refunded models.Money) int64 {
+	if total <= 0 || earned <= 0 {
+		return 0
+	}
+	if refunded > total {
+		refunded = total
+	}
+
+	due := earned * int64(refunded) / int64(total)
+	if due <= reversed {
+		return 0
+	}
+	return due - reversed
+}
+
+// Consume takes points off the remaining points of entries, in the order
+// given, and returns what is taken off each of them. Points left once every
+// entry is used up are not taken.
+func Consume(entries []models.LoyaltyEntry, points int64) []int64 {
+	taken := make([]int64, len(entries))
+	for i, entry := range entries {
+		if points <= 0 {
+			break
+		}
+		take := entry.Remaining
+		if take > points {
+			take = points
+		}
+		if take <= 0 {
+			continue
+		}
+		taken[i] = take
+		points -= take
+	}
+	return taken
+}
+
+// ExpiresAt returns when points earned at earned expire, or nil when points
+// never expire.
+func ExpiresAt(earned time.Time, months uint) *time.Time {
+	if months == 0 {
+		return nil
+	}
+	expires := earned.AddDate(0, int(months), 0)
+	return &expires
+}
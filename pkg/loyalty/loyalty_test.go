@@ -0,0 +1,75 @@
+package loyalty
+
+import (
+	"testing"
+	"time"
+
+	"postui_api/pkg/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func uintPtr(v uint) *uint { return &v }
+
+func TestNormalize(t *testing.T) {
+	assert.Equal(t, "+34600123456", Normalize("+34 600-12.34 56"))
+	assert.Equal(t, "600123456", Normalize("(600) 123 456"))
+}
+
+func TestMultiplier(t *testing.T) {
+	rules := []models.LoyaltyRule{
+		{Category: "wine", Multiplier: 20000},
+		{ProductID: uintPtr(7), Multiplier: 0},
+	}
+
+	assert.Equal(t, uint(NormalMultiplier), Multiplier(1, "bread", rules))
+	assert.Equal(t, uint(20000), Multiplier(2, "wine", rules))
+	assert.Equal(t, uint(0), Multiplier(7, "wine", rules), "A product rule should take precedence over its category")
+}
+
+func TestEarn(t *testing.T) {
+	items := []Item{
+		{Line: models.OrderLine{ProductID: 1, Total: 1250}, Category: "bread"}, // 12.50 -> 12 points
+		{Line: models.OrderLine{ProductID: 2, Total: 999}, Category: "wine"},   // 9.99 doubled -> 19 points
+		{Line: models.OrderLine{ProductID: 7, Total: 5000}, Category: "wine"},  // Earns nothing
+	}
+	rules := []models.LoyaltyRule{
+		{Category: "wine", Multiplier: 20000},
+		{ProductID: uintPtr(7), Multiplier: 0},
+	}
+
+	assert.Equal(t, int64(31), Earn(items, rules, 1))
+	assert.Equal(t, int64(0), Earn(items, rules, 0))
+}
+
+func TestPoints(t *testing.T) {
+	assert.Equal(t, int64(100), Points(100, 1))
+	assert.Equal(t, int64(34), Points(101, 3), "Points should cover the amount")
+	assert.Equal(t, int64(0), Points(100, 0))
+}
+
+func TestReversal(t *testing.T) {
+	// Three partial refunds of a third each take back every earned point
+	first := Reversal(100, 0, 3000, 1000)
+	second := Reversal(100, first, 3000, 2000)
+	third := Reversal(100, first+second, 3000, 3000)
+
+	assert.Equal(t, int64(33), first)
+	assert.Equal(t, int64(33), second)
+	assert.Equal(t, int64(34), third)
+	assert.Equal(t, int64(0), Reversal(100, 100, 3000, 3000))
+}
+
+func TestConsume(t *testing.T) {
+	entries := []models.LoyaltyEntry{{Remaining: 30}, {Remaining: 0}, {Remaining: 50}}
+
+	assert.Equal(t, []int64{30, 0, 20}, Consume(entries, 50))
+	assert.Equal(t, []int64{30, 0, 50}, Consume(entries, 500))
+}
+
+func TestExpiresAt(t *testing.T) {
+	earned := time.Date(2026, 1, 15, 10, 0, 0, 0, time.UTC)
+
+	assert.Nil(t, ExpiresAt(earned, 0))
+	assert.Equal(t, time.Date(2026, 7, 15, 10, 0, 0, 0, time.UTC), *ExpiresAt(earned, 6))
+}
@@ -0,0 +1,152 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+	"postui_api/pkg/cache"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// IdempotencyHeader is the request header holding the key of a write that
+// may be retried
+const IdempotencyHeader = "Idempotency-Key"
+
+// maxIdempotencyKey is the longest key accepted
+const maxIdempotencyKey = 255
+
+// IdempotencyWindow is how long a stored response is replayed. It is read
+// from IDEMPOTENCY_WINDOW (ex: "30m") and defaults to a day.
+var IdempotencyWindow = idempotencyWindow()
+
+func idempotencyWindow() time.Duration {
+	if window, err := time.ParseDuration(os.Getenv("IDEMPOTENCY_WINDOW")); err == nil && window > 0 {
+		return window
+	}
+	return 24 * time.Hour
+}
+
+// storedResponse is what is kept under an idempotency key. Status stays 0
+// while the first request is being processed.
+type storedResponse struct {
+	Hash        string `json:"hash"`
+	Status      int    `json:"status"`
+	ContentType string `json:"content_type,omitempty"`
+	Body        []byte `json:"body,omitempty"`
+}
+
+// recordingWriter keeps a copy of the response body written by the handler
+type recordingWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *recordingWriter) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *recordingWriter) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
+
+// Idempotency replays the response of a request sent again with the same
+// Idempotency-Key header and body, so that retries do not write twice.
+// Keys are scoped by user, method and path. Reusing a key with another body
+// is refused with 422, and a retry arriving while the first request is still
+// processed with 409. Requests without the header, or sent while the cache
+// is unreachable, are processed as usual. Server errors are not stored so
+// the request can be retried.
+func Idempotency(store cache.Cache, window time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(IdempotencyHeader)
+		if key == "" {
+			c.Next()
+			return
+		}
+		if len(key) > maxIdempotencyKey {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Idempotency-Key is too long"})
+			c.Abort()
+			return
+		}
+
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "could not read request body"})
+			c.Abort()
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		sum := sha256.Sum256(body)
+		hash := hex.EncodeToString(sum[:])
+		cacheKey := "idempotency_" + c.GetString("username") + "_" + c.Request.Method + "_" + c.Request.URL.Path + "_" + key
+		ctx := c.Request.Context()
+
+		pending, _ := json.Marshal(storedResponse{Hash: hash})
+		acquired, err := store.SetNX(ctx, cacheKey, pending, window).Result()
+		if err != nil {
+			c.Next()
+			return
+		}
+
+		if !acquired {
+			replayResponse(c, store, cacheKey, hash)
+			return
+		}
+
+		writer := &recordingWriter{ResponseWriter: c.Writer}
+		c.Writer = writer
+
+		completed := false
+		defer func() {
+			if !completed {
+				store.Del(ctx, cacheKey)
+			}
+		}()
+
+		c.Next()
+
+		if writer.Status() >= http.StatusInternalServerError {
+			return
+		}
+
+		stored, _ := json.Marshal(storedResponse{Hash: hash, Status: writer.Status(), ContentType: writer.Header().Get("Content-Type"), Body: writer.body.Bytes()})
+		store.Set(ctx, cacheKey, stored, window)
+		completed = true
+	}
+}
+
+// replayResponse answers a retry with the response stored under cacheKey
+func replayResponse(c *gin.Context, store cache.Cache, cacheKey, hash string) {
+	defer c.Abort()
+
+	raw, err := store.Get(c.Request.Context(), cacheKey).Bytes()
+	if err != nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "a request with this Idempotency-Key is being processed"})
+		return
+	}
+
+	var stored storedResponse
+	if err := json.Unmarshal(raw, &stored); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	switch {
+	case stored.Hash != hash:
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "Idempotency-Key was already used with another request body"})
+	case stored.Status == 0:
+		c.JSON(http.StatusConflict, gin.H{"error": "a request with this Idempotency-Key is being processed"})
+	default:
+		c.Header("Idempotent-Replayed", "true")
+		c.Data(stored.Status, stored.ContentType, stored.Body)
+	}
+}
@@ -0,0 +1,150 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"postui_api/pkg/cache"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-redis/redis/v8"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func idempotentRouter(store cache.Cache, calls *int, status int) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.POST("/orders", Idempotency(store, time.Hour), func(c *gin.Context) {
+		*calls++
+		c.JSON(status, gin.H{"data": *calls})
+	})
+	return r
+}
+
+func idempotentRequest(key, body string) *http.Request {
+	req := httptest.NewRequest(http.MethodPost, "/orders", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	if key != "" {
+		req.Header.Set(IdempotencyHeader, key)
+	}
+	return req
+}
+
+func TestIdempotencyWithoutKey(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// The cache is not touched
+	store := cache.NewMockCache(ctrl)
+
+	calls := 0
+	r := idempotentRouter(store, &calls, http.StatusCreated)
+
+	for i := 0; i < 2; i++ {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, idempotentRequest("", `{"total":121}`))
+		assert.Equal(t, http.StatusCreated, w.Code)
+	}
+	assert.Equal(t, 2, calls)
+}
+
+func TestIdempotencyStoresFirstResponse(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := cache.NewMockCache(ctrl)
+	cacheKey := "idempotency__POST_/orders_abc"
+
+	store.EXPECT().SetNX(gomock.Any(), cacheKey, gomock.Any(), time.Hour).Return(redis.NewBoolResult(true, nil)).Times(1)
+	store.EXPECT().
+		Set(gomock.Any(), cacheKey, gomock.Any(), time.Hour).
+		DoAndReturn(func(_ interface{}, _ string, value interface{}, _ time.Duration) *redis.StatusCmd {
+			var stored storedResponse
+			assert.NoError(t, json.Unmarshal(value.([]byte), &stored))
+			assert.Equal(t, http.StatusCreated, stored.Status)
+			assert.JSONEq(t, `{"data":1}`, string(stored.Body))
+			return redis.NewStatusResult("OK", nil)
+		}).Times(1)
+	store.EXPECT().Del(gomock.Any(), gomock.Any()).Times(0)
+
+	calls := 0
+	r := idempotentRouter(store, &calls, http.StatusCreated)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, idempotentRequest("abc", `{"total":121}`))
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, 1, calls)
+}
+
+func TestIdempotencyReplaysRetry(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := cache.NewMockCache(ctrl)
+	body := `{"total":121}`
+
+	// The first request with the same body already completed
+	sum := sha256.Sum256([]byte(body))
+	stored, _ := json.Marshal(storedResponse{Hash: hex.EncodeToString(sum[:]), Status: http.StatusCreated, ContentType: "application/json; charset=utf-8", Body: []byte(`{"data":1}`)})
+	store.EXPECT().SetNX(gomock.Any(), gomock.Any(), gomock.Any(), time.Hour).Return(redis.NewBoolResult(false, nil)).Times(1)
+	store.EXPECT().Get(gomock.Any(), gomock.Any()).Return(redis.NewStringResult(string(stored), nil)).Times(1)
+
+	calls := 0
+	r := idempotentRouter(store, &calls, http.StatusCreated)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, idempotentRequest("abc", body))
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, `{"data":1}`, w.Body.String())
+	assert.Equal(t, "true", w.Header().Get("Idempotent-Replayed"))
+	assert.Equal(t, 0, calls, "the handler must not run again")
+}
+
+func TestIdempotencyConflictingBody(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := cache.NewMockCache(ctrl)
+
+	// The key was used for another body
+	stored, _ := json.Marshal(storedResponse{Hash: "other", Status: http.StatusCreated, Body: []byte(`{"data":1}`)})
+	store.EXPECT().SetNX(gomock.Any(), gomock.Any(), gomock.Any(), time.Hour).Return(redis.NewBoolResult(false, nil)).Times(1)
+	store.EXPECT().Get(gomock.Any(), gomock.Any()).Return(redis.NewStringResult(string(stored), nil)).Times(1)
+
+	calls := 0
+	r := idempotentRouter(store, &calls, http.StatusCreated)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, idempotentRequest("abc", `{"total":242}`))
+
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	assert.Equal(t, 0, calls)
+}
+
+func TestIdempotencyForgetsServerErrors(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := cache.NewMockCache(ctrl)
+
+	// The key is released so that the retry is processed
+	store.EXPECT().SetNX(gomock.Any(), gomock.Any(), gomock.Any(), time.Hour).Return(redis.NewBoolResult(true, nil)).Times(1)
+	store.EXPECT().Del(gomock.Any(), "idempotency__POST_/orders_abc").Return(redis.NewIntResult(1, nil)).Times(1)
+	store.EXPECT().Set(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+
+	calls := 0
+	r := idempotentRouter(store, &calls, http.StatusInternalServerError)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, idempotentRequest("abc", `{"total":121}`))
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+}
@@ -0,0 +1,69 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+
+	"github.com/shopspring/decimal"
+)
+
+// BarcodeValue tells what scale labels embed after the item code
+type BarcodeValue string
+
+const (
+	BarcodeWeight BarcodeValue = "weight" // In kg
+	BarcodePrice  BarcodeValue = "price"  // Price of the label, with VAT
+)
+
+// BarcodeFormat describes the EAN-13 labels printed by scales: a prefix of
+// the restricted circulation range (20 to 29), the item code of the
+// product, the weight or price, then the check digit. The prefix, item code
+// and value take the 12 digits before the check digit.
+type BarcodeFormat struct {
+	Prefix      string       `json:"prefix" binding:"required,numeric,min=1,max=3"` // (ex: 21)
+	ItemDigits  uint8        `json:"item_digits" binding:"required,min=1,max=10"`
+	Value       BarcodeValue `json:"value" binding:"required,oneof=weight price"`
+	ValueDigits uint8        `json:"value_digits" binding:"required,min=1,max=10"`
+	Decimals    uint8        `json:"decimals" binding:"max=3"` // Of the value (ex: 3 for weights in grams, 2 for prices in cents)
+}
+
+// BarcodeFormats are the formats of scale labels stored as JSON
+type BarcodeFormats []BarcodeFormat
+
+// Value implements driver.Valuer
+func (f BarcodeFormats) Value() (driver.Value, error) {
+	if f == nil {
+		return "[]", nil
+	}
+	b, err := json.Marshal(f)
+	return string(b), err
+}
+
+// Scan implements sql.Scanner
+func (f *BarcodeFormats) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		*f = nil
+		return nil
+	case []byte:
+		return json.Unmarshal(v, f)
+	case string:
+		return json.Unmarshal([]byte(v), f)
+	default:
+		return errors.New("unsupported type for BarcodeFormats")
+	}
+}
+
+// BarcodeResolution is the product a scanned barcode stands for and the
+// quantity to sell. Quantity is 1 for the barcode of a product, the weight
+// of weight labels, and the weight the price pays for on price labels,
+// whose lines charge Price when sent with the label as price_label.
+type BarcodeResolution struct {
+	Barcode  string          `json:"barcode"`
+	Product  Product         `json:"product"`
+	Variant  *ProductVariant `json:"variant,omitempty"` // Set on the barcodes of variants
+	Quantity decimal.Decimal `json:"quantity"`
+	Price    *Money          `json:"price,omitempty"`  // In cents, set on price labels
+	Format   *BarcodeFormat  `json:"format,omitempty"` // Set on scale labels
+}
@@ -0,0 +1,30 @@
+package models
+
+import "github.com/shopspring/decimal"
+
+type CheckoutLine struct {
+	ProductID  uint            `json:"product_id" binding:"required"`
+	VariantID  *uint           `json:"variant_id"`                   // Required for products sold by variant
+	Quantity   decimal.Decimal `json:"quantity" binding:"required"`  // decimal.NewFromString("136.02")
+	PriceLabel string          `json:"price_label" binding:"max=32"` // Price label the line was scanned from, whose printed price is charged
+}
+
+type Checkout struct {
+	CustomerID    *uint          `json:"customer_id"`
+	CustomerName  string         `json:"customer_name"`               // Defaults to the name of the customer
+	LoyaltyID     string         `json:"loyalty_id" binding:"max=32"` // Card number or phone of the account earning points
+	CashoutNumber uint           `json:"cashout_number" binding:"required"`
+	Lines         []CheckoutLine `json:"lines" binding:"required,min=1,dive"`
+	CouponCode    string         `json:"coupon_code" binding:"max=40"`
+}
+
+// OrderDetail is an order together with its lines, VAT breakdown and the
+// promotions applied to it. The products of the lines are only included on
+// request.
+type OrderDetail struct {
+	Order
+	Lines     []OrderLine     `json:"lines"`
+	Taxes     []TaxLine       `json:"taxes"`
+	Discounts []OrderDiscount `json:"discounts,omitempty"`
+	Products  []Product       `json:"products,omitempty"`
+}
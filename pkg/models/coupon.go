@@ -0,0 +1,43 @@
+package models
+
+import "time"
+
+// Coupon is a code given at checkout to apply its promotion, usually a
+// coupon only one
+type Coupon struct {
+	ID               uint       `json:"id" gorm:"primary_key"`
+	Code             string     `json:"code" gorm:"size:40;uniqueIndex"` // Upper case
+	PromotionID      uint       `json:"promotion_id" gorm:"index"`
+	MaxRedemptions   uint       `json:"max_redemptions"`    // 0 for unlimited, 1 for single use
+	Redemptions      uint       `json:"redemptions"`        // Not reversed
+	PerCustomerLimit uint       `json:"per_customer_limit"` // 0 for unlimited
+	ExpiresAt        *time.Time `json:"expires_at,omitempty"`
+	Active           bool       `json:"active"`
+	CreatedAt        time.Time  `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt        time.Time  `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// CreateCoupons creates a coupon with the given code, or Count coupons with
+// generated codes
+type CreateCoupons struct {
+	PromotionID      uint       `json:"promotion_id" binding:"required"`
+	Code             string     `json:"code" binding:"omitempty,alphanum,max=40"`
+	Count            uint       `json:"count" binding:"omitempty,max=1000"` // Codes to generate, defaults to 1
+	Prefix           string     `json:"prefix" binding:"omitempty,alphanum,max=8"`
+	MaxRedemptions   uint       `json:"max_redemptions"`    // 0 for unlimited, 1 for single use
+	PerCustomerLimit uint       `json:"per_customer_limit"` // 0 for unlimited
+	ExpiresAt        *time.Time `json:"expires_at"`
+}
+
+// CouponRedemption records the use of a coupon by an order. Redemptions of
+// voided or fully refunded orders are reversed, which frees the coupon.
+type CouponRedemption struct {
+	ID         uint       `json:"id" gorm:"primary_key"`
+	CouponID   uint       `json:"coupon_id" gorm:"index"`
+	OrderID    uint       `json:"order_id" gorm:"index"`
+	CustomerID *uint      `json:"customer_id,omitempty" gorm:"index"`
+	Customer   string     `json:"customer" gorm:"index"`
+	Amount     Money      `json:"amount"` // In cents, discount given
+	ReversedAt *time.Time `json:"reversed_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at" gorm:"autoCreateTime"`
+}
@@ -0,0 +1,32 @@
+package models
+
+import "time"
+
+// Customer is someone orders can be linked to, for invoices addressed to
+// them and for their purchase history
+type Customer struct {
+	ID        uint      `json:"id" gorm:"primary_key"`
+	Name      string    `json:"name" gorm:"size:128;index"`
+	TaxID     string    `json:"tax_id" gorm:"size:32;index"`
+	Email     string    `json:"email" gorm:"size:254;index"`
+	Phone     string    `json:"phone" gorm:"size:32;index"`
+	Address   string    `json:"address"`
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+type CreateCustomer struct {
+	Name    string `json:"name" binding:"required,max=128"`
+	TaxID   string `json:"tax_id" binding:"max=32"`
+	Email   string `json:"email" binding:"omitempty,email,max=254"`
+	Phone   string `json:"phone" binding:"max=32"`
+	Address string `json:"address" binding:"max=256"`
+}
+
+type UpdateCustomer struct {
+	Name    string `json:"name" binding:"max=128"`
+	TaxID   string `json:"tax_id" binding:"max=32"`
+	Email   string `json:"email" binding:"omitempty,email,max=254"`
+	Phone   string `json:"phone" binding:"max=32"`
+	Address string `json:"address" binding:"max=256"`
+}
@@ -0,0 +1,63 @@
+package models
+
+import "time"
+
+// FiscalRecordKind tells whether a fiscal record issues or cancels an invoice
+type FiscalRecordKind string
+
+const (
+	FiscalRegistration FiscalRecordKind = "registration"
+	FiscalCancellation FiscalRecordKind = "cancellation"
+)
+
+// Invoice types of fiscal registration records
+const (
+	InvoiceSimplified    = "F2" // Ticket
+	InvoiceRectification = "R5" // Refund of a ticket
+)
+
+// FiscalRecord is a link of the tamper-evident chain of issued tickets. Its
+// hash covers its content and the hash of the previous record, so changing
+// or removing a record breaks every following one. Records are append-only:
+// voids and refunds add records instead of changing the original one.
+type FiscalRecord struct {
+	ID               uint             `json:"id" gorm:"primary_key"`
+	Kind             FiscalRecordKind `json:"kind" gorm:"size:16;not null"`
+	OrderID          uint             `json:"order_id" gorm:"index;not null"`
+	IssuerTaxID      string           `json:"issuer_tax_id" gorm:"size:32"`
+	Invoice          string           `json:"invoice" gorm:"size:48;index"`
+	IssueDate        string           `json:"issue_date" gorm:"size:10"`            // dd-mm-yyyy
+	InvoiceType      string           `json:"invoice_type,omitempty" gorm:"size:2"` // Registrations only
+	RectifiedInvoice string           `json:"rectified_invoice,omitempty" gorm:"size:48"`
+	RectifiedDate    string           `json:"rectified_date,omitempty" gorm:"size:10"` // dd-mm-yyyy
+	TaxTotal         Money            `json:"tax_total"`                               // In cents
+	Total            Money            `json:"total"`                                   // In cents, with VAT
+	Taxes            TaxLines         `json:"taxes" gorm:"type:jsonb"`
+	GeneratedAt      string           `json:"generated_at" gorm:"size:25"` // RFC 3339 with the offset, as hashed
+	PreviousHash     string           `json:"previous_hash" gorm:"size:64;uniqueIndex"`
+	Hash             string           `json:"hash" gorm:"size:64;uniqueIndex;not null"`
+	CreatedAt        time.Time        `json:"created_at" gorm:"autoCreateTime"`
+}
+
+// FiscalChain is the head of the chain of fiscal records. Its row is locked
+// while a record is appended so that two records never follow the same one.
+type FiscalChain struct {
+	ID     uint `gorm:"primary_key"`
+	Length uint
+}
+
+// FiscalChainID is the primary key of the single FiscalChain row
+const FiscalChainID = 1
+
+// ChainBreak is a fiscal record that does not follow from the previous one
+type ChainBreak struct {
+	RecordID uint   `json:"record_id"`
+	Reason   string `json:"reason"`
+}
+
+// ChainVerification is the result of walking the chain of fiscal records
+type ChainVerification struct {
+	Records int          `json:"records"`
+	Valid   bool         `json:"valid"`
+	Breaks  []ChainBreak `json:"breaks"`
+}
@@ -0,0 +1,41 @@
+package models
+
+import "time"
+
+// GiftCardTransactionKind is what changed the balance of a gift card
+type GiftCardTransactionKind string
+
+const (
+	GiftCardIssue  GiftCardTransactionKind = "issue"  // Card sold
+	GiftCardRedeem GiftCardTransactionKind = "redeem" // Card used to pay an order
+	GiftCardRefund GiftCardTransactionKind = "refund" // Money given back to the card
+	GiftCardExpire GiftCardTransactionKind = "expire" // Balance left when the card expired
+	GiftCardVoid   GiftCardTransactionKind = "void"   // Balance left when the order that sold the card was voided
+)
+
+// GiftCard is a stored balance issued by selling a gift card product. Its
+// number is printed as a barcode on the card.
+type GiftCard struct {
+	ID           uint                  `json:"id" gorm:"primary_key"`
+	Number       string                `json:"number" gorm:"size:32;uniqueIndex"`
+	Balance      Money                 `json:"balance"`                // In cents
+	Currency     string                `json:"currency" gorm:"size:3"` // ISO 4217 (ex: EUR)
+	ExpiresAt    *time.Time            `json:"expires_at,omitempty"`
+	OrderLineID  *uint                 `json:"order_line_id,omitempty" gorm:"index"` // Line that sold the card
+	VoidedAt     *time.Time            `json:"voided_at,omitempty"`                  // Set when the order that sold the card was voided
+	CreatedAt    time.Time             `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt    time.Time             `json:"updated_at" gorm:"autoUpdateTime"`
+	Transactions []GiftCardTransaction `json:"transactions,omitempty" gorm:"foreignKey:GiftCardID"`
+}
+
+// GiftCardTransaction is an entry of the balance ledger of a gift card
+type GiftCardTransaction struct {
+	ID         uint                    `json:"id" gorm:"primary_key"`
+	GiftCardID uint                    `json:"gift_card_id" gorm:"index;not null"`
+	Kind       GiftCardTransactionKind `json:"kind" gorm:"size:16"`
+	Amount     Money                   `json:"amount"`  // In cents, negative when taken off the balance
+	Balance    Money                   `json:"balance"` // In cents, after the transaction
+	OrderID    *uint                   `json:"order_id,omitempty" gorm:"index"`
+	PaymentID  *uint                   `json:"payment_id,omitempty"`
+	CreatedAt  time.Time               `json:"created_at" gorm:"autoCreateTime"`
+}
@@ -0,0 +1,30 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// InvoiceSeries numbers the paid orders of a cashout number for a year
+// without gaps. Prefixes being alphanumeric, the dashes of the code keep the
+// series of different prefixes and cashout numbers apart. Its counter is only incremented inside the transaction that
+// pays an order, so a rollback gives the number back.
+type InvoiceSeries struct {
+	ID            uint      `json:"id" gorm:"primary_key"`
+	Code          string    `json:"code" gorm:"size:32;uniqueIndex;not null"` // (ex: T-1-2026)
+	CashoutNumber uint      `json:"cashout_number"`
+	Year          int       `json:"year"`
+	LastNumber    uint      `json:"last_number"`
+	CreatedAt     time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt     time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// InvoiceSeriesCode returns the code of the series of cashoutNumber for year
+func InvoiceSeriesCode(prefix string, cashoutNumber uint, year int) string {
+	return fmt.Sprintf("%s-%d-%d", prefix, cashoutNumber, year)
+}
+
+// Invoice returns the printed invoice number of number in the series
+func (s InvoiceSeries) Invoice(number uint) string {
+	return fmt.Sprintf("%s/%06d", s.Code, number)
+}
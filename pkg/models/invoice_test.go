@@ -0,0 +1,17 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInvoice(t *testing.T) {
+	series := InvoiceSeries{Code: InvoiceSeriesCode("T", 3, 2026)}
+
+	assert.Equal(t, "T-3-2026", series.Code)
+	assert.Equal(t, "T-3-2026/000042", series.Invoice(42))
+
+	// Prefix T1 on cashout 2 and prefix T on cashout 12 are different series
+	assert.NotEqual(t, InvoiceSeriesCode("T1", 2, 2026), InvoiceSeriesCode("T", 12, 2026))
+}
@@ -0,0 +1,61 @@
+package models
+
+import "time"
+
+// LoyaltyEntryKind is what changed the points of a loyalty account
+type LoyaltyEntryKind string
+
+const (
+	LoyaltyEarn    LoyaltyEntryKind = "earn"    // Points earned by a sale
+	LoyaltyRedeem  LoyaltyEntryKind = "redeem"  // Points used to pay an order
+	LoyaltyCredit  LoyaltyEntryKind = "credit"  // Redeemed points given back
+	LoyaltyReverse LoyaltyEntryKind = "reverse" // Earned points taken back by a refund or void
+	LoyaltyExpire  LoyaltyEntryKind = "expire"  // Earned points left when they expired
+)
+
+// LoyaltyAccount holds the points earned by the card number or phone
+// captured on orders. The account is opened by the first sale earning
+// points.
+type LoyaltyAccount struct {
+	ID         uint           `json:"id" gorm:"primary_key"`
+	Identifier string         `json:"identifier" gorm:"size:32;uniqueIndex"` // Card number or phone
+	CustomerID *uint          `json:"customer_id,omitempty" gorm:"index"`
+	Points     int64          `json:"points"` // Balance, negative when refunds took back points already redeemed
+	CreatedAt  time.Time      `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt  time.Time      `json:"updated_at" gorm:"autoUpdateTime"`
+	Entries    []LoyaltyEntry `json:"entries,omitempty" gorm:"foreignKey:AccountID"`
+}
+
+// LoyaltyEntry is an entry of the points ledger of an account. Earned and
+// credited points are redeemed oldest expiry first, so those entries keep
+// the points they have left.
+type LoyaltyEntry struct {
+	ID        uint             `json:"id" gorm:"primary_key"`
+	AccountID uint             `json:"account_id" gorm:"index;not null"`
+	Kind      LoyaltyEntryKind `json:"kind" gorm:"size:16"`
+	Points    int64            `json:"points"`              // Negative when taken off the balance
+	Balance   int64            `json:"balance"`             // After the entry
+	Remaining int64            `json:"remaining,omitempty"` // Points of earn and credit entries not used yet
+	ExpiresAt *time.Time       `json:"expires_at,omitempty" gorm:"index"`
+	OrderID   *uint            `json:"order_id,omitempty" gorm:"index"`
+	PaymentID *uint            `json:"payment_id,omitempty" gorm:"index"`
+	CreatedAt time.Time        `json:"created_at" gorm:"autoCreateTime"`
+}
+
+// LoyaltyRule multiplies the points earned on a product or the products of
+// a category. A rule for the product takes precedence over one for its
+// category.
+type LoyaltyRule struct {
+	ID         uint      `json:"id" gorm:"primary_key"`
+	ProductID  *uint     `json:"product_id,omitempty" gorm:"index"`
+	Category   string    `json:"category,omitempty" gorm:"size:64;index"`
+	Multiplier uint      `json:"multiplier"` // In basis points (ex: 20000 for double points, 0 for none)
+	CreatedAt  time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt  time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+type CreateLoyaltyRule struct {
+	ProductID  *uint  `json:"product_id"`
+	Category   string `json:"category" binding:"required_without=ProductID,max=64"`
+	Multiplier *uint  `json:"multiplier" binding:"required,max=100000"` // In basis points
+}
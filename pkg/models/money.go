@@ -0,0 +1,38 @@
+package models
+
+import (
+	"os"
+
+	"github.com/shopspring/decimal"
+)
+
+// Money is an amount in minor units (cents) of a currency
+type Money int64
+
+// MaxMoney is the largest amount accepted for a single price or total
+const MaxMoney = 99999999999
+
+// DefaultCurrency is the ISO 4217 code used for amounts created without one
+var DefaultCurrency = defaultCurrency()
+
+func defaultCurrency() string {
+	if currency := os.Getenv("CURRENCY"); currency != "" {
+		return currency
+	}
+	return "EUR"
+}
+
+// Valid reports whether m is a non-negative amount not greater than MaxMoney
+func (m Money) Valid() bool {
+	return m >= 0 && m <= MaxMoney
+}
+
+// Decimal returns m in major units
+func (m Money) Decimal() decimal.Decimal {
+	return decimal.New(int64(m), -2)
+}
+
+// String formats m in major units with two decimals (ex: "12.34")
+func (m Money) String() string {
+	return m.Decimal().StringFixed(2)
+}
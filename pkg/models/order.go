@@ -7,25 +7,64 @@ import (
 )
 
 type Order struct {
-	ID            uint          `json:"id" gorm:"primary_key"`
-	Vendor        string        `json:"customer"`
-	Total         uint16        `json:"total"` // In cents, with VAT
-	LinesID       pq.Int64Array `json:"lines_id" gorm:"type:integer[]" swaggertype:"array,integer" swaggerformat:"int64"`
-	CashoutNumber uint          `json:"cashout_number"`
-	CreatedAt     time.Time     `json:"created_at" gorm:"autoCreateTime"`
-	UpdatedAt     time.Time     `json:"updated_at" gorm:"autoUpdateTime"`
+	ID            uint        `json:"id" gorm:"primary_key"`
+	CustomerID    *uint       `json:"customer_id,omitempty" gorm:"index"`
+	CustomerName  string      `json:"customer_name"`                                  // Name of the customer, the only record of it on orders without a customer
+	LoyaltyID     string      `json:"loyalty_id,omitempty" gorm:"size:32;index"`      // Card number or phone of the loyalty account the sale earns points for
+	Cashier       string      `json:"cashier,omitempty" gorm:"size:64;index"`         // Username of who rang up the order
+	ClientID      *string     `json:"client_id,omitempty" gorm:"size:36;uniqueIndex"` // UUID given by the terminal to orders synced after being sold offline
+	Total         Money       `json:"total"`                                          // In cents, with VAT
+	Taxes         TaxLines    `json:"taxes" gorm:"type:jsonb"`                        // Breakdown per rate, computed with the rates in effect when the lines were priced
+	Currency      string      `json:"currency" gorm:"size:3"`                         // ISO 4217 (ex: EUR)
+	CashoutNumber uint        `json:"cashout_number"`
+	Status        OrderStatus `json:"status" gorm:"size:16;index"`
+	RefundOfID    *uint       `json:"refund_of_id,omitempty" gorm:"index"`    // Set on refunds, ID of the refunded order
+	RefundTender  Tender      `json:"refund_tender,omitempty" gorm:"size:16"` // Set on refunds
+	ZReportID     *uint       `json:"z_report_id,omitempty" gorm:"index"`     // Set once closed by a Z report
+	InvoiceNumber uint        `json:"invoice_number,omitempty"`               // Sequential in its series, set when paid
+	Invoice       string      `json:"invoice,omitempty" gorm:"size:48"`       // Printed invoice number (ex: T-1-2026/000042)
+	CreatedAt     time.Time   `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt     time.Time   `json:"updated_at" gorm:"autoUpdateTime"`
+	Lines         []OrderLine `json:"lines,omitempty" gorm:"foreignKey:OrderID;constraint:OnDelete:CASCADE"`
 }
 
+// CreateOrder total is optional: it is computed from the lines and, when
+// sent, must match the computed one. The lines must not belong to another
+// order yet.
 type CreateOrder struct {
-	Vendor        string        `json:"customer" binding:"required"`
-	Total         uint16        `json:"total" binding:"required"` // In cents, with VAT
+	CustomerID    *uint         `json:"customer_id"`
+	CustomerName  string        `json:"customer_name" binding:"required_without_all=CustomerID Customer"` // Defaults to the name of the customer
+	Customer      string        `json:"customer"`                                                         // Former name of customer_name, still accepted
+	LoyaltyID     string        `json:"loyalty_id" binding:"max=32"`
+	Total         Money         `json:"total" binding:"min=0,max=99999999999"` // In cents, with VAT
 	LinesID       pq.Int64Array `json:"lines_id" binding:"required" gorm:"type:bigint[]" swaggertype:"array,integer" swaggerformat:"int64"`
 	CashoutNumber uint          `json:"cashout_number" binding:"required"`
 }
 
+// Name is the customer name sent, under its current or its former key
+func (o CreateOrder) Name() string {
+	if o.CustomerName != "" {
+		return o.CustomerName
+	}
+	return o.Customer
+}
+
+// UpdateOrder lines are left unchanged unless lines_id is sent, in which
+// case the lines of the order left out are deleted
 type UpdateOrder struct {
-	Vendor        string  `json:"customer"`
-	Total         uint16  `json:"total"` // In cents, with VAT
-	LinesID       []int64 `json:"lines_id" binding:"required" gorm:"type:integer[]" swaggertype:"array,integer" swaggerformat:"int64"`
+	CustomerID    *uint   `json:"customer_id"`
+	CustomerName  string  `json:"customer_name"`
+	Customer      string  `json:"customer"` // Former name of customer_name, still accepted
+	LoyaltyID     string  `json:"loyalty_id" binding:"max=32"`
+	Total         Money   `json:"total" binding:"min=0,max=99999999999"`                      // In cents, with VAT
+	LinesID       []int64 `json:"lines_id" swaggertype:"array,integer" swaggerformat:"int64"` // Replaces the lines of the order when sent
 	CashoutNumber uint    `json:"cashout_number"`
 }
+
+// Name is the customer name sent, under its current or its former key
+func (o UpdateOrder) Name() string {
+	if o.CustomerName != "" {
+		return o.CustomerName
+	}
+	return o.Customer
+}
@@ -7,28 +7,40 @@ import (
 )
 
 type OrderLine struct {
-	ID        uint            `json:"id" gorm:"primary_key"`
-	ProductID uint            `json:"product_id"`
-	Quantity  decimal.Decimal `json:"quantity" gorm:"type:decimal(10,2)"` // decimal.NewFromString("136.02")
-	Price     uint16          `json:"price"`                              // In Cents, with VAT
-	Vat       uint16          `json:"vat"`                                // (ex: 2100 for 21.00%)
-	Total     uint16          `json:"total"`                              // In Cents, with VAT
-	CreatedAt time.Time       `json:"created_at" gorm:"autoCreateTime"`
-	UpdatedAt time.Time       `json:"updated_at" gorm:"autoUpdateTime"`
+	ID             uint            `json:"id" gorm:"primary_key"`
+	OrderID        *uint           `json:"order_id,omitempty" gorm:"index"` // Set once the line is part of an order
+	ProductID      uint            `json:"product_id"`
+	VariantID      *uint           `json:"variant_id,omitempty" gorm:"index"`        // Set on lines of products sold by variant
+	Quantity       decimal.Decimal `json:"quantity" gorm:"type:decimal(10,3)"`       // decimal.NewFromString("1.235"), kg to the gram for products sold by weight
+	Price          Money           `json:"price"`                                    // In Cents, with VAT
+	Currency       string          `json:"currency" gorm:"size:3"`                   // ISO 4217 (ex: EUR)
+	Vat            uint16          `json:"vat"`                                      // (ex: 2100 for 21.00%)
+	Surcharge      uint16          `json:"surcharge,omitempty"`                      // Equivalence surcharge (ex: 520 for 5.20%)
+	Total          Money           `json:"total"`                                    // In Cents, with VAT, after Discount
+	Discount       Money           `json:"discount"`                                 // In Cents, taken off price × quantity by promotions
+	RefundOfLineID *uint           `json:"refund_of_line_id,omitempty" gorm:"index"` // Set on refund lines, which carry negative quantities and totals
+	Stocked        bool            `json:"stocked,omitempty"`                        // Set when checkout took the quantity from stock, which is returned if the order is voided or deleted unpaid
+	CreatedAt      time.Time       `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt      time.Time       `json:"updated_at" gorm:"autoUpdateTime"`
 }
 
+// CreateOrderLine amounts are optional: they are computed from the product
+// catalog and, when sent, must match the computed ones
 type CreateOrderLine struct {
-	ProductID uint            `json:"product_id" binding:"required"`
-	Quantity  decimal.Decimal `json:"quantity" gorm:"type:decimal(10,2)" binding:"required"` // decimal.NewFromString("136.02")
-	Price     uint16          `json:"price" binding:"required"`                              // In Cents, with VAT
-	Vat       uint16          `json:"vat" binding:"required"`                                // (ex: 2100 for 21.00%)
-	Total     uint16          `json:"total" binding:"required"`                              // In Cents
+	ProductID  uint            `json:"product_id" binding:"required"`
+	VariantID  *uint           `json:"variant_id"`                                            // Required for products sold by variant
+	Quantity   decimal.Decimal `json:"quantity" gorm:"type:decimal(10,2)" binding:"required"` // decimal.NewFromString("136.02")
+	Price      Money           `json:"price" binding:"min=0,max=99999999999"`                 // In Cents, with VAT
+	Vat        uint16          `json:"vat"`                                                   // (ex: 2100 for 21.00%)
+	Total      Money           `json:"total" binding:"min=0,max=99999999999"`                 // In Cents
+	PriceLabel string          `json:"price_label" binding:"max=32"`                          // Price label the line was scanned from, whose printed price is charged
 }
 
 type UpdateOrderLine struct {
 	ProductID uint            `json:"product_id"`
-	Quantity  decimal.Decimal `json:"quantity" gorm:"type:decimal(10,2)"` // decimal.NewFromString("136.02")
-	Price     uint16          `json:"price"`                              // In Cents, with VAT
-	Vat       uint16          `json:"vat"`                                // (ex: 2100 for 21.00%)
-	Total     uint16          `json:"total"`                              // In Cents, with VAT
+	VariantID *uint           `json:"variant_id"`                            // Required when changing to a product sold by variant
+	Quantity  decimal.Decimal `json:"quantity" gorm:"type:decimal(10,2)"`    // decimal.NewFromString("136.02")
+	Price     Money           `json:"price" binding:"min=0,max=99999999999"` // In Cents, with VAT
+	Vat       uint16          `json:"vat"`                                   // (ex: 2100 for 21.00%)
+	Total     Money           `json:"total" binding:"min=0,max=99999999999"` // In Cents, with VAT
 }
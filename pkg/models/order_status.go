@@ -0,0 +1,50 @@
+package models
+
+import "time"
+
+type OrderStatus string
+
+const (
+	OrderOpen     OrderStatus = "open"
+	OrderParked   OrderStatus = "parked"
+	OrderPaid     OrderStatus = "paid"
+	OrderVoided   OrderStatus = "voided"
+	OrderRefunded OrderStatus = "refunded"
+)
+
+// orderTransitions lists the statuses an order can move to from each status
+var orderTransitions = map[OrderStatus][]OrderStatus{
+	OrderOpen:   {OrderParked, OrderPaid, OrderVoided},
+	OrderParked: {OrderOpen, OrderVoided},
+	OrderPaid:   {OrderVoided, OrderRefunded},
+}
+
+// CanTransitionTo reports whether an order in status s can move to status to
+func (s OrderStatus) CanTransitionTo(to OrderStatus) bool {
+	for _, allowed := range orderTransitions[s] {
+		if allowed == to {
+			return true
+		}
+	}
+	return false
+}
+
+// Editable reports whether an order in status s can still be updated or deleted
+func (s OrderStatus) Editable() bool {
+	return s == OrderOpen || s == OrderParked
+}
+
+// OrderTransition records a status change of an order
+type OrderTransition struct {
+	ID        uint        `json:"id" gorm:"primary_key"`
+	OrderID   uint        `json:"order_id" gorm:"index"`
+	From      OrderStatus `json:"from" gorm:"size:16"`
+	To        OrderStatus `json:"to" gorm:"size:16"`
+	Reason    string      `json:"reason"`
+	Username  string      `json:"username"`
+	CreatedAt time.Time   `json:"created_at" gorm:"autoCreateTime"`
+}
+
+type TransitionOrder struct {
+	Reason string `json:"reason"` // Required to void an order
+}
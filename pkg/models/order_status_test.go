@@ -0,0 +1,28 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOrderStatusCanTransitionTo(t *testing.T) {
+	assert.True(t, OrderOpen.CanTransitionTo(OrderPaid))
+	assert.True(t, OrderOpen.CanTransitionTo(OrderParked))
+	assert.True(t, OrderParked.CanTransitionTo(OrderOpen))
+	assert.True(t, OrderPaid.CanTransitionTo(OrderVoided))
+	assert.True(t, OrderPaid.CanTransitionTo(OrderRefunded))
+
+	assert.False(t, OrderParked.CanTransitionTo(OrderPaid), "Parked orders must be resumed before being paid")
+	assert.False(t, OrderPaid.CanTransitionTo(OrderOpen), "Paid orders cannot be reopened")
+	assert.False(t, OrderVoided.CanTransitionTo(OrderOpen), "Voided orders are final")
+	assert.False(t, OrderRefunded.CanTransitionTo(OrderPaid), "Refunded orders are final")
+}
+
+func TestOrderStatusEditable(t *testing.T) {
+	assert.True(t, OrderOpen.Editable())
+	assert.True(t, OrderParked.Editable())
+	assert.False(t, OrderPaid.Editable())
+	assert.False(t, OrderVoided.Editable())
+	assert.False(t, OrderRefunded.Editable())
+}
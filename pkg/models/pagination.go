@@ -6,6 +6,12 @@ type PaginatedProductResponse struct {
 	Pagination Pagination `json:"pagination"`
 }
 
+// PaginatedOrderResponse represents a paginated list of orders
+type PaginatedOrderResponse struct {
+	Data       []Order    `json:"data"`
+	Pagination Pagination `json:"pagination"`
+}
+
 // Pagination contains pagination information
 type Pagination struct {
 	Page       int   `json:"page"`
@@ -0,0 +1,28 @@
+package models
+
+import "time"
+
+// Payment is a tender used to settle part or all of an order. An order can
+// be split across several payments. Payments of refunds are negative.
+type Payment struct {
+	ID        uint      `json:"id" gorm:"primary_key"`
+	OrderID   uint      `json:"order_id" gorm:"index;not null"`
+	Tender    Tender    `json:"tender" gorm:"size:16;not null"`
+	Amount    Money     `json:"amount"`              // In cents, applied to the order total
+	Tendered  Money     `json:"tendered"`            // In cents, handed over by the customer
+	Change    Money     `json:"change"`              // In cents, given back in cash
+	Reference string    `json:"reference,omitempty"` // Card authorization, voucher, gift card number or loyalty account...
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
+}
+
+// CreatePayment adds a payment to an open order. For cash, the amount
+// handed over can be sent as tendered and the change is computed; other
+// tenders cannot exceed what is left to pay. Gift cards are given by their
+// number as reference, loyalty points by the card number or phone of their
+// account, which defaults to the one captured on the order.
+type CreatePayment struct {
+	Tender    Tender `json:"tender" binding:"required,oneof=cash card voucher store_credit gift_card loyalty_points"`
+	Amount    Money  `json:"amount" binding:"min=0,max=99999999999"`   // In cents, defaults to what is left to pay
+	Tendered  Money  `json:"tendered" binding:"min=0,max=99999999999"` // In cents, cash only
+	Reference string `json:"reference" binding:"max=64"`
+}
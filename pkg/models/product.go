@@ -4,31 +4,84 @@ import (
 	"time"
 
 	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
 )
 
 type Product struct {
 	ID            uint            `json:"id" gorm:"primary_key"`
 	Name          string          `json:"name"`
-	Price         uint16          `json:"price"`                           // In cents, with VAT
-	Vat           uint16          `json:"vat"`                             // (ex: 2100 for 21.00%)
-	Stock         decimal.Decimal `json:"stock" gorm:"type:decimal(10,2)"` // decimal.NewFromString("136.02")
-	BarcodeNumber string          `json:"barcode_number"`
+	Price         Money           `json:"price"`                                    // In cents, with VAT
+	Currency      string          `json:"currency" gorm:"size:3"`                   // ISO 4217 (ex: EUR)
+	Vat           uint16          `json:"vat"`                                      // (ex: 2100 for 21.00%), unless TaxCategoryID is set
+	TaxCategoryID *uint           `json:"tax_category_id,omitempty" gorm:"index"`   // Rates of the category in effect on the day of the sale apply
+	Stock         decimal.Decimal `json:"stock" gorm:"type:decimal(10,3)"`          // decimal.NewFromString("136.025")
+	BarcodeNumber string          `json:"barcode_number"`                           // Main barcode, also one of Barcodes
+	ItemCode      string          `json:"item_code,omitempty" gorm:"size:16;index"` // Code of the product on scale labels (PLU), without leading zeros, unique among products
+	Category      string          `json:"category" gorm:"size:64;index"`            // Promotions can target every product of a category
+	GiftCard      bool            `json:"gift_card"`                                // Each unit sold issues a gift card of the price
+	Revision      int64           `json:"revision" gorm:"->;index"`                 // Bumped by the database on every change, see ProductChanges
 	CreatedAt     time.Time       `json:"created_at" gorm:"autoCreateTime"`
 	UpdatedAt     time.Time       `json:"updated_at" gorm:"autoUpdateTime"`
+	DeletedAt     gorm.DeletedAt  `json:"deleted_at,omitempty" gorm:"index" swaggertype:"string" format:"date-time"` // Set on deleted products, which are kept as tombstones
+
+	Barcodes          []ProductBarcode  `json:"barcodes,omitempty" gorm:"foreignKey:ProductID;constraint:OnDelete:CASCADE"` // Every barcode the product is scanned with, BarcodeNumber included
+	VariantAttributes VariantAttributes `json:"variant_attributes,omitempty" gorm:"type:jsonb"`                             // Set on products sold by variant (ex: ["size", "color"])
+	Variants          []ProductVariant  `json:"variants,omitempty" gorm:"foreignKey:ProductID"`
+}
+
+// ProductChanges are the products created, updated or deleted after a
+// cursor, in the order they changed. Deleted products come with DeletedAt
+// set. Cursor is the revision to send as since to get the next changes.
+type ProductChanges struct {
+	Products []Product `json:"products"`
+	Cursor   int64     `json:"cursor"`
+	HasMore  bool      `json:"has_more"` // More changes may be waiting past the cursor
+}
+
+// ProductBarcode is one of the barcodes of a product, such as the EAN of a
+// unit and the one of a pack, or an old and a new EAN. A barcode belongs to
+// a single product.
+type ProductBarcode struct {
+	ID        uint            `json:"id" gorm:"primary_key"`
+	ProductID uint            `json:"product_id" gorm:"not null;index"`
+	Code      string          `json:"code" gorm:"size:32;not null;uniqueIndex"`
+	VariantID *uint           `json:"variant_id,omitempty" gorm:"index"`                     // Set on the barcode of a variant
+	Quantity  decimal.Decimal `json:"quantity" gorm:"type:decimal(10,3);not null;default:1"` // Units sold per scan (ex: 6 for a pack of six)
+	CreatedAt time.Time       `json:"created_at" gorm:"autoCreateTime"`
+}
+
+type CreateProductBarcode struct {
+	Code     string          `json:"code" binding:"required,max=32"`
+	Quantity decimal.Decimal `json:"quantity"` // Units sold per scan, defaults to 1
 }
 
 type CreateProducts struct {
 	Name          string          `json:"name" binding:"required"`
-	Price         uint16          `json:"price" binding:"required"` // In cents, with VAT
-	Vat           uint16          `json:"vat" binding:"required"`   // (ex: 2100 for 21.00%)
+	Price         Money           `json:"price" binding:"required,min=0,max=99999999999"` // In cents, with VAT
+	Currency      string          `json:"currency" binding:"omitempty,iso4217"`           // ISO 4217, defaults to the CURRENCY environment variable
+	Vat           uint16          `json:"vat" binding:"required_without=TaxCategoryID"`   // (ex: 2100 for 21.00%)
+	TaxCategoryID *uint           `json:"tax_category_id"`
 	Stock         decimal.Decimal `json:"stock" gorm:"type:decimal(10,2)" binding:"required"`
-	BarcodeNumber string          `json:"barcode_number" binding:"required"`
+	BarcodeNumber string          `json:"barcode_number" binding:"required,max=32"`
+	ItemCode      string          `json:"item_code" binding:"omitempty,numeric,max=10"`
+	Category      string          `json:"category" binding:"max=64"`
+	GiftCard      bool            `json:"gift_card"`
+
+	Barcodes          []CreateProductBarcode `json:"barcodes" binding:"max=20,dive"`                          // Barcodes other than barcode_number
+	VariantAttributes []string               `json:"variant_attributes" binding:"max=5,dive,required,max=32"` // Attributes the variants of the product differ by, for products sold by variant
 }
 
 type UpdateProduct struct {
 	Name          string          `json:"name"`
-	Price         uint16          `json:"price"` // In cents, with VAT
-	Vat           uint16          `json:"vat"`   // (ex: 2100 for 21.00%)
+	Price         Money           `json:"price" binding:"min=0,max=99999999999"` // In cents, with VAT
+	Currency      string          `json:"currency" binding:"omitempty,iso4217"`  // ISO 4217
+	Vat           uint16          `json:"vat"`                                   // (ex: 2100 for 21.00%)
+	TaxCategoryID *uint           `json:"tax_category_id"`
 	Stock         decimal.Decimal `json:"stock" gorm:"type:decimal(10,2)"`
-	BarcodeNumber string          `json:"barcode_number"`
+	BarcodeNumber string          `json:"barcode_number" binding:"max=32"`
+	ItemCode      string          `json:"item_code" binding:"omitempty,numeric,max=10"`
+	Category      string          `json:"category" binding:"max=64"`
+	GiftCard      bool            `json:"gift_card"`
+
+	Barcodes []CreateProductBarcode `json:"barcodes" binding:"omitempty,max=20,dive"` // Replace the barcodes other than barcode_number when set
 }
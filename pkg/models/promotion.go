@@ -0,0 +1,89 @@
+package models
+
+import "time"
+
+// PromotionKind is the rule a promotion applies
+type PromotionKind string
+
+const (
+	PromotionPercentage PromotionKind = "percentage"      // Percent off the matching lines
+	PromotionFixed      PromotionKind = "fixed"           // Amount off every matching unit
+	PromotionBuyXGetY   PromotionKind = "buy_x_get_y"     // For every BuyQuantity units bought, GetQuantity more are free
+	PromotionBundle     PromotionKind = "bundle"          // BuyQuantity units of a line sold together for Amount
+	PromotionThreshold  PromotionKind = "order_threshold" // Percent or Amount off orders of at least Threshold
+)
+
+// Promotion is a discount rule evaluated when an order is checked out. Line
+// promotions target a product, a category, or every product when neither is
+// set. A line gets at most one line promotion and an order at most one
+// threshold promotion, the one with the highest priority. Coupon only
+// promotions are left out unless one of their coupons is redeemed.
+type Promotion struct {
+	ID          uint          `json:"id" gorm:"primary_key"`
+	Name        string        `json:"name" gorm:"size:64"` // Printed on receipts
+	Kind        PromotionKind `json:"kind" gorm:"size:16"`
+	ProductID   *uint         `json:"product_id,omitempty" gorm:"index"`
+	Category    string        `json:"category" gorm:"size:64"`
+	Percent     uint16        `json:"percent"`      // (ex: 1500 for 15.00%)
+	Amount      Money         `json:"amount"`       // In cents, with VAT
+	BuyQuantity uint          `json:"buy_quantity"` // Units to buy for buy_x_get_y, units of a bundle
+	GetQuantity uint          `json:"get_quantity"` // Free units for buy_x_get_y
+	Threshold   Money         `json:"threshold"`    // In cents, order total reaching the threshold
+	Priority    int           `json:"priority"`     // Higher first
+	StartsAt    *time.Time    `json:"starts_at,omitempty"`
+	EndsAt      *time.Time    `json:"ends_at,omitempty"` // Excluded
+	Active      bool          `json:"active"`
+	CouponOnly  bool          `json:"coupon_only"` // Only applied when one of its coupon codes is given
+	CreatedAt   time.Time     `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt   time.Time     `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+type CreatePromotion struct {
+	Name        string        `json:"name" binding:"required,max=64"`
+	Kind        PromotionKind `json:"kind" binding:"required,oneof=percentage fixed buy_x_get_y bundle order_threshold"`
+	ProductID   *uint         `json:"product_id"`
+	Category    string        `json:"category" binding:"max=64"`
+	Percent     uint16        `json:"percent" binding:"max=10000"`               // (ex: 1500 for 15.00%)
+	Amount      Money         `json:"amount" binding:"min=0,max=99999999999"`    // In cents, with VAT
+	BuyQuantity uint          `json:"buy_quantity"`                              // Units to buy for buy_x_get_y, units of a bundle
+	GetQuantity uint          `json:"get_quantity"`                              // Free units for buy_x_get_y
+	Threshold   Money         `json:"threshold" binding:"min=0,max=99999999999"` // In cents
+	Priority    int           `json:"priority"`
+	StartsAt    *time.Time    `json:"starts_at"`
+	EndsAt      *time.Time    `json:"ends_at"`
+	Active      *bool         `json:"active"` // Defaults to true
+	CouponOnly  bool          `json:"coupon_only"`
+}
+
+// UpdatePromotion changes the given fields of a promotion, omitted ones are
+// left unchanged
+type UpdatePromotion struct {
+	Name        string        `json:"name" binding:"max=64"`
+	Kind        PromotionKind `json:"kind" binding:"omitempty,oneof=percentage fixed buy_x_get_y bundle order_threshold"`
+	ProductID   *uint         `json:"product_id"`
+	Category    *string       `json:"category" binding:"omitempty,max=64"`
+	Percent     *uint16       `json:"percent" binding:"omitempty,max=10000"`
+	Amount      *Money        `json:"amount" binding:"omitempty,min=0,max=99999999999"`
+	BuyQuantity *uint         `json:"buy_quantity"`
+	GetQuantity *uint         `json:"get_quantity"`
+	Threshold   *Money        `json:"threshold" binding:"omitempty,min=0,max=99999999999"`
+	Priority    *int          `json:"priority"`
+	StartsAt    *time.Time    `json:"starts_at"`
+	EndsAt      *time.Time    `json:"ends_at"`
+	Active      *bool         `json:"active"`
+	CouponOnly  *bool         `json:"coupon_only"`
+}
+
+// OrderDiscount records a promotion applied to an order. Line promotions
+// reference the discounted line, threshold promotions are spread over the
+// lines and have no OrderLineID.
+type OrderDiscount struct {
+	ID          uint      `json:"id" gorm:"primary_key"`
+	OrderID     uint      `json:"order_id" gorm:"index"`
+	OrderLineID *uint     `json:"order_line_id,omitempty"`
+	PromotionID uint      `json:"promotion_id"`
+	Name        string    `json:"name" gorm:"size:64"`
+	Amount      Money     `json:"amount"`     // In cents, with VAT, taken off the order
+	Line        int       `json:"-" gorm:"-"` // Index of the discounted line before lines are stored, -1 for the order
+	CreatedAt   time.Time `json:"created_at" gorm:"autoCreateTime"`
+}
@@ -0,0 +1,21 @@
+package models
+
+import "github.com/shopspring/decimal"
+
+// RefundLine is the quantity to refund of a line of the refunded order
+type RefundLine struct {
+	OrderLineID uint            `json:"order_line_id" binding:"required"`
+	Quantity    decimal.Decimal `json:"quantity" binding:"required"` // decimal.NewFromString("136.02")
+	Restock     bool            `json:"restock"`
+}
+
+// CreateRefund refunds the given lines, or everything not refunded yet when
+// no lines are given
+type CreateRefund struct {
+	Reason        string       `json:"reason" binding:"required"`
+	Tender        Tender       `json:"tender" binding:"required,oneof=cash card voucher store_credit gift_card"`
+	GiftCard      string       `json:"gift_card" binding:"max=32"` // Number of the card credited when refunding to a gift card
+	CashoutNumber uint         `json:"cashout_number"`             // Defaults to the one of the refunded order
+	Restock       bool         `json:"restock"`                    // Used for every line of a full refund
+	Lines         []RefundLine `json:"lines" binding:"omitempty,dive"`
+}
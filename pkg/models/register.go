@@ -0,0 +1,79 @@
+package models
+
+import "time"
+
+// SessionStatus is the state of a register session
+type SessionStatus string
+
+const (
+	SessionOpen   SessionStatus = "open"
+	SessionClosed SessionStatus = "closed"
+)
+
+// MovementKind tells whether cash was put into or taken out of a drawer
+type MovementKind string
+
+const (
+	MovementPayIn  MovementKind = "pay_in"
+	MovementPayOut MovementKind = "pay_out"
+)
+
+// RegisterSession is the period between opening and closing the cash drawer
+// of a cashout number. Only one session per cashout number can be open.
+type RegisterSession struct {
+	ID            uint                `json:"id" gorm:"primary_key"`
+	CashoutNumber uint                `json:"cashout_number" gorm:"index;not null"`
+	Status        SessionStatus       `json:"status" gorm:"size:16;index"`
+	Currency      string              `json:"currency" gorm:"size:3"` // ISO 4217 (ex: EUR)
+	OpeningFloat  Money               `json:"opening_float"`          // In cents
+	ExpectedCash  Money               `json:"expected_cash"`          // In cents, set on close
+	CountedCash   Money               `json:"counted_cash"`           // In cents, set on close
+	Variance      Money               `json:"variance"`               // In cents, counted minus expected
+	OpenedBy      string              `json:"opened_by"`
+	ClosedBy      string              `json:"closed_by,omitempty"`
+	OpenedAt      time.Time           `json:"opened_at" gorm:"autoCreateTime"`
+	ClosedAt      *time.Time          `json:"closed_at,omitempty"`
+	Movements     []CashMovement      `json:"movements,omitempty" gorm:"foreignKey:SessionID"`
+	Counts        []DenominationCount `json:"counts,omitempty" gorm:"foreignKey:SessionID"`
+}
+
+// CashMovement is cash put into or taken out of a drawer outside of a sale
+type CashMovement struct {
+	ID        uint         `json:"id" gorm:"primary_key"`
+	SessionID uint         `json:"session_id" gorm:"index;not null"`
+	Kind      MovementKind `json:"kind" gorm:"size:16"`
+	Amount    Money        `json:"amount"` // In cents, always positive
+	Reason    string       `json:"reason"`
+	Username  string       `json:"username"`
+	CreatedAt time.Time    `json:"created_at" gorm:"autoCreateTime"`
+}
+
+// DenominationCount is how many bills or coins of a value were counted when
+// closing a session
+type DenominationCount struct {
+	ID           uint  `json:"id" gorm:"primary_key"`
+	SessionID    uint  `json:"session_id" gorm:"index;not null"`
+	Denomination Money `json:"denomination"` // In cents (ex: 500 for a 5.00 bill)
+	Quantity     uint  `json:"quantity"`
+}
+
+type OpenSession struct {
+	CashoutNumber uint  `json:"cashout_number" binding:"required"`
+	OpeningFloat  Money `json:"opening_float" binding:"min=0,max=99999999999"` // In cents
+}
+
+type CreateCashMovement struct {
+	Kind   MovementKind `json:"kind" binding:"required,oneof=pay_in pay_out"`
+	Amount Money        `json:"amount" binding:"required,min=1,max=99999999999"` // In cents
+	Reason string       `json:"reason" binding:"required"`
+}
+
+type CountDenomination struct {
+	Denomination Money `json:"denomination" binding:"required,min=1,max=99999999999"` // In cents
+	Quantity     uint  `json:"quantity"`
+}
+
+// CloseSession holds the cash counted in the drawer, per denomination
+type CloseSession struct {
+	Counts []CountDenomination `json:"counts" binding:"required,dive"`
+}
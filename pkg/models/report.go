@@ -0,0 +1,58 @@
+package models
+
+import "time"
+
+// SalesSummary totals the sales and refunds of a period. Refunds are netted
+// against sales, so NetSales and Taxes are what was actually kept.
+type SalesSummary struct {
+	From         time.Time `json:"from"`
+	To           time.Time `json:"to"`
+	Currency     string    `json:"currency"`
+	SalesCount   int       `json:"sales_count"`
+	GrossSales   Money     `json:"gross_sales"` // In cents
+	RefundsCount int       `json:"refunds_count"`
+	Refunds      Money     `json:"refunds"`   // In cents, as a positive amount
+	NetSales     Money     `json:"net_sales"` // In cents
+	Discounts    Money     `json:"discounts"` // In cents, given by promotions on the net sales
+	Taxes        []TaxLine `json:"taxes"`
+}
+
+// TenderTotal is what was taken with a tender. Refunds paid back with it
+// are subtracted.
+type TenderTotal struct {
+	Tender Tender `json:"tender"`
+	Count  int    `json:"count"`  // Number of payments
+	Amount Money  `json:"amount"` // In cents
+}
+
+// RegisterReport is an X or Z report of the orders of a cashout number not
+// closed by a Z report yet. Number is only set on Z reports.
+type RegisterReport struct {
+	SalesSummary
+	Number        uint          `json:"number,omitempty"`
+	CashoutNumber uint          `json:"cashout_number"`
+	VoidsCount    int           `json:"voids_count"`
+	Voids         Money         `json:"voids"` // In cents
+	Tenders       []TenderTotal `json:"tenders"`
+}
+
+// ZReport is a numbered end-of-day close of a cashout number. The orders it
+// covers reference it and can no longer be changed.
+type ZReport struct {
+	ID            uint      `json:"id" gorm:"primary_key"`
+	CashoutNumber uint      `json:"cashout_number" gorm:"uniqueIndex:idx_z_reports_cashout_number_number;not null"`
+	Number        uint      `json:"number" gorm:"uniqueIndex:idx_z_reports_cashout_number_number;not null"` // Sequential per cashout number
+	From          time.Time `json:"from"`
+	To            time.Time `json:"to"`
+	Currency      string    `json:"currency" gorm:"size:3"`
+	SalesCount    int       `json:"sales_count"`
+	GrossSales    Money     `json:"gross_sales"` // In cents
+	RefundsCount  int       `json:"refunds_count"`
+	Refunds       Money     `json:"refunds"`   // In cents, as a positive amount
+	NetSales      Money     `json:"net_sales"` // In cents
+	Discounts     Money     `json:"discounts"` // In cents
+	VoidsCount    int       `json:"voids_count"`
+	Voids         Money     `json:"voids"` // In cents
+	Username      string    `json:"username"`
+	CreatedAt     time.Time `json:"created_at" gorm:"autoCreateTime"`
+}
@@ -0,0 +1,61 @@
+package models
+
+import (
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// StoreSettingsID is the primary key of the single StoreSettings row
+const StoreSettingsID = 1
+
+// ReceiptCode is what is printed at the bottom of receipts to scan the order
+type ReceiptCode string
+
+const (
+	ReceiptCodeNone    ReceiptCode = "none"
+	ReceiptCodeCode128 ReceiptCode = "code128"
+	ReceiptCodeQR      ReceiptCode = "qr"
+)
+
+// StoreSettings describes the store and how its receipts are laid out
+type StoreSettings struct {
+	ID             uint           `json:"id" gorm:"primary_key"`
+	Name           string         `json:"name"`
+	TaxID          string         `json:"tax_id"`
+	HeaderLines    pq.StringArray `json:"header_lines" gorm:"type:text[]" swaggertype:"array,string"` // Printed under the name (address, phone...)
+	FooterLines    pq.StringArray `json:"footer_lines" gorm:"type:text[]" swaggertype:"array,string"` // Printed at the bottom
+	Width          uint           `json:"width"`                                                      // Characters per line (ex: 42 or 48)
+	CodePage       uint8          `json:"code_page"`                                                  // ESC/POS character code table (ex: 19 for PC858)
+	Code           ReceiptCode    `json:"code" gorm:"size:16"`
+	InvoicePrefix  string         `json:"invoice_prefix" gorm:"size:8"`      // Prefix of the invoice series of sales
+	RefundPrefix   string         `json:"refund_prefix" gorm:"size:8"`       // Prefix of the invoice series of refunds
+	GiftCardMonths uint           `json:"gift_card_months"`                  // Validity of gift cards, 0 when they never expire
+	LoyaltyPerUnit uint           `json:"loyalty_per_unit"`                  // Points earned per currency unit spent, 0 when sales earn none
+	LoyaltyValue   Money          `json:"loyalty_value"`                     // In cents, what a point pays for, 0 when points cannot be redeemed
+	LoyaltyMonths  uint           `json:"loyalty_months"`                    // Validity of earned points, 0 when they never expire
+	BarcodeFormats BarcodeFormats `json:"barcode_formats" gorm:"type:jsonb"` // Scale labels decoded when resolving barcodes
+	UpdatedAt      time.Time      `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// DefaultStoreSettings are used until the store is configured
+func DefaultStoreSettings() StoreSettings {
+	return StoreSettings{ID: StoreSettingsID, Width: 42, CodePage: 19, Code: ReceiptCodeCode128, InvoicePrefix: "T", RefundPrefix: "R"}
+}
+
+type UpdateStoreSettings struct {
+	Name           string          `json:"name" binding:"max=64"`
+	TaxID          string          `json:"tax_id" binding:"max=32"`
+	HeaderLines    []string        `json:"header_lines" binding:"max=8,dive,max=64"`
+	FooterLines    []string        `json:"footer_lines" binding:"max=8,dive,max=64"`
+	Width          uint            `json:"width" binding:"omitempty,oneof=32 42 48"`
+	CodePage       uint8           `json:"code_page" binding:"omitempty,oneof=16 19"` // 16 for WPC1252, 19 for PC858
+	Code           ReceiptCode     `json:"code" binding:"omitempty,oneof=none code128 qr"`
+	InvoicePrefix  string          `json:"invoice_prefix" binding:"omitempty,alphanum,max=8"` // Starts new series for the following invoices
+	RefundPrefix   string          `json:"refund_prefix" binding:"omitempty,alphanum,max=8"`
+	GiftCardMonths uint            `json:"gift_card_months" binding:"max=120"`
+	LoyaltyPerUnit uint            `json:"loyalty_per_unit" binding:"max=1000"`
+	LoyaltyValue   Money           `json:"loyalty_value" binding:"min=0,max=10000"` // In cents
+	LoyaltyMonths  uint            `json:"loyalty_months" binding:"max=120"`
+	BarcodeFormats []BarcodeFormat `json:"barcode_formats" binding:"max=10,dive"` // Replaces the formats when sent
+}
@@ -0,0 +1,43 @@
+package models
+
+import "time"
+
+// SyncStatus is the outcome of syncing one order
+type SyncStatus string
+
+const (
+	SyncAccepted  SyncStatus = "accepted"  // The order was recorded
+	SyncDuplicate SyncStatus = "duplicate" // The order had already been synced
+	SyncRejected  SyncStatus = "rejected"  // The order could not be recorded, see the reason
+)
+
+// SyncOrder is a sale a terminal made while it could not reach the API. The
+// client id makes syncing it again harmless. Lines are priced from the
+// catalog with the promotions current at the local time of the sale; when a
+// total is sent it must match. Payments can only use tenders that need no
+// online check. The order is paid when they cover its total, and left open
+// otherwise.
+type SyncOrder struct {
+	ClientID      string          `json:"client_id" binding:"required,uuid"`
+	CreatedAt     time.Time       `json:"created_at" binding:"required"` // Local time of the sale
+	CustomerID    *uint           `json:"customer_id"`
+	CustomerName  string          `json:"customer_name"`
+	LoyaltyID     string          `json:"loyalty_id" binding:"max=32"`
+	CashoutNumber uint            `json:"cashout_number" binding:"required"`
+	Lines         []CheckoutLine  `json:"lines" binding:"required,min=1,dive"`
+	Total         Money           `json:"total" binding:"min=0,max=99999999999"` // In cents, with VAT
+	Payments      []CreatePayment `json:"payments" binding:"dive"`
+}
+
+// SyncOrders is a batch of orders sold offline, applied in the given order
+type SyncOrders struct {
+	Orders []SyncOrder `json:"orders" binding:"required,min=1,max=100"`
+}
+
+// SyncResult tells what became of a synced order
+type SyncResult struct {
+	ClientID string     `json:"client_id"`
+	Status   SyncStatus `json:"status"`
+	OrderID  *uint      `json:"order_id,omitempty"`
+	Reason   string     `json:"reason,omitempty"` // Why the order was rejected
+}
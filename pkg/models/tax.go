@@ -0,0 +1,78 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// TaxCategory groups the products taxed alike (ex: standard, reduced,
+// books). Its rates are dated so that a change of rate applies to the sales
+// made from a given day without touching the products.
+type TaxCategory struct {
+	ID        uint      `json:"id" gorm:"primary_key"`
+	Name      string    `json:"name" gorm:"size:64;uniqueIndex;not null"`
+	Rates     []TaxRate `json:"rates" gorm:"foreignKey:TaxCategoryID;constraint:OnDelete:CASCADE"`
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// TaxRate is the rate of a category from ValidFrom until the next rate of
+// the category takes over
+type TaxRate struct {
+	ID            uint      `json:"id" gorm:"primary_key"`
+	TaxCategoryID uint      `json:"tax_category_id" gorm:"index;not null"`
+	Vat           uint16    `json:"vat"`       // (ex: 2100 for 21.00%)
+	Surcharge     uint16    `json:"surcharge"` // Equivalence surcharge charged on top of the VAT (ex: 520 for 5.20%)
+	ValidFrom     time.Time `json:"valid_from" gorm:"index"`
+	CreatedAt     time.Time `json:"created_at" gorm:"autoCreateTime"`
+}
+
+type CreateTaxCategory struct {
+	Name  string          `json:"name" binding:"required,max=64"`
+	Rates []CreateTaxRate `json:"rates" binding:"required,min=1,dive"`
+}
+
+type CreateTaxRate struct {
+	Vat       uint16    `json:"vat" binding:"max=10000"`       // (ex: 2100 for 21.00%)
+	Surcharge uint16    `json:"surcharge" binding:"max=10000"` // (ex: 520 for 5.20%)
+	ValidFrom time.Time `json:"valid_from" binding:"required"`
+}
+
+// TaxLine is the tax breakdown of an order for a single rate
+type TaxLine struct {
+	Vat          uint16 `json:"vat"`                     // (ex: 2100 for 21.00%)
+	Surcharge    uint16 `json:"surcharge,omitempty"`     // (ex: 520 for 5.20%)
+	Base         Money  `json:"base"`                    // In cents, without taxes
+	Tax          Money  `json:"tax"`                     // In cents, VAT
+	SurchargeTax Money  `json:"surcharge_tax,omitempty"` // In cents
+	Total        Money  `json:"total"`                   // In cents, with taxes
+}
+
+// TaxLines is a tax breakdown stored as JSON
+type TaxLines []TaxLine
+
+// Value implements driver.Valuer
+func (t TaxLines) Value() (driver.Value, error) {
+	if t == nil {
+		return "[]", nil
+	}
+	b, err := json.Marshal(t)
+	return string(b), err
+}
+
+// Scan implements sql.Scanner
+func (t *TaxLines) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		*t = nil
+		return nil
+	case []byte:
+		return json.Unmarshal(v, t)
+	case string:
+		return json.Unmarshal([]byte(v), t)
+	default:
+		return errors.New("unsupported type for TaxLines")
+	}
+}
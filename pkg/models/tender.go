@@ -0,0 +1,13 @@
+package models
+
+// Tender is a way a customer pays or is paid back
+type Tender string
+
+const (
+	TenderCash        Tender = "cash"
+	TenderCard        Tender = "card"
+	TenderVoucher     Tender = "voucher"
+	TenderStoreCredit Tender = "store_credit"
+	TenderGiftCard    Tender = "gift_card"
+	TenderLoyalty     Tender = "loyalty_points"
+)
@@ -0,0 +1,100 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+)
+
+// VariantAttributes are the names of the attributes the variants of a
+// product differ by (ex: size and color)
+type VariantAttributes []string
+
+// Value implements driver.Valuer
+func (a VariantAttributes) Value() (driver.Value, error) {
+	if a == nil {
+		return "[]", nil
+	}
+	b, err := json.Marshal(a)
+	return string(b), err
+}
+
+// Scan implements sql.Scanner
+func (a *VariantAttributes) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		*a = nil
+		return nil
+	case []byte:
+		return json.Unmarshal(v, a)
+	case string:
+		return json.Unmarshal([]byte(v), a)
+	default:
+		return errors.New("unsupported type for VariantAttributes")
+	}
+}
+
+// VariantOptions are the values a variant takes for the attributes of its
+// product (ex: {"size": "M", "color": "red"})
+type VariantOptions map[string]string
+
+// Value implements driver.Valuer
+func (o VariantOptions) Value() (driver.Value, error) {
+	if o == nil {
+		return "{}", nil
+	}
+	b, err := json.Marshal(o)
+	return string(b), err
+}
+
+// Scan implements sql.Scanner
+func (o *VariantOptions) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		*o = nil
+		return nil
+	case []byte:
+		return json.Unmarshal(v, o)
+	case string:
+		return json.Unmarshal([]byte(v), o)
+	default:
+		return errors.New("unsupported type for VariantOptions")
+	}
+}
+
+// ProductVariant is one of the sizes, colors... a product is sold in. Each
+// variant has its own SKU, barcode and stock, and may override the price of
+// its product.
+type ProductVariant struct {
+	ID        uint            `json:"id" gorm:"primary_key"`
+	ProductID uint            `json:"product_id" gorm:"not null;index"`
+	SKU       string          `json:"sku" gorm:"size:64;not null;index:idx_product_variants_sku,unique,where:deleted_at IS NULL"`
+	Options   VariantOptions  `json:"options" gorm:"type:jsonb"`
+	Barcode   string          `json:"barcode,omitempty" gorm:"size:32"` // Also one of the barcodes of the product
+	Price     *Money          `json:"price,omitempty"`                  // In cents, with VAT, overriding the price of the product
+	Stock     decimal.Decimal `json:"stock" gorm:"type:decimal(10,3)"`
+	CreatedAt time.Time       `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt time.Time       `json:"updated_at" gorm:"autoUpdateTime"`
+	DeletedAt gorm.DeletedAt  `json:"deleted_at,omitempty" gorm:"index" swaggertype:"string" format:"date-time"` // Past order lines keep referring to deleted variants
+}
+
+type CreateProductVariant struct {
+	SKU     string          `json:"sku" binding:"required,max=64"`
+	Options VariantOptions  `json:"options" binding:"required"` // A value for each attribute of the product
+	Barcode string          `json:"barcode" binding:"max=32"`
+	Price   *Money          `json:"price" binding:"omitempty,min=0,max=99999999999"` // In cents, with VAT, defaults to the price of the product
+	Stock   decimal.Decimal `json:"stock"`
+}
+
+type UpdateProductVariant struct {
+	SKU        string           `json:"sku" binding:"max=64"`
+	Options    VariantOptions   `json:"options"`
+	Barcode    string           `json:"barcode" binding:"max=32"`
+	Price      *Money           `json:"price" binding:"omitempty,min=0,max=99999999999"` // In cents, with VAT
+	ClearPrice bool             `json:"clear_price"`                                     // Sells the variant at the price of its product again
+	Stock      *decimal.Decimal `json:"stock"`                                           // Zero included
+}
@@ -0,0 +1,85 @@
+// Package payments applies tenders to orders and computes the change due.
+package payments
+
+import (
+	"errors"
+	"fmt"
+
+	"postui_api/pkg/models"
+)
+
+var (
+	// ErrNothingDue is returned when a payment is added to an order that is
+	// already fully paid.
+	ErrNothingDue = errors.New("order is already fully paid")
+	// ErrOverpayment is returned when a tender other than cash would pay
+	// more than what is left, since only cash can give change.
+	ErrOverpayment = errors.New("payment exceeds amount due")
+	// ErrInsufficientTendered is returned when the cash handed over is less
+	// than the amount it should pay.
+	ErrInsufficientTendered = errors.New("tendered amount is less than payment amount")
+)
+
+// Paid returns the sum of the amounts applied by payments.
+func Paid(payments []models.Payment) models.Money {
+	var paid models.Money
+	for _, payment := range payments {
+		paid += payment.Amount
+	}
+	return paid
+}
+
+// Due returns what is left to pay of total once payments are applied.
+func Due(total models.Money, payments []models.Payment) models.Money {
+	due := total - Paid(payments)
+	if due < 0 {
+		return 0
+	}
+	return due
+}
+
+// Apply builds the payment of input against due, the amount left to pay.
+// The amount defaults to what is due, capped to the tendered cash. Cash
+// tendered above the amount is given back as change.
+func Apply(due models.Money, input models.CreatePayment) (models.Payment, error) {
+	if due <= 0 {
+		return models.Payment{}, ErrNothingDue
+	}
+
+	payment := models.Payment{Tender: input.Tender, Amount: input.Amount, Reference: input.Reference}
+
+	if input.Tender != models.TenderCash {
+		if payment.Amount == 0 {
+			payment.Amount = due
+		}
+		if payment.Amount > due {
+			return models.Payment{}, fmt.Errorf("%w: %s left to pay", ErrOverpayment, due)
+		}
+		payment.Tendered = payment.Amount
+		return payment, nil
+	}
+
+	if payment.Amount == 0 {
+		payment.Amount = due
+		if input.Tendered != 0 && input.Tendered < due {
+			payment.Amount = input.Tendered
+		}
+	}
+	if payment.Amount > due {
+		payment.Amount = due
+	}
+
+	payment.Tendered = input.Tendered
+	if payment.Tendered == 0 {
+		payment.Tendered = input.Amount
+	}
+	if payment.Tendered == 0 {
+		payment.Tendered = payment.Amount
+	}
+	if payment.Tendered < payment.Amount {
+		return models.Payment{}, fmt.Errorf("%w: %s tendered for %s", ErrInsufficientTendered, payment.Tendered, payment.Amount)
+	}
+	payment.Change = payment.Tendered - payment.Amount
+
+	return payment, nil
+}
@@ -0,0 +1,56 @@
+package payments
+
+import (
+	"errors"
+	"postui_api/pkg/models"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDue(t *testing.T) {
+	payments := []models.Payment{{Amount: 300}, {Amount: 200}}
+
+	assert.Equal(t, models.Money(500), Paid(payments))
+	assert.Equal(t, models.Money(500), Due(1000, payments))
+	assert.Equal(t, models.Money(0), Due(400, payments), "Due should never be negative")
+}
+
+func TestApplyCard(t *testing.T) {
+	payment, err := Apply(1000, models.CreatePayment{Tender: models.TenderCard, Reference: "AUTH42"})
+	assert.NoError(t, err)
+	assert.Equal(t, models.Payment{Tender: models.TenderCard, Amount: 1000, Tendered: 1000, Reference: "AUTH42"}, payment)
+
+	payment, err = Apply(1000, models.CreatePayment{Tender: models.TenderVoucher, Amount: 400})
+	assert.NoError(t, err)
+	assert.Equal(t, models.Money(400), payment.Amount, "A split payment should only cover its amount")
+
+	_, err = Apply(1000, models.CreatePayment{Tender: models.TenderCard, Amount: 1001})
+	assert.True(t, errors.Is(err, ErrOverpayment))
+}
+
+func TestApplyCash(t *testing.T) {
+	payment, err := Apply(1250, models.CreatePayment{Tender: models.TenderCash, Tendered: 2000})
+	assert.NoError(t, err)
+	assert.Equal(t, models.Money(1250), payment.Amount)
+	assert.Equal(t, models.Money(2000), payment.Tendered)
+	assert.Equal(t, models.Money(750), payment.Change)
+
+	payment, err = Apply(1250, models.CreatePayment{Tender: models.TenderCash, Tendered: 1000})
+	assert.NoError(t, err)
+	assert.Equal(t, models.Money(1000), payment.Amount, "Cash short of the total should be a partial payment")
+	assert.Equal(t, models.Money(0), payment.Change)
+
+	payment, err = Apply(1250, models.CreatePayment{Tender: models.TenderCash, Amount: 1500})
+	assert.NoError(t, err)
+	assert.Equal(t, models.Money(1250), payment.Amount)
+	assert.Equal(t, models.Money(250), payment.Change)
+
+	_, err = Apply(1250, models.CreatePayment{Tender: models.TenderCash, Amount: 1000, Tendered: 500})
+	assert.True(t, errors.Is(err, ErrInsufficientTendered))
+}
+
+func TestApplyNothingDue(t *testing.T) {
+	_, err := Apply(0, models.CreatePayment{Tender: models.TenderCash, Tendered: 500})
+	assert.True(t, errors.Is(err, ErrNothingDue))
+}
@@ -0,0 +1,262 @@
+// Package pricing computes order line and order amounts from the product
+// catalog, so that terminals cannot dictate what they charge.
+package pricing
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"postui_api/pkg/models"
+
+	"github.com/shopspring/decimal"
+)
+
+var (
+	// ErrPriceMismatch is returned when an amount sent by a client disagrees
+	// with the one computed from the catalog.
+	ErrPriceMismatch = errors.New("amount does not match catalog")
+	// ErrAmountOutOfRange is returned when a computed amount does not fit
+	// in models.MaxMoney.
+	ErrAmountOutOfRange = errors.New("amount out of range")
+	// ErrCurrencyMismatch is returned when lines of the same order are
+	// priced in different currencies.
+	ErrCurrencyMismatch = errors.New("lines have different currencies")
+	// ErrNoTaxRate is returned when the tax category of a product has no
+	// rate in effect on the day of the sale.
+	ErrNoTaxRate = errors.New("no tax rate in effect")
+)
+
+var basisPoints = decimal.NewFromInt(10000)
+
+// LineTotal returns price × quantity rounded to the nearest cent.
+func LineTotal(price models.Money, quantity decimal.Decimal) models.Money {
+	return models.Money(decimal.NewFromInt(int64(price)).Mul(quantity).Round(0).IntPart())
+}
+
+// Tax returns the VAT included in a VAT-inclusive total, vat being the rate
+// in basis points.
+func Tax(total models.Money, vat uint16) models.Money {
+	_, tax, _ := Split(total, vat, 0)
+	return tax
+}
+
+// Split divides a total including VAT and equivalence surcharge into its
+// base, VAT and surcharge, the rates being in basis points. The three parts
+// always add up to total.
+func Split(total models.Money, vat, surcharge uint16) (base, tax, surchargeTax models.Money) {
+	gross := decimal.NewFromInt(int64(total))
+	rates := basisPoints.Add(decimal.NewFromInt(int64(vat) + int64(surcharge)))
+
+	base = models.Money(gross.Mul(basisPoints).Div(rates).Round(0).IntPart())
+	surchargeTax = models.Money(gross.Mul(decimal.NewFromInt(int64(surcharge))).Div(rates).Round(0).IntPart())
+	return base, total - base - surchargeTax, surchargeTax
+}
+
+// EffectiveRate returns the rate among rates in effect at the given time,
+// the one that took effect last
+func EffectiveRate(rates []models.TaxRate, at time.Time) (models.TaxRate, error) {
+	var effective *models.TaxRate
+	for i, rate := range rates {
+		if rate.ValidFrom.After(at) {
+			continue
+		}
+		if effective == nil || rate.ValidFrom.After(effective.ValidFrom) || (rate.ValidFrom.Equal(effective.ValidFrom) && rate.ID > effective.ID) {
+			effective = &rates[i]
+		}
+	}
+
+	if effective == nil {
+		return models.TaxRate{}, fmt.Errorf("%w on %s", ErrNoTaxRate, at.Format(time.DateOnly))
+	}
+	return *effective, nil
+}
+
+// PriceLine builds an order line for quantity units of product at its
+// catalog price and VAT rate. Products of a tax category get the rate in
+// effect from ApplyRate.
+func PriceLine(product models.Product, quantity decimal.Decimal) models.OrderLine {
+	return models.OrderLine{
+		ProductID: product.ID,
+		Quantity:  quantity,
+		Price:     product.Price,
+		Currency:  product.Currency,
+		Vat:       product.Vat,
+		Total:     LineTotal(product.Price, quantity),
+	}
+}
+
+// ApplyVariant makes line a line of variant, at the price of the variant
+// when it overrides the one of its product
+func ApplyVariant(line *models.OrderLine, variant models.ProductVariant) {
+	variantID := variant.ID
+	line.VariantID = &variantID
+	if variant.Price != nil {
+		line.Price = *variant.Price
+		line.Total = LineTotal(line.Price, line.Quantity)
+	}
+}
+
+// ApplyRate sets the VAT and surcharge of line to the ones of rate
+func ApplyRate(line *models.OrderLine, rate models.TaxRate) {
+	line.Vat = rate.Vat
+	line.Surcharge = rate.Surcharge
+}
+
+// CheckLine compares the amounts a client sent for a line with the priced
+// line. Zero values are treated as not sent.
+func CheckLine(line models.OrderLine, price models.Money, vat uint16, total models.Money) error {
+	if price != 0 && price != line.Price {
+		return fmt.Errorf("%w: price for product %d is %d, got %d", ErrPriceMismatch, line.ProductID, line.Price, price)
+	}
+	if vat != 0 && vat != line.Vat {
+		return fmt.Errorf("%w: vat for product %d is %d, got %d", ErrPriceMismatch, line.ProductID, line.Vat, vat)
+	}
+	if total != 0 && total != line.Total {
+		return fmt.Errorf("%w: total for product %d is %d, got %d", ErrPriceMismatch, line.ProductID, line.Total, total)
+	}
+	return nil
+}
+
+// CheckRange fails with ErrAmountOutOfRange when amount is negative or
+// greater than models.MaxMoney.
+func CheckRange(amount models.Money) error {
+	if !amount.Valid() {
+		return fmt.Errorf("%w: %d", ErrAmountOutOfRange, amount)
+	}
+	return nil
+}
+
+// OrderTotal sums the totals of lines.
+func OrderTotal(lines []models.OrderLine) models.Money {
+	var total models.Money
+	for _, line := range lines {
+		total += line.Total
+	}
+	return total
+}
+
+// CheckTotal compares the order total a client sent with the computed one.
+// A zero value is treated as not sent.
+func CheckTotal(computed, sent models.Money) error {
+	if sent != 0 && sent != computed {
+		return fmt.Errorf("%w: order total is %d, got %d", ErrPriceMismatch, computed, sent)
+	}
+	return nil
+}
+
+// RefundLine prices the refund of quantity units of line, refunded holding
+// the quantity and total already refunded for it. Units of a discounted line
+// are refunded at their share of the discounted total. Refunding everything
+// left of a line gives back exactly what is left of its total, so rounding
+// never refunds more than was charged. The returned line has negative
+// amounts.
+func RefundLine(line models.OrderLine, quantity decimal.Decimal, refunded models.OrderLine) models.OrderLine {
+	remainingTotal := line.Total - refunded.Total
+
+	gross := LineTotal(line.Price, quantity)
+	total := gross
+	if line.Discount != 0 {
+		total = models.Money(decimal.NewFromInt(int64(line.Total)).Mul(quantity).Div(line.Quantity).Round(0).IntPart())
+	}
+	if quantity.Equal(line.Quantity.Sub(refunded.Quantity)) || total > remainingTotal {
+		total = remainingTotal
+	}
+
+	var discount models.Money
+	if line.Discount != 0 {
+		discount = gross - total
+	}
+
+	lineID := line.ID
+	return models.OrderLine{
+		ProductID:      line.ProductID,
+		VariantID:      line.VariantID,
+		Quantity:       quantity.Neg(),
+		Price:          line.Price,
+		Currency:       line.Currency,
+		Vat:            line.Vat,
+		Surcharge:      line.Surcharge,
+		Total:          -total,
+		Discount:       -discount,
+		RefundOfLineID: &lineID,
+	}
+}
+
+// Currency returns the currency shared by all lines, or the default
+// currency when there are none.
+func Currency(lines []models.OrderLine) (string, error) {
+	currency := models.DefaultCurrency
+	for i, line := range lines {
+		if i == 0 {
+			currency = line.Currency
+			continue
+		}
+		if line.Currency != currency {
+			return "", fmt.Errorf("%w: %s and %s", ErrCurrencyMismatch, currency, line.Currency)
+		}
+	}
+	return currency, nil
+}
+
+// taxRates identifies a line of a tax breakdown
+type taxRates struct {
+	vat       uint16
+	surcharge uint16
+}
+
+// Breakdown groups lines by VAT and surcharge rates. The taxes of each rate
+// are computed on the rate total rather than per line to avoid accumulating
+// rounding errors.
+func Breakdown(lines []models.OrderLine) []models.TaxLine {
+	totals := make(map[taxRates]models.Money)
+	for _, line := range lines {
+		totals[taxRates{line.Vat, line.Surcharge}] += line.Total
+	}
+
+	breakdown := make([]models.TaxLine, 0, len(totals))
+	for rates, total := range totals {
+		base, tax, surchargeTax := Split(total, rates.vat, rates.surcharge)
+		breakdown = append(breakdown, models.TaxLine{Vat: rates.vat, Surcharge: rates.surcharge, Base: base, Tax: tax, SurchargeTax: surchargeTax, Total: total})
+	}
+	sortBreakdown(breakdown)
+
+	return breakdown
+}
+
+// MergeBreakdowns adds up breakdowns computed separately, such as the ones
+// stored with each order of a report, rate by rate
+func MergeBreakdowns(breakdowns ...[]models.TaxLine) []models.TaxLine {
+	merged := make(map[taxRates]models.TaxLine)
+	for _, breakdown := range breakdowns {
+		for _, line := range breakdown {
+			rates := taxRates{line.Vat, line.Surcharge}
+			sum := merged[rates]
+			sum.Vat, sum.Surcharge = line.Vat, line.Surcharge
+			sum.Base += line.Base
+			sum.Tax += line.Tax
+			sum.SurchargeTax += line.SurchargeTax
+			sum.Total += line.Total
+			merged[rates] = sum
+		}
+	}
+
+	breakdown := make([]models.TaxLine, 0, len(merged))
+	for _, line := range merged {
+		breakdown = append(breakdown, line)
+	}
+	sortBreakdown(breakdown)
+
+	return breakdown
+}
+
+// sortBreakdown orders a breakdown by VAT rate, then surcharge rate
+func sortBreakdown(breakdown []models.TaxLine) {
+	sort.Slice(breakdown, func(i, j int) bool {
+		if breakdown[i].Vat != breakdown[j].Vat {
+			return breakdown[i].Vat < breakdown[j].Vat
+		}
+		return breakdown[i].Surcharge < breakdown[j].Surcharge
+	})
+}
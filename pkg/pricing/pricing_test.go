@@ -0,0 +1,188 @@
+package pricing
+
+import (
+	"errors"
+	"postui_api/pkg/models"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLineTotal(t *testing.T) {
+	quantity, _ := decimal.NewFromString("1.255")
+
+	assert.Equal(t, models.Money(300), LineTotal(100, decimal.NewFromInt(3)))
+	assert.Equal(t, models.Money(251), LineTotal(200, quantity), "Line total should be rounded to the nearest cent")
+	assert.Equal(t, models.Money(7000000), LineTotal(70000, decimal.NewFromInt(100)), "Line total should not overflow")
+}
+
+func TestTax(t *testing.T) {
+	assert.Equal(t, models.Money(21), Tax(121, 2100))
+	assert.Equal(t, models.Money(0), Tax(121, 0))
+	assert.Equal(t, models.Money(91), Tax(1000, 1000))
+}
+
+func TestSplit(t *testing.T) {
+	// 21% VAT and 5.2% equivalence surcharge on a base of 10.00
+	base, tax, surchargeTax := Split(1262, 2100, 520)
+	assert.Equal(t, models.Money(1000), base)
+	assert.Equal(t, models.Money(210), tax)
+	assert.Equal(t, models.Money(52), surchargeTax)
+
+	base, tax, surchargeTax = Split(999, 1000, 140)
+	assert.Equal(t, models.Money(999), base+tax+surchargeTax, "Parts should add up to the total")
+}
+
+func TestEffectiveRate(t *testing.T) {
+	rates := []models.TaxRate{
+		{ID: 1, Vat: 1000, ValidFrom: time.Date(2012, 9, 1, 0, 0, 0, 0, time.UTC)},
+		{ID: 2, Vat: 500, ValidFrom: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{ID: 3, Vat: 0, ValidFrom: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)},
+	}
+
+	rate, err := EffectiveRate(rates, time.Date(2023, 12, 31, 23, 59, 0, 0, time.UTC))
+	assert.NoError(t, err)
+	assert.Equal(t, uint16(1000), rate.Vat)
+
+	rate, err = EffectiveRate(rates, time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC))
+	assert.NoError(t, err)
+	assert.Equal(t, uint(3), rate.ID, "The rate added last should win over one taking effect the same day")
+
+	_, err = EffectiveRate(rates, time.Date(2010, 1, 1, 0, 0, 0, 0, time.UTC))
+	assert.True(t, errors.Is(err, ErrNoTaxRate))
+}
+
+func TestPriceLine(t *testing.T) {
+	product := models.Product{ID: 7, Price: 250, Currency: "EUR", Vat: 1000}
+
+	line := PriceLine(product, decimal.NewFromInt(2))
+
+	assert.Equal(t, uint(7), line.ProductID)
+	assert.Equal(t, models.Money(250), line.Price)
+	assert.Equal(t, "EUR", line.Currency)
+	assert.Equal(t, uint16(1000), line.Vat)
+	assert.Equal(t, models.Money(500), line.Total)
+}
+
+func TestApplyVariant(t *testing.T) {
+	product := models.Product{ID: 7, Price: 250, Currency: "EUR", Vat: 1000}
+	price := models.Money(300)
+
+	// XL costs more
+	line := PriceLine(product, decimal.NewFromInt(2))
+	ApplyVariant(&line, models.ProductVariant{ID: 4, ProductID: 7, Price: &price})
+	assert.Equal(t, uint(4), *line.VariantID)
+	assert.Equal(t, models.Money(300), line.Price)
+	assert.Equal(t, models.Money(600), line.Total)
+
+	// M is at the price of the product
+	line = PriceLine(product, decimal.NewFromInt(2))
+	ApplyVariant(&line, models.ProductVariant{ID: 5, ProductID: 7})
+	assert.Equal(t, uint(5), *line.VariantID)
+	assert.Equal(t, models.Money(500), line.Total)
+}
+
+func TestCheckLine(t *testing.T) {
+	line := models.OrderLine{ProductID: 1, Price: 100, Vat: 2100, Total: 200}
+
+	assert.NoError(t, CheckLine(line, 0, 0, 0), "Omitted amounts should be accepted")
+	assert.NoError(t, CheckLine(line, 100, 2100, 200))
+	assert.True(t, errors.Is(CheckLine(line, 1, 0, 0), ErrPriceMismatch))
+	assert.True(t, errors.Is(CheckLine(line, 0, 400, 0), ErrPriceMismatch))
+	assert.True(t, errors.Is(CheckLine(line, 0, 0, 199), ErrPriceMismatch))
+}
+
+func TestCheckTotal(t *testing.T) {
+	assert.NoError(t, CheckTotal(500, 0))
+	assert.NoError(t, CheckTotal(500, 500))
+	assert.True(t, errors.Is(CheckTotal(500, 499), ErrPriceMismatch))
+}
+
+func TestBreakdown(t *testing.T) {
+	lines := []models.OrderLine{
+		{Vat: 2100, Total: 121},
+		{Vat: 1000, Total: 550},
+		{Vat: 2100, Total: 242},
+	}
+
+	breakdown := Breakdown(lines)
+
+	assert.Equal(t, []models.TaxLine{
+		{Vat: 1000, Base: 500, Tax: 50, Total: 550},
+		{Vat: 2100, Base: 300, Tax: 63, Total: 363},
+	}, breakdown)
+	assert.Equal(t, models.Money(913), OrderTotal(lines))
+}
+
+func TestBreakdownSurcharge(t *testing.T) {
+	lines := []models.OrderLine{
+		{Vat: 2100, Total: 121},
+		{Vat: 2100, Surcharge: 520, Total: 1262},
+	}
+
+	assert.Equal(t, []models.TaxLine{
+		{Vat: 2100, Base: 100, Tax: 21, Total: 121},
+		{Vat: 2100, Surcharge: 520, Base: 1000, Tax: 210, SurchargeTax: 52, Total: 1262},
+	}, Breakdown(lines))
+}
+
+func TestMergeBreakdowns(t *testing.T) {
+	merged := MergeBreakdowns(
+		[]models.TaxLine{{Vat: 2100, Base: 100, Tax: 21, Total: 121}},
+		[]models.TaxLine{{Vat: 1000, Base: 500, Tax: 50, Total: 550}, {Vat: 2100, Base: 100, Tax: 21, Total: 121}},
+	)
+
+	assert.Equal(t, []models.TaxLine{
+		{Vat: 1000, Base: 500, Tax: 50, Total: 550},
+		{Vat: 2100, Base: 200, Tax: 42, Total: 242},
+	}, merged)
+}
+
+func TestCheckRange(t *testing.T) {
+	assert.NoError(t, CheckRange(0))
+	assert.NoError(t, CheckRange(models.MaxMoney))
+	assert.True(t, errors.Is(CheckRange(-1), ErrAmountOutOfRange))
+	assert.True(t, errors.Is(CheckRange(models.MaxMoney+1), ErrAmountOutOfRange))
+}
+
+func TestCurrency(t *testing.T) {
+	currency, err := Currency(nil)
+	assert.NoError(t, err)
+	assert.Equal(t, models.DefaultCurrency, currency)
+
+	currency, err = Currency([]models.OrderLine{{Currency: "USD"}, {Currency: "USD"}})
+	assert.NoError(t, err)
+	assert.Equal(t, "USD", currency)
+
+	_, err = Currency([]models.OrderLine{{Currency: "USD"}, {Currency: "EUR"}})
+	assert.True(t, errors.Is(err, ErrCurrencyMismatch))
+}
+
+func TestRefundLine(t *testing.T) {
+	line := models.OrderLine{ID: 3, ProductID: 1, Quantity: decimal.NewFromInt(3), Price: 333, Currency: "EUR", Vat: 2100, Total: 1000}
+
+	partial := RefundLine(line, decimal.NewFromInt(1), models.OrderLine{})
+	assert.True(t, partial.Quantity.Equal(decimal.NewFromInt(-1)))
+	assert.Equal(t, models.Money(-333), partial.Total)
+	assert.Equal(t, uint(3), *partial.RefundOfLineID)
+
+	// What is left after refunding one unit is 667, not 2 × 333
+	rest := RefundLine(line, decimal.NewFromInt(2), models.OrderLine{Quantity: decimal.NewFromInt(1), Total: 333})
+	assert.True(t, rest.Quantity.Equal(decimal.NewFromInt(-2)))
+	assert.Equal(t, models.Money(-667), rest.Total)
+}
+
+func TestRefundDiscountedLine(t *testing.T) {
+	// Three units at 3.00 with one free
+	line := models.OrderLine{ID: 4, ProductID: 1, Quantity: decimal.NewFromInt(3), Price: 300, Currency: "EUR", Vat: 2100, Total: 600, Discount: 300}
+
+	partial := RefundLine(line, decimal.NewFromInt(1), models.OrderLine{})
+	assert.Equal(t, models.Money(-200), partial.Total, "Units should be refunded at their share of the discounted total")
+	assert.Equal(t, models.Money(-100), partial.Discount)
+
+	rest := RefundLine(line, decimal.NewFromInt(2), models.OrderLine{Quantity: decimal.NewFromInt(1), Total: 200})
+	assert.Equal(t, models.Money(-400), rest.Total)
+	assert.Equal(t, models.Money(-200), rest.Discount)
+}
@@ -0,0 +1,209 @@
+// Package promotions evaluates discount rules against the lines of an order
+// when it is checked out, so that discounts are decided by the server.
+package promotions
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"postui_api/pkg/models"
+	"postui_api/pkg/pricing"
+
+	"github.com/shopspring/decimal"
+)
+
+// ErrInvalidPromotion is returned when a promotion lacks the parameters its
+// kind needs.
+var ErrInvalidPromotion = errors.New("invalid promotion")
+
+var basisPoints = decimal.NewFromInt(10000)
+
+// Item is a line priced from the catalog together with the category of its
+// product.
+type Item struct {
+	Line     models.OrderLine
+	Category string
+}
+
+// Validate checks that p has the parameters its kind needs.
+func Validate(p models.Promotion) error {
+	switch p.Kind {
+	case models.PromotionPercentage:
+		if p.Percent == 0 {
+			return fmt.Errorf("%w: percent is required", ErrInvalidPromotion)
+		}
+	case models.PromotionFixed:
+		if p.Amount == 0 {
+			return fmt.Errorf("%w: amount is required", ErrInvalidPromotion)
+		}
+	case models.PromotionBuyXGetY:
+		if p.BuyQuantity == 0 || p.GetQuantity == 0 {
+			return fmt.Errorf("%w: buy and get quantities are required", ErrInvalidPromotion)
+		}
+	case models.PromotionBundle:
+		if p.BuyQuantity < 2 || p.Amount == 0 {
+			return fmt.Errorf("%w: a bundle needs at least 2 units and an amount", ErrInvalidPromotion)
+		}
+	case models.PromotionThreshold:
+		if p.Threshold == 0 {
+			return fmt.Errorf("%w: threshold is required", ErrInvalidPromotion)
+		}
+		if (p.Percent == 0) == (p.Amount == 0) {
+			return fmt.Errorf("%w: either percent or amount is required", ErrInvalidPromotion)
+		}
+		if p.Amount > p.Threshold {
+			return fmt.Errorf("%w: amount exceeds threshold", ErrInvalidPromotion)
+		}
+	default:
+		return fmt.Errorf("%w: unknown kind %q", ErrInvalidPromotion, p.Kind)
+	}
+
+	if p.StartsAt != nil && p.EndsAt != nil && !p.EndsAt.After(*p.StartsAt) {
+		return fmt.Errorf("%w: ends before it starts", ErrInvalidPromotion)
+	}
+	return nil
+}
+
+// Current reports whether p is active at now.
+func Current(p models.Promotion, now time.Time) bool {
+	if !p.Active {
+		return false
+	}
+	if p.StartsAt != nil && now.Before(*p.StartsAt) {
+		return false
+	}
+	if p.EndsAt != nil && !now.Before(*p.EndsAt) {
+		return false
+	}
+	return true
+}
+
+// Apply takes the discounts of the promotions current at now off the lines
+// of items. Each line gets the first line promotion by priority that gives
+// it a discount, then the first threshold promotion reached by the
+// discounted total is spread over the lines in proportion to their totals,
+// so that the VAT breakdown stays exact. It returns the discounted lines and
+// the applied discounts, whose Line is the index of the discounted line.
+func Apply(items []Item, promotions []models.Promotion, now time.Time) ([]models.OrderLine, []models.OrderDiscount) {
+	current := make([]models.Promotion, 0, len(promotions))
+	for _, p := range promotions {
+		if Current(p, now) {
+			current = append(current, p)
+		}
+	}
+	sort.SliceStable(current, func(i, j int) bool {
+		if current[i].Priority != current[j].Priority {
+			return current[i].Priority > current[j].Priority
+		}
+		return current[i].ID < current[j].ID
+	})
+
+	lines := make([]models.OrderLine, len(items))
+	var discounts []models.OrderDiscount
+
+	for i, item := range items {
+		lines[i] = item.Line
+
+		for _, p := range current {
+			if p.Kind == models.PromotionThreshold || !matches(p, item) {
+				continue
+			}
+
+			amount := lineDiscount(p, lines[i])
+			if amount <= 0 {
+				continue
+			}
+			if amount > lines[i].Total {
+				amount = lines[i].Total
+			}
+
+			lines[i].Discount += amount
+			lines[i].Total -= amount
+			discounts = append(discounts, models.OrderDiscount{PromotionID: p.ID, Name: p.Name, Amount: amount, Line: i})
+			break
+		}
+	}
+
+	subtotal := pricing.OrderTotal(lines)
+	for _, p := range current {
+		if p.Kind != models.PromotionThreshold || subtotal <= 0 || subtotal < p.Threshold {
+			continue
+		}
+
+		amount := p.Amount
+		if p.Percent != 0 {
+			amount = percentOf(subtotal, p.Percent)
+		}
+		if amount > subtotal {
+			amount = subtotal
+		}
+		if amount <= 0 {
+			continue
+		}
+
+		spread(lines, amount, subtotal)
+		discounts = append(discounts, models.OrderDiscount{PromotionID: p.ID, Name: p.Name, Amount: amount, Line: -1})
+		break
+	}
+
+	return lines, discounts
+}
+
+// matches reports whether the line promotion p targets item
+func matches(p models.Promotion, item Item) bool {
+	switch {
+	case p.ProductID != nil:
+		return item.Line.ProductID == *p.ProductID
+	case p.Category != "":
+		return item.Category == p.Category
+	default:
+		return true
+	}
+}
+
+// lineDiscount returns the discount of the line promotion p on line
+func lineDiscount(p models.Promotion, line models.OrderLine) models.Money {
+	switch p.Kind {
+	case models.PromotionPercentage:
+		return percentOf(line.Total, p.Percent)
+	case models.PromotionFixed:
+		return pricing.LineTotal(p.Amount, line.Quantity)
+	case models.PromotionBuyXGetY:
+		groups := wholeUnits(line) / int64(p.BuyQuantity+p.GetQuantity)
+		return pricing.LineTotal(line.Price, decimal.NewFromInt(groups*int64(p.GetQuantity)))
+	case models.PromotionBundle:
+		groups := wholeUnits(line) / int64(p.BuyQuantity)
+		saving := pricing.LineTotal(line.Price, decimal.NewFromInt(int64(p.BuyQuantity))) - p.Amount
+		return models.Money(groups) * saving
+	default:
+		return 0
+	}
+}
+
+// wholeUnits returns the number of whole units of line
+func wholeUnits(line models.OrderLine) int64 {
+	return line.Quantity.Floor().IntPart()
+}
+
+// percentOf returns percent basis points of amount rounded to the nearest cent
+func percentOf(amount models.Money, percent uint16) models.Money {
+	return models.Money(decimal.NewFromInt(int64(amount)).Mul(decimal.NewFromInt(int64(percent))).Div(basisPoints).Round(0).IntPart())
+}
+
+// spread takes amount off lines in proportion to their totals, subtotal
+// being the sum of them. Shares are rounded on running totals so that they
+// add up to amount exactly and never exceed the total of their line.
+func spread(lines []models.OrderLine, amount, subtotal models.Money) {
+	var cumulative, taken models.Money
+	for i := range lines {
+		cumulative += lines[i].Total
+		upTo := models.Money(decimal.NewFromInt(int64(amount)).Mul(decimal.NewFromInt(int64(cumulative))).Div(decimal.NewFromInt(int64(subtotal))).Round(0).IntPart())
+
+		share := upTo - taken
+		taken = upTo
+		lines[i].Discount += share
+		lines[i].Total -= share
+	}
+}
@@ -0,0 +1,130 @@
+package promotions
+
+import (
+	"errors"
+	"postui_api/pkg/models"
+	"postui_api/pkg/pricing"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+var now = time.Date(2026, 3, 14, 12, 0, 0, 0, time.UTC)
+
+func item(productID uint, category string, price models.Money, vat uint16, quantity int64) Item {
+	product := models.Product{ID: productID, Price: price, Currency: "EUR", Vat: vat, Category: category}
+	return Item{Line: pricing.PriceLine(product, decimal.NewFromInt(quantity)), Category: category}
+}
+
+func TestValidate(t *testing.T) {
+	starts := now
+	ends := now.Add(-time.Hour)
+
+	assert.NoError(t, Validate(models.Promotion{Kind: models.PromotionPercentage, Percent: 1000}))
+	assert.NoError(t, Validate(models.Promotion{Kind: models.PromotionThreshold, Threshold: 5000, Amount: 500}))
+	assert.True(t, errors.Is(Validate(models.Promotion{Kind: models.PromotionPercentage}), ErrInvalidPromotion))
+	assert.True(t, errors.Is(Validate(models.Promotion{Kind: models.PromotionFixed}), ErrInvalidPromotion))
+	assert.True(t, errors.Is(Validate(models.Promotion{Kind: models.PromotionBuyXGetY, BuyQuantity: 2}), ErrInvalidPromotion))
+	assert.True(t, errors.Is(Validate(models.Promotion{Kind: models.PromotionBundle, BuyQuantity: 1, Amount: 100}), ErrInvalidPromotion))
+	assert.True(t, errors.Is(Validate(models.Promotion{Kind: models.PromotionThreshold, Threshold: 5000, Amount: 500, Percent: 1000}), ErrInvalidPromotion), "Threshold promotions take either a percent or an amount")
+	assert.True(t, errors.Is(Validate(models.Promotion{Kind: "free_lunch"}), ErrInvalidPromotion))
+	assert.True(t, errors.Is(Validate(models.Promotion{Kind: models.PromotionPercentage, Percent: 1000, StartsAt: &starts, EndsAt: &ends}), ErrInvalidPromotion))
+}
+
+func TestCurrent(t *testing.T) {
+	before := now.Add(-time.Hour)
+	after := now.Add(time.Hour)
+
+	assert.True(t, Current(models.Promotion{Active: true}, now))
+	assert.False(t, Current(models.Promotion{Active: false}, now))
+	assert.True(t, Current(models.Promotion{Active: true, StartsAt: &before, EndsAt: &after}, now))
+	assert.False(t, Current(models.Promotion{Active: true, StartsAt: &after}, now), "Promotions should not apply before they start")
+	assert.False(t, Current(models.Promotion{Active: true, EndsAt: &now}, now), "Promotions should not apply once they end")
+}
+
+func TestApplyLinePromotions(t *testing.T) {
+	productID := uint(2)
+	items := []Item{
+		item(1, "bakery", 250, 1000, 2),
+		item(2, "drinks", 150, 2100, 3),
+		item(3, "drinks", 100, 2100, 4),
+		item(4, "dairy", 300, 1000, 5),
+	}
+	promotions := []models.Promotion{
+		{ID: 1, Name: "Bakery -10%", Kind: models.PromotionPercentage, Category: "bakery", Percent: 1000, Active: true},
+		{ID: 2, Name: "3x2 lemonade", Kind: models.PromotionBuyXGetY, ProductID: &productID, BuyQuantity: 2, GetQuantity: 1, Active: true},
+		{ID: 3, Name: "Drinks -0.20", Kind: models.PromotionFixed, Category: "drinks", Amount: 20, Active: true},
+		{ID: 4, Name: "2 yogurts for 5.00", Kind: models.PromotionBundle, Category: "dairy", BuyQuantity: 2, Amount: 500, Active: true},
+		{ID: 5, Name: "Expired", Kind: models.PromotionPercentage, Percent: 5000},
+	}
+
+	lines, discounts := Apply(items, promotions, now)
+
+	assert.Equal(t, models.Money(50), lines[0].Discount)
+	assert.Equal(t, models.Money(450), lines[0].Total)
+	assert.Equal(t, models.Money(150), lines[1].Discount, "One unit out of three should be free")
+	assert.Equal(t, models.Money(300), lines[1].Total)
+	assert.Equal(t, models.Money(80), lines[2].Discount)
+	assert.Equal(t, models.Money(200), lines[3].Discount, "Two bundles of two should save 1.00 each")
+	assert.Equal(t, models.Money(1300), lines[3].Total)
+
+	assert.Equal(t, []models.OrderDiscount{
+		{PromotionID: 1, Name: "Bakery -10%", Amount: 50, Line: 0},
+		{PromotionID: 2, Name: "3x2 lemonade", Amount: 150, Line: 1},
+		{PromotionID: 3, Name: "Drinks -0.20", Amount: 80, Line: 2},
+		{PromotionID: 4, Name: "2 yogurts for 5.00", Amount: 200, Line: 3},
+	}, discounts)
+}
+
+func TestApplyPriority(t *testing.T) {
+	items := []Item{item(1, "bakery", 1000, 1000, 2)}
+	promotions := []models.Promotion{
+		{ID: 1, Name: "-10%", Kind: models.PromotionPercentage, Percent: 1000, Priority: 1, Active: true},
+		{ID: 2, Name: "3x2", Kind: models.PromotionBuyXGetY, BuyQuantity: 2, GetQuantity: 1, Priority: 5, Active: true},
+		{ID: 3, Name: "-50%", Kind: models.PromotionPercentage, Percent: 5000, Priority: 0, Active: true},
+	}
+
+	lines, discounts := Apply(items, promotions, now)
+
+	assert.Len(t, discounts, 1, "A line should get a single line promotion")
+	assert.Equal(t, uint(1), discounts[0].PromotionID, "Promotions giving no discount should be skipped")
+	assert.Equal(t, models.Money(1800), lines[0].Total)
+}
+
+func TestApplyThreshold(t *testing.T) {
+	items := []Item{
+		item(1, "", 1000, 1000, 1),
+		item(2, "", 2000, 2100, 1),
+	}
+	promotions := []models.Promotion{
+		{ID: 1, Name: "5.00 off 50.00", Kind: models.PromotionThreshold, Threshold: 5000, Amount: 500, Priority: 2, Active: true},
+		{ID: 2, Name: "10% off 20.00", Kind: models.PromotionThreshold, Threshold: 2000, Percent: 1000, Priority: 1, Active: true},
+	}
+
+	lines, discounts := Apply(items, promotions, now)
+
+	assert.Equal(t, []models.OrderDiscount{{PromotionID: 2, Name: "10% off 20.00", Amount: 300, Line: -1}}, discounts, "Thresholds not reached should be skipped")
+	assert.Equal(t, models.Money(100), lines[0].Discount)
+	assert.Equal(t, models.Money(200), lines[1].Discount)
+	assert.Equal(t, models.Money(2700), pricing.OrderTotal(lines))
+}
+
+func TestApplyThresholdRounding(t *testing.T) {
+	items := []Item{
+		item(1, "", 100, 1000, 1),
+		item(2, "", 100, 1000, 1),
+		item(3, "", 100, 1000, 1),
+	}
+	promotions := []models.Promotion{{ID: 1, Name: "1.00 off", Kind: models.PromotionThreshold, Threshold: 300, Amount: 100, Active: true}}
+
+	lines, _ := Apply(items, promotions, now)
+
+	var discount models.Money
+	for _, line := range lines {
+		discount += line.Discount
+		assert.Equal(t, line.Price-line.Discount, line.Total)
+	}
+	assert.Equal(t, models.Money(100), discount, "Spread shares should add up to the discount")
+}
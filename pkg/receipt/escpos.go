@@ -0,0 +1,146 @@
+package receipt
+
+import (
+	"bytes"
+	"strings"
+
+	"postui_api/pkg/models"
+)
+
+// ESC/POS character code tables the receipts can be encoded with
+const (
+	CodePageWPC1252 = 16
+	CodePagePC858   = 19
+)
+
+// pc858 maps the characters of Western European languages to code page
+// PC858, which is PC850 with the euro sign
+var pc858 = map[rune]byte{
+	'Ç': 0x80, 'ü': 0x81, 'é': 0x82, 'â': 0x83, 'ä': 0x84, 'à': 0x85, 'å': 0x86, 'ç': 0x87,
+	'ê': 0x88, 'ë': 0x89, 'è': 0x8A, 'ï': 0x8B, 'î': 0x8C, 'ì': 0x8D, 'Ä': 0x8E, 'Å': 0x8F,
+	'É': 0x90, 'æ': 0x91, 'Æ': 0x92, 'ô': 0x93, 'ö': 0x94, 'ò': 0x95, 'û': 0x96, 'ù': 0x97,
+	'ÿ': 0x98, 'Ö': 0x99, 'Ü': 0x9A, 'ø': 0x9B, '£': 0x9C, 'Ø': 0x9D, '×': 0x9E, 'ƒ': 0x9F,
+	'á': 0xA0, 'í': 0xA1, 'ó': 0xA2, 'ú': 0xA3, 'ñ': 0xA4, 'Ñ': 0xA5, 'ª': 0xA6, 'º': 0xA7,
+	'¿': 0xA8, '®': 0xA9, '¬': 0xAA, '½': 0xAB, '¼': 0xAC, '¡': 0xAD, '«': 0xAE, '»': 0xAF,
+	'Á': 0xB5, 'Â': 0xB6, 'À': 0xB7, '©': 0xB8, '¢': 0xBD, '¥': 0xBE, 'ã': 0xC6, 'Ã': 0xC7,
+	'Ê': 0xD2, 'Ë': 0xD3, 'È': 0xD4, '€': 0xD5, 'Í': 0xD6, 'Î': 0xD7, 'Ï': 0xD8, 'Ì': 0xDE,
+	'Ó': 0xE0, 'ß': 0xE1, 'Ô': 0xE2, 'Ò': 0xE3, 'õ': 0xE4, 'Õ': 0xE5, 'µ': 0xE6, 'Ú': 0xE9,
+	'Û': 0xEA, 'Ù': 0xEB, '°': 0xF8, '·': 0xFA,
+}
+
+// wpc1252 maps the characters of Windows-1252 that are not at their Latin-1
+// code point
+var wpc1252 = map[rune]byte{
+	'€': 0x80, '‚': 0x82, 'ƒ': 0x83, '„': 0x84, '…': 0x85, '‰': 0x89, 'Š': 0x8A, 'Œ': 0x8C,
+	'Ž': 0x8E, '‘': 0x91, '’': 0x92, '“': 0x93, '”': 0x94, '•': 0x95, '–': 0x96, '—': 0x97,
+	'™': 0x99, 'š': 0x9A, 'œ': 0x9C, 'ž': 0x9E, 'Ÿ': 0x9F,
+}
+
+// ESCPOS renders r as an ESC/POS byte stream encoded with the code page of
+// the store settings, ending with a paper cut.
+func ESCPOS(r Receipt) []byte {
+	defaults := models.DefaultStoreSettings()
+	p := &escposPrinter{width: int(r.Store.Width), codePage: r.Store.CodePage}
+	if p.width == 0 {
+		p.width = int(defaults.Width)
+	}
+	if p.codePage == 0 {
+		p.codePage = defaults.CodePage
+	}
+
+	// Initialize the printer and select the code page
+	p.b.Write([]byte{0x1B, 0x40, 0x1B, 0x74, p.codePage})
+
+	layout(r, p)
+
+	return p.b.Bytes()
+}
+
+// escposPrinter prints receipts as ESC/POS commands
+type escposPrinter struct {
+	b        bytes.Buffer
+	width    int
+	codePage uint8
+}
+
+func (p *escposPrinter) title(text string) {
+	// Centered, bold, double width and height
+	p.b.Write([]byte{0x1B, 0x61, 0x01, 0x1B, 0x45, 0x01, 0x1D, 0x21, 0x11})
+	p.text(truncate(text, p.width/2))
+	p.b.Write([]byte{0x1D, 0x21, 0x00, 0x1B, 0x45, 0x00, 0x1B, 0x61, 0x00})
+}
+
+func (p *escposPrinter) center(text string) {
+	p.b.Write([]byte{0x1B, 0x61, 0x01})
+	p.text(truncate(text, p.width))
+	p.b.Write([]byte{0x1B, 0x61, 0x00})
+}
+
+func (p *escposPrinter) row(label, value string, bold bool) {
+	if bold {
+		p.b.Write([]byte{0x1B, 0x45, 0x01})
+	}
+	p.text(fit(label, value, p.width))
+	if bold {
+		p.b.Write([]byte{0x1B, 0x45, 0x00})
+	}
+}
+
+func (p *escposPrinter) rule() {
+	p.text(strings.Repeat("-", p.width))
+}
+
+func (p *escposPrinter) code(kind models.ReceiptCode, data string) {
+	p.b.Write([]byte{0x1B, 0x61, 0x01})
+
+	switch kind {
+	case models.ReceiptCodeCode128:
+		// Height, module width, human readable text below, then CODE128 subset B
+		p.b.Write([]byte{0x1D, 0x68, 80, 0x1D, 0x77, 2, 0x1D, 0x48, 2})
+		content := "{B" + data
+		p.b.Write([]byte{0x1D, 0x6B, 73, byte(len(content))})
+		p.b.WriteString(content)
+	case models.ReceiptCodeQR:
+		size := len(data) + 3
+		p.b.Write([]byte{0x1D, 0x28, 0x6B, 0x04, 0x00, 0x31, 0x41, 0x32, 0x00}) // Model 2
+		p.b.Write([]byte{0x1D, 0x28, 0x6B, 0x03, 0x00, 0x31, 0x43, 0x06})       // Module size
+		p.b.Write([]byte{0x1D, 0x28, 0x6B, 0x03, 0x00, 0x31, 0x45, 0x31})       // Error correction M
+		p.b.Write([]byte{0x1D, 0x28, 0x6B, byte(size % 256), byte(size / 256), 0x31, 0x50, 0x30})
+		p.b.WriteString(data)
+		p.b.Write([]byte{0x1D, 0x28, 0x6B, 0x03, 0x00, 0x31, 0x51, 0x30}) // Print
+	}
+
+	p.b.WriteByte('\n')
+	p.b.Write([]byte{0x1B, 0x61, 0x00})
+}
+
+func (p *escposPrinter) end() {
+	// Feed past the cutter and cut partially
+	p.b.Write([]byte{0x1B, 0x64, 0x04, 0x1D, 0x56, 0x42, 0x00})
+}
+
+// text writes a line encoded with the code page of the printer
+func (p *escposPrinter) text(line string) {
+	p.b.Write(encode(line, p.codePage))
+	p.b.WriteByte('\n')
+}
+
+// encode converts text to codePage, replacing what it cannot hold with '?'
+func encode(text string, codePage uint8) []byte {
+	encoded := make([]byte, 0, len(text))
+	for _, r := range text {
+		switch {
+		case r < 0x80:
+			encoded = append(encoded, byte(r))
+		case codePage == CodePageWPC1252 && r >= 0xA0 && r <= 0xFF:
+			encoded = append(encoded, byte(r))
+		case codePage == CodePageWPC1252 && wpc1252[r] != 0:
+			encoded = append(encoded, wpc1252[r])
+		case codePage == CodePagePC858 && pc858[r] != 0:
+			encoded = append(encoded, pc858[r])
+		default:
+			encoded = append(encoded, '?')
+		}
+	}
+	return encoded
+}
@@ -0,0 +1,170 @@
+// Package receipt lays out order receipts, either as plain text or as an
+// ESC/POS byte stream for thermal printers.
+package receipt
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"postui_api/pkg/models"
+)
+
+// timeLayout is how dates are printed on receipts
+const timeLayout = "2006-01-02 15:04"
+
+// Line is an order line with the name of its product
+type Line struct {
+	models.OrderLine
+	Name string
+}
+
+// Receipt is everything printed on the receipt of an order
+type Receipt struct {
+	Store     models.StoreSettings
+	Order     models.Order
+	Lines     []Line
+	Taxes     []models.TaxLine
+	Discounts []models.OrderDiscount
+	Payments  []models.Payment
+}
+
+// printer is what a receipt is laid out on
+type printer interface {
+	// title prints text centered and emphasized
+	title(text string)
+	// center prints text centered
+	center(text string)
+	// row prints label on the left and value on the right
+	row(label, value string, bold bool)
+	// rule prints a separator line
+	rule()
+	// code prints data as a barcode or QR code
+	code(kind models.ReceiptCode, data string)
+	// end finishes the receipt
+	end()
+}
+
+// layout prints r on p
+func layout(r Receipt, p printer) {
+	if r.Store.Name != "" {
+		p.title(r.Store.Name)
+	}
+	for _, line := range r.Store.HeaderLines {
+		p.center(line)
+	}
+	if r.Store.TaxID != "" {
+		p.center("Tax ID: " + r.Store.TaxID)
+	}
+	p.rule()
+
+	document := fmt.Sprintf("Order #%d", r.Order.ID)
+	if r.Order.Invoice != "" {
+		document = "Invoice " + r.Order.Invoice
+	}
+	if r.Order.RefundOfID != nil {
+		document = fmt.Sprintf("Refund #%d of #%d", r.Order.ID, *r.Order.RefundOfID)
+		if r.Order.Invoice != "" {
+			document = "Refund " + r.Order.Invoice
+		}
+	}
+	p.row(document, r.Order.CreatedAt.Format(timeLayout), false)
+	p.row(fmt.Sprintf("Cashout %d", r.Order.CashoutNumber), r.Order.CustomerName, false)
+	p.rule()
+
+	// Lines are printed before discounts, which are listed under their line
+	// or before the total for order discounts
+	for _, line := range r.Lines {
+		p.row(line.Name, "", false)
+		p.row(fmt.Sprintf("  %s x %s", line.Quantity.String(), line.Price), (line.Total + line.Discount).String(), false)
+		for _, discount := range r.Discounts {
+			if discount.OrderLineID != nil && *discount.OrderLineID == line.ID {
+				p.row("  "+discount.Name, (-discount.Amount).String(), false)
+			}
+		}
+	}
+	p.rule()
+
+	for _, discount := range r.Discounts {
+		if discount.OrderLineID == nil {
+			p.row(discount.Name, (-discount.Amount).String(), false)
+		}
+	}
+
+	p.row("TOTAL", r.Order.Currency+" "+r.Order.Total.String(), true)
+	for _, tax := range r.Taxes {
+		p.row(fmt.Sprintf("VAT %s%% on %s", models.Money(tax.Vat), tax.Base), tax.Tax.String(), false)
+		if tax.Surcharge != 0 {
+			p.row(fmt.Sprintf("Surcharge %s%% on %s", models.Money(tax.Surcharge), tax.Base), tax.SurchargeTax.String(), false)
+		}
+	}
+
+	if len(r.Payments) > 0 {
+		p.rule()
+	}
+	for _, payment := range r.Payments {
+		p.row(tenderLabel(payment.Tender), payment.Tendered.String(), false)
+		if payment.Change != 0 {
+			p.row("Change", payment.Change.String(), false)
+		}
+	}
+	p.rule()
+
+	for _, line := range r.Store.FooterLines {
+		p.center(line)
+	}
+	if r.Store.Code != "" && r.Store.Code != models.ReceiptCodeNone {
+		p.code(r.Store.Code, fmt.Sprintf("%d", r.Order.ID))
+	}
+	p.end()
+}
+
+// tenderLabel returns the printed name of tender
+func tenderLabel(tender models.Tender) string {
+	label := strings.ReplaceAll(string(tender), "_", " ")
+	if label == "" {
+		return label
+	}
+	return strings.ToUpper(label[:1]) + label[1:]
+}
+
+// fit lays label and value out on a line of width characters, truncating
+// label, then value, if they do not fit
+func fit(label, value string, width int) string {
+	if width < 0 {
+		width = 0
+	}
+	value = truncate(value, width)
+
+	space := width - utf8.RuneCountInString(value) - 1
+	if value == "" {
+		space = width
+	}
+	if space < 0 {
+		space = 0
+	}
+	label = truncate(label, space)
+
+	if value == "" {
+		return label
+	}
+	padding := width - utf8.RuneCountInString(label) - utf8.RuneCountInString(value)
+	if padding < 0 {
+		padding = 0
+	}
+	return label + strings.Repeat(" ", padding) + value
+}
+
+// centered pads text on the left to center it on a line of width characters
+func centered(text string, width int) string {
+	text = truncate(text, width)
+	return strings.Repeat(" ", (width-utf8.RuneCountInString(text))/2) + text
+}
+
+// truncate cuts text to at most width characters
+func truncate(text string, width int) string {
+	if utf8.RuneCountInString(text) <= width {
+		return text
+	}
+	return string([]rune(text)[:width])
+}
@@ -0,0 +1,124 @@
+package receipt
+
+import (
+	"bytes"
+	"postui_api/pkg/models"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func receiptFixture() Receipt {
+	return Receipt{
+		Store: models.StoreSettings{
+			Name:        "Café Sol",
+			TaxID:       "B12345678",
+			HeaderLines: pq.StringArray{"Calle Mayor 1", "Madrid"},
+			FooterLines: pq.StringArray{"¡Gracias!"},
+			Width:       42,
+			CodePage:    CodePagePC858,
+			Code:        models.ReceiptCodeCode128,
+		},
+		Order: models.Order{ID: 1234, CustomerName: "maria", Total: 350, Currency: "EUR", CashoutNumber: 2, CreatedAt: time.Date(2026, 10, 17, 9, 30, 0, 0, time.UTC)},
+		Lines: []Line{
+			{OrderLine: models.OrderLine{Quantity: decimal.NewFromInt(2), Price: 125, Vat: 1000, Total: 250}, Name: "Café con leche"},
+			{OrderLine: models.OrderLine{Quantity: decimal.NewFromInt(1), Price: 100, Vat: 1000, Total: 100}, Name: "Croissant"},
+		},
+		Taxes:    []models.TaxLine{{Vat: 1000, Base: 318, Tax: 32, Total: 350}},
+		Payments: []models.Payment{{Tender: models.TenderCash, Amount: 350, Tendered: 500, Change: 150}},
+	}
+}
+
+func TestText(t *testing.T) {
+	text := Text(receiptFixture())
+
+	assert.Contains(t, text, "                 CAFÉ SOL\n")
+	assert.Contains(t, text, "Order #1234               2026-10-17 09:30\n")
+	assert.Contains(t, text, "Café con leche\n  2 x 1.25                            2.50\n")
+	assert.Contains(t, text, "TOTAL                             EUR 3.50\n")
+	assert.Contains(t, text, "VAT 10.00% on 3.18                    0.32\n")
+	assert.Contains(t, text, "Cash                                  5.00\nChange                                1.50\n")
+	assert.Contains(t, text, "*1234*")
+	for _, line := range strings.Split(strings.TrimSuffix(text, "\n"), "\n") {
+		assert.LessOrEqual(t, len([]rune(line)), 42, line)
+	}
+}
+
+func TestTextLongValues(t *testing.T) {
+	r := receiptFixture()
+	r.Store.Width = 32
+	r.Order.CustomerName = "Distribuciones Hosteleras del Mediterráneo Sociedad Limitada"
+	r.Discounts = []models.OrderDiscount{{Name: strings.Repeat("Two coffees and a croissant ", 3), Amount: 10}}
+
+	text := Text(r)
+
+	assert.Contains(t, text, "Distribuciones Hosteleras del Me\n")
+	for _, line := range strings.Split(strings.TrimSuffix(text, "\n"), "\n") {
+		assert.LessOrEqual(t, len([]rune(line)), 32, line)
+	}
+}
+
+func TestFit(t *testing.T) {
+	assert.Equal(t, "Total       3.50", fit("Total", "3.50", 16))
+	assert.Equal(t, "Tot 123456789012", fit("Total", "123456789012", 16))
+	assert.Equal(t, "1234567890123456", fit("Total", "12345678901234567890", 16), "A value wider than the line should be cut to it")
+	assert.Equal(t, "", fit("Total", "3.50", 0))
+}
+
+func TestTextSurcharge(t *testing.T) {
+	ticket := receiptFixture()
+	ticket.Taxes = []models.TaxLine{{Vat: 1000, Surcharge: 140, Base: 314, Tax: 32, SurchargeTax: 4, Total: 350}}
+
+	text := Text(ticket)
+
+	assert.Contains(t, text, "VAT 10.00% on 3.14                    0.32\n")
+	assert.Contains(t, text, "Surcharge 1.40% on 3.14               0.04\n")
+}
+
+func TestESCPOS(t *testing.T) {
+	stream := ESCPOS(receiptFixture())
+
+	assert.True(t, bytes.HasPrefix(stream, []byte{0x1B, 0x40, 0x1B, 0x74, CodePagePC858}), "The printer should be initialized with the code page")
+	assert.True(t, bytes.HasSuffix(stream, []byte{0x1D, 0x56, 0x42, 0x00}), "The paper should be cut")
+	assert.True(t, bytes.Contains(stream, []byte("Caf\x82 con leche\n")), "Text should be encoded with PC858")
+	assert.True(t, bytes.Contains(stream, []byte{0x1D, 0x6B, 73, 6, '{', 'B', '1', '2', '3', '4'}), "The order ID should be printed as a CODE128 barcode")
+}
+
+func TestEncode(t *testing.T) {
+	assert.Equal(t, []byte{'1', '0', ' ', 0xD5}, encode("10 €", CodePagePC858))
+	assert.Equal(t, []byte{'1', '0', ' ', 0x80}, encode("10 €", CodePageWPC1252))
+	assert.Equal(t, []byte{0xE9}, encode("é", CodePageWPC1252))
+	assert.Equal(t, []byte{'?'}, encode("漢", CodePagePC858))
+}
+
+func TestTextInvoice(t *testing.T) {
+	r := receiptFixture()
+	r.Order.InvoiceNumber = 42
+	r.Order.Invoice = "T2-2026/000042"
+
+	assert.Contains(t, Text(r), "Invoice T2-2026/000042    2026-10-17 09:30\n")
+}
+
+func TestTextDiscounts(t *testing.T) {
+	lineID := uint(1)
+	r := receiptFixture()
+	r.Lines[0].ID = lineID
+	r.Lines[0].Discount = 33
+	r.Lines[0].Total = 217
+	r.Lines[1].Discount = 2
+	r.Lines[1].Total = 98
+	r.Order.Total = 315
+	r.Discounts = []models.OrderDiscount{
+		{OrderLineID: &lineID, Name: "Coffee -10%", Amount: 25},
+		{Name: "Happy hour", Amount: 10},
+	}
+
+	text := Text(r)
+
+	assert.Contains(t, text, "Café con leche\n  2 x 1.25                            2.50\n  Coffee -10%                        -0.25\n", "Line discounts should follow the gross amount of their line")
+	assert.Contains(t, text, "Happy hour                           -0.10\nTOTAL", "Order discounts should be printed before the total")
+}
@@ -0,0 +1,53 @@
+package receipt
+
+import (
+	"strings"
+
+	"postui_api/pkg/models"
+)
+
+// Text renders r as plain text lines of the width of the store settings.
+func Text(r Receipt) string {
+	p := &textPrinter{width: int(r.Store.Width)}
+	if p.width == 0 {
+		p.width = int(models.DefaultStoreSettings().Width)
+	}
+
+	layout(r, p)
+
+	return p.b.String()
+}
+
+// textPrinter prints receipts as plain text
+type textPrinter struct {
+	b     strings.Builder
+	width int
+}
+
+func (p *textPrinter) title(text string) {
+	p.center(strings.ToUpper(text))
+}
+
+func (p *textPrinter) center(text string) {
+	p.line(centered(text, p.width))
+}
+
+func (p *textPrinter) row(label, value string, bold bool) {
+	p.line(fit(label, value, p.width))
+}
+
+func (p *textPrinter) rule() {
+	p.line(strings.Repeat("-", p.width))
+}
+
+// code prints the data a scanner would read, since text cannot hold a barcode
+func (p *textPrinter) code(kind models.ReceiptCode, data string) {
+	p.center("*" + data + "*")
+}
+
+func (p *textPrinter) end() {}
+
+func (p *textPrinter) line(text string) {
+	p.b.WriteString(text)
+	p.b.WriteString("\n")
+}
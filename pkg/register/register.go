@@ -0,0 +1,45 @@
+// Package register computes the cash expected in and counted from a drawer
+// when a register session is closed.
+package register
+
+import "postui_api/pkg/models"
+
+// Counted returns the cash represented by counts.
+func Counted(counts []models.DenominationCount) models.Money {
+	var counted models.Money
+	for _, count := range counts {
+		counted += count.Denomination * models.Money(count.Quantity)
+	}
+	return counted
+}
+
+// Expected returns the cash that should be in the drawer of session given
+// its movements and the cash payments taken during it. Cash refunds are
+// negative payments and lower what is expected.
+func Expected(session models.RegisterSession, movements []models.CashMovement, payments []models.Payment) models.Money {
+	expected := session.OpeningFloat
+
+	for _, movement := range movements {
+		switch movement.Kind {
+		case models.MovementPayIn:
+			expected += movement.Amount
+		case models.MovementPayOut:
+			expected -= movement.Amount
+		}
+	}
+
+	for _, payment := range payments {
+		if payment.Tender == models.TenderCash {
+			expected += payment.Amount
+		}
+	}
+
+	return expected
+}
+
+// Close fills the closing amounts of session from the counted cash.
+func Close(session *models.RegisterSession, movements []models.CashMovement, payments []models.Payment, counts []models.DenominationCount) {
+	session.ExpectedCash = Expected(*session, movements, payments)
+	session.CountedCash = Counted(counts)
+	session.Variance = session.CountedCash - session.ExpectedCash
+}
@@ -0,0 +1,38 @@
+package register
+
+import (
+	"postui_api/pkg/models"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCounted(t *testing.T) {
+	counts := []models.DenominationCount{
+		{Denomination: 2000, Quantity: 2},
+		{Denomination: 50, Quantity: 3},
+		{Denomination: 1, Quantity: 0},
+	}
+
+	assert.Equal(t, models.Money(4150), Counted(counts))
+}
+
+func TestClose(t *testing.T) {
+	session := models.RegisterSession{OpeningFloat: 10000}
+	movements := []models.CashMovement{
+		{Kind: models.MovementPayIn, Amount: 500},
+		{Kind: models.MovementPayOut, Amount: 2000},
+	}
+	payments := []models.Payment{
+		{Tender: models.TenderCash, Amount: 1250, Tendered: 2000, Change: 750},
+		{Tender: models.TenderCard, Amount: 3000},
+		{Tender: models.TenderCash, Amount: -250},
+	}
+	counts := []models.DenominationCount{{Denomination: 5000, Quantity: 1}, {Denomination: 1000, Quantity: 4}}
+
+	Close(&session, movements, payments, counts)
+
+	assert.Equal(t, models.Money(9500), session.ExpectedCash, "Card payments should not be expected in the drawer")
+	assert.Equal(t, models.Money(9000), session.CountedCash)
+	assert.Equal(t, models.Money(-500), session.Variance)
+}
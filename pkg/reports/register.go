@@ -0,0 +1,66 @@
+package reports
+
+import (
+	"sort"
+
+	"postui_api/pkg/models"
+)
+
+// Register builds the X or Z report of orders, the orders of a cashout
+// number not closed yet, from their lines and payments. Voided orders are
+// only counted as voids; their lines and payments are left out.
+func Register(cashoutNumber uint, orders []models.Order, lines []models.OrderLine, payments []models.Payment) models.RegisterReport {
+	report := models.RegisterReport{CashoutNumber: cashoutNumber}
+
+	var sold []models.Order
+	soldOrders := make(map[uint]bool)
+
+	for _, order := range orders {
+		if order.Status == models.OrderVoided {
+			report.VoidsCount++
+			report.Voids += order.Total
+			continue
+		}
+		if order.Status != models.OrderPaid && order.Status != models.OrderRefunded {
+			continue
+		}
+
+		sold = append(sold, order)
+		soldOrders[order.ID] = true
+	}
+
+	var counted []models.OrderLine
+	for _, line := range lines {
+		if line.OrderID != nil && soldOrders[*line.OrderID] {
+			counted = append(counted, line)
+		}
+	}
+
+	report.SalesSummary = Sales(sold, counted)
+	report.Tenders = tenderTotals(payments, soldOrders)
+
+	return report
+}
+
+// tenderTotals sums the payments of orders per tender, sorted by tender
+func tenderTotals(payments []models.Payment, orders map[uint]bool) []models.TenderTotal {
+	totals := make(map[models.Tender]models.TenderTotal)
+	for _, payment := range payments {
+		if !orders[payment.OrderID] {
+			continue
+		}
+		total := totals[payment.Tender]
+		total.Tender = payment.Tender
+		total.Count++
+		total.Amount += payment.Amount
+		totals[payment.Tender] = total
+	}
+
+	tenders := make([]models.TenderTotal, 0, len(totals))
+	for _, total := range totals {
+		tenders = append(tenders, total)
+	}
+	sort.Slice(tenders, func(i, j int) bool { return tenders[i].Tender < tenders[j].Tender })
+
+	return tenders
+}
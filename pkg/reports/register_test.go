@@ -0,0 +1,81 @@
+package reports
+
+import (
+	"postui_api/pkg/models"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func orderID(id uint) *uint { return &id }
+
+func registerFixture() ([]models.Order, []models.OrderLine, []models.Payment) {
+	refundOf := uint(1)
+	orders := []models.Order{
+		{ID: 1, Total: 363, Currency: "EUR", Status: models.OrderRefunded},
+		{ID: 2, Total: 550, Currency: "EUR", Status: models.OrderPaid},
+		{ID: 3, Total: 1000, Currency: "EUR", Status: models.OrderVoided},
+		{ID: 4, Total: -363, Currency: "EUR", Status: models.OrderPaid, RefundOfID: &refundOf},
+		{ID: 5, Total: 200, Currency: "EUR", Status: models.OrderOpen},
+	}
+	lines := []models.OrderLine{
+		{ID: 1, OrderID: orderID(1), Vat: 2100, Total: 363},
+		{ID: 2, OrderID: orderID(2), Vat: 1000, Total: 550},
+		{ID: 3, OrderID: orderID(3), Vat: 2100, Total: 1000},
+		{ID: 4, OrderID: orderID(4), Vat: 2100, Total: -363},
+		{ID: 5, OrderID: orderID(5), Vat: 2100, Total: 200},
+	}
+	payments := []models.Payment{
+		{OrderID: 1, Tender: models.TenderCash, Amount: 363},
+		{OrderID: 2, Tender: models.TenderCard, Amount: 300},
+		{OrderID: 2, Tender: models.TenderCash, Amount: 250},
+		{OrderID: 4, Tender: models.TenderCash, Amount: -363},
+		{OrderID: 5, Tender: models.TenderCard, Amount: 200},
+	}
+	return orders, lines, payments
+}
+
+func TestRegister(t *testing.T) {
+	orders, lines, payments := registerFixture()
+
+	report := Register(1, orders, lines, payments)
+
+	assert.Equal(t, uint(1), report.CashoutNumber)
+	assert.Equal(t, 2, report.SalesCount)
+	assert.Equal(t, models.Money(913), report.GrossSales)
+	assert.Equal(t, 1, report.RefundsCount)
+	assert.Equal(t, models.Money(363), report.Refunds)
+	assert.Equal(t, models.Money(550), report.NetSales)
+	assert.Equal(t, 1, report.VoidsCount)
+	assert.Equal(t, models.Money(1000), report.Voids)
+	assert.Equal(t, []models.TaxLine{
+		{Vat: 1000, Base: 500, Tax: 50, Total: 550},
+		{Vat: 2100, Base: 0, Tax: 0, Total: 0},
+	}, report.Taxes, "Voided and open orders should be left out of the taxes")
+	assert.Equal(t, []models.TenderTotal{
+		{Tender: models.TenderCard, Count: 1, Amount: 300},
+		{Tender: models.TenderCash, Count: 3, Amount: 250},
+	}, report.Tenders)
+}
+
+func TestText(t *testing.T) {
+	orders, lines, payments := registerFixture()
+	report := Register(1, orders, lines, payments)
+	report.Number = 12
+	report.From = time.Date(2026, 10, 17, 8, 0, 0, 0, time.UTC)
+	report.To = time.Date(2026, 10, 17, 20, 30, 0, 0, time.UTC)
+
+	text := Text(report, 32)
+
+	assert.True(t, strings.HasPrefix(text, "          Z REPORT #12\n"))
+	assert.Contains(t, text, "From            2026-10-17 08:00\n")
+	assert.Contains(t, text, "Sales (2)                   9.13\n")
+	assert.Contains(t, text, "Refunds (1)                -3.63\n")
+	assert.Contains(t, text, "VAT 10.00%\n")
+	assert.Contains(t, text, "Cash (3)                    2.50\n")
+	for _, line := range strings.Split(strings.TrimSuffix(text, "\n"), "\n") {
+		assert.LessOrEqual(t, len(line), 32, line)
+	}
+}
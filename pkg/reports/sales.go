@@ -0,0 +1,49 @@
+// Package reports aggregates orders into the figures shown on sales reports.
+package reports
+
+import (
+	"postui_api/pkg/models"
+	"postui_api/pkg/pricing"
+)
+
+// Sales summarizes orders and their lines. Refund orders count as refunds
+// and their negative amounts are subtracted from the sales, as are the
+// discounts of refunded lines. Taxes add up the breakdowns stored with the
+// orders, so that each sale counts at the rates it was made at.
+func Sales(orders []models.Order, lines []models.OrderLine) models.SalesSummary {
+	summary := models.SalesSummary{Currency: models.DefaultCurrency}
+	breakdowns := make([][]models.TaxLine, 0, len(orders)+1)
+	stored := make(map[uint]bool)
+
+	for i, order := range orders {
+		if i == 0 && order.Currency != "" {
+			summary.Currency = order.Currency
+		}
+		if order.Taxes != nil {
+			breakdowns = append(breakdowns, order.Taxes)
+			stored[order.ID] = true
+		}
+
+		if order.RefundOfID != nil {
+			summary.RefundsCount++
+			summary.Refunds -= order.Total
+			continue
+		}
+		summary.SalesCount++
+		summary.GrossSales += order.Total
+	}
+
+	summary.NetSales = summary.GrossSales - summary.Refunds
+	// Orders recorded before breakdowns were stored are broken down from
+	// their lines
+	var unstored []models.OrderLine
+	for _, line := range lines {
+		summary.Discounts += line.Discount
+		if line.OrderID == nil || !stored[*line.OrderID] {
+			unstored = append(unstored, line)
+		}
+	}
+	summary.Taxes = pricing.MergeBreakdowns(append(breakdowns, pricing.Breakdown(unstored))...)
+
+	return summary
+}
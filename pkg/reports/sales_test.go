@@ -0,0 +1,57 @@
+package reports
+
+import (
+	"postui_api/pkg/models"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSales(t *testing.T) {
+	originalID := uint(1)
+	orders := []models.Order{
+		{ID: 1, Total: 363, Currency: "EUR"},
+		{ID: 2, Total: 550, Currency: "EUR"},
+		{ID: 3, Total: -121, Currency: "EUR", RefundOfID: &originalID},
+	}
+	lines := []models.OrderLine{
+		{Vat: 2100, Total: 363, Discount: 40},
+		{Vat: 1000, Total: 550},
+		{Vat: 2100, Total: -121, Discount: -10},
+	}
+
+	summary := Sales(orders, lines)
+
+	assert.Equal(t, "EUR", summary.Currency)
+	assert.Equal(t, 2, summary.SalesCount)
+	assert.Equal(t, models.Money(913), summary.GrossSales)
+	assert.Equal(t, 1, summary.RefundsCount)
+	assert.Equal(t, models.Money(121), summary.Refunds)
+	assert.Equal(t, models.Money(792), summary.NetSales)
+	assert.Equal(t, models.Money(30), summary.Discounts, "Discounts of refunded lines should be subtracted")
+	assert.Equal(t, []models.TaxLine{
+		{Vat: 1000, Base: 500, Tax: 50, Total: 550},
+		{Vat: 2100, Base: 200, Tax: 42, Total: 242},
+	}, summary.Taxes)
+}
+
+func TestSalesStoredTaxes(t *testing.T) {
+	soldID, legacyID := uint(1), uint(2)
+	orders := []models.Order{
+		// Sold with the equivalence surcharge, broken down when it was sold
+		{ID: soldID, Total: 1262, Currency: "EUR", Taxes: models.TaxLines{{Vat: 2100, Surcharge: 520, Base: 1000, Tax: 210, SurchargeTax: 52, Total: 1262}}},
+		// Recorded before breakdowns were stored
+		{ID: legacyID, Total: 121, Currency: "EUR"},
+	}
+	lines := []models.OrderLine{
+		{OrderID: &soldID, Vat: 2100, Surcharge: 520, Total: 1262},
+		{OrderID: &legacyID, Vat: 2100, Total: 121},
+	}
+
+	summary := Sales(orders, lines)
+
+	assert.Equal(t, []models.TaxLine{
+		{Vat: 2100, Base: 100, Tax: 21, Total: 121},
+		{Vat: 2100, Surcharge: 520, Base: 1000, Tax: 210, SurchargeTax: 52, Total: 1262},
+	}, summary.Taxes)
+}
@@ -0,0 +1,102 @@
+package reports
+
+import (
+	"fmt"
+	"strings"
+
+	"postui_api/pkg/models"
+)
+
+// textTimeLayout is how dates are printed on text reports
+const textTimeLayout = "2006-01-02 15:04"
+
+// Text lays report out as plain text lines of width characters, ready to be
+// printed on a receipt printer.
+func Text(report models.RegisterReport, width int) string {
+	var b strings.Builder
+
+	title := "X REPORT"
+	if report.Number != 0 {
+		title = fmt.Sprintf("Z REPORT #%d", report.Number)
+	}
+	center(&b, title, width)
+	center(&b, fmt.Sprintf("Cashout %d", report.CashoutNumber), width)
+	row(&b, "From", report.From.Format(textTimeLayout), width)
+	row(&b, "To", report.To.Format(textTimeLayout), width)
+	rule(&b, width)
+
+	row(&b, fmt.Sprintf("Sales (%d)", report.SalesCount), report.GrossSales.String(), width)
+	row(&b, fmt.Sprintf("Refunds (%d)", report.RefundsCount), (-report.Refunds).String(), width)
+	row(&b, "Net sales", report.NetSales.String(), width)
+	if report.Discounts != 0 {
+		row(&b, "  Discounts", report.Discounts.String(), width)
+	}
+	row(&b, fmt.Sprintf("Voids (%d)", report.VoidsCount), report.Voids.String(), width)
+	rule(&b, width)
+
+	for _, tax := range report.Taxes {
+		if tax.Surcharge != 0 {
+			row(&b, fmt.Sprintf("VAT %s%% + surcharge %s%%", models.Money(tax.Vat), models.Money(tax.Surcharge)), "", width)
+		} else {
+			row(&b, fmt.Sprintf("VAT %s%%", models.Money(tax.Vat)), "", width)
+		}
+		row(&b, "  Base", tax.Base.String(), width)
+		row(&b, "  Tax", tax.Tax.String(), width)
+		if tax.Surcharge != 0 {
+			row(&b, "  Surcharge", tax.SurchargeTax.String(), width)
+		}
+		row(&b, "  Total", tax.Total.String(), width)
+	}
+	if len(report.Taxes) > 0 {
+		rule(&b, width)
+	}
+
+	for _, tender := range report.Tenders {
+		row(&b, fmt.Sprintf("%s (%d)", tenderLabel(tender.Tender), tender.Count), tender.Amount.String(), width)
+	}
+	row(&b, "Currency", report.Currency, width)
+
+	return b.String()
+}
+
+// tenderLabel returns the printed name of tender
+func tenderLabel(tender models.Tender) string {
+	label := strings.ReplaceAll(string(tender), "_", " ")
+	if label == "" {
+		return label
+	}
+	return strings.ToUpper(label[:1]) + label[1:]
+}
+
+// row writes label on the left and value on the right of a line
+func row(b *strings.Builder, label, value string, width int) {
+	if value == "" {
+		b.WriteString(label)
+		b.WriteString("\n")
+		return
+	}
+
+	padding := width - len(label) - len(value)
+	if padding < 1 {
+		padding = 1
+	}
+	b.WriteString(label)
+	b.WriteString(strings.Repeat(" ", padding))
+	b.WriteString(value)
+	b.WriteString("\n")
+}
+
+// center writes text centered on a line
+func center(b *strings.Builder, text string, width int) {
+	if padding := (width - len(text)) / 2; padding > 0 {
+		b.WriteString(strings.Repeat(" ", padding))
+	}
+	b.WriteString(text)
+	b.WriteString("\n")
+}
+
+// rule writes a line of dashes
+func rule(b *strings.Builder, width int) {
+	b.WriteString(strings.Repeat("-", width))
+	b.WriteString("\n")
+}